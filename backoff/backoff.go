@@ -0,0 +1,80 @@
+/*
+Package backoff contains the backoff and retry primitives used by the dataloader to retry failed batches.
+They are exported so that batch functions and adapters (e.g. retrying unprocessed keys or rate limited
+requests) share one implementation.
+
+	b := backoff.Exponential{Base: 10 * time.Millisecond, Max: time.Second, Jitter: 0.2}
+	err := backoff.Retry(ctx, b, 3, func() error {
+		return fetch(ctx, keys)
+	})
+*/
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the duration to wait before a retry attempt
+type Backoff interface {
+	// Delay returns the duration to wait before the retry attempt (starting at 1)
+	Delay(attempt int) time.Duration
+}
+
+// Exponential is a backoff which doubles the Base duration for every attempt, up to Max. Jitter is the
+// fraction (0 - 1) of each delay which is randomized, spreading out retries from concurrent callers. With
+// a Jitter of 0.2 each delay is between 80% and 100% of the exponential delay. A Max of 0 means the delay
+// is not capped.
+type Exponential struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter float64
+}
+
+// Delay returns the duration to wait before the retry attempt (starting at 1)
+func (e Exponential) Delay(attempt int) time.Duration {
+	d := e.Base
+	for i := 1; i < attempt && (e.Max <= 0 || d < e.Max); i++ {
+		d *= 2
+	}
+	if e.Max > 0 && d > e.Max {
+		d = e.Max
+	}
+
+	if e.Jitter > 0 && d > 0 {
+		jitter := e.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		d -= time.Duration(rand.Float64() * jitter * float64(d))
+	}
+	return d
+}
+
+// Sleep waits for the duration and returns the contexts error if the context is done first
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retry calls fn until it returns nil, retrying up to attempts times and waiting for the backoff delay
+// before each retry. The error of the last call is returned once the retries are exhausted, or the
+// contexts error if the context is done while waiting.
+func Retry(ctx context.Context, b Backoff, attempts int, fn func() error) error {
+	err := fn()
+	for attempt := 1; attempt <= attempts && err != nil; attempt++ {
+		if sleepErr := Sleep(ctx, b.Delay(attempt)); sleepErr != nil {
+			return sleepErr
+		}
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/backoff"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExponentialDelay ensures delays double up to the max
+func TestExponentialDelay(t *testing.T) {
+	// setup
+	b := backoff.Exponential{Base: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+
+	// assert
+	assert.Equal(t, 10*time.Millisecond, b.Delay(1), "Expected the base delay for the first attempt")
+	assert.Equal(t, 20*time.Millisecond, b.Delay(2), "Expected the delay to double")
+	assert.Equal(t, 40*time.Millisecond, b.Delay(3), "Expected the delay to double")
+	assert.Equal(t, 50*time.Millisecond, b.Delay(4), "Expected the delay to be capped")
+	assert.Equal(t, 50*time.Millisecond, b.Delay(100), "Expected the delay to be capped")
+}
+
+// TestExponentialJitter ensures jittered delays stay within the jitter fraction
+func TestExponentialJitter(t *testing.T) {
+	// setup
+	b := backoff.Exponential{Base: 100 * time.Millisecond, Max: time.Second, Jitter: 0.2}
+
+	// assert
+	for i := 0; i < 100; i++ {
+		d := b.Delay(2)
+		assert.True(t, d >= 160*time.Millisecond && d <= 200*time.Millisecond, "Expected delay within jitter")
+	}
+}
+
+// TestRetry ensures fn is retried until it succeeds or the attempts are exhausted
+func TestRetry(t *testing.T) {
+	// setup
+	b := backoff.Exponential{Base: time.Millisecond, Max: time.Millisecond}
+	errFailed := errors.New("failed")
+	calls := 0
+
+	// invoke
+	err := backoff.Retry(context.Background(), b, 3, func() error {
+		calls++
+		if calls < 2 {
+			return errFailed
+		}
+		return nil
+	})
+
+	// assert
+	assert.NoError(t, err, "Expected the retry to succeed")
+	assert.Equal(t, 2, calls, "Expected a single retry")
+
+	calls = 0
+	err = backoff.Retry(context.Background(), b, 2, func() error {
+		calls++
+		return errFailed
+	})
+	assert.Equal(t, errFailed, err, "Expected the last error")
+	assert.Equal(t, 3, calls, "Expected the call and two retries")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = backoff.Retry(ctx, b, 2, func() error { return errFailed })
+	assert.Equal(t, context.Canceled, err, "Expected the context error")
+}
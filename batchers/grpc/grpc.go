@@ -0,0 +1,173 @@
+/*
+Package grpc contains a batch function which loads the keys of a batch from a gRPC service exposing a
+GetMany RPC with a single call.
+
+The batch function is independent of the generated code of the service. It converts the keys of a batch
+into the request message, invokes the RPC and converts the response message back into a ResultMap:
+
+	batch := grpcbatch.New(
+		func(keys dataloader.Keys) interface{} {
+			return &pb.GetUsersRequest{Ids: keys.StringKeys()}
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return client.GetUsers(ctx, req.(*pb.GetUsersRequest))
+		},
+		func(resp interface{}) (dataloader.ResultMap, error) {
+			users := resp.(*pb.GetUsersResponse).Users
+			results := dataloader.NewResultMap(len(users))
+			for _, u := range users {
+				results.Set(dataloader.StringKey(u.Id), dataloader.Result{Result: u, Err: nil})
+			}
+			return results, nil
+		},
+	)
+
+The context of the batch is passed to the call so its deadline is propagated to the service. Keys missing
+from the response resolve to ErrNotFound.
+*/
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// ErrNotFound is the error of the keys missing from the response
+var ErrNotFound = errors.New("grpc: no result in response")
+
+// RequestFunc converts the keys of a batch into the request message
+type RequestFunc func(keys dataloader.Keys) interface{}
+
+// CallFunc invokes the GetMany RPC with the request message and returns the response message
+type CallFunc func(ctx context.Context, req interface{}) (interface{}, error)
+
+// ResponseFunc converts the response message into the results of the keys
+type ResponseFunc func(resp interface{}) (dataloader.ResultMap, error)
+
+// options contains the configuration of the gRPC batch function
+type options struct {
+	timeout    time.Duration
+	missingErr error
+	logger     logger.Logger
+}
+
+// Option accepts the batch function options and sets an option on them.
+type Option func(*options)
+
+// New returns a batch function which loads the keys of each batch with a single call. If the call or
+// converting the response fails, each key of the batch resolves to the error.
+func New(request RequestFunc, call CallFunc, response ResponseFunc, opts ...Option) dataloader.BatchFunction {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if keys.IsEmpty() {
+			empty := dataloader.NewResultMap(0)
+			return &empty
+		}
+
+		// don't call the service once the deadline of the batch has passed
+		if err := ctx.Err(); err != nil {
+			return errorResultMap(keys, err)
+		}
+
+		if o.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+		}
+
+		results, err := o.getMany(ctx, keys, request, call, response)
+		if err != nil {
+			o.logger.Error("get many failed", "keys", keys.Length(), "error", err)
+			return errorResultMap(keys, err)
+		}
+
+		if o.missingErr != nil {
+			for _, k := range keys.StringKeys() {
+				if _, ok := results[k]; !ok {
+					results[k] = dataloader.Result{Result: nil, Err: o.missingErr}
+				}
+			}
+		}
+		return &results
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithTimeout sets the maximum duration of a call. A shorter deadline of the batch context takes precedence.
+// Defaults to the deadline of the batch context.
+func WithTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.timeout = t
+	}
+}
+
+// WithMissingError sets the error of the keys missing from the response. A nil error leaves the keys out of
+// the ResultMap. Defaults to ErrNotFound.
+func WithMissingError(err error) Option {
+	return func(o *options) {
+		o.missingErr = err
+	}
+}
+
+// WithLogger configures the logger for the batch function. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ============================================== private =============================================
+
+// getMany invokes the RPC with the keys and converts the response into a ResultMap
+func (o options) getMany(
+	ctx context.Context,
+	keys dataloader.Keys,
+	request RequestFunc,
+	call CallFunc,
+	response ResponseFunc,
+) (dataloader.ResultMap, error) {
+	resp, err := call(ctx, request(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := response(resp)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = dataloader.NewResultMap(keys.Length())
+	}
+	return results, nil
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the batch function options
+func formatOptions(opts *options) {
+	opts.missingErr = ErrNotFound
+	opts.logger = logger.Noop()
+}
+
+// errorResultMap returns a ResultMap which resolves every key to the error
+func errorResultMap(keys dataloader.Keys, err error) *dataloader.ResultMap {
+	r := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = dataloader.Result{Result: nil, Err: err}
+	}
+	return &r
+}
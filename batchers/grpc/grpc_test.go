@@ -0,0 +1,179 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	grpcbatch "github.com/andy9775/dataloader/batchers/grpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== fake messages ==============================================
+
+type getUsersRequest struct {
+	IDs []string
+}
+
+type user struct {
+	ID   string
+	Name string
+}
+
+type getUsersResponse struct {
+	Users []user
+}
+
+// fakeService serves the users of the request and records the requests and contexts of the calls
+type fakeService struct {
+	users    map[string]user
+	err      error
+	requests []*getUsersRequest
+	ctx      context.Context
+}
+
+func (s *fakeService) GetUsers(ctx context.Context, req *getUsersRequest) (*getUsersResponse, error) {
+	s.requests = append(s.requests, req)
+	s.ctx = ctx
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	resp := &getUsersResponse{}
+	for _, id := range req.IDs {
+		if u, ok := s.users[id]; ok {
+			resp.Users = append(resp.Users, u)
+		}
+	}
+	return resp, nil
+}
+
+// =============================================== test helpers ==============================================
+
+// newBatch returns a batch function which loads the users from the service
+func newBatch(s *fakeService, opts ...grpcbatch.Option) dataloader.BatchFunction {
+	return grpcbatch.New(
+		func(keys dataloader.Keys) interface{} {
+			return &getUsersRequest{IDs: keys.StringKeys()}
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.GetUsers(ctx, req.(*getUsersRequest))
+		},
+		func(resp interface{}) (dataloader.ResultMap, error) {
+			users := resp.(*getUsersResponse).Users
+			results := dataloader.NewResultMap(len(users))
+			for _, u := range users {
+				results.Set(dataloader.StringKey(u.ID), dataloader.Result{Result: u.Name, Err: nil})
+			}
+			return results, nil
+		},
+		opts...,
+	)
+}
+
+// ================================================== tests ==================================================
+
+// TestBatch ensures the keys are loaded with a single call and keys missing from the response resolve to
+// ErrNotFound
+func TestBatch(t *testing.T) {
+	// setup
+	s := &fakeService{users: map[string]user{"1": {ID: "1", Name: "alice"}, "3": {ID: "3", Name: "carol"}}}
+	batch := newBatch(s)
+
+	// invoke
+	r := batch(
+		context.Background(),
+		dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3")),
+	)
+
+	// assert
+	assert.Len(t, s.requests, 1, "Expected a single call")
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, s.requests[0].IDs, "Expected the keys in the request")
+	assert.Equal(t, 3, r.Length(), "Expected a result for each key")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the converted value")
+	assert.Equal(t, "carol", r.GetValueForString("3").Result, "Expected the converted value")
+	assert.Equal(t, grpcbatch.ErrNotFound, r.GetValueForString("2").Err, "Expected the missing key error")
+}
+
+// TestBatchMissingError ensures the error of missing keys is configurable
+func TestBatchMissingError(t *testing.T) {
+	// setup
+	s := &fakeService{users: map[string]user{"1": {ID: "1", Name: "alice"}}}
+	batch := newBatch(s, grpcbatch.WithMissingError(nil))
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 1, r.Length(), "Expected the missing key to be left out")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the converted value")
+}
+
+// TestBatchCallError ensures each key resolves to the error of a failed call
+func TestBatchCallError(t *testing.T) {
+	// setup
+	callErr := errors.New("rpc error: code = Unavailable")
+	batch := newBatch(&fakeService{err: callErr})
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.Equal(t, callErr, r.GetValueForString(k).Err, "Expected the call error")
+	}
+}
+
+// TestBatchDeadline ensures the deadline of the batch context is propagated to the call
+func TestBatchDeadline(t *testing.T) {
+	// setup
+	s := &fakeService{}
+	batch := newBatch(s)
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	// invoke
+	batch(ctx, dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	d, ok := s.ctx.Deadline()
+	assert.True(t, ok, "Expected the call to have a deadline")
+	assert.Equal(t, deadline, d, "Expected the deadline of the batch context")
+}
+
+// TestBatchTimeout ensures the timeout shortens the deadline of the call
+func TestBatchTimeout(t *testing.T) {
+	// setup
+	s := &fakeService{}
+	batch := newBatch(s, grpcbatch.WithTimeout(time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	// invoke
+	batch(ctx, dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	d, ok := s.ctx.Deadline()
+	assert.True(t, ok, "Expected the call to have a deadline")
+	assert.True(t, time.Until(d) <= time.Second, "Expected the timeout to shorten the deadline")
+}
+
+// TestBatchExpired ensures the service is not called once the batch context is done
+func TestBatchExpired(t *testing.T) {
+	// setup
+	s := &fakeService{}
+	batch := newBatch(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// invoke
+	r := batch(ctx, dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	assert.Empty(t, s.requests, "Expected no call")
+	assert.Equal(t, context.Canceled, r.GetValueForString("1").Err, "Expected the context error")
+}
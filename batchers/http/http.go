@@ -0,0 +1,242 @@
+/*
+Package http contains a batch function which loads the keys of a batch from a remote JSON endpoint with a
+single request.
+
+The batch function POSTs the raw values of the keys as {"ids": [...]} and expects a response of the form
+
+	{
+		"data":   {"1": {"name": "alice"}, "3": {"name": "carol"}},
+		"errors": {"2": "user is disabled"}
+	}
+
+where the results and errors are keyed by the Key.String() values. Keys with an error resolve to a
+KeyError and keys missing from both objects resolve to ErrNotFound. A failed request or an unsuccessful
+status code resolves every key of the batch to the error.
+
+	batch := httpbatch.New("https://users.internal/batch", httpbatch.WithTimeout(time.Second))
+*/
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// ErrNotFound is the error of the keys missing from the response
+var ErrNotFound = errors.New("http: no result in response")
+
+// Doer sends HTTP requests. It is implemented by *http.Client.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// DecodeFunc decodes the JSON value of a key
+type DecodeFunc func(key dataloader.Key, data json.RawMessage) (interface{}, error)
+
+// KeyError is the error the endpoint returned for a key
+type KeyError struct {
+	Key     string
+	Message string
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("http: key %s: %s", e.Key, e.Message)
+}
+
+// StatusError is the error of a response with an unsuccessful status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// request is the body of the batch request
+type request struct {
+	IDs []interface{} `json:"ids"`
+}
+
+// response is the body of the batch response
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors map[string]string          `json:"errors"`
+}
+
+// options contains the configuration of the HTTP batch function
+type options struct {
+	client  Doer
+	timeout time.Duration
+	header  http.Header
+	decode  DecodeFunc
+	logger  logger.Logger
+}
+
+// Option accepts the batch function options and sets an option on them.
+type Option func(*options)
+
+// New returns a batch function which requests the keys of each batch from the endpoint at the url and
+// assembles the ResultMap from the response.
+func New(url string, opts ...Option) dataloader.BatchFunction {
+	// default options
+	o := options{header: http.Header{}}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if keys.IsEmpty() {
+			empty := dataloader.NewResultMap(0)
+			return &empty
+		}
+
+		if o.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+		}
+
+		resp, err := o.fetch(ctx, url, keys)
+		if err != nil {
+			o.logger.Error("request failed", "url", url, "keys", keys.Length(), "error", err)
+			return errorResultMap(keys, err)
+		}
+		return o.results(keys, resp)
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithClient sets the client which sends the requests. Defaults to http.DefaultClient.
+func WithClient(c Doer) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithTimeout sets the maximum duration of a request. Defaults to the deadline of the batch context.
+func WithTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.timeout = t
+	}
+}
+
+// WithHeader adds a header sent with each request, e.g. for authentication
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Add(key, value)
+	}
+}
+
+// WithDecoder sets the function which decodes the JSON value of each key. Defaults to decoding into an
+// interface{}.
+func WithDecoder(fn DecodeFunc) Option {
+	return func(o *options) {
+		o.decode = fn
+	}
+}
+
+// WithLogger configures the logger for the batch function. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ============================================== private =============================================
+
+// fetch posts the raw values of the keys to the endpoint and decodes the response
+func (o options) fetch(ctx context.Context, url string, keys dataloader.Keys) (*response, error) {
+	body, err := json.Marshal(request{IDs: keys.Keys()})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range o.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := o.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &StatusError{StatusCode: res.StatusCode}
+	}
+
+	var resp response
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("http: unable to decode response: %v", err)
+	}
+	return &resp, nil
+}
+
+// results maps the results and errors of the response to the keys
+func (o options) results(keys dataloader.Keys, resp *response) *dataloader.ResultMap {
+	strs := keys.StringKeys()
+
+	results := dataloader.NewResultMap(len(strs))
+	for i, raw := range keys.Keys() {
+		k := strs[i]
+		if msg, ok := resp.Errors[k]; ok {
+			results[k] = dataloader.Result{Result: nil, Err: &KeyError{Key: k, Message: msg}}
+			continue
+		}
+
+		data, ok := resp.Data[k]
+		if !ok {
+			results[k] = dataloader.Result{Result: nil, Err: ErrNotFound}
+			continue
+		}
+
+		key, ok := raw.(dataloader.Key)
+		if !ok {
+			key = dataloader.StringKey(k)
+		}
+		v, err := o.decode(key, data)
+		results[k] = dataloader.Result{Result: v, Err: err}
+	}
+	return &results
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the batch function options
+func formatOptions(opts *options) {
+	opts.client = http.DefaultClient
+	opts.decode = func(_ dataloader.Key, data json.RawMessage) (interface{}, error) {
+		var v interface{}
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}
+	opts.logger = logger.Noop()
+}
+
+// errorResultMap returns a ResultMap which resolves every key to the error
+func errorResultMap(keys dataloader.Keys, err error) *dataloader.ResultMap {
+	r := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = dataloader.Result{Result: nil, Err: err}
+	}
+	return &r
+}
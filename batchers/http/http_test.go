@@ -0,0 +1,169 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	httpbatch "github.com/andy9775/dataloader/batchers/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// request records the requests received by a test server
+type request struct {
+	header http.Header
+	ids    []interface{}
+}
+
+// newServer returns a server which responds to each request with the handler and records the requests
+func newServer(handler func(w http.ResponseWriter, ids []interface{})) (*httptest.Server, *[]request) {
+	var requests []request
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			IDs []interface{} `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, request{header: r.Header, ids: body.IDs})
+		handler(w, body.IDs)
+	}))
+	return s, &requests
+}
+
+// respond writes the response with the users and errors
+func respond(w http.ResponseWriter, data map[string]interface{}, errs map[string]string) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+}
+
+// ================================================== tests ==================================================
+
+// TestBatch ensures the keys are requested with a single request and mapped to their results and errors
+func TestBatch(t *testing.T) {
+	// setup
+	s, requests := newServer(func(w http.ResponseWriter, _ []interface{}) {
+		respond(w, map[string]interface{}{"1": "alice", "3": "carol"}, map[string]string{"2": "user is disabled"})
+	})
+	defer s.Close()
+	batch := httpbatch.New(s.URL)
+
+	// invoke
+	r := batch(
+		context.Background(),
+		dataloader.NewKeysWith(
+			dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3"), dataloader.StringKey("4"),
+		),
+	)
+
+	// assert
+	assert.Len(t, *requests, 1, "Expected a single request")
+	assert.Equal(t, []interface{}{"1", "2", "3", "4"}, (*requests)[0].ids, "Expected the keys in the request")
+	assert.Equal(t, "application/json", (*requests)[0].header.Get("Content-Type"), "Expected a JSON request")
+	assert.Equal(t, 4, r.Length(), "Expected a result for each key")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the decoded value")
+	assert.Equal(t, "carol", r.GetValueForString("3").Result, "Expected the decoded value")
+	assert.Equal(
+		t,
+		&httpbatch.KeyError{Key: "2", Message: "user is disabled"},
+		r.GetValueForString("2").Err,
+		"Expected the error of the key",
+	)
+	assert.Equal(t, httpbatch.ErrNotFound, r.GetValueForString("4").Err, "Expected the missing key error")
+}
+
+// TestBatchOptions ensures the headers and decoding of the values are configurable
+func TestBatchOptions(t *testing.T) {
+	// setup
+	type user struct {
+		Name string `json:"name"`
+	}
+	s, requests := newServer(func(w http.ResponseWriter, _ []interface{}) {
+		respond(w, map[string]interface{}{"1": user{Name: "alice"}}, nil)
+	})
+	defer s.Close()
+	batch := httpbatch.New(
+		s.URL,
+		httpbatch.WithHeader("Authorization", "Bearer token"),
+		httpbatch.WithDecoder(func(_ dataloader.Key, data json.RawMessage) (interface{}, error) {
+			var u user
+			err := json.Unmarshal(data, &u)
+			return u, err
+		}),
+	)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	assert.Equal(t, "Bearer token", (*requests)[0].header.Get("Authorization"), "Expected the configured header")
+	assert.Equal(t, user{Name: "alice"}, r.GetValueForString("1").Result, "Expected the custom decoded value")
+}
+
+// TestBatchStatusError ensures each key resolves to a StatusError for an unsuccessful response
+func TestBatchStatusError(t *testing.T) {
+	// setup
+	s, _ := newServer(func(w http.ResponseWriter, _ []interface{}) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer s.Close()
+	batch := httpbatch.New(s.URL)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.Equal(
+			t,
+			&httpbatch.StatusError{StatusCode: http.StatusServiceUnavailable},
+			r.GetValueForString(k).Err,
+			"Expected the status error",
+		)
+	}
+}
+
+// TestBatchTimeout ensures each key resolves to an error when the request exceeds the timeout
+func TestBatchTimeout(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	s, _ := newServer(func(w http.ResponseWriter, _ []interface{}) {
+		<-release
+	})
+	defer s.Close()
+	defer close(release)
+	batch := httpbatch.New(s.URL, httpbatch.WithTimeout(10*time.Millisecond))
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	assert.Error(t, r.GetValueForString("1").Err, "Expected the request to time out")
+	assert.Nil(t, r.GetValueForString("1").Result, "Expected no result")
+}
+
+// TestBatchClient ensures the requests are sent with the configured client
+func TestBatchClient(t *testing.T) {
+	// setup
+	clientErr := errors.New("connection refused")
+	client := doerFunc(func(*http.Request) (*http.Response, error) {
+		return nil, clientErr
+	})
+	batch := httpbatch.New("http://users.invalid/batch", httpbatch.WithClient(client))
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	assert.Equal(t, clientErr, r.GetValueForString("1").Err, "Expected the client error")
+}
+
+// doerFunc implements Doer with a function
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(r *http.Request) (*http.Response, error) { return fn(r) }
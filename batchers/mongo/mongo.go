@@ -0,0 +1,185 @@
+/*
+Package mongo contains a batch function which loads the keys of a batch from a MongoDB collection with a
+single {_id: {$in: keys}} query.
+
+The batch function accepts any collection which implements the Collection interface, allowing applications
+to use the driver of their choice. For the official driver the collection is wrapped as:
+
+	type collection struct{ *mongo.Collection }
+
+	func (c collection) Find(ctx context.Context, filter interface{}) (mongobatch.Cursor, error) {
+		return c.Collection.Find(ctx, filter)
+	}
+
+The decode function maps each document to the key it belongs to and its value. Keys without a document
+resolve to ErrNoDocuments.
+*/
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// ErrNoDocuments is the error of the keys without a document
+var ErrNoDocuments = errors.New("mongo: no documents in result")
+
+// Collection finds the documents matching a filter
+type Collection interface {
+	// Find returns a cursor over the documents matching the filter
+	Find(ctx context.Context, filter interface{}) (Cursor, error)
+}
+
+// Cursor iterates over the documents returned by Find. It is implemented by *mongo.Cursor of the official
+// driver.
+type Cursor interface {
+	// Next advances the cursor to the next document and returns false once the documents are exhausted or
+	// an error occurred
+	Next(ctx context.Context) bool
+	// Decode decodes the current document into the value
+	Decode(v interface{}) error
+	// Err returns the error which stopped the cursor
+	Err() error
+	// Close closes the cursor
+	Close(ctx context.Context) error
+}
+
+// DecodeFunc decodes the current document and returns the key the document belongs to and its value
+type DecodeFunc func(Cursor) (dataloader.Key, interface{}, error)
+
+// options contains the configuration of the MongoDB batch function
+type options struct {
+	field      string
+	keyValue   func(dataloader.Key) interface{}
+	missingErr error
+	logger     logger.Logger
+}
+
+// Option accepts the batch function options and sets an option on them.
+type Option func(*options)
+
+// New returns a batch function which finds the documents of the keys of each batch in the collection and
+// assembles the ResultMap from the decoded documents. If the query or decoding a document fails, each key
+// of the batch resolves to the error.
+func New(c Collection, decode DecodeFunc, opts ...Option) dataloader.BatchFunction {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if keys.IsEmpty() {
+			empty := dataloader.NewResultMap(0)
+			return &empty
+		}
+
+		results, err := o.find(ctx, c, keys, decode)
+		if err != nil {
+			o.logger.Error("find failed", "keys", keys.Length(), "error", err)
+			return errorResultMap(keys, err)
+		}
+
+		if o.missingErr != nil {
+			for _, k := range keys.StringKeys() {
+				if _, ok := results[k]; !ok {
+					results[k] = dataloader.Result{Result: nil, Err: o.missingErr}
+				}
+			}
+		}
+		return &results
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithField sets the field matched against the keys. Defaults to "_id".
+func WithField(field string) Option {
+	return func(o *options) {
+		o.field = field
+	}
+}
+
+// WithKeyValue sets the function which returns the value matched against the field for a key, e.g. to
+// convert a hex string to an ObjectID. Defaults to the raw value of the key.
+func WithKeyValue(fn func(dataloader.Key) interface{}) Option {
+	return func(o *options) {
+		o.keyValue = fn
+	}
+}
+
+// WithMissingError sets the error of the keys without a document. A nil error leaves the keys out of the
+// ResultMap. Defaults to ErrNoDocuments.
+func WithMissingError(err error) Option {
+	return func(o *options) {
+		o.missingErr = err
+	}
+}
+
+// WithLogger configures the logger for the batch function. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ============================================== private =============================================
+
+// find queries the documents of the keys and decodes them into a ResultMap
+func (o options) find(
+	ctx context.Context,
+	c Collection,
+	keys dataloader.Keys,
+	decode DecodeFunc,
+) (dataloader.ResultMap, error) {
+	values := make([]interface{}, 0, keys.Length())
+	for _, k := range keys.Keys() {
+		if key, ok := k.(dataloader.Key); ok {
+			values = append(values, o.keyValue(key))
+		} else {
+			values = append(values, k)
+		}
+	}
+
+	cursor, err := c.Find(ctx, map[string]interface{}{o.field: map[string]interface{}{"$in": values}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := dataloader.NewResultMap(keys.Length())
+	for cursor.Next(ctx) {
+		key, value, err := decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		results.Set(key, dataloader.Result{Result: value, Err: nil})
+	}
+	return results, cursor.Err()
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the batch function options
+func formatOptions(opts *options) {
+	opts.field = "_id"
+	opts.keyValue = func(key dataloader.Key) interface{} { return key.Raw() }
+	opts.missingErr = ErrNoDocuments
+	opts.logger = logger.Noop()
+}
+
+// errorResultMap returns a ResultMap which resolves every key to the error
+func errorResultMap(keys dataloader.Keys, err error) *dataloader.ResultMap {
+	r := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = dataloader.Result{Result: nil, Err: err}
+	}
+	return &r
+}
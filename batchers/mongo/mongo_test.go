@@ -0,0 +1,154 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	mongobatch "github.com/andy9775/dataloader/batchers/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================= fake collection =============================================
+
+type user struct {
+	ID   string
+	Name string
+}
+
+// fakeCollection serves the users matching the $in filter and records the filter
+type fakeCollection struct {
+	users  map[string]user
+	err    error
+	filter interface{}
+}
+
+func (c *fakeCollection) Find(_ context.Context, filter interface{}) (mongobatch.Cursor, error) {
+	c.filter = filter
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	cursor := &fakeCursor{}
+	for _, v := range filter.(map[string]interface{})["_id"].(map[string]interface{})["$in"].([]interface{}) {
+		if u, ok := c.users[fmt.Sprint(v)]; ok {
+			cursor.docs = append(cursor.docs, u)
+		}
+	}
+	return cursor, nil
+}
+
+type fakeCursor struct {
+	docs    []user
+	current user
+}
+
+func (c *fakeCursor) Next(context.Context) bool {
+	if len(c.docs) == 0 {
+		return false
+	}
+	c.current, c.docs = c.docs[0], c.docs[1:]
+	return true
+}
+
+func (c *fakeCursor) Decode(v interface{}) error {
+	*v.(*user) = c.current
+	return nil
+}
+
+func (c *fakeCursor) Err() error                  { return nil }
+func (c *fakeCursor) Close(context.Context) error { return nil }
+
+// =============================================== test helpers ==============================================
+
+// decodeUser decodes a user document
+func decodeUser(c mongobatch.Cursor) (dataloader.Key, interface{}, error) {
+	var u user
+	err := c.Decode(&u)
+	return dataloader.StringKey(u.ID), u.Name, err
+}
+
+// ================================================== tests ==================================================
+
+// TestBatch ensures the keys are found with a single $in query and keys without a document resolve to
+// ErrNoDocuments
+func TestBatch(t *testing.T) {
+	// setup
+	c := &fakeCollection{users: map[string]user{"1": {ID: "1", Name: "alice"}, "3": {ID: "3", Name: "carol"}}}
+	batch := mongobatch.New(c, decodeUser)
+
+	// invoke
+	r := batch(
+		context.Background(),
+		dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3")),
+	)
+
+	// assert
+	assert.Equal(
+		t,
+		map[string]interface{}{"_id": map[string]interface{}{"$in": []interface{}{
+			dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3"),
+		}}},
+		c.filter,
+		"Expected an $in filter with the keys",
+	)
+	assert.Equal(t, 3, r.Length(), "Expected a result for each key")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the decoded value")
+	assert.Equal(t, "carol", r.GetValueForString("3").Result, "Expected the decoded value")
+	assert.Equal(t, mongobatch.ErrNoDocuments, r.GetValueForString("2").Err, "Expected the missing key error")
+}
+
+// TestBatchOptions ensures the key values and the error of missing keys are configurable
+func TestBatchOptions(t *testing.T) {
+	// setup
+	c := &fakeCollection{users: map[string]user{"id_1": {ID: "1", Name: "alice"}}}
+	batch := mongobatch.New(
+		c,
+		decodeUser,
+		mongobatch.WithKeyValue(func(key dataloader.Key) interface{} { return "id_" + key.String() }),
+		mongobatch.WithMissingError(nil),
+	)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 1, r.Length(), "Expected the missing key to be left out")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the decoded value")
+}
+
+// TestBatchField ensures the keys are matched against the configured field
+func TestBatchField(t *testing.T) {
+	// setup
+	c := &fakeCollection{err: errors.New("stop")}
+	batch := mongobatch.New(c, decodeUser, mongobatch.WithField("user_id"))
+
+	// invoke
+	batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1")))
+
+	// assert
+	assert.Equal(
+		t,
+		map[string]interface{}{"user_id": map[string]interface{}{"$in": []interface{}{dataloader.StringKey("1")}}},
+		c.filter,
+		"Expected the filter to match the field",
+	)
+}
+
+// TestBatchFindError ensures each key resolves to the error of a failed query
+func TestBatchFindError(t *testing.T) {
+	// setup
+	findErr := errors.New("connection refused")
+	batch := mongobatch.New(&fakeCollection{err: findErr}, decodeUser)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.Equal(t, findErr, r.GetValueForString(k).Err, "Expected the find error")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Package sql contains a batch function which loads the keys of a batch with a single SQL query using an IN
+clause.
+
+The query template contains the Placeholder where the bind parameters of the keys are inserted. The raw
+value of each key is passed as a bind parameter and the scan function maps each returned row to the key it
+belongs to and its value. Keys without a row resolve to sql.ErrNoRows.
+
+	import sqlbatch "github.com/andy9775/dataloader/batchers/sql"
+
+	batch := sqlbatch.New(
+		db,
+		"SELECT id, name FROM users WHERE id IN ({{keys}})",
+		func(rows *sql.Rows) (dataloader.Key, interface{}, error) {
+			var id, name string
+			err := rows.Scan(&id, &name)
+			return dataloader.StringKey(id), name, err
+		},
+	)
+	loader := dataloader.NewDataLoader(100, batch, standard.NewStandardStrategy())
+*/
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Placeholder is replaced by the bind parameters of the keys in the query template
+const Placeholder = "{{keys}}"
+
+// Querier executes the query. It is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// ScanFunc scans the current row and returns the key the row belongs to and its value
+type ScanFunc func(*sql.Rows) (dataloader.Key, interface{}, error)
+
+// options contains the configuration of the SQL batch function
+type options struct {
+	bindVar    func(i int) string
+	missingErr error
+	logger     logger.Logger
+}
+
+// Option accepts the batch function options and sets an option on them.
+type Option func(*options)
+
+// New returns a batch function which queries the keys of each batch with the query template and assembles
+// the ResultMap from the scanned rows. If the query or scanning a row fails, each key of the batch resolves
+// to the error.
+func New(db Querier, query string, scan ScanFunc, opts ...Option) dataloader.BatchFunction {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		args := keys.Keys()
+		if len(args) == 0 {
+			empty := dataloader.NewResultMap(0)
+			return &empty
+		}
+
+		results, err := o.query(ctx, db, query, args, scan)
+		if err != nil {
+			o.logger.Error("query failed", "keys", len(args), "error", err)
+			return errorResultMap(keys, err)
+		}
+
+		if o.missingErr != nil {
+			for _, k := range keys.StringKeys() {
+				if _, ok := results[k]; !ok {
+					results[k] = dataloader.Result{Result: nil, Err: o.missingErr}
+				}
+			}
+		}
+		return &results
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithBindVars sets the function which returns the bind parameter for the argument at the index (starting
+// at 1), e.g. Dollar for PostgreSQL. Defaults to Question.
+func WithBindVars(fn func(i int) string) Option {
+	return func(o *options) {
+		o.bindVar = fn
+	}
+}
+
+// WithMissingError sets the error of the keys without a row. A nil error leaves the keys out of the
+// ResultMap. Defaults to sql.ErrNoRows.
+func WithMissingError(err error) Option {
+	return func(o *options) {
+		o.missingErr = err
+	}
+}
+
+// WithLogger configures the logger for the batch function. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// Question returns the "?" bind parameter used by MySQL and SQLite
+func Question(int) string {
+	return "?"
+}
+
+// Dollar returns the "$n" bind parameter used by PostgreSQL
+func Dollar(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+
+// ============================================== private =============================================
+
+// query executes the query with the raw values of the keys and scans the rows into a ResultMap
+func (o options) query(
+	ctx context.Context,
+	db Querier,
+	query string,
+	args []interface{},
+	scan ScanFunc,
+) (dataloader.ResultMap, error) {
+	bindVars := make([]string, len(args))
+	for i := range args {
+		bindVars[i] = o.bindVar(i + 1)
+	}
+
+	query = strings.Replace(query, Placeholder, strings.Join(bindVars, ", "), 1)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := dataloader.NewResultMap(len(args))
+	for rows.Next() {
+		key, value, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		results.Set(key, dataloader.Result{Result: value, Err: nil})
+	}
+	return results, rows.Err()
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the batch function options
+func formatOptions(opts *options) {
+	opts.bindVar = Question
+	opts.missingErr = sql.ErrNoRows
+	opts.logger = logger.Noop()
+}
+
+// errorResultMap returns a ResultMap which resolves every key to the error
+func errorResultMap(keys dataloader.Keys, err error) *dataloader.ResultMap {
+	r := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = dataloader.Result{Result: nil, Err: err}
+	}
+	return &r
+}
@@ -0,0 +1,180 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	sqlbatch "github.com/andy9775/dataloader/batchers/sql"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================================================ fake driver ===============================================
+
+// fakeDB is the data served by the fake driver. Each data source name opens its own fakeDB.
+type fakeDB struct {
+	m     sync.Mutex
+	users map[string]string // id -> name
+	err   error
+
+	query string
+	args  []interface{}
+}
+
+var (
+	dbMutex sync.Mutex
+	dbs     = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+// openDB returns a *sql.DB backed by a fakeDB serving the users
+func openDB(t *testing.T, users map[string]string, err error) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{users: users, err: err}
+
+	dbMutex.Lock()
+	dbs[t.Name()] = fake
+	dbMutex.Unlock()
+
+	db, openErr := sql.Open("fake", t.Name())
+	if openErr != nil {
+		t.Fatal(openErr)
+	}
+	return db, fake
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	dbMutex.Lock()
+	defer dbMutex.Unlock()
+	return &fakeConn{db: dbs[name]}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+// QueryContext records the query and returns a row for each argument matching a user
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.m.Lock()
+	defer c.db.m.Unlock()
+
+	c.db.query = query
+	c.db.args = nil
+	if c.db.err != nil {
+		return nil, c.db.err
+	}
+
+	rows := &fakeRows{}
+	for _, arg := range args {
+		c.db.args = append(c.db.args, arg.Value)
+		id := fmt.Sprint(arg.Value)
+		if name, ok := c.db.users[id]; ok {
+			rows.values = append(rows.values, []driver.Value{id, name})
+		}
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// =============================================== test helpers ==============================================
+
+// scanUser scans the id and name of a user row
+func scanUser(rows *sql.Rows) (dataloader.Key, interface{}, error) {
+	var id, name string
+	err := rows.Scan(&id, &name)
+	return dataloader.StringKey(id), name, err
+}
+
+// ================================================== tests ==================================================
+
+// TestBatch ensures the keys are queried with a single IN clause and keys without a row resolve to
+// sql.ErrNoRows
+func TestBatch(t *testing.T) {
+	// setup
+	db, fake := openDB(t, map[string]string{"1": "alice", "3": "carol"}, nil)
+	defer db.Close()
+	batch := sqlbatch.New(db, "SELECT id, name FROM users WHERE id IN ({{keys}})", scanUser)
+
+	// invoke
+	r := batch(
+		context.Background(),
+		dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3")),
+	)
+
+	// assert
+	assert.Equal(t, "SELECT id, name FROM users WHERE id IN (?, ?, ?)", fake.query, "Expected a bind parameter per key")
+	assert.Equal(t, []interface{}{"1", "2", "3"}, fake.args, "Expected the raw keys as arguments")
+	assert.Equal(t, 3, r.Length(), "Expected a result for each key")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the scanned value")
+	assert.Equal(t, "carol", r.GetValueForString("3").Result, "Expected the scanned value")
+	assert.Equal(t, sql.ErrNoRows, r.GetValueForString("2").Err, "Expected the missing key to resolve to an error")
+}
+
+// TestBatchOptions ensures the bind parameters and the error of missing keys are configurable
+func TestBatchOptions(t *testing.T) {
+	// setup
+	db, fake := openDB(t, map[string]string{"1": "alice"}, nil)
+	defer db.Close()
+	batch := sqlbatch.New(
+		db,
+		"SELECT id, name FROM users WHERE id IN ({{keys}})",
+		scanUser,
+		sqlbatch.WithBindVars(sqlbatch.Dollar),
+		sqlbatch.WithMissingError(nil),
+	)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, "SELECT id, name FROM users WHERE id IN ($1, $2)", fake.query, "Expected numbered bind parameters")
+	assert.Equal(t, 1, r.Length(), "Expected the missing key to be left out")
+	assert.Equal(t, "alice", r.GetValueForString("1").Result, "Expected the scanned value")
+}
+
+// TestBatchQueryError ensures each key resolves to the error of a failed query
+func TestBatchQueryError(t *testing.T) {
+	// setup
+	queryErr := errors.New("connection refused")
+	db, _ := openDB(t, nil, queryErr)
+	defer db.Close()
+	batch := sqlbatch.New(db, "SELECT id, name FROM users WHERE id IN ({{keys}})", scanUser)
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2")))
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.Equal(t, queryErr, r.GetValueForString(k).Err, "Expected the query error")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Package breaker contains a circuit breaker for batch functions.
+
+The circuit breaker tracks consecutive failed batches. Once the failure threshold
+is reached the circuit opens and batches resolve immediately to ErrOpen without
+calling the batch function, giving a failing downstream time to recover. After
+the open timeout a single trial batch is let through (half-open). If it succeeds
+the circuit closes, otherwise it opens again.
+*/
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/logger"
+)
+
+// ErrOpen is returned for each key in a batch while the circuit is open
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of the circuit
+type State int
+
+// Circuit states
+const (
+	Closed   State = iota // batches are passed to the batch function
+	Open                  // batches resolve to ErrOpen
+	HalfOpen              // a single trial batch is passed to the batch function
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// options contains the configuration of the circuit breaker
+type options struct {
+	threshold   int
+	openTimeout time.Duration
+	logger      logger.Logger
+}
+
+// Option accepts the breaker options and sets an option on it.
+type Option func(*options)
+
+// Breaker is a circuit breaker which guards batch functions
+type Breaker interface {
+	// Wrap returns a batch function which calls the provided batch function while the circuit is closed
+	Wrap(dataloader.BatchFunction) dataloader.BatchFunction
+	// State returns the current state of the circuit
+	State() State
+}
+
+// New returns a new instance of a closed circuit breaker
+func New(opts ...Option) Breaker {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return &breaker{options: o}
+}
+
+// ============================================== option setters =============================================
+
+// WithFailureThreshold sets the number of consecutive failed batches which open the circuit. Default is 5.
+func WithFailureThreshold(n int) Option {
+	return func(o *options) {
+		o.threshold = n
+	}
+}
+
+// WithOpenTimeout sets how long the circuit stays open before a trial batch is let through.
+// Default is 30 seconds.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.openTimeout = d
+	}
+}
+
+// WithLogger configures the logger for the breaker. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ===========================================================================================================
+
+type breaker struct {
+	m        sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool // a trial batch is in flight while half-open
+
+	options options
+}
+
+// Wrap returns a batch function guarded by the circuit breaker. A batch fails if the batch function
+// doesn't return a successful result for any of the keys.
+func (b *breaker) Wrap(batch dataloader.BatchFunction) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if !b.allow() {
+			return errorResultMap(keys, ErrOpen)
+		}
+
+		success := false
+		defer func() { b.record(success) }() // a panicking batch still ends the trial and counts as a failure
+
+		r := batch(ctx, keys)
+		success = !failed(keys, r)
+		return r
+	}
+}
+
+// State returns the current state of the circuit
+func (b *breaker) State() State {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.state == Open && time.Since(b.openedAt) >= b.options.openTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// ============================================== private =============================================
+
+// allow returns true if the batch function may be called
+func (b *breaker) allow() bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.options.openTimeout {
+			return false
+		}
+		b.options.logger.Info("circuit half-open, allowing trial batch")
+		b.state = HalfOpen
+		b.trial = true
+		return true
+	case HalfOpen:
+		if b.trial {
+			return false // only a single trial batch at a time
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the circuit with the outcome of a batch
+func (b *breaker) record(success bool) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.trial = false
+	if success {
+		if b.state != Closed {
+			b.options.logger.Info("circuit closed")
+		}
+		b.state = Closed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.options.threshold {
+		b.options.logger.Info("circuit open", "failures", b.failures, "timeout", b.options.openTimeout)
+		b.state = Open
+		b.openedAt = time.Now()
+	}
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the breaker options
+func formatOptions(opts *options) {
+	opts.threshold = 5
+	opts.openTimeout = 30 * time.Second
+	opts.logger = logger.Noop()
+}
+
+// failed returns true if the ResultMap doesn't contain a successful result for any of the keys
+func failed(keys dataloader.Keys, r *dataloader.ResultMap) bool {
+	if r == nil {
+		return !keys.IsEmpty()
+	}
+	for _, k := range keys.StringKeys() {
+		if v, ok := (*r)[k]; ok && v.Err == nil {
+			return false
+		}
+	}
+	return !keys.IsEmpty()
+}
+
+// errorResultMap returns a ResultMap which contains the provided error for each key
+func errorResultMap(keys dataloader.Keys, err error) *dataloader.ResultMap {
+	r := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = dataloader.Result{Result: nil, Err: err}
+	}
+	return &r
+}
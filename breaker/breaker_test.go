@@ -0,0 +1,117 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/breaker"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a batch function which fails while fail returns true
+func getBatchFunction(callCount *int, fail func() bool) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		*callCount += 1
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.StringKeys() {
+			if fail() {
+				m[k] = dataloader.Result{Result: nil, Err: errors.New("unavailable")}
+			} else {
+				m[k] = dataloader.Result{Result: k, Err: nil}
+			}
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestBreakerOpensAndRecovers ensures the circuit opens after the threshold and closes after a trial batch
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	// setup
+	callCount := 0
+	failing := true
+	b := breaker.New(breaker.WithFailureThreshold(2), breaker.WithOpenTimeout(20*time.Millisecond))
+	batch := b.Wrap(getBatchFunction(&callCount, func() bool { return failing }))
+	keys := dataloader.NewKeysWith(dataloader.StringKey("1"))
+	ctx := context.Background()
+
+	// invoke / assert
+	batch(ctx, keys)
+	assert.Equal(t, breaker.Closed, b.State(), "Expected circuit to be closed below the threshold")
+	batch(ctx, keys)
+	assert.Equal(t, breaker.Open, b.State(), "Expected circuit to open at the threshold")
+
+	r := batch(ctx, keys)
+	v, _ := r.GetValue(dataloader.StringKey("1"))
+	assert.Equal(t, breaker.ErrOpen, v.Err, "Expected ErrOpen while the circuit is open")
+	assert.Equal(t, 2, callCount, "Expected batch function not to be called while open")
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, breaker.HalfOpen, b.State(), "Expected circuit to be half-open after the timeout")
+
+	failing = false
+	r = batch(ctx, keys)
+	v, _ = r.GetValue(dataloader.StringKey("1"))
+	assert.NoError(t, v.Err, "Expected result from the trial batch")
+	assert.Equal(t, breaker.Closed, b.State(), "Expected successful trial batch to close the circuit")
+	assert.Equal(t, 3, callCount, "Expected trial batch to call the batch function")
+}
+
+// TestBreakerFailedTrialReopens ensures a failed trial batch opens the circuit again
+func TestBreakerFailedTrialReopens(t *testing.T) {
+	// setup
+	callCount := 0
+	b := breaker.New(breaker.WithFailureThreshold(1), breaker.WithOpenTimeout(10*time.Millisecond))
+	batch := b.Wrap(getBatchFunction(&callCount, func() bool { return true }))
+	keys := dataloader.NewKeysWith(dataloader.StringKey("1"))
+	ctx := context.Background()
+
+	// invoke
+	batch(ctx, keys)
+	time.Sleep(20 * time.Millisecond)
+	batch(ctx, keys) // trial
+
+	// assert
+	assert.Equal(t, breaker.Open, b.State(), "Expected failed trial to open the circuit")
+	assert.Equal(t, 2, callCount, "Expected trial batch to call the batch function")
+}
+
+// TestBreakerPanickingTrialReopens ensures a panicking trial batch opens the circuit again instead of leaving
+// the trial in flight
+func TestBreakerPanickingTrialReopens(t *testing.T) {
+	// setup
+	callCount := 0
+	panicking := true
+	b := breaker.New(breaker.WithFailureThreshold(1), breaker.WithOpenTimeout(10*time.Millisecond))
+	batch := b.Wrap(func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if panicking {
+			callCount += 1
+			panic("trial panic")
+		}
+		return getBatchFunction(&callCount, func() bool { return false })(ctx, keys)
+	})
+	keys := dataloader.NewKeysWith(dataloader.StringKey("1"))
+	ctx := context.Background()
+
+	// invoke / assert
+	assert.Panics(t, func() { batch(ctx, keys) }, "Expected panic to be passed on")
+	assert.Equal(t, breaker.Open, b.State(), "Expected panicking batch to open the circuit")
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Panics(t, func() { batch(ctx, keys) }, "Expected panic to be passed on") // trial
+	assert.Equal(t, breaker.Open, b.State(), "Expected panicking trial to open the circuit")
+
+	time.Sleep(20 * time.Millisecond)
+	panicking = false
+	r := batch(ctx, keys)
+	v, _ := r.GetValue(dataloader.StringKey("1"))
+	assert.NoError(t, v.Err, "Expected a new trial batch after the panicking trial")
+	assert.Equal(t, breaker.Closed, b.State(), "Expected successful trial batch to close the circuit")
+	assert.Equal(t, 3, callCount, "Expected each batch to call the batch function")
+}
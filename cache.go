@@ -2,7 +2,10 @@ package dataloader
 
 import "context"
 
-// Cache provides an interface for caching strategies
+// Cache provides an interface for caching strategies.
+// The DataLoader consults the cache (see WithCache) before passing a key to the strategy. Keys found
+// in the cache are never added to a pending batch, instead the strategies load counter is incremented
+// via LoadNoOp. Results returned by the batch function are stored in the cache.
 type Cache interface {
 	// SetResult sets a single result for a specified key
 	SetResult(context.Context, Key, Result)
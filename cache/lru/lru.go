@@ -0,0 +1,139 @@
+/*
+Package lru contains a least recently used cache implementation.
+
+The lru cache stores up to a maximum number of results. Once the maximum is reached,
+storing a new result evicts the least recently used result. The cache is go routine
+safe.
+*/
+package lru
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/andy9775/dataloader"
+)
+
+// New returns a new instance of the lru cache which stores at most maxEntries results.
+// A maxEntries value <= 0 means the cache is unbounded.
+func New(maxEntries int) dataloader.Cache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+type lruCache struct {
+	m sync.Mutex
+
+	maxEntries int
+	ll         *list.List // front is most recently used
+	items      map[string]*list.Element
+}
+
+type entry struct {
+	key    string
+	result dataloader.Result
+}
+
+// SetResult stores the result for the key, evicting the least recently used result if full
+func (c *lruCache) SetResult(ctx context.Context, key dataloader.Key, result dataloader.Result) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.set(key.String(), result)
+}
+
+// SetResultMap stores each result in the result map
+func (c *lruCache) SetResultMap(ctx context.Context, resultMap dataloader.ResultMap) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for k, v := range resultMap {
+		c.set(k, v)
+	}
+}
+
+// GetResult returns the result for the key and marks it as recently used
+func (c *lruCache) GetResult(ctx context.Context, key dataloader.Key) (dataloader.Result, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.get(key.String())
+}
+
+// GetResultMap returns the results found for the keys. It returns true if a result was found
+// for every key.
+func (c *lruCache) GetResultMap(ctx context.Context, keys ...dataloader.Key) (dataloader.ResultMap, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	found := true
+	result := dataloader.NewResultMap(len(keys))
+	for _, key := range keys {
+		r, ok := c.get(key.String())
+		if !ok {
+			found = false
+			continue
+		}
+		result.Set(key, r)
+	}
+
+	return result, found
+}
+
+// Delete removes the result for the key. It returns false if no result was stored.
+func (c *lruCache) Delete(ctx context.Context, key dataloader.Key) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	e, ok := c.items[key.String()]
+	if !ok {
+		return false
+	}
+
+	c.remove(e)
+	return true
+}
+
+// ClearAll removes every result from the cache
+func (c *lruCache) ClearAll(ctx context.Context) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	return true
+}
+
+// ================================================= helpers =================================================
+
+func (c *lruCache) set(key string, result dataloader.Result) {
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*entry).result = result
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry{key: key, result: result})
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *lruCache) get(key string) (dataloader.Result, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return dataloader.Result{}, false
+	}
+
+	c.ll.MoveToFront(e)
+	return e.Value.(*entry).result, true
+}
+
+func (c *lruCache) remove(e *list.Element) {
+	c.ll.Remove(e)
+	delete(c.items, e.Value.(*entry).key)
+}
@@ -0,0 +1,69 @@
+package lru_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/cache/lru"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// ================================================== tests ==================================================
+
+// TestEvictsLeastRecentlyUsed ensures the least recently used result is evicted once the cache is full
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	cache := lru.New(2)
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+	cache.SetResult(ctx, PrimaryKey(2), dataloader.Result{Result: 2, Err: nil})
+
+	// invoke
+	_, ok := cache.GetResult(ctx, PrimaryKey(1)) // 1 is now most recently used
+	assert.True(t, ok, "Expected result to have been found")
+	cache.SetResult(ctx, PrimaryKey(3), dataloader.Result{Result: 3, Err: nil})
+
+	// assert
+	_, ok = cache.GetResult(ctx, PrimaryKey(2))
+	assert.False(t, ok, "Expected least recently used result to be evicted")
+
+	r, ok := cache.GetResultMap(ctx, PrimaryKey(1), PrimaryKey(3))
+	assert.True(t, ok, "Expected results to have been found")
+	assert.Equal(t, 2, r.Length(), "Expected 2 results")
+}
+
+// TestDeleteAndClearAll ensures results can be removed from the cache
+func TestDeleteAndClearAll(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	cache := lru.New(10)
+	resultMap := dataloader.NewResultMap(2)
+	resultMap.Set(PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+	resultMap.Set(PrimaryKey(2), dataloader.Result{Result: 2, Err: nil})
+	cache.SetResultMap(ctx, resultMap)
+
+	// invoke / assert
+	assert.True(t, cache.Delete(ctx, PrimaryKey(1)), "Expected result to be deleted")
+	assert.False(t, cache.Delete(ctx, PrimaryKey(1)), "Expected no result to delete")
+
+	r, ok := cache.GetResultMap(ctx, PrimaryKey(1), PrimaryKey(2))
+	assert.False(t, ok, "Expected missing result")
+	assert.Equal(t, 1, r.Length(), "Expected 1 result")
+
+	assert.True(t, cache.ClearAll(ctx), "Expected cache to be cleared")
+	_, ok = cache.GetResult(ctx, PrimaryKey(2))
+	assert.False(t, ok, "Expected no results after clearing")
+}
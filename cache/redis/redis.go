@@ -0,0 +1,272 @@
+/*
+Package redis contains a cache implementation backed by redis.
+
+The redis cache stores serialized results keyed by Key.String() allowing multiple
+service instances to share a dataloader cache. The cache accepts any client which
+implements the Client interface, allowing applications to use their redis client
+library of choice. Results are serialized using a pluggable codec (JSON by default)
+and may be stored with a per key TTL.
+*/
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/codec"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Client provides the redis operations required by the cache. Implementations typically wrap a
+// redis client library.
+type Client interface {
+	// Get returns the value stored for the key and true, or false if the key does not exist
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// MGet returns the values stored for the keys in the order of the keys, nil for keys which do not exist
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	// Set stores the value for the key. A ttl of 0 means the value does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del removes the keys
+	Del(ctx context.Context, keys ...string) error
+	// Scan returns a page of about count keys matching the glob style pattern, starting at the cursor, and the
+	// cursor of the next page. Iteration starts and ends with a cursor of 0.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+}
+
+// scanCount is the number of keys requested from each call to Scan
+const scanCount = 100
+
+// options contains the configuration of the redis cache
+type options struct {
+	prefix   string
+	codec    codec.Codec
+	keyCodec codec.KeyCodec
+	ttl      func(dataloader.Key) time.Duration
+	logger   logger.Logger
+}
+
+// Option accepts the cache options and sets an option on it.
+type Option func(*options)
+
+// New returns a new instance of the redis cache using the provided client.
+func New(client Client, opts ...Option) dataloader.Cache {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return &redisCache{client: client, options: o}
+}
+
+// ============================================== option setters =============================================
+
+// WithPrefix sets the prefix prepended to each key stored in redis. Defaults to "dataloader:".
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithCodec sets the codec used to serialize results. Defaults to codec.JSON().
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+// WithKeyCodec sets the codec used to encode keys as redis keys. Defaults to codec.StringKeyCodec().
+// Results stored via SetResultMap are only identified by their Key.String() value and are encoded as
+// dataloader.StringKey values, the codec should therefore encode a key and its string value identically.
+func WithKeyCodec(c codec.KeyCodec) Option {
+	return func(o *options) {
+		o.keyCodec = c
+	}
+}
+
+// WithTTL sets the ttl for every stored result. Defaults to no expiry.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = func(dataloader.Key) time.Duration { return ttl }
+	}
+}
+
+// WithTTLFunc sets a function which returns the ttl for each stored result. Keys passed to the function
+// from SetResultMap are dataloader.StringKey values.
+func WithTTLFunc(fn func(dataloader.Key) time.Duration) Option {
+	return func(o *options) {
+		o.ttl = fn
+	}
+}
+
+// WithLogger configures the logger used to report redis and serialization errors. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ===========================================================================================================
+
+type redisCache struct {
+	client  Client
+	options options
+}
+
+// SetResult serializes and stores the result for the key
+func (c *redisCache) SetResult(ctx context.Context, key dataloader.Key, result dataloader.Result) {
+	data, err := c.options.codec.Marshal(result)
+	if err != nil {
+		c.options.logger.Error("unable to marshal result", "key", key.String(), "error", err)
+		return
+	}
+
+	k, err := c.key(key)
+	if err != nil {
+		c.options.logger.Error("unable to encode key", "error", err)
+		return
+	}
+
+	if err = c.client.Set(ctx, k, data, c.options.ttl(key)); err != nil {
+		c.options.logger.Error("unable to store result", "key", key.String(), "error", err)
+	}
+}
+
+// SetResultMap serializes and stores each result in the result map
+func (c *redisCache) SetResultMap(ctx context.Context, resultMap dataloader.ResultMap) {
+	for k, v := range resultMap {
+		c.SetResult(ctx, dataloader.StringKey(k), v)
+	}
+}
+
+// GetResult returns the result for the key. Redis and serialization errors are treated as a cache miss.
+func (c *redisCache) GetResult(ctx context.Context, key dataloader.Key) (dataloader.Result, bool) {
+	k, err := c.key(key)
+	if err != nil {
+		c.options.logger.Error("unable to encode key", "error", err)
+		return dataloader.Result{}, false
+	}
+
+	data, ok, err := c.client.Get(ctx, k)
+	if err != nil {
+		c.options.logger.Error("unable to get result", "key", key.String(), "error", err)
+		return dataloader.Result{}, false
+	}
+	if !ok {
+		return dataloader.Result{}, false
+	}
+
+	r, err := c.options.codec.Unmarshal(data)
+	if err != nil {
+		c.options.logger.Error("unable to unmarshal result", "key", key.String(), "error", err)
+		return dataloader.Result{}, false
+	}
+	return r, true
+}
+
+// GetResultMap returns the results found for the keys with a single call to MGet. It returns true if a result
+// was found for every key. Redis and serialization errors are treated as a cache miss.
+func (c *redisCache) GetResultMap(ctx context.Context, keys ...dataloader.Key) (dataloader.ResultMap, bool) {
+	result := dataloader.NewResultMap(len(keys))
+	found := true
+	encoded, redisKeys := make([]dataloader.Key, 0, len(keys)), make([]string, 0, len(keys))
+	for _, key := range keys {
+		k, err := c.key(key)
+		if err != nil {
+			c.options.logger.Error("unable to encode key", "error", err)
+			found = false
+			continue
+		}
+		encoded, redisKeys = append(encoded, key), append(redisKeys, k)
+	}
+	if len(redisKeys) == 0 {
+		return result, found
+	}
+
+	values, err := c.client.MGet(ctx, redisKeys...)
+	if err != nil {
+		c.options.logger.Error("unable to get results", "keys", len(redisKeys), "error", err)
+		return result, false
+	}
+
+	for i, key := range encoded {
+		if i >= len(values) || values[i] == nil {
+			found = false
+			continue
+		}
+
+		r, err := c.options.codec.Unmarshal(values[i])
+		if err != nil {
+			c.options.logger.Error("unable to unmarshal result", "key", key.String(), "error", err)
+			found = false
+			continue
+		}
+		result.Set(key, r)
+	}
+	return result, found
+}
+
+// Delete removes the result for the key
+func (c *redisCache) Delete(ctx context.Context, key dataloader.Key) bool {
+	k, err := c.key(key)
+	if err != nil {
+		c.options.logger.Error("unable to encode key", "error", err)
+		return false
+	}
+
+	if err = c.client.Del(ctx, k); err != nil {
+		c.options.logger.Error("unable to delete result", "key", key.String(), "error", err)
+		return false
+	}
+	return true
+}
+
+// ClearAll removes every result stored under the configured prefix. Keys are iterated with Scan so that
+// redis isn't blocked while the keys are listed, and each page of keys is deleted as it is returned.
+func (c *redisCache) ClearAll(ctx context.Context) bool {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.options.prefix+"*", scanCount)
+		if err != nil {
+			c.options.logger.Error("unable to scan keys", "error", err)
+			return false
+		}
+
+		if len(keys) > 0 {
+			if err = c.client.Del(ctx, keys...); err != nil {
+				c.options.logger.Error("unable to delete keys", "error", err)
+				return false
+			}
+		}
+
+		if next == 0 {
+			return true
+		}
+		cursor = next
+	}
+}
+
+// ================================================= helpers =================================================
+
+// key returns the redis key for the provided key
+func (c *redisCache) key(key dataloader.Key) (string, error) {
+	k, err := c.options.keyCodec.EncodeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return c.options.prefix + k, nil
+}
+
+// formatOptions configures the default values for the cache
+func formatOptions(opts *options) {
+	opts.prefix = "dataloader:"
+	opts.codec = codec.JSON()
+	opts.keyCodec = codec.StringKeyCodec()
+	opts.ttl = func(dataloader.Key) time.Duration { return 0 }
+	opts.logger = logger.Noop()
+}
@@ -0,0 +1,194 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/cache/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== mock client ===============================================
+type mockClient struct {
+	m     sync.Mutex
+	data  map[string][]byte
+	ttls  map[string]time.Duration
+	mgets int
+	scans int
+
+	scanned []string // matching keys of the current scan
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *mockClient) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	v, ok := c.data[key]
+	return v, ok, nil
+}
+
+func (c *mockClient) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.mgets += 1
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		values[i] = c.data[k]
+	}
+	return values, nil
+}
+
+func (c *mockClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.data[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *mockClient) Del(ctx context.Context, keys ...string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for _, k := range keys {
+		delete(c.data, k)
+	}
+	return nil
+}
+
+// Scan pages through the keys matching when the iteration started, using the cursor as the offset of the page
+func (c *mockClient) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.scans += 1
+	if cursor == 0 {
+		c.scanned = nil
+		for k := range c.data {
+			if strings.HasPrefix(k, strings.TrimSuffix(match, "*")) {
+				c.scanned = append(c.scanned, k)
+			}
+		}
+		sort.Strings(c.scanned)
+	}
+
+	keys := c.scanned[cursor:]
+	if int64(len(keys)) <= count {
+		return keys, 0, nil
+	}
+	return keys[:count], cursor + uint64(count), nil
+}
+
+// ================================================== tests ==================================================
+
+// TestRoundTrip ensures results are serialized and restored with a per key ttl
+func TestRoundTrip(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	client := newMockClient()
+	cache := redis.New(client, redis.WithTTLFunc(func(k dataloader.Key) time.Duration {
+		return time.Duration(len(k.String())) * time.Minute
+	}))
+
+	// invoke
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "one", Err: nil})
+	cache.SetResult(ctx, PrimaryKey(10), dataloader.Result{Result: nil, Err: errors.New("failed")})
+
+	// assert
+	r, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "one", r.Result.(string), "Expected stored result")
+
+	r, ok = cache.GetResult(ctx, PrimaryKey(10))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.EqualError(t, r.Err, "failed", "Expected stored error")
+
+	assert.Equal(t, time.Minute, client.ttls["dataloader:1"], "Expected ttl for key")
+	assert.Equal(t, 2*time.Minute, client.ttls["dataloader:10"], "Expected ttl for key")
+}
+
+// TestClearAll ensures only keys with the configured prefix are removed
+func TestClearAll(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	client := newMockClient()
+	client.data["other:1"] = []byte("{}")
+	cache := redis.New(client, redis.WithPrefix("users:"))
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "one", Err: nil})
+
+	// invoke
+	assert.True(t, cache.ClearAll(ctx), "Expected cache to be cleared")
+
+	// assert
+	_, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.False(t, ok, "Expected result to be removed")
+	assert.Len(t, client.data, 1, "Expected keys outside of the prefix to remain")
+}
+
+// TestClearAllScansPages ensures the keys are scanned and deleted page by page
+func TestClearAllScansPages(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	client := newMockClient()
+	for i := 0; i < 250; i++ {
+		client.data[fmt.Sprintf("other:%d", i)] = []byte("{}")
+	}
+	cache := redis.New(client, redis.WithPrefix("users:"))
+	for i := 0; i < 250; i++ {
+		cache.SetResult(ctx, PrimaryKey(i), dataloader.Result{Result: "value", Err: nil})
+	}
+
+	// invoke
+	assert.True(t, cache.ClearAll(ctx), "Expected cache to be cleared")
+
+	// assert
+	assert.Equal(t, 3, client.scans, "Expected a scan for each page of keys")
+	assert.Len(t, client.data, 250, "Expected only the keys with the prefix to be removed")
+}
+
+// TestGetResultMap ensures the results are fetched with a single call and missing keys are reported
+func TestGetResultMap(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	client := newMockClient()
+	cache := redis.New(client)
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "one", Err: nil})
+	cache.SetResult(ctx, PrimaryKey(2), dataloader.Result{Result: "two", Err: nil})
+
+	// invoke
+	r, ok := cache.GetResultMap(ctx, PrimaryKey(1), PrimaryKey(2))
+	missing, missingOK := cache.GetResultMap(ctx, PrimaryKey(1), PrimaryKey(3))
+
+	// assert
+	assert.True(t, ok, "Expected a result for every key")
+	assert.Equal(t, "one", r.GetValueForString("1").Result, "Expected stored result")
+	assert.Equal(t, "two", r.GetValueForString("2").Result, "Expected stored result")
+	assert.False(t, missingOK, "Expected a missing key to be reported")
+	assert.Equal(t, 1, missing.Length(), "Expected the results of the found keys")
+	assert.Equal(t, 2, client.mgets, "Expected a single MGet for each call")
+}
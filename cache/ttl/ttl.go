@@ -0,0 +1,293 @@
+/*
+Package ttl contains a time based expiring cache implementation.
+
+The ttl cache stores each result for a configured duration after which the result
+expires and is no longer returned. Expired results are removed by a background
+sweeper go routine which runs until the context passed to New is cancelled. The
+cache is go routine safe and is useful for long lived, application scoped loaders.
+
+With WithStaleWhileRevalidate, expired results are still returned for a stale window
+while a background batch refreshes them, so that hot keys don't wait for the batch
+function once their result expires. Each stale key is refreshed at most once at a time
+(see package keymutex) and the stale keys found by a single GetResultMap call are refreshed
+in one batch.
+*/
+package ttl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/keymutex"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// options contains the configuration of the TTL cache
+type options struct {
+	sweepInterval time.Duration
+	staleWindow   time.Duration
+	refresh       dataloader.BatchFunction
+	clock         clock.Clock
+	logger        logger.Logger
+}
+
+// Option accepts the cache options and sets an option on it.
+type Option func(*options)
+
+// New returns a new instance of the ttl cache. Each result expires after the provided ttl.
+// The background sweeper removes expired results until the provided context is cancelled.
+func New(ctx context.Context, ttl time.Duration, opts ...Option) dataloader.Cache {
+	// default options
+	o := options{}
+	formatOptions(&o, ttl)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	c := &ttlCache{
+		ctx:         ctx,
+		ttl:         ttl,
+		staleWindow: o.staleWindow,
+		refresh:     o.refresh,
+		clock:       o.clock,
+		logger:      o.logger,
+		items:       make(map[string]entry),
+		refreshing:  keymutex.New(),
+	}
+
+	if o.sweepInterval > 0 {
+		go c.sweep(ctx, o.sweepInterval)
+	}
+
+	return c
+}
+
+// ============================================== option setters =============================================
+
+// WithSweepInterval sets how often the sweeper removes expired results. Defaults to the ttl.
+func WithSweepInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.sweepInterval = d
+	}
+}
+
+// WithStaleWhileRevalidate returns expired results for up to the window after they expire and refreshes
+// them in the background with the batch function, called with the context passed to New. A refreshed result
+// replaces the stale result unless it failed, in which case the stale result is returned until the window
+// ends. Defaults to no stale window.
+func WithStaleWhileRevalidate(window time.Duration, refresh dataloader.BatchFunction) Option {
+	return func(o *options) {
+		o.staleWindow = window
+		o.refresh = refresh
+	}
+}
+
+// WithClock sets the time source used to expire results and schedule the sweeper, e.g. a clock.Mock in
+// tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger used to report panics in the refresh batch function. Default is a no op
+// logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ===========================================================================================================
+
+type ttlCache struct {
+	m sync.Mutex
+
+	ctx         context.Context
+	ttl         time.Duration
+	staleWindow time.Duration
+	refresh     dataloader.BatchFunction
+	clock       clock.Clock
+	logger      logger.Logger
+	items       map[string]entry
+	refreshing  keymutex.KeyMutex // locked for the keys with a refresh in flight
+}
+
+type entry struct {
+	result  dataloader.Result
+	expires time.Time
+}
+
+// SetResult stores the result for the key
+func (c *ttlCache) SetResult(ctx context.Context, key dataloader.Key, result dataloader.Result) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.items[key.String()] = entry{result: result, expires: c.clock.Now().Add(c.ttl)}
+}
+
+// SetResultMap stores each result in the result map
+func (c *ttlCache) SetResultMap(ctx context.Context, resultMap dataloader.ResultMap) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	expires := c.clock.Now().Add(c.ttl)
+	for k, v := range resultMap {
+		c.items[k] = entry{result: v, expires: expires}
+	}
+}
+
+// GetResult returns the result for the key if it has not expired, or is within the stale window
+func (c *ttlCache) GetResult(ctx context.Context, key dataloader.Key) (dataloader.Result, bool) {
+	c.m.Lock()
+	r, ok, stale := c.get(key.String(), c.clock.Now())
+	var refresh []dataloader.Key
+	if stale {
+		refresh = c.markRefreshing(refresh, key)
+	}
+	c.m.Unlock()
+
+	c.revalidate(refresh)
+	return r, ok
+}
+
+// GetResultMap returns the unexpired and stale results found for the keys. It returns true if a result was
+// found for every key.
+func (c *ttlCache) GetResultMap(ctx context.Context, keys ...dataloader.Key) (dataloader.ResultMap, bool) {
+	c.m.Lock()
+
+	now := c.clock.Now()
+	found := true
+	result := dataloader.NewResultMap(len(keys))
+	var refresh []dataloader.Key
+	for _, key := range keys {
+		r, ok, stale := c.get(key.String(), now)
+		if !ok {
+			found = false
+			continue
+		}
+		if stale {
+			refresh = c.markRefreshing(refresh, key)
+		}
+		result.Set(key, r)
+	}
+	c.m.Unlock()
+
+	c.revalidate(refresh)
+	return result, found
+}
+
+// Delete removes the result for the key. It returns false if no result was stored.
+func (c *ttlCache) Delete(ctx context.Context, key dataloader.Key) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	k := key.String()
+	if _, ok := c.items[k]; !ok {
+		return false
+	}
+
+	delete(c.items, k)
+	return true
+}
+
+// ClearAll removes every result from the cache
+func (c *ttlCache) ClearAll(ctx context.Context) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.items = make(map[string]entry)
+	return true
+}
+
+// ================================================= helpers =================================================
+
+// get returns the result for the key. The third return value is true if the result expired and is returned
+// within the stale window.
+func (c *ttlCache) get(key string, now time.Time) (dataloader.Result, bool, bool) {
+	e, ok := c.items[key]
+	if !ok || now.After(e.expires.Add(c.staleWindow)) {
+		return dataloader.Result{}, false, false
+	}
+
+	return e.result, true, now.After(e.expires)
+}
+
+// markRefreshing appends the key to the keys to refresh unless a refresh is already in flight for it. The
+// key stays locked until its refresh completes.
+func (c *ttlCache) markRefreshing(refresh []dataloader.Key, key dataloader.Key) []dataloader.Key {
+	if c.refresh == nil || !c.refreshing.TryLock(key.String()) {
+		return refresh
+	}
+	return append(refresh, key)
+}
+
+// revalidate calls the refresh batch function with the keys in the background and replaces their stale
+// results with the successful refreshed results. Results deleted while the refresh is in flight aren't
+// restored. A panic in the refresh batch function is logged and treated as a failed refresh.
+func (c *ttlCache) revalidate(keys []dataloader.Key) {
+	if len(keys) == 0 {
+		return
+	}
+
+	go func() {
+		defer func() {
+			for _, key := range keys {
+				c.refreshing.Unlock(key.String())
+			}
+		}()
+		defer func() {
+			if v := recover(); v != nil {
+				c.logger.Error("recovered from panic in refresh batch function", "keys", len(keys), "panic", v)
+			}
+		}()
+
+		r := c.refresh(c.ctx, dataloader.NewKeysWith(keys...))
+
+		c.m.Lock()
+		defer c.m.Unlock()
+
+		expires := c.clock.Now().Add(c.ttl)
+		for _, key := range keys {
+			k := key.String()
+			if _, ok := c.items[k]; !ok || r == nil {
+				continue
+			}
+			if v, ok := (*r)[k]; ok && v.Err == nil {
+				c.items[k] = entry{result: v, expires: expires}
+			}
+		}
+	}()
+}
+
+// sweep removes expired results every interval until the context is cancelled
+func (c *ttlCache) sweep(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(interval):
+			now := c.clock.Now()
+			c.m.Lock()
+			for k, e := range c.items {
+				if now.After(e.expires.Add(c.staleWindow)) {
+					delete(c.items, k)
+				}
+			}
+			c.m.Unlock()
+		}
+	}
+}
+
+// formatOptions configures the default values for the cache
+func formatOptions(opts *options, ttl time.Duration) {
+	opts.sweepInterval = ttl
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
@@ -0,0 +1,219 @@
+package ttl_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/cache/ttl"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// ================================================== tests ==================================================
+
+// TestResultExpires ensures results are not returned after the ttl has passed
+func TestResultExpires(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cache := ttl.New(ctx, 50*time.Millisecond, ttl.WithSweepInterval(10*time.Millisecond))
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+
+	// invoke / assert
+	r, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, r.Result.(int), "Expected cached result")
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, ok = cache.GetResult(ctx, PrimaryKey(1))
+	assert.False(t, ok, "Expected result to have expired")
+	assert.False(t, cache.Delete(ctx, PrimaryKey(1)), "Expected sweeper to have removed the result")
+}
+
+// TestStaleWhileRevalidate ensures expired results are returned within the stale window while the stale keys
+// are refreshed in a single background batch
+func TestStaleWhileRevalidate(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var m sync.Mutex
+	var batches [][]string
+	release := make(chan struct{})
+	refresh := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		batches = append(batches, keys.StringKeys())
+		m.Unlock()
+
+		<-release
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: "fresh", Err: nil})
+		}
+		return &r
+	}
+	cache := ttl.New(ctx, 20*time.Millisecond, ttl.WithStaleWhileRevalidate(time.Second, refresh))
+	cache.SetResultMap(ctx, dataloader.ResultMap{
+		"1": dataloader.Result{Result: "stale", Err: nil},
+		"2": dataloader.Result{Result: "stale", Err: nil},
+	})
+	time.Sleep(40 * time.Millisecond)
+
+	// invoke / assert
+	rm, ok := cache.GetResultMap(ctx, PrimaryKey(1), PrimaryKey(2))
+	assert.True(t, ok, "Expected the stale results to be found")
+	assert.Equal(t, "stale", rm.GetValueForString("1").Result, "Expected the stale result")
+	r, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.True(t, ok, "Expected the stale result to be found while it is refreshed")
+	assert.Equal(t, "stale", r.Result, "Expected the stale result")
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for r.Result != "fresh" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		r, _ = cache.GetResult(ctx, PrimaryKey(1))
+	}
+	assert.Equal(t, "fresh", r.Result, "Expected the refreshed result")
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, [][]string{{"1", "2"}}, batches, "Expected the stale keys to be refreshed in a single batch")
+}
+
+// TestStaleWindowEnds ensures a stale result whose refresh failed is not returned after the stale window
+func TestStaleWindowEnds(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refresh := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: nil, Err: errors.New("failed")})
+		}
+		return &r
+	}
+	cache := ttl.New(ctx, 20*time.Millisecond, ttl.WithStaleWhileRevalidate(100*time.Millisecond, refresh))
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "stale", Err: nil})
+	time.Sleep(30 * time.Millisecond)
+
+	// invoke / assert
+	r, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.True(t, ok, "Expected the stale result to be found")
+	assert.Equal(t, "stale", r.Result, "Expected the stale result")
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, ok = cache.GetResult(ctx, PrimaryKey(1))
+	assert.False(t, ok, "Expected the stale result not to be returned after the stale window")
+}
+
+// TestRefreshDeduplicated ensures concurrent reads of a stale key start a single refresh and that the results
+// expire according to the clock of the cache
+func TestRefreshDeduplicated(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	release := make(chan struct{})
+	refresh := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: "fresh", Err: nil})
+		}
+		return &r
+	}
+	clk := clock.NewMock()
+	cache := ttl.New(
+		ctx,
+		time.Minute,
+		ttl.WithClock(clk),
+		ttl.WithSweepInterval(0),
+		ttl.WithStaleWhileRevalidate(time.Minute, refresh),
+	)
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "stale", Err: nil})
+
+	// invoke
+	r, _ := cache.GetResult(ctx, PrimaryKey(1))
+	clk.Add(90 * time.Second)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.GetResult(ctx, PrimaryKey(1))
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	// assert
+	assert.Equal(t, "stale", r.Result, "Expected the result before it expires on the clock")
+	assert.Eventually(t, func() bool {
+		r, _ := cache.GetResult(ctx, PrimaryKey(1))
+		return r.Result == "fresh"
+	}, time.Second, time.Millisecond, "Expected the refreshed result")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected a single refresh for the stale key")
+}
+
+// TestRefreshPanicRecovered ensures a panic in the refresh batch function is recovered and the key is refreshed
+// again by a later read
+func TestRefreshPanicRecovered(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	refresh := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("refresh failure")
+		}
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: "fresh", Err: nil})
+		}
+		return &r
+	}
+	clk := clock.NewMock()
+	cache := ttl.New(
+		ctx,
+		time.Minute,
+		ttl.WithClock(clk),
+		ttl.WithSweepInterval(0),
+		ttl.WithStaleWhileRevalidate(time.Minute, refresh),
+	)
+	cache.SetResult(ctx, PrimaryKey(1), dataloader.Result{Result: "stale", Err: nil})
+	clk.Add(90 * time.Second)
+
+	// invoke / assert
+	r, ok := cache.GetResult(ctx, PrimaryKey(1))
+	assert.True(t, ok, "Expected the stale result to be found")
+	assert.Equal(t, "stale", r.Result, "Expected the stale result")
+	assert.Eventually(t, func() bool {
+		r, _ := cache.GetResult(ctx, PrimaryKey(1))
+		return r.Result == "fresh"
+	}, time.Second, time.Millisecond, "Expected the key to be refreshed after the panic")
+	assert.True(t, atomic.LoadInt32(&calls) >= 2, "Expected the key to be refreshed again")
+}
@@ -0,0 +1,13 @@
+package dataloader
+
+import "context"
+
+// LoadFunc loads the key like Load and calls the function with the result of the key in a new go routine once
+// it resolves. A key without a result resolves to a NotFoundError. LoadFunc does not block the caller.
+func (d *dataloader) LoadFunc(ctx context.Context, key Key, fn func(Result), opts ...LoadOption) {
+	thunk := d.Load(ctx, key, opts...)
+
+	go func() {
+		fn(resolve(key, thunk))
+	}()
+}
@@ -0,0 +1,40 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadFunc ensures the callback is called with the result once the batch resolves without blocking the
+// caller
+func TestLoadFunc(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	batch := getBatchFunction(func() { <-release }, dataloader.Result{Result: "result", Err: nil})
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	resultChan := make(chan dataloader.Result, 1)
+
+	// invoke
+	loader.LoadFunc(context.Background(), PrimaryKey(1), func(r dataloader.Result) {
+		resultChan <- r
+	})
+
+	// assert
+	select {
+	case <-resultChan:
+		assert.Fail(t, "Expected the callback to wait for the batch")
+	default:
+	}
+	close(release)
+
+	select {
+	case r := <-resultChan:
+		assert.Equal(t, "result", r.Result, "Expected the result of the key")
+	case <-time.After(time.Second):
+		assert.Fail(t, "Expected the callback to be called")
+	}
+}
@@ -0,0 +1,28 @@
+package dataloader
+
+import "context"
+
+// LoadChan loads the key like Load and returns a channel which receives the result of the key once it
+// resolves, so that callers can select on the result together with the cancellation of the context or other
+// channels. A key without a result receives a NotFoundError. The channel is closed after the result.
+func (d *dataloader) LoadChan(ctx context.Context, key Key, opts ...LoadOption) <-chan Result {
+	thunk := d.Load(ctx, key, opts...)
+
+	resultChan := make(chan Result, 1) // buffered so that an abandoned channel doesn't block the go routine
+	go func() {
+		defer close(resultChan)
+
+		resultChan <- resolve(key, thunk)
+	}()
+	return resultChan
+}
+
+// resolve calls the thunk and returns its result, or a result holding a NotFoundError if the thunk didn't
+// find a result for the key
+func resolve(key Key, thunk Thunk) Result {
+	result, ok := thunk()
+	if !ok && result.Err == nil {
+		return notFoundResult(key)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadChan ensures the result is delivered on the channel which is closed afterwards
+func TestLoadChan(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(1)
+		if key := keys.Keys()[0].(PrimaryKey); key == 1 { // key 2 is missing
+			m.Set(key, dataloader.Result{Result: "result", Err: nil})
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+
+	// invoke
+	resultChan := loader.LoadChan(context.Background(), PrimaryKey(1))
+	missingChan := loader.LoadChan(context.Background(), PrimaryKey(2))
+
+	// assert
+	select {
+	case r := <-resultChan:
+		assert.Equal(t, "result", r.Result, "Expected the result of the key")
+	case <-time.After(time.Second):
+		assert.Fail(t, "Expected the result to be delivered")
+	}
+	_, open := <-resultChan
+	assert.False(t, open, "Expected the channel to be closed after the result")
+	assert.True(t, dataloader.IsNotFound((<-missingChan).Err), "Expected a not found error for the missing key")
+}
+
+// TestLoadChanSelect ensures callers can stop waiting for the result when their context is done
+func TestLoadChanSelect(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	defer close(release)
+	batch := getBatchFunction(func() { <-release }, dataloader.Result{Result: "result", Err: nil})
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	// invoke
+	var timedOut bool
+	select {
+	case <-loader.LoadChan(ctx, PrimaryKey(1)):
+	case <-ctx.Done():
+		timedOut = true
+	}
+
+	// assert
+	assert.True(t, timedOut, "Expected the context to be done before the result")
+}
@@ -0,0 +1,61 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// chunkBatch returns a batch function which calls the batch function with at most size unique keys at a
+// time and merges the returned ResultMaps. Up to parallelism chunks are executed concurrently. A panic in
+// a chunk resolves the keys of the chunk to a PanicError.
+func chunkBatch(batch BatchFunction, size, parallelism int, l logger.Logger) BatchFunction {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	return func(ctx context.Context, keys Keys) *ResultMap {
+		chunks := chunkKeys(keys, size)
+		if len(chunks) <= 1 {
+			return batch(ctx, keys)
+		}
+
+		run := recoverBatch(batch, l)
+		result := NewResultMap(keys.Length())
+		var m sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, parallelism)
+
+		for _, chunk := range chunks {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(chunk Keys) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				r := run(ctx, chunk)
+				if r == nil {
+					return
+				}
+
+				m.Lock()
+				defer m.Unlock()
+				result.Merge(*r)
+			}(chunk)
+		}
+
+		wg.Wait()
+		return &result
+	}
+}
+
+// chunkKeys splits the unique keys into chunks of at most size keys
+func chunkKeys(k Keys, size int) []Keys {
+	if size <= 0 || k.Length() <= size {
+		return []Keys{k}
+	}
+	return k.Chunk(size)
+}
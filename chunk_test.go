@@ -0,0 +1,134 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================================================== tests ==================================================
+
+// TestMaxBatchSizeChunksKeys ensures batches larger than the max batch size are split and merged
+func TestMaxBatchSizeChunksKeys(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var sizes []int
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		sizes = append(sizes, keys.Length())
+		m.Unlock()
+
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: k.(PrimaryKey).String(), Err: nil})
+		}
+		return &r
+	}
+	loader := dataloader.NewDataLoader(5, batch, newMockStrategy(), dataloader.WithMaxBatchSize(2))
+
+	// invoke
+	r := loader.LoadMany(
+		context.Background(),
+		PrimaryKey(1), PrimaryKey(2), PrimaryKey(3), PrimaryKey(3), PrimaryKey(4), PrimaryKey(5),
+	)()
+
+	// assert
+	assert.Equal(t, []int{2, 2, 1}, sizes, "Expected unique keys to be split into chunks of at most 2 keys")
+	assert.Equal(t, 5, r.Length(), "Expected merged results for every key")
+	for i := 1; i <= 5; i++ {
+		v, ok := r.GetValue(PrimaryKey(i))
+		assert.True(t, ok, "Expected result for key")
+		assert.Equal(t, PrimaryKey(i).String(), v.Result, "Expected result from chunk")
+	}
+}
+
+// TestChunkParallelismLimit ensures chunks are executed concurrently without exceeding the parallelism limit
+func TestChunkParallelismLimit(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	running, maxRunning, calls := 0, 0, 0
+	release := make(chan struct{})
+	var once sync.Once
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		running += 1
+		calls += 1
+		if running > maxRunning {
+			maxRunning = running
+		}
+		if running == 2 {
+			once.Do(func() { close(release) }) // both chunks are running concurrently
+		}
+		m.Unlock()
+
+		<-release
+
+		m.Lock()
+		running -= 1
+		m.Unlock()
+
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: nil, Err: errors.New("chunk failure")})
+		}
+		return &r
+	}
+	loader := dataloader.NewDataLoader(
+		4,
+		batch,
+		newMockStrategy(),
+		dataloader.WithMaxBatchSize(1),
+		dataloader.WithChunkParallelism(2),
+	)
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3), PrimaryKey(4))()
+
+	// assert
+	assert.Equal(t, 4, calls, "Expected batch function to be called for each chunk")
+	assert.Equal(t, 2, maxRunning, "Expected at most 2 chunks to run concurrently")
+	assert.Equal(t, 4, r.Length(), "Expected merged results for every key")
+	for i := 1; i <= 4; i++ {
+		v, _ := r.GetValue(PrimaryKey(i))
+		assert.EqualError(t, v.Err, "chunk failure", "Expected chunk errors to be merged")
+	}
+}
+
+// TestChunkPanicRecovered ensures a panic in a concurrently executed chunk resolves the keys of the chunk to a
+// PanicError without affecting the other chunks
+func TestChunkPanicRecovered(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			if k.(PrimaryKey) == 2 {
+				panic("chunk panic")
+			}
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: k.(PrimaryKey).String(), Err: nil})
+		}
+		return &r
+	}
+	loader := dataloader.NewDataLoader(
+		2,
+		batch,
+		newMockStrategy(),
+		dataloader.WithMaxBatchSize(1),
+		dataloader.WithChunkParallelism(2),
+	)
+
+	// invoke
+	var r dataloader.ResultMap
+	assert.NotPanics(t, func() {
+		r = loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	}, "Expected panic to be recovered")
+
+	// assert
+	v, _ := r.GetValue(PrimaryKey(1))
+	assert.Equal(t, "1", v.Result, "Expected result from the other chunk")
+	v, _ = r.GetValue(PrimaryKey(2))
+	assert.IsType(t, &dataloader.PanicError{}, v.Err, "Expected PanicError for the keys of the chunk")
+}
@@ -0,0 +1,45 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+)
+
+// Error classes returned by DefaultErrorClassifier
+const (
+	ErrorClassTimeout  = "timeout"
+	ErrorClassCanceled = "canceled"
+	ErrorClassInternal = "internal"
+)
+
+// ErrorClassifier returns the class of an error returned by the batch function (e.g. timeout, not-found,
+// throttled, internal). Error metrics are labeled by the returned class.
+type ErrorClassifier func(error) string
+
+// DefaultErrorClassifier classifies context deadline and timeout errors as ErrorClassTimeout, context
+// cancellation as ErrorClassCanceled and all other errors as ErrorClassInternal. Wrapped errors are
+// classified by the errors they wrap.
+func DefaultErrorClassifier(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+	var t interface{ Timeout() bool }
+	if errors.As(err, &t) && t.Timeout() {
+		return ErrorClassTimeout // e.g. net.Error
+	}
+	return ErrorClassInternal
+}
+
+// classifyErrors returns the number of results which contain an error by error class
+func classifyErrors(r ResultMap, classify ErrorClassifier) map[string]int {
+	classes := make(map[string]int)
+	for _, v := range r {
+		if v.Err != nil {
+			classes[classify(v.Err)]++
+		}
+	}
+	return classes
+}
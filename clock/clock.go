@@ -0,0 +1,129 @@
+/*
+Package clock contains the time source used by the strategies to call the batch function after a timeout.
+The strategies, and the loader for its hedging delay, accept a Clock through their options so that tests
+can control the timing deterministically by advancing a Mock clock instead of sleeping or patching the time
+package.
+
+	clk := clock.NewMock()
+	strategy := standard.NewStandardStrategy(standard.WithClock(clk))(10, batch)
+
+	thunk := strategy.Load(ctx, key)
+	clk.BlockUntil(1)              // wait for the worker to wait on the timeout
+	clk.Add(16 * time.Millisecond) // the worker times out and calls the batch function
+	thunk()
+*/
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// New returns a clock backed by the time package
+func New() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// ================================================== mock ==================================================
+
+// Mock is a clock which only moves when Add or Set is called. It is safe for concurrent use.
+type Mock struct {
+	m       sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []waiter
+}
+
+// waiter is a call to After which hasn't fired yet
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewMock returns a mock clock set to the current time
+func NewMock() *Mock {
+	m := &Mock{now: time.Now()}
+	m.cond = sync.NewCond(&m.m)
+	return m
+}
+
+// Now returns the time of the mock clock
+func (m *Mock) Now() time.Time {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return m.now
+}
+
+// After returns a channel which receives the time once the clock has been advanced by the duration
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	c := make(chan time.Time, 1) // buffered channel won't block when firing
+	if d <= 0 {
+		c <- m.now
+		return c
+	}
+	m.waiters = append(m.waiters, waiter{at: m.now.Add(d), c: c})
+	m.cond.Broadcast()
+	return c
+}
+
+// Add advances the clock by the duration and fires the channels of the calls to After which are due
+func (m *Mock) Add(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set sets the clock to the time and fires the channels of the calls to After which are due
+func (m *Mock) Set(t time.Time) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.now = t
+	pending := m.waiters[:0]
+	for _, w := range m.waiters {
+		if w.at.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- t
+	}
+	m.waiters = pending
+}
+
+// BlockUntil blocks until at least n calls to After are waiting for the clock to advance. Callers of After
+// which select on the channel in a loop, like the strategy workers, call After once per iteration, so the
+// count includes the channels they stopped waiting on.
+func (m *Mock) BlockUntil(n int) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	for len(m.waiters) < n {
+		m.cond.Wait()
+	}
+}
+
+// Waiters returns the number of calls to After waiting for the clock to advance
+func (m *Mock) Waiters() int {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return len(m.waiters)
+}
@@ -0,0 +1,78 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMockAfter ensures the channels returned by After fire once the clock is advanced past their duration
+func TestMockAfter(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	start := clk.Now()
+
+	// invoke
+	short := clk.After(time.Second)
+	long := clk.After(time.Minute)
+	clk.Add(time.Second)
+
+	// assert
+	select {
+	case now := <-short:
+		assert.Equal(t, start.Add(time.Second), now, "Expected the time of the clock")
+	default:
+		assert.Fail(t, "Expected the channel to fire")
+	}
+	select {
+	case <-long:
+		assert.Fail(t, "Expected the channel not to fire before its duration")
+	default:
+	}
+	assert.Equal(t, 1, clk.Waiters(), "Expected one call to After to be waiting")
+
+	clk.Add(time.Minute)
+	select {
+	case <-long:
+	default:
+		assert.Fail(t, "Expected the channel to fire")
+	}
+	assert.Equal(t, 0, clk.Waiters(), "Expected no call to After to be waiting")
+}
+
+// TestMockBlockUntil ensures BlockUntil returns once the calls to After are waiting
+func TestMockBlockUntil(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	done := make(chan struct{})
+
+	// invoke
+	go func() {
+		clk.BlockUntil(2)
+		close(done)
+	}()
+	clk.After(time.Second)
+	clk.After(time.Second)
+
+	// assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Expected BlockUntil to return")
+	}
+}
+
+// TestSystemClock ensures the system clock uses the time package
+func TestSystemClock(t *testing.T) {
+	// setup
+	clk := clock.New()
+
+	// invoke
+	start := clk.Now()
+	<-clk.After(time.Millisecond)
+
+	// assert
+	assert.True(t, time.Since(start) >= time.Millisecond, "Expected the duration to elapse")
+}
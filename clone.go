@@ -0,0 +1,102 @@
+package dataloader
+
+import "reflect"
+
+// ResultCloner returns a copy of a result which is safe for the caller to mutate
+type ResultCloner func(Result) Result
+
+// DeepCopy is a reflect based ResultCloner which recursively copies the value of the result through
+// pointers, slices, maps, arrays, interfaces and exported struct fields. Unexported struct fields,
+// channels and functions are copied shallowly. Cyclic pointers are preserved. The error is not copied.
+func DeepCopy(r Result) Result {
+	if r.Result == nil {
+		return r
+	}
+
+	v := reflect.ValueOf(r.Result)
+	r.Result = deepCopy(v, make(map[visit]reflect.Value)).Interface()
+	return r
+}
+
+// ============================================== private =============================================
+
+// clone returns a copy of the result if the loader is configured with a result cloner
+func (d *dataloader) clone(r Result) Result {
+	if d.resultCloner == nil {
+		return r
+	}
+	return d.resultCloner(r)
+}
+
+// visit identifies a copied pointer. A pointer to a struct and a pointer to its first field share the address,
+// so the address alone doesn't identify the value.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// deepCopy recursively copies the value. visited maps pointers which were already copied to their copy.
+func deepCopy(v reflect.Value, visited map[visit]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		id := visit{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := visited[id]; ok {
+			return c
+		}
+		c := reflect.New(v.Elem().Type())
+		visited[id] = c
+		c.Elem().Set(deepCopy(v.Elem(), visited))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), visited))
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), visited))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), visited))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, deepCopy(v.MapIndex(k), visited))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v) // copies unexported fields shallowly
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i), visited))
+			}
+		}
+		return c
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,85 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name    string
+	Tags    []string
+	Friends map[string]*user
+	Parent  *user
+}
+
+// TestDeepCopy ensures nested values are copied and cycles are preserved
+func TestDeepCopy(t *testing.T) {
+	// setup
+	u := &user{Name: "a", Tags: []string{"x"}, Friends: map[string]*user{"b": {Name: "b"}}}
+	u.Parent = u
+
+	// invoke
+	c := dataloader.DeepCopy(dataloader.Result{Result: u, Err: nil}).Result.(*user)
+
+	// assert
+	assert.Equal(t, u.Name, c.Name, "Expected the copy to be equal")
+	assert.True(t, c != u, "Expected a new pointer")
+	assert.True(t, c.Parent == c, "Expected the cycle to point to the copy")
+
+	c.Tags[0] = "y"
+	c.Friends["b"].Name = "c"
+	assert.Equal(t, "x", u.Tags[0], "Expected the slice to be copied")
+	assert.Equal(t, "b", u.Friends["b"].Name, "Expected the map values to be copied")
+}
+
+// TestDeepCopyFieldPointer ensures a pointer to the first field of a struct is copied alongside a pointer to
+// the struct sharing its address
+func TestDeepCopyFieldPointer(t *testing.T) {
+	// setup
+	type node struct{ Val int }
+	type holder struct {
+		N *node
+		V *int
+	}
+	n := &node{Val: 1}
+
+	// invoke
+	var c holder
+	assert.NotPanics(t, func() {
+		c = dataloader.DeepCopy(dataloader.Result{Result: holder{N: n, V: &n.Val}, Err: nil}).Result.(holder)
+	}, "Expected pointers sharing an address to be copied")
+
+	// assert
+	assert.Equal(t, 1, c.N.Val, "Expected the struct to be copied")
+	assert.Equal(t, 1, *c.V, "Expected the field pointer to be copied")
+	assert.True(t, c.N != n && c.V != &n.Val, "Expected new pointers")
+}
+
+// TestResultClonerIsolatesCachedResults ensures mutating a returned result doesn't affect the cache
+func TestResultClonerIsolatesCachedResults(t *testing.T) {
+	// setup
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: &user{Name: "original"}, Err: nil})
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithCache(newMockCache(1)),
+		dataloader.WithResultCloner(nil),
+	)
+
+	// invoke
+	r, _ := loader.Load(context.Background(), PrimaryKey(1))()
+	r.Result.(*user).Name = "mutated"
+
+	cached, _ := loader.Load(context.Background(), PrimaryKey(1))()
+	cached.Result.(*user).Name = "mutated again"
+
+	many := loader.LoadMany(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.Equal(t, "original", many.GetValueForString("1").Result.(*user).Name,
+		"Expected the cached result not to be affected by callers")
+}
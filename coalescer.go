@@ -0,0 +1,113 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Coalescer shares a batch function between loaders, typically the per request loaders of a process. Calls to
+// Batch which arrive within the same window are merged into a single call to the batch function with the
+// distinct keys of every call, and each caller receives the results for its own keys. This reduces duplicate
+// backend load when concurrent requests load the same cold keys, e.g. during traffic spikes on hot entities.
+type Coalescer struct {
+	batch  BatchFunction
+	window time.Duration
+
+	m       sync.Mutex
+	pending *coalescedBatch // batch accepting keys until its window closes
+}
+
+// coalescedBatch is the merged batch for a window
+type coalescedBatch struct {
+	ctx  context.Context // context of the first caller, detached from its cancellation
+	keys Keys
+	seen map[string]bool
+
+	done   chan struct{}
+	result *ResultMap
+}
+
+// NewCoalescer returns a coalescer which merges the calls to Batch made within the window into a single call
+// to the batch function. A panic in the batch function resolves the keys of every caller to a PanicError.
+func NewCoalescer(batch BatchFunction, window time.Duration) *Coalescer {
+	return &Coalescer{batch: recoverBatch(batch, logger.Noop()), window: window}
+}
+
+// Batch is a BatchFunction which adds the keys to the batch for the current window and blocks until the
+// merged batch returns or the context is done. Pass Batch to NewDataLoader in place of the batch function.
+// The merged call receives the context of the first caller in the window without its deadline or
+// cancellation, so that a cancelled request doesn't fail the other callers.
+func (c *Coalescer) Batch(ctx context.Context, keys Keys) *ResultMap {
+	b := c.add(ctx, keys)
+
+	select {
+	case <-ctx.Done():
+		return errorResultMap(keys, ctx.Err())
+	case <-b.done:
+	}
+
+	result := NewResultMap(keys.Length())
+	if b.result == nil {
+		return &result
+	}
+	for _, k := range keys.StringKeys() {
+		if v, ok := (*b.result)[k]; ok {
+			result[k] = v
+		}
+	}
+	return &result
+}
+
+// ============================================== private =============================================
+
+// add adds the keys to the batch of the current window, opening a window if none is open
+func (c *Coalescer) add(ctx context.Context, keys Keys) *coalescedBatch {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	b := c.pending
+	if b == nil {
+		b = &coalescedBatch{
+			ctx:  detachedContext{ctx},
+			keys: NewKeys(keys.Length()),
+			seen: make(map[string]bool, keys.Length()),
+			done: make(chan struct{}),
+		}
+		c.pending = b
+		time.AfterFunc(c.window, func() { c.execute(b) })
+	}
+
+	keys.ForEach(func(key Key) bool {
+		if !b.seen[key.String()] {
+			b.seen[key.String()] = true
+			b.keys.Append(key)
+		}
+		return true
+	})
+	return b
+}
+
+// execute closes the window and calls the batch function with the merged keys
+func (c *Coalescer) execute(b *coalescedBatch) {
+	c.m.Lock()
+	if c.pending == b {
+		c.pending = nil
+	}
+	c.m.Unlock()
+
+	b.result = c.batch(b.ctx, b.keys)
+	close(b.done)
+}
+
+// detachedContext carries the values of its parent without its deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
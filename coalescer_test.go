@@ -0,0 +1,123 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoalescerMergesBatches ensures concurrent batches within a window call the batch function once
+func TestCoalescerMergesBatches(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var calls [][]string
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		calls = append(calls, keys.StringKeys())
+		m.Unlock()
+
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(dataloader.Key), dataloader.Result{Result: k.(dataloader.Key).String(), Err: nil})
+		}
+		return &r
+	}
+	coalescer := dataloader.NewCoalescer(batch, 20*time.Millisecond)
+
+	// invoke
+	var wg sync.WaitGroup
+	results := make([]dataloader.ResultMap, 2)
+	requests := [][]dataloader.Key{{PrimaryKey(1), PrimaryKey(2)}, {PrimaryKey(2), PrimaryKey(3)}}
+	for i, keys := range requests {
+		wg.Add(1)
+		go func(i int, keys []dataloader.Key) {
+			defer wg.Done()
+			loader := dataloader.NewDataLoader(2, coalescer.Batch, newMockStrategy())
+			results[i] = loader.LoadMany(context.Background(), keys...)()
+		}(i, keys)
+	}
+	wg.Wait()
+
+	// assert
+	if assert.Equal(t, 1, len(calls), "Expected a single call to the batch function") {
+		assert.ElementsMatch(t, []string{"1", "2", "3"}, calls[0], "Expected the distinct keys of both batches")
+	}
+	assert.Equal(t, 2, results[0].Length(), "Expected results for the first requests keys only")
+	assert.Equal(t, "1", results[0].GetValueForString("1").Result, "Expected the result for the key")
+	assert.Equal(t, 2, results[1].Length(), "Expected results for the second requests keys only")
+	assert.Equal(t, "3", results[1].GetValueForString("3").Result, "Expected the result for the key")
+}
+
+// TestCoalescerCancelledCaller ensures a cancelled caller doesn't fail the merged batch
+func TestCoalescerCancelledCaller(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(dataloader.Key), dataloader.Result{Result: "ok", Err: ctx.Err()})
+		}
+		return &r
+	}
+	coalescer := dataloader.NewCoalescer(batch, 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	keys := dataloader.NewKeys(1)
+	keys.Append(PrimaryKey(1))
+
+	// invoke
+	cancelled := make(chan *dataloader.ResultMap)
+	go func() { cancelled <- coalescer.Batch(ctx, keys) }()
+	time.Sleep(5 * time.Millisecond) // ensure the cancelled caller opens the window
+	cancel()
+	r := coalescer.Batch(context.Background(), keys)
+
+	// assert
+	assert.Equal(t, context.Canceled, (*<-cancelled)["1"].Err, "Expected the cancelled caller to fail")
+	assert.Nil(t, (*r)["1"].Err, "Expected the merged batch not to be cancelled")
+	assert.Equal(t, "ok", (*r)["1"].Result, "Expected the result for the key")
+}
+
+// TestCoalescerStructKey ensures keys whose raw value isn't a Key, e.g. a StructKey, are merged into the batch
+func TestCoalescerStructKey(t *testing.T) {
+	// setup
+	type query struct{ ID int }
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		keys.ForEach(func(k dataloader.Key) bool {
+			r.Set(k, dataloader.Result{Result: k.Raw().(query).ID, Err: nil})
+			return true
+		})
+		return &r
+	}
+	coalescer := dataloader.NewCoalescer(batch, time.Millisecond)
+	key := dataloader.StructKey(query{ID: 1})
+
+	// invoke
+	r := coalescer.Batch(context.Background(), dataloader.NewKeysWith(key))
+
+	// assert
+	v, ok := r.GetValue(key)
+	assert.True(t, ok, "Expected a result for the key")
+	assert.Equal(t, 1, v.Result, "Expected the result for the key")
+}
+
+// TestCoalescerPanic ensures a panicking batch function resolves every caller to a PanicError
+func TestCoalescerPanic(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		panic("boom")
+	}
+	coalescer := dataloader.NewCoalescer(batch, time.Millisecond)
+
+	// invoke
+	r := coalescer.Batch(context.Background(), dataloader.NewKeysWith(PrimaryKey(1)))
+
+	// assert
+	var panicErr *dataloader.PanicError
+	assert.ErrorAs(t, (*r)["1"].Err, &panicErr, "Expected the panic to be returned")
+	assert.Equal(t, "boom", panicErr.Value, "Expected the panic value")
+}
@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/andy9775/dataloader"
+)
+
+// flags of the header byte of results encoded by the binary codec
+const (
+	binaryHasValue byte = 1 << iota
+	binaryHasError
+)
+
+// BinaryUnmarshalerKey is implemented by (pointers to) keys which can be decoded from bytes
+type BinaryUnmarshalerKey interface {
+	dataloader.Key
+	encoding.BinaryUnmarshaler
+}
+
+// BinaryKeyCodec returns a KeyCodec which encodes keys implementing encoding.BinaryMarshaler using
+// MarshalBinary. Keys which do not implement encoding.BinaryMarshaler are encoded using Key.String(). Keys
+// are decoded by calling UnmarshalBinary on a new key returned by the provided function, which should
+// return a pointer to an empty key value.
+func BinaryKeyCodec(newKey func() BinaryUnmarshalerKey) KeyCodec {
+	return &binaryKeyCodec{newKey: newKey}
+}
+
+// Binary returns a Codec which encodes result values implementing encoding.BinaryMarshaler using
+// MarshalBinary, e.g. protobuf or msgpack backed types. Values are decoded by calling UnmarshalBinary on a
+// new value returned by the provided function, which should return a pointer to an empty value. The
+// decoded Result contains that pointer. Marshal returns an error for values which do not implement
+// encoding.BinaryMarshaler.
+func Binary(newValue func() encoding.BinaryUnmarshaler) Codec {
+	return &binaryCodec{newValue: newValue}
+}
+
+// ======================================= binary key codec implementation ===================================
+
+type binaryKeyCodec struct {
+	newKey func() BinaryUnmarshalerKey
+}
+
+func (*binaryKeyCodec) EncodeKey(key dataloader.Key) (string, error) {
+	m, ok := key.(encoding.BinaryMarshaler)
+	if !ok {
+		return key.String(), nil
+	}
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("codec: unable to marshal key %s: %v", key.String(), err)
+	}
+	return string(b), nil
+}
+
+func (c *binaryKeyCodec) DecodeKey(s string) (dataloader.Key, error) {
+	key := c.newKey()
+	if err := key.UnmarshalBinary([]byte(s)); err != nil {
+		return nil, fmt.Errorf("codec: unable to unmarshal key %q: %v", s, err)
+	}
+	return key, nil
+}
+
+// ========================================= binary codec implementation =====================================
+
+// binaryCodec encodes a result as a header byte of flags, followed by the length prefixed error message
+// if the result has an error and the marshaled value if the result has a value
+type binaryCodec struct {
+	newValue func() encoding.BinaryUnmarshaler
+}
+
+func (*binaryCodec) Marshal(r dataloader.Result) ([]byte, error) {
+	var header byte
+	data := []byte{0}
+
+	if r.Err != nil {
+		header |= binaryHasError
+		msg := r.Err.Error()
+		var size [binary.MaxVarintLen64]byte
+		data = append(data, size[:binary.PutUvarint(size[:], uint64(len(msg)))]...)
+		data = append(data, msg...)
+	}
+
+	if r.Result != nil {
+		m, ok := r.Result.(encoding.BinaryMarshaler)
+		if !ok {
+			return nil, fmt.Errorf("codec: result of type %T does not implement encoding.BinaryMarshaler", r.Result)
+		}
+		b, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		header |= binaryHasValue
+		data = append(data, b...)
+	}
+
+	data[0] = header
+	return data, nil
+}
+
+func (c *binaryCodec) Unmarshal(data []byte) (dataloader.Result, error) {
+	if len(data) == 0 {
+		return dataloader.Result{}, errors.New("codec: missing header")
+	}
+	header, data := data[0], data[1:]
+
+	var r dataloader.Result
+	if header&binaryHasError != 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return dataloader.Result{}, errors.New("codec: malformed error message")
+		}
+		r.Err = errors.New(string(data[n : n+int(size)]))
+		data = data[n+int(size):]
+	}
+
+	if header&binaryHasValue != 0 {
+		v := c.newValue()
+		if err := v.UnmarshalBinary(data); err != nil {
+			return dataloader.Result{}, err
+		}
+		r.Result = v
+	}
+	return r, nil
+}
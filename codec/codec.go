@@ -0,0 +1,58 @@
+/*
+Package codec contains encoders used to serialize results for remote caches.
+
+A Codec converts a dataloader.Result to and from bytes. Errors are serialized as
+their message and restored as plain errors.
+*/
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/andy9775/dataloader"
+)
+
+// Codec serializes results for storage outside of the process
+type Codec interface {
+	// Marshal encodes the result
+	Marshal(dataloader.Result) ([]byte, error)
+	// Unmarshal decodes a result previously encoded by Marshal
+	Unmarshal([]byte) (dataloader.Result, error)
+}
+
+// JSON returns a Codec which encodes results as JSON. Result values are decoded into their generic JSON
+// representation (e.g. map[string]interface{} for objects, float64 for numbers).
+func JSON() Codec {
+	return &jsonCodec{}
+}
+
+// ========================================= json codec implementation =======================================
+
+type jsonCodec struct{}
+
+type jsonResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Err    string      `json:"error,omitempty"`
+}
+
+func (*jsonCodec) Marshal(r dataloader.Result) ([]byte, error) {
+	j := jsonResult{Result: r.Result}
+	if r.Err != nil {
+		j.Err = r.Err.Error()
+	}
+	return json.Marshal(j)
+}
+
+func (*jsonCodec) Unmarshal(data []byte) (dataloader.Result, error) {
+	var j jsonResult
+	if err := json.Unmarshal(data, &j); err != nil {
+		return dataloader.Result{}, err
+	}
+
+	r := dataloader.Result{Result: j.Result}
+	if j.Err != "" {
+		r.Err = errors.New(j.Err)
+	}
+	return r, nil
+}
@@ -0,0 +1,193 @@
+package codec_test
+
+import (
+	"compress/gzip"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+func (p PrimaryKey) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *PrimaryKey) UnmarshalText(b []byte) error {
+	i, err := strconv.Atoi(string(b))
+	*p = PrimaryKey(i)
+	return err
+}
+
+func (p PrimaryKey) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(p))
+	return b, nil
+}
+
+func (p *PrimaryKey) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return fmt.Errorf("expected 8 bytes, got %d", len(b))
+	}
+	*p = PrimaryKey(binary.BigEndian.Uint64(b))
+	return nil
+}
+
+// user is a result value which implements binary marshaling
+type user struct {
+	Name string
+}
+
+func (u user) MarshalBinary() ([]byte, error) {
+	return []byte(u.Name), nil
+}
+
+func (u *user) UnmarshalBinary(b []byte) error {
+	u.Name = string(b)
+	return nil
+}
+
+// ================================================== tests ==================================================
+
+// TestJSONRoundTrip ensures results and errors survive encoding
+func TestJSONRoundTrip(t *testing.T) {
+	// setup
+	c := codec.JSON()
+
+	// invoke
+	data, err := c.Marshal(dataloader.Result{Result: "value", Err: errors.New("failed")})
+	assert.NoError(t, err, "Expected no error encoding the result")
+	r, err := c.Unmarshal(data)
+
+	// assert
+	assert.NoError(t, err, "Expected no error decoding the result")
+	assert.Equal(t, "value", r.Result, "Expected decoded value")
+	assert.EqualError(t, r.Err, "failed", "Expected decoded error")
+}
+
+// TestTextKeyCodecRoundTrip ensures custom keys survive encoding
+func TestTextKeyCodecRoundTrip(t *testing.T) {
+	// setup
+	c := codec.TextKeyCodec(func() codec.TextUnmarshalerKey { return new(PrimaryKey) })
+
+	// invoke
+	s, err := c.EncodeKey(PrimaryKey(42))
+	assert.NoError(t, err, "Expected no error encoding the key")
+	key, err := c.DecodeKey(s)
+
+	// assert
+	assert.NoError(t, err, "Expected no error decoding the key")
+	assert.Equal(t, PrimaryKey(42), *key.(*PrimaryKey), "Expected decoded key")
+
+	_, err = c.DecodeKey("not_a_number")
+	assert.Error(t, err, "Expected error decoding an invalid key")
+}
+
+// TestCompressRoundTrip ensures values above the threshold are compressed and all values survive encoding
+func TestCompressRoundTrip(t *testing.T) {
+	// setup
+	c := codec.Compress(codec.JSON(), codec.Gzip(gzip.BestSpeed), 64)
+	small := dataloader.Result{Result: "small", Err: nil}
+	large := dataloader.Result{Result: strings.Repeat("large ", 100), Err: nil}
+
+	// invoke
+	smallData, err := c.Marshal(small)
+	assert.NoError(t, err, "Expected small value to encode")
+	largeData, err := c.Marshal(large)
+	assert.NoError(t, err, "Expected large value to encode")
+
+	// assert
+	plain, _ := codec.JSON().Marshal(large)
+	assert.True(t, len(largeData) < len(plain), "Expected large value to be compressed")
+
+	r, err := c.Unmarshal(smallData)
+	assert.NoError(t, err, "Expected small value to decode")
+	assert.Equal(t, small.Result, r.Result, "Expected small value to survive encoding")
+
+	r, err = c.Unmarshal(largeData)
+	assert.NoError(t, err, "Expected large value to decode")
+	assert.Equal(t, large.Result, r.Result, "Expected large value to survive encoding")
+}
+
+// TestBinaryRoundTrip ensures values and errors survive binary encoding
+func TestBinaryRoundTrip(t *testing.T) {
+	// setup
+	c := codec.Binary(func() encoding.BinaryUnmarshaler { return &user{} })
+
+	for _, expected := range []dataloader.Result{
+		{Result: user{Name: "alice"}, Err: nil},
+		{Result: user{Name: "bob"}, Err: errors.New("stale")},
+		{Result: nil, Err: errors.New("failed")},
+		{Result: nil, Err: nil},
+	} {
+		// invoke
+		data, err := c.Marshal(expected)
+		assert.NoError(t, err, "Expected no error encoding the result")
+		r, err := c.Unmarshal(data)
+
+		// assert
+		assert.NoError(t, err, "Expected no error decoding the result")
+		if expected.Result != nil {
+			assert.Equal(t, expected.Result, *r.Result.(*user), "Expected decoded value")
+		} else {
+			assert.Nil(t, r.Result, "Expected no value")
+		}
+		if expected.Err != nil {
+			assert.EqualError(t, r.Err, expected.Err.Error(), "Expected decoded error")
+		} else {
+			assert.NoError(t, r.Err, "Expected no error")
+		}
+	}
+}
+
+// TestBinaryErrors ensures values which can't be marshaled and malformed data return errors
+func TestBinaryErrors(t *testing.T) {
+	// setup
+	c := codec.Binary(func() encoding.BinaryUnmarshaler { return &user{} })
+
+	// invoke
+	_, marshalErr := c.Marshal(dataloader.Result{Result: "value", Err: nil})
+	_, emptyErr := c.Unmarshal(nil)
+	_, truncatedErr := c.Unmarshal([]byte{2, 10, 'a'})
+
+	// assert
+	assert.Error(t, marshalErr, "Expected an error for a value without binary marshaling")
+	assert.Error(t, emptyErr, "Expected an error for empty data")
+	assert.Error(t, truncatedErr, "Expected an error for a truncated error message")
+}
+
+// TestBinaryKeyCodecRoundTrip ensures custom keys survive binary encoding
+func TestBinaryKeyCodecRoundTrip(t *testing.T) {
+	// setup
+	c := codec.BinaryKeyCodec(func() codec.BinaryUnmarshalerKey { return new(PrimaryKey) })
+
+	// invoke
+	s, err := c.EncodeKey(PrimaryKey(42))
+	assert.NoError(t, err, "Expected no error encoding the key")
+	key, err := c.DecodeKey(s)
+
+	// assert
+	assert.NoError(t, err, "Expected no error decoding the key")
+	assert.Len(t, s, 8, "Expected the binary encoding of the key")
+	assert.Equal(t, PrimaryKey(42), *key.(*PrimaryKey), "Expected decoded key")
+
+	_, err = c.DecodeKey("short")
+	assert.Error(t, err, "Expected error decoding an invalid key")
+}
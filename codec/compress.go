@@ -0,0 +1,109 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+
+	"github.com/andy9775/dataloader"
+)
+
+// encoded values are prefixed with a header byte identifying whether the value was compressed
+const (
+	headerRaw        byte = 0
+	headerCompressed byte = 1
+)
+
+// Compressor compresses encoded results. Implementations may wrap snappy, zstd or any other algorithm.
+type Compressor interface {
+	Compress([]byte) ([]byte, error)
+	Decompress([]byte) ([]byte, error)
+}
+
+// Gzip returns a Compressor which uses gzip at the provided compression level (see compress/gzip)
+func Gzip(level int) Compressor {
+	return &gzipCompressor{level: level}
+}
+
+// Compress returns a Codec which compresses values encoded by the codec whose size exceeds the threshold
+// in bytes. Smaller values are stored uncompressed to avoid the compression overhead. Values encoded by
+// the returned Codec are prefixed with a header byte and can only be decoded by a compressing Codec.
+func Compress(c Codec, compressor Compressor, threshold int) Codec {
+	return &compressCodec{codec: c, compressor: compressor, threshold: threshold}
+}
+
+// ====================================== compress codec implementation ======================================
+
+type compressCodec struct {
+	codec      Codec
+	compressor Compressor
+	threshold  int
+}
+
+func (c *compressCodec) Marshal(r dataloader.Result) ([]byte, error) {
+	data, err := c.codec.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) <= c.threshold {
+		return append([]byte{headerRaw}, data...), nil
+	}
+
+	compressed, err := c.compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{headerCompressed}, compressed...), nil
+}
+
+func (c *compressCodec) Unmarshal(data []byte) (dataloader.Result, error) {
+	if len(data) == 0 {
+		return dataloader.Result{}, errors.New("codec: missing compression header")
+	}
+
+	switch data[0] {
+	case headerRaw:
+		return c.codec.Unmarshal(data[1:])
+	case headerCompressed:
+		decompressed, err := c.compressor.Decompress(data[1:])
+		if err != nil {
+			return dataloader.Result{}, err
+		}
+		return c.codec.Unmarshal(decompressed)
+	default:
+		return dataloader.Result{}, errors.New("codec: unknown compression header")
+	}
+}
+
+// ========================================= gzip compressor implementation ==================================
+
+type gzipCompressor struct {
+	level int
+}
+
+func (g *gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, g.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (*gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/andy9775/dataloader"
+)
+
+// KeyCodec serializes keys so that custom key types survive crossing process boundaries, for example
+// when used as the key of a remote cache entry.
+type KeyCodec interface {
+	// EncodeKey encodes the key as a string
+	EncodeKey(dataloader.Key) (string, error)
+	// DecodeKey decodes a key previously encoded by EncodeKey
+	DecodeKey(string) (dataloader.Key, error)
+}
+
+// StringKeyCodec returns a KeyCodec which encodes keys using Key.String() and decodes them as a
+// dataloader.StringKey. This is the default used by the remote caches.
+func StringKeyCodec() KeyCodec {
+	return &stringKeyCodec{}
+}
+
+// TextKeyCodec returns a KeyCodec which encodes keys implementing encoding.TextMarshaler using MarshalText.
+// Keys which do not implement encoding.TextMarshaler are encoded using Key.String(). Keys are decoded by
+// calling UnmarshalText on a new key returned by the provided function, which should return a pointer to
+// an empty key value.
+func TextKeyCodec(newKey func() TextUnmarshalerKey) KeyCodec {
+	return &textKeyCodec{newKey: newKey}
+}
+
+// TextUnmarshalerKey is implemented by (pointers to) keys which can be decoded from text
+type TextUnmarshalerKey interface {
+	dataloader.Key
+	encoding.TextUnmarshaler
+}
+
+// ====================================== string key codec implementation ====================================
+
+type stringKeyCodec struct{}
+
+func (*stringKeyCodec) EncodeKey(key dataloader.Key) (string, error) {
+	return key.String(), nil
+}
+
+func (*stringKeyCodec) DecodeKey(s string) (dataloader.Key, error) {
+	return dataloader.StringKey(s), nil
+}
+
+// ======================================= text key codec implementation =====================================
+
+type textKeyCodec struct {
+	newKey func() TextUnmarshalerKey
+}
+
+func (*textKeyCodec) EncodeKey(key dataloader.Key) (string, error) {
+	m, ok := key.(encoding.TextMarshaler)
+	if !ok {
+		return key.String(), nil
+	}
+
+	b, err := m.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("codec: unable to marshal key %s: %v", key.String(), err)
+	}
+	return string(b), nil
+}
+
+func (c *textKeyCodec) DecodeKey(s string) (dataloader.Key, error) {
+	key := c.newKey()
+	if err := key.UnmarshalText([]byte(s)); err != nil {
+		return nil, fmt.Errorf("codec: unable to unmarshal key %q: %v", s, err)
+	}
+	return key, nil
+}
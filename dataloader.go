@@ -2,24 +2,100 @@ package dataloader
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/go-log/log"
+	"github.com/andy9775/dataloader/backoff"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/logger"
 )
 
 // DataLoader is the identifying interface for the dataloader.
 // Each DataLoader instance tracks the resolved elements.
 // Note that calling Load and LoadMany on the same dataloader instance
 // will increment the same counter, once for each method call.
+//
+// Load and LoadMany are safe for concurrent use by multiple go routines, including
+// concurrent calls with overlapping keys. Each ThunkMany returns a ResultMap owned by
+// the caller which contains the results for all of the callers keys and is never
+// shared with other callers. A returned Thunk or ThunkMany may itself be called from
+// multiple go routines. The configured Cache must be go routine safe.
 type DataLoader interface {
 	// Load returns a Thunk for the specified Key.
 	// Internally Load adds the provided key to the keys array and returns a callback
-	// function which when called returns the value for the key
-	Load(context.Context, Key) Thunk
+	// function which when called returns the value for the key. The LoadOptions override
+	// the loader and strategy configuration for the call.
+	Load(context.Context, Key, ...LoadOption) Thunk
+
+	// LoadChan loads the key like Load and returns a channel which receives the result of the key, so that
+	// callers can select on it together with other channels.
+	LoadChan(context.Context, Key, ...LoadOption) <-chan Result
+
+	// LoadFunc loads the key like Load and calls the function with the result of the key in a new go routine
+	// once it resolves, for event driven code which doesn't block on a Thunk.
+	LoadFunc(context.Context, Key, func(Result), ...LoadOption)
 
 	// LoadMany returns a ThunkMany for the specified keys.
 	// Internally LoadMany adds the provided keys to the keys array and returns a callback
 	// function which when called returns the values for the provided keys.
 	LoadMany(context.Context, ...Key) ThunkMany
+
+	// LoadManyOrdered loads the keys like LoadMany and returns a function which returns a Result for each
+	// key in the order of the keys, e.g. for paginated responses, and a KeyErrors error aggregating the
+	// errors of the keys which failed to resolve.
+	LoadManyOrdered(context.Context, ...Key) func() ([]Result, error)
+
+	// LoadMap loads the keys like LoadMany, blocks until they resolve and returns the values mapped by the keys
+	// identifier, and a KeyErrors error aggregating the errors of the keys which failed to resolve.
+	LoadMap(context.Context, ...Key) (map[string]interface{}, error)
+
+	// LoadManyStream loads the keys like LoadMany and returns a ThunkManyStream which delivers the result of
+	// each key on a channel as soon as it resolves, instead of once every key has resolved.
+	LoadManyStream(context.Context, ...Key) ThunkManyStream
+
+	// LastBatchID returns the ID of the most recent call to the batch function. Batch IDs
+	// start at 1 and increase monotonically for each call to the batch function made by
+	// the loader. LastBatchID returns 0 if the batch function has not been called.
+	LastBatchID() uint64
+
+	// Prime stores the result for the key in the loader, replacing any existing primed result.
+	// Subsequent calls to Load or LoadMany for the key return the primed result without calling the
+	// batch function. This is useful for results which are already known, e.g. after a create mutation.
+	Prime(context.Context, Key, Result)
+
+	// Clear removes the primed and cached result for the key so that the next call to Load or LoadMany
+	// for the key calls the batch function. Thunks which have already resolved are not affected.
+	Clear(context.Context, Key)
+
+	// ClearAll removes all primed and cached results.
+	ClearAll(context.Context)
+
+	// Flush calls the batch function with the keys which have accumulated if the strategy implements
+	// Flusher, instead of waiting for capacity or a timeout. Flush is a no-op for other strategies.
+	Flush(context.Context)
+
+	// SwapStrategy replaces the strategy on a live loader with a new strategy built by the StrategyFunction,
+	// e.g. to compare strategies under production traffic. Loads issued after the swap use the new
+	// strategy while keys pending in the previous strategy are flushed if it implements Flusher.
+	SwapStrategy(context.Context, StrategyFunction)
+
+	// StrategyState returns a snapshot of the strategies internal state if the strategy implements
+	// Introspectable. The second return value is false for other strategies.
+	StrategyState() (StrategyState, bool)
+
+	// Stats returns a snapshot of the loaders counters, e.g. the number of loads and batches and the cache
+	// hit rate.
+	Stats() Stats
+
+	// Drain flushes the pending keys and blocks until the strategy reports no pending keys (see
+	// Introspectable) and no call to the batch function is in flight. Drain returns the contexts error if
+	// the context is done first.
+	Drain(context.Context) error
 }
 
 // StrategyFunction defines the return type of strategy builder functions.
@@ -30,6 +106,64 @@ type StrategyFunction func(int, BatchFunction) Strategy
 // the loader capacity
 type BatchFunction func(context.Context, Keys) *ResultMap
 
+// BatchFunctionE is a batch function which may fail as a whole (e.g. connection refused). A non-nil error
+// is returned as the result for every key in the batch. Use FromBatchFunctionE to pass it to the loader.
+type BatchFunctionE func(context.Context, Keys) (*ResultMap, error)
+
+// FromBatchFunctionE adapts a BatchFunctionE to a BatchFunction. If the batch function returns an error,
+// each key resolves to a result containing the error.
+func FromBatchFunctionE(batch BatchFunctionE) BatchFunction {
+	return func(ctx context.Context, keys Keys) *ResultMap {
+		r, err := batch(ctx, keys)
+		if err != nil {
+			return errorResultMap(keys, err)
+		}
+		if r == nil {
+			empty := NewResultMap(0)
+			return &empty
+		}
+		return r
+	}
+}
+
+// RateLimiter throttles calls to the batch function. It is satisfied by *rate.Limiter from
+// golang.org/x/time/rate.
+type RateLimiter interface {
+	// Wait blocks until the batch function may be called. A non-nil error (e.g. the context is done)
+	// fails the batch.
+	Wait(context.Context) error
+}
+
+// BatchValidator is called with the keys and the ResultMap returned by the batch function after every
+// call to the batch function. Returning a non-nil error fails the batch.
+type BatchValidator func(Keys, *ResultMap) error
+
+// ErrThunkConsumed is returned by a Thunk which has already been called when the loader is configured
+// with WithSingleUseThunks.
+var ErrThunkConsumed = errors.New("dataloader: thunk result already consumed")
+
+// DuplicateKeyPolicy determines how LoadMany handles duplicate keys
+type DuplicateKeyPolicy int
+
+// Duplicate key policies
+const (
+	// DedupeDuplicateKeys silently resolves duplicate keys once (default)
+	DedupeDuplicateKeys DuplicateKeyPolicy = iota
+	// RejectDuplicateKeys resolves every key passed to LoadMany to a DuplicateKeysError
+	RejectDuplicateKeys
+)
+
+// DuplicateKeysError is returned for every key passed to LoadMany if the keys contain duplicates and the
+// loader is configured with RejectDuplicateKeys.
+type DuplicateKeysError struct {
+	// Keys contains the string value of each duplicated key
+	Keys []string
+}
+
+func (e *DuplicateKeysError) Error() string {
+	return fmt.Sprintf("dataloader: duplicate keys passed to LoadMany: %s", strings.Join(e.Keys, ", "))
+}
+
 // Thunk returns a result for the key that it was generated for.
 // Calling the Thunk function will block until the result is returned from the batch function.
 type Thunk func() (Result, bool)
@@ -51,8 +185,11 @@ func NewDataLoader(
 	fn StrategyFunction,
 	opts ...Option,
 ) DataLoader {
+	return newDataLoader(capacity, batch, fn, opts...)
+}
 
-	loader := dataloader{}
+func newDataLoader(capacity int, batch BatchFunction, fn StrategyFunction, opts ...Option) *dataloader {
+	loader := dataloader{primed: NewResultMap(0), primaryKeys: make(map[string]int)}
 
 	// set the options
 	for _, apply := range opts {
@@ -69,20 +206,104 @@ func NewDataLoader(
 	}
 
 	if loader.logger == nil {
-		loader.logger = log.DefaultLogger // no op logger
+		loader.logger = logger.Noop()
+	}
+
+	if loader.metrics == nil {
+		loader.metrics = NewNoOpMetrics()
+	}
+
+	if loader.clock == nil {
+		loader.clock = clock.New()
+	}
+
+	if loader.backoff == nil {
+		loader.backoff = backoff.Exponential{Base: 10 * time.Millisecond, Max: time.Second}.Delay
+	}
+
+	if loader.errorClassifier == nil {
+		loader.errorClassifier = DefaultErrorClassifier
 	}
 
+	if loader.pipeline == nil {
+		loader.pipeline = DefaultPipeline
+	}
+	loader.lookups, loader.fallbacks = loader.bindPipeline(loader.pipeline)
+
 	// wrap the batch function and implement tracing around it
 	batchFunc := func(ogCtx context.Context, keys Keys) *ResultMap {
-		ctx, finish := loader.tracer.Batch(ogCtx)
+		atomic.AddInt64(&loader.inflight, 1)
+		defer atomic.AddInt64(&loader.inflight, -1)
 
-		r := batch(ctx, keys)
+		calls := loader.inflightCalls(keys)
+		id := atomic.AddUint64(&loader.batchID, 1)
+		info := BatchInfo{BatchID: id, Keys: keys, Size: keys.Length(), Strategy: strategyFromContext(ogCtx)}
+		ctx, finish := loader.tracer.Batch(context.WithValue(ogCtx, batchInfoKey{}, info))
+		loader.logger.Debug("executing batch", "batch_id", id, "keys", keys.Length())
 
-		finish(*r)
-		return r
+		// route a fraction of the batches to the canary batch function
+		fn, canary := batch, false
+		if loader.canaryBatch != nil && rand.Float64() < loader.canaryFraction {
+			fn, canary = loader.canaryBatch, true
+			loader.logger.Debug("routing batch to canary", "batch_id", id)
+		}
+		if loader.replicaBatch != nil {
+			fn = loader.routeReplica(fn)
+		}
+		if loader.batchRouter != nil {
+			fn = loader.routeBatch(fn)
+		}
+		if loader.maxBatchSize > 0 {
+			fn = chunkBatch(fn, loader.maxBatchSize, loader.parallelism, loader.logger)
+		}
+		fn = Chain(fn, loader.middlewares...)
+
+		loader.hooks.BatchStart(ctx, info)
+		started := time.Now()
+		r := loader.execute(ctx, id, fn, keys)
+		for attempt := 1; attempt <= loader.retryAttempts && batchFailed(keys, *r); attempt++ {
+			if backoff.Sleep(ctx, loader.backoff(attempt)) != nil {
+				break
+			}
+			loader.logger.Info("retrying batch", "batch_id", id, "attempt", attempt)
+			r = loader.execute(ctx, id, fn, keys)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			loader.logger.Info("batch exceeded the context deadline", "batch_id", id)
+			r = deadlineExceeded(keys, *r, loader.deadlinePolicy)
+		}
+		finished := time.Now()
+		errCount := countErrors(*r)
+		info.Duration, info.Errors = finished.Sub(started), errCount
+		atomic.AddUint64(&loader.batches, 1)
+		atomic.AddUint64(&loader.batchedKeys, uint64(keys.Length()))
+		loader.hooks.BatchEnd(ctx, info)
+		loader.metrics.BatchExecuted(canary, finished.Sub(started), errCount)
+		for class, count := range classifyErrors(*r, loader.errorClassifier) {
+			loader.metrics.ClassifiedErrors(canary, class, count)
+		}
+
+		// tag each result with the batch which produced it. A new result map is built as the
+		// batch function may return a result map shared with other callers.
+		result := NewResultMap(len(*r))
+		for k, v := range *r {
+			v.Source = Source{BatchID: id, Started: started, Finished: finished}
+			result[k] = v
+		}
+
+		if loader.cacheWarming {
+			loader.warm(ctx, keys, result)
+		}
+		loader.forget(calls)
+
+		finish(result)
+		return &result
 	}
 
-	loader.strategy = fn(capacity, batchFunc)
+	loader.capacity = capacity
+	loader.batchFunc = batchFunc
+	loader.userBatch = batch
+	loader.strategy = loader.newStrategy(fn)
 
 	return &loader
 }
@@ -104,41 +325,397 @@ func WithTracer(tracer Tracer) Option {
 }
 
 // WithLogger adds a logger to the dataloader. The default is a no op logger
-func WithLogger(logger log.Logger) Option {
+func WithLogger(logger logger.Logger) Option {
 	return func(l *dataloader) {
 		l.logger = logger
 	}
 }
 
+// WithExecutor runs calls to the batch function on the provided executor. By default the batch
+// function is called on the strategies go routine.
+func WithExecutor(executor Executor) Option {
+	return func(l *dataloader) {
+		l.executor = executor
+	}
+}
+
+// WithMetrics adds a metrics recorder to the dataloader. The default is a no op recorder
+func WithMetrics(metrics Metrics) Option {
+	return func(l *dataloader) {
+		l.metrics = metrics
+	}
+}
+
+// Use decorates the batch function with the middlewares, the first middleware being the outermost. The
+// middlewares are called once per call to the batch function (including retries) with every key in the
+// batch, after the keys have been routed to the canary or replica and split into chunks. Calling Use more
+// than once appends the middlewares.
+func Use(middlewares ...BatchMiddleware) Option {
+	return func(l *dataloader) {
+		l.middlewares = append(l.middlewares, middlewares...)
+	}
+}
+
+// WithHooks sets callbacks which are invoked when a batch starts and ends and when a key is resolved from
+// the cache or passed to the strategy.
+func WithHooks(hooks Hooks) Option {
+	return func(l *dataloader) {
+		l.hooks = hooks
+	}
+}
+
+// WithBatchValidator adds a validator which is called after every call to the batch function. If the
+// validator returns an error, each key in the batch resolves to a result containing the error.
+func WithBatchValidator(validator BatchValidator) Option {
+	return func(l *dataloader) {
+		l.validator = validator
+	}
+}
+
+// WithSingleUseThunks configures the returned Thunk and ThunkMany functions to drop the resolved results
+// after they are first called, allowing them to be garbage collected. Subsequent calls to a Thunk return
+// ErrThunkConsumed and subsequent calls to a ThunkMany return an empty ResultMap. This is useful for
+// memory sensitive jobs which load many keys and never read the results twice. Note that results are
+// still stored in the configured cache.
+func WithSingleUseThunks() Option {
+	return func(l *dataloader) {
+		l.singleUseThunks = true
+	}
+}
+
+// WithCanary routes the provided fraction (0 to 1) of calls to the batch function to the canary batch
+// function instead. The configured metrics recorder records the latency and errors of each batch labeled
+// by whether the canary was used, allowing a new batch function to be rolled out gradually.
+func WithCanary(fraction float64, canaryBatch BatchFunction) Option {
+	return func(l *dataloader) {
+		l.canaryFraction = fraction
+		l.canaryBatch = canaryBatch
+	}
+}
+
+// WithErrorClassifier sets the function used to classify errors returned by the batch function. Error
+// metrics are labeled by the returned class. The default is DefaultErrorClassifier.
+func WithErrorClassifier(classifier ErrorClassifier) Option {
+	return func(l *dataloader) {
+		l.errorClassifier = classifier
+	}
+}
+
+// WithMaxBatchSize limits the number of keys passed to the batch function. Batches with more keys are
+// split into chunks of at most size keys, calling the batch function once per chunk and merging the
+// results (e.g. to respect SQL IN clause or API limits). A size of zero (default) disables chunking.
+func WithMaxBatchSize(size int) Option {
+	return func(l *dataloader) {
+		l.maxBatchSize = size
+	}
+}
+
+// WithChunkParallelism sets the number of chunks which are passed to the batch function concurrently when
+// batches are split by WithMaxBatchSize. The default of one calls the batch function for each chunk in turn.
+func WithChunkParallelism(parallelism int) Option {
+	return func(l *dataloader) {
+		l.parallelism = parallelism
+	}
+}
+
+// WithKeyHedging calls the batch function directly with a single key if the batch containing the key
+// hasn't returned after the delay, using the first result to return. Only keys for which critical returns
+// true are hedged, allowing latency critical keys to avoid waiting behind a large batch. Hedging only
+// applies to Load. The hedged call bypasses the loader: it isn't traced, counted in the stats or metrics,
+// and doesn't pass through the middlewares.
+func WithKeyHedging(delay time.Duration, critical func(Key) bool) Option {
+	return func(l *dataloader) {
+		l.hedgeDelay = delay
+		l.hedgeKey = critical
+	}
+}
+
+// WithClock sets the time source of the loaders timers, e.g. a clock.Mock controlling the hedging delay in
+// tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(l *dataloader) {
+		l.clock = c
+	}
+}
+
+// WithCacheWarming stores results which the batch function returns for keys that were not in the batch
+// (e.g. author rows already fetched by a join) in the loaders cache and the provided sibling caches,
+// instead of discarding them. Subsequent loads for those keys are served from the cache.
+func WithCacheWarming(siblings ...Cache) Option {
+	return func(l *dataloader) {
+		l.cacheWarming = true
+		l.warmCaches = siblings
+	}
+}
+
+// WithRetry retries a failed batch up to attempts times, waiting for the duration returned by backoff
+// before each retry. A batch fails if the batch function doesn't return a successful result for any of
+// the keys, or if the batch validator returns an error. If backoff is nil, an exponential backoff
+// starting at 10ms and capped at 1s is used. Retries stop once the context is done.
+func WithRetry(attempts int, backoff Backoff) Option {
+	return func(l *dataloader) {
+		l.retryAttempts = attempts
+		l.backoff = backoff
+	}
+}
+
+// WithDuplicateKeyPolicy sets how LoadMany handles duplicate keys. The default, DedupeDuplicateKeys,
+// resolves duplicate keys once. RejectDuplicateKeys resolves every key to a DuplicateKeysError without
+// calling the batch function, for applications which consider duplicate keys a programming error.
+func WithDuplicateKeyPolicy(policy DuplicateKeyPolicy) Option {
+	return func(l *dataloader) {
+		l.duplicateKeyPolicy = policy
+	}
+}
+
+// WithRateLimiter throttles how often the batch function is called. Each batch waits for the rate limiter
+// before calling the batch function. Keys loaded while a batch waits are queued by the strategy into the
+// next batch. Retries (see WithRetry) also wait for the rate limiter. If the rate limiter returns an
+// error, each key in the batch resolves to the error.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(l *dataloader) {
+		l.rateLimiter = limiter
+	}
+}
+
+// WithReplicaBatch routes keys to the replica batch function, e.g. reading from a database read replica
+// which lags the primary by up to maxStaleness. Loads made with a context which tolerates less staleness
+// (see FreshRead and WithMaxStaleness) are passed to the primary batch function. Keys which the replica
+// fails to resolve (missing or error results) fall back to the primary batch function.
+func WithReplicaBatch(replica BatchFunction, maxStaleness time.Duration) Option {
+	return func(l *dataloader) {
+		l.replicaBatch = replica
+		l.replicaStaleness = maxStaleness
+	}
+}
+
+// WithBatchRouter lets the loader front several batch functions, e.g. hot keys stored in Redis and cold keys
+// in Postgres, while sharing a single cache and Load API. Each batch is split by the ID the router returns
+// for each key and the batch functions are called concurrently. Keys routed to an ID missing from batches
+// are passed to the batch function the loader was created with (and its replica, see WithReplicaBatch).
+func WithBatchRouter(router BatchRouter, batches map[BatchID]BatchFunction) Option {
+	return func(l *dataloader) {
+		l.batchRouter = router
+		l.batchFunctions = batches
+	}
+}
+
+// WithDeadlineExceededPolicy sets which results are kept when the batch context's deadline expires before
+// the batch function returns. Keys without a kept result resolve to context.DeadlineExceeded, which is
+// recorded by the metrics recorder as an ErrorClassTimeout error by the DefaultErrorClassifier. The
+// default is KeepPartialResults.
+func WithDeadlineExceededPolicy(policy DeadlineExceededPolicy) Option {
+	return func(l *dataloader) {
+		l.deadlinePolicy = policy
+	}
+}
+
+// WithPipeline sets the stages keys pass through on the read path, replacing DefaultPipeline
+// (IdentityMapStage, LoaderCacheStage, BatchStage). Stages before BatchStage short-circuit the pipeline and
+// stages after it are fallbacks for keys the batch function failed to resolve. BatchStage is appended if
+// it is not included.
+func WithPipeline(stages ...Stage) Option {
+	return func(l *dataloader) {
+		l.pipeline = stages
+	}
+}
+
+// WithErrorStorage stores results with a non-nil Err returned by the batch function in the stages before the
+// batch stage, e.g. to cache NotFoundError results. By default only successful results are stored so that
+// transient failures are retried by later loads.
+func WithErrorStorage() Option {
+	return func(l *dataloader) {
+		l.storeErrors = true
+	}
+}
+
+// WithResultCloner copies each result before it is returned to the caller, whether it is resolved from the
+// primed results, the cache or the batch function, so that a caller mutating a returned value (e.g. through
+// a pointer) doesn't corrupt the value seen by other callers or future cache hits. If cloner is nil,
+// DeepCopy is used.
+func WithResultCloner(cloner ResultCloner) Option {
+	return func(l *dataloader) {
+		if cloner == nil {
+			cloner = DeepCopy
+		}
+		l.resultCloner = cloner
+	}
+}
+
+// WithResultSpill releases the results retained by a ThunkMany once it resolved more than threshold results
+// (counted in results, not bytes), e.g. for ETL jobs which keep many ThunkMany around. The results are
+// written to a temporary file in dir (the default temporary directory if empty) and the calls to the
+// ThunkMany after the first read them back from the file. It doesn't bound the memory used while loading:
+// the batch results and the ResultMap returned by the first call are held in memory in full.
+// Results are encoded with encoding/gob, so the concrete types of result values must be registered with
+// gob.Register. Results with an error are kept in memory so that their errors keep their identity. If the
+// results can't be encoded they are kept in memory and the failure is logged. The file is removed once the
+// ThunkMany is garbage collected.
+func WithResultSpill(dir string, threshold int) Option {
+	return func(l *dataloader) {
+		l.spillDir = dir
+		l.spillThreshold = threshold
+	}
+}
+
 // ================================================================================================
 
 type dataloader struct {
-	strategy Strategy
+	// batchID and the stats counters must be first in the struct to ensure 64-bit alignment for atomic
+	// operations
+	batchID     uint64
+	loads       uint64
+	batches     uint64
+	batchedKeys uint64
+	cacheHits   uint64
+	cacheMisses uint64
+	inflight    int64 // number of calls to the batch function which haven't returned
+
+	// strategyMutex guards strategy which may be replaced by SwapStrategy
+	strategyMutex sync.RWMutex
+	strategy      Strategy
+	capacity      int
+	batchFunc     BatchFunction // wrapped batch function passed to the strategy
+	userBatch     BatchFunction // batch function provided to NewDataLoader
+	clock         clock.Clock
+
 	cache    Cache
 	tracer   Tracer
-	logger   log.Logger
+	logger   logger.Logger
+	executor Executor
+	metrics  Metrics
+
+	rateLimiter RateLimiter
+	hooks       Hooks
+
+	middlewares     []BatchMiddleware
+	validator       BatchValidator
+	errorClassifier ErrorClassifier
+
+	duplicateKeyPolicy DuplicateKeyPolicy
+	deadlinePolicy     DeadlineExceededPolicy
+
+	singleUseThunks bool
+	maxBatchSize    int
+	parallelism     int
+
+	hedgeDelay time.Duration
+	hedgeKey   func(Key) bool
+
+	retryAttempts int
+	backoff       Backoff
+
+	replicaBatch     BatchFunction
+	replicaStaleness time.Duration
+	replicaMutex     sync.Mutex
+	primaryKeys      map[string]int // keys flagged for the primary and the number of pending loads
+
+	batchRouter    BatchRouter
+	batchFunctions map[BatchID]BatchFunction
+
+	// callsMutex guards calls, the loads of keys which are pending or executing in the strategy
+	callsMutex sync.Mutex
+	calls      map[string]*call
+
+	cacheWarming bool
+	warmCaches   []Cache
+
+	resultCloner ResultCloner
+
+	storeErrors bool
+
+	spillDir       string
+	spillThreshold int
+
+	pipeline  []Stage
+	lookups   []Stage // stages before the batch stage
+	fallbacks []Stage // stages after the batch stage
+
+	canaryFraction float64
+	canaryBatch    BatchFunction
+
+	primeMutex sync.RWMutex
+	primed     ResultMap
 }
 
 // Load returns the Thunk for the specified Key by calling the Load method on the provided strategy.
 // Load method references the cache to check if a result already exists for the key. If a result exists,
 // it returns a Thunk which simply returns the cached result (non-blocking).
-func (d *dataloader) Load(ogCtx context.Context, key Key) Thunk {
+func (d *dataloader) Load(ctx context.Context, key Key, opts ...LoadOption) Thunk {
+	atomic.AddUint64(&d.loads, 1)
+	ctx, o := loadOptions(ctx, opts)
+	thunk := d.load(ctx, key, o)
+	if d.singleUseThunks {
+		return singleUseThunk(thunk)
+	}
+	return thunk
+}
+
+// LoadMany returns a ThunkMany for the specified keys by calling the LoadMany method on the provided
+// strategy.
+// LoadMany references the cache and returns a ThunkMany which returns the cached values when called
+// (non-blocking).
+func (d *dataloader) LoadMany(ctx context.Context, keyArr ...Key) ThunkMany {
+	atomic.AddUint64(&d.loads, uint64(len(keyArr)))
+	thunkMany := d.loadMany(ctx, keyArr...)
+	if d.spillThreshold > 0 {
+		thunkMany = d.spillThunkMany(keyArr, thunkMany)
+	}
+	if d.singleUseThunks {
+		return singleUseThunkMany(thunkMany)
+	}
+	return thunkMany
+}
+
+func (d *dataloader) load(ogCtx context.Context, key Key, o LoadOptions) Thunk {
 	ctx, finish := d.tracer.Load(ogCtx, key)
+	strategy, release := d.acquireStrategy()
+	defer release()
 
-	if r, ok := d.cache.GetResult(ctx, key); ok {
-		d.logger.Logf("cache hit for: %d", key)
-		d.strategy.LoadNoOp(ctx)
-		return func() (Result, bool) {
-			finish(r)
+	if !o.SkipCache {
+		if r, ok := d.lookup(ctx, key); ok {
+			strategy.LoadNoOp(ctx)
+			r = d.clone(r)
+			return func() (Result, bool) {
+				finish(r)
 
-			return r, ok
+				return r, ok
+			}
 		}
 	}
 
-	thunk := d.strategy.Load(ctx, key)
+	unmark := func() {}
+	if d.replicaBatch != nil && d.requiresPrimary(ctx) {
+		unmark = d.markPrimary(key)
+	}
+
+	enqueued := time.Now()
+	thunk := d.loadInflight(ctx, strategy, key, func(strategy Strategy) Thunk {
+		thunk := strategy.Load(ctx, key)
+		if d.hedgeKey != nil && d.hedgeKey(key) {
+			thunk = d.hedge(ctx, key, thunk)
+		}
+		return thunk
+	})
+	if f, ok := strategy.(Flusher); ok && o.Flush {
+		f.Flush(ctx)
+	}
+
+	var once sync.Once
+	var result Result
+	var ok bool
 	return func() (Result, bool) {
-		result, ok := thunk()
-		d.cache.SetResult(ctx, key, result)
+		once.Do(func() {
+			result, ok = thunk()
+			unmark()
+			d.recordLatency(key, enqueued, result)
+
+			var fellBack bool
+			if result, ok, fellBack = d.fallback(ctx, key, result, ok); ok && !fellBack && d.storable(ctx, result) {
+				d.store(ctx, key, result)
+			}
+			result = d.clone(result)
+		})
 
 		finish(result)
 
@@ -146,19 +723,33 @@ func (d *dataloader) Load(ogCtx context.Context, key Key) Thunk {
 	}
 }
 
-// LoadMany returns a ThunkMany for the specified keys by calling the LoadMany method on the provided
-// strategy.
-// LoadMany references the cache and returns a ThunkMany which returns the cached values when called
-// (non-blocking).
-func (d *dataloader) LoadMany(ogCtx context.Context, keyArr ...Key) ThunkMany {
+func (d *dataloader) loadMany(ogCtx context.Context, keyArr ...Key) ThunkMany {
 	ctx, finish := d.tracer.LoadMany(ogCtx, keyArr)
+	strategy, release := d.acquireStrategy()
+	defer release()
+
+	if d.duplicateKeyPolicy == RejectDuplicateKeys {
+		if dup := duplicateKeys(keyArr); len(dup) > 0 {
+			d.logger.Info("rejecting LoadMany with duplicate keys", "keys", dup)
+			strategy.LoadNoOp(ctx) // count the call towards the strategies capacity
+
+			err := &DuplicateKeysError{Keys: dup}
+			result := NewResultMap(len(keyArr))
+			for _, key := range keyArr {
+				result.Set(key, Result{Result: nil, Err: err})
+			}
+			return func() ResultMap {
+				finish(result)
+				return result
+			}
+		}
+	}
 
 	var cached, missed = ResultMap{}, []Key{}
 	for _, key := range keyArr {
-		if r, ok := d.cache.GetResult(ctx, key); ok {
-			d.logger.Logf("cache hit for: %d", key)
-			d.strategy.LoadNoOp(ctx)
-			cached[key.String()] = r
+		if r, ok := d.lookup(ctx, key); ok {
+			strategy.LoadNoOp(ctx)
+			cached[key.String()] = d.clone(r)
 		} else {
 			missed = append(missed, key)
 		}
@@ -171,17 +762,288 @@ func (d *dataloader) LoadMany(ogCtx context.Context, keyArr ...Key) ThunkMany {
 		}
 	}
 
-	thunkMany := d.strategy.LoadMany(ctx, missed...)
+	unmark := func() {}
+	if d.replicaBatch != nil && d.requiresPrimary(ctx) {
+		unmark = d.markPrimary(missed...)
+	}
+
+	enqueued := time.Now()
+	thunkMany := d.loadManyInflight(ctx, strategy, missed, func(strategy Strategy, keyArr ...Key) ThunkMany {
+		return strategy.LoadMany(ctx, keyArr...)
+	})
+
+	var once sync.Once
+	var result ResultMap
 	return func() ResultMap {
-		cached := cached
-		result := thunkMany()
-		d.cache.SetResultMap(ctx, result)
+		once.Do(func() {
+			r := thunkMany()
+			unmark()
 
-		for k, v := range cached {
-			result[k] = v
-		}
+			// build a new result map so that the callers data is isolated from the strategies result map
+			// which may be shared with other callers
+			result = NewResultMap(len(keyArr))
+			stored, storedKeys := NewResultMap(len(missed)), make([]Key, 0, len(missed))
+			for _, k := range missed {
+				v, ok := r.GetValue(k)
+				if ok {
+					d.recordLatency(k, enqueued, v)
+				}
+
+				var fb bool
+				if v, ok, fb = d.fallback(ctx, k, v, ok); ok && !fb && d.storable(ctx, v) {
+					stored.Set(k, v)
+					storedKeys = append(storedKeys, k)
+				}
+				if ok {
+					result.Set(k, d.clone(v))
+				}
+			}
+			d.storeMany(ctx, storedKeys, stored)
+			for k, v := range cached {
+				result[k] = v
+			}
+		})
 		finish(result)
 
 		return result
 	}
 }
+
+// LastBatchID returns the ID of the last batch executed by the loader
+func (d *dataloader) LastBatchID() uint64 {
+	return atomic.LoadUint64(&d.batchID)
+}
+
+// Prime stores the result for the key so that it is returned without calling the batch function
+func (d *dataloader) Prime(ctx context.Context, key Key, result Result) {
+	d.primeMutex.Lock()
+	defer d.primeMutex.Unlock()
+
+	d.primed.Set(key, result)
+}
+
+// Clear removes the primed and cached result for the key
+func (d *dataloader) Clear(ctx context.Context, key Key) {
+	d.primeMutex.Lock()
+	delete(d.primed, key.String())
+	d.primeMutex.Unlock()
+
+	d.cache.Delete(ctx, key)
+	for _, stage := range d.pipeline {
+		if c, ok := stage.(clearer); ok {
+			c.clear(ctx, key)
+		}
+	}
+}
+
+// ClearAll removes all primed and cached results
+func (d *dataloader) ClearAll(ctx context.Context) {
+	d.primeMutex.Lock()
+	d.primed = NewResultMap(0)
+	d.primeMutex.Unlock()
+
+	d.cache.ClearAll(ctx)
+	for _, stage := range d.pipeline {
+		if c, ok := stage.(clearer); ok {
+			c.clearAll(ctx)
+		}
+	}
+}
+
+// Flush calls the batch function with the pending keys if the strategy supports flushing
+func (d *dataloader) Flush(ctx context.Context) {
+	if f, ok := d.currentStrategy().(Flusher); ok {
+		f.Flush(ctx)
+	}
+}
+
+// SwapStrategy replaces the strategy with a new strategy built by fn. Loads after the swap use the new
+// strategy. The swap waits for loads which are enqueuing keys in the previous strategy (see acquireStrategy),
+// then keys pending in the previous strategy are flushed if it implements Flusher, otherwise they resolve
+// once the previous strategy reaches capacity or times out. The previous strategy is then closed if it
+// implements Closer.
+func (d *dataloader) SwapStrategy(ctx context.Context, fn StrategyFunction) {
+	strategy := d.newStrategy(fn)
+
+	d.strategyMutex.Lock()
+	previous := d.strategy
+	d.strategy = strategy
+	d.strategyMutex.Unlock()
+
+	d.logger.Info("swapped strategy, draining previous strategy")
+	if f, ok := previous.(Flusher); ok {
+		f.Flush(ctx)
+	}
+	if c, ok := previous.(Closer); ok {
+		c.Close()
+	}
+}
+
+// Close closes the strategy if it implements Closer, e.g. to unsubscribe an idle strategy from a Tracker
+// which outlives the loader. Keys must not be loaded after Close.
+func (d *dataloader) Close() {
+	if c, ok := d.currentStrategy().(Closer); ok {
+		c.Close()
+	}
+}
+
+// StrategyState returns a snapshot of the strategies state if the strategy is introspectable
+func (d *dataloader) StrategyState() (StrategyState, bool) {
+	if i, ok := d.currentStrategy().(Introspectable); ok {
+		return i.State(), true
+	}
+	return StrategyState{}, false
+}
+
+// currentStrategy returns the strategy used for new loads
+func (d *dataloader) currentStrategy() Strategy {
+	d.strategyMutex.RLock()
+	defer d.strategyMutex.RUnlock()
+
+	return d.strategy
+}
+
+// acquireStrategy returns the strategy and holds it until release is called. Loads hold the strategy while
+// they enqueue their keys so that SwapStrategy doesn't flush and close the previous strategy before the keys
+// of the loads which captured it are enqueued. The strategy must not be acquired again before release.
+func (d *dataloader) acquireStrategy() (strategy Strategy, release func()) {
+	d.strategyMutex.RLock()
+	return d.strategy, d.strategyMutex.RUnlock
+}
+
+// newStrategy builds a strategy whose calls to the batch function carry the strategies name (see
+// BatchInfo). Strategies which don't implement Named are named by their type.
+func (d *dataloader) newStrategy(fn StrategyFunction) Strategy {
+	var name string
+	strategy := fn(d.capacity, func(ctx context.Context, keys Keys) *ResultMap {
+		return d.batchFunc(context.WithValue(ctx, strategyNameKey{}, name), keys)
+	})
+
+	// the strategy doesn't call the batch function before it is returned to the caller
+	if n, ok := strategy.(Named); ok {
+		name = n.Name()
+	} else {
+		name = fmt.Sprintf("%T", strategy)
+	}
+	return strategy
+}
+
+// singleUseThunk returns a Thunk which drops its reference to the provided thunk, and therefore the resolved
+// result, after the first call.
+func singleUseThunk(thunk Thunk) Thunk {
+	var m sync.Mutex
+	return func() (Result, bool) {
+		m.Lock()
+		defer m.Unlock()
+
+		if thunk == nil {
+			return Result{Result: nil, Err: ErrThunkConsumed}, false
+		}
+
+		r, ok := thunk()
+		thunk = nil
+		return r, ok
+	}
+}
+
+// singleUseThunkMany returns a ThunkMany which drops its reference to the provided thunk, and therefore the
+// resolved results, after the first call.
+func singleUseThunkMany(thunkMany ThunkMany) ThunkMany {
+	var m sync.Mutex
+	return func() ResultMap {
+		m.Lock()
+		defer m.Unlock()
+
+		if thunkMany == nil {
+			return NewResultMap(0)
+		}
+
+		r := thunkMany()
+		thunkMany = nil
+		return r
+	}
+}
+
+// execute waits for the rate limiter, calls the batch function on the executor and validates the
+// returned results
+func (d *dataloader) execute(ctx context.Context, id uint64, fn BatchFunction, keys Keys) *ResultMap {
+	if d.rateLimiter != nil {
+		if err := d.rateLimiter.Wait(ctx); err != nil {
+			d.logger.Error("batch rate limited", "batch_id", id, "error", err)
+			return errorResultMap(keys, err)
+		}
+	}
+
+	r := executeBatch(ctx, d.executor, fn, keys, d.logger)
+	if d.validator != nil {
+		if err := d.validator(keys, r); err != nil {
+			d.logger.Error("batch failed validation", "batch_id", id, "error", err)
+			r = errorResultMap(keys, err)
+		}
+	}
+	return r
+}
+
+// duplicateKeys returns the string value of each key which appears more than once
+func duplicateKeys(keyArr []Key) []string {
+	seen := make(map[string]int, len(keyArr))
+	var dup []string
+	for _, key := range keyArr {
+		k := key.String()
+		seen[k]++
+		if seen[k] == 2 {
+			dup = append(dup, k)
+		}
+	}
+	return dup
+}
+
+// countErrors returns the number of results which contain an error
+func countErrors(r ResultMap) int {
+	count := 0
+	for _, v := range r {
+		if v.Err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// recordLatency records the queue wait and execution latency for a result returned by the batch function
+func (d *dataloader) recordLatency(key Key, enqueued time.Time, r Result) {
+	if r.Source.BatchID == 0 { // not resolved by the batch function
+		return
+	}
+
+	queueWait := r.Source.Started.Sub(enqueued)
+	if queueWait < 0 {
+		queueWait = 0
+	}
+	d.metrics.LoadLatency(key, queueWait, r.Source.Finished.Sub(r.Source.Started))
+}
+
+// ============================================== context helpers =============================================
+
+type batchInfoKey struct{}
+
+// BatchIDFromContext returns the ID of the batch which the context was created for. The context passed to
+// the BatchFunction contains the batch ID.
+func BatchIDFromContext(ctx context.Context) (uint64, bool) {
+	info, ok := ctx.Value(batchInfoKey{}).(BatchInfo)
+	return info.BatchID, ok
+}
+
+// BatchInfoFromContext returns the ID, keys, size and strategy name of the batch which the context was
+// created for. The context passed to the Tracer's Batch method and the BatchFunction contains the info.
+func BatchInfoFromContext(ctx context.Context) (BatchInfo, bool) {
+	info, ok := ctx.Value(batchInfoKey{}).(BatchInfo)
+	return info, ok
+}
+
+type strategyNameKey struct{}
+
+// strategyFromContext returns the name of the strategy which called the batch function
+func strategyFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(strategyNameKey{}).(string)
+	return name
+}
@@ -2,7 +2,10 @@ package dataloader_test
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -240,3 +243,438 @@ func TestLoadManyCacheMiss(t *testing.T) {
 	)
 	assert.Equal(t, 1, callCount, "Expected batch function to  be called")
 }
+
+// ============================================= test concurrency ============================================
+
+// TestConcurrentLoadManyOverlappingKeys ensures concurrent callers to LoadMany with overlapping keys each
+// receive a complete and isolated ResultMap for their own keys.
+func TestConcurrentLoadManyOverlappingKeys(t *testing.T) {
+	// setup
+	shared := dataloader.NewResultMap(10)
+	for i := 0; i < 10; i++ {
+		shared.Set(PrimaryKey(i), dataloader.Result{Result: i, Err: nil})
+	}
+	// batch function returns the same result map to every caller
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		return &shared
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+
+	// invoke / assert
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			keys := []dataloader.Key{PrimaryKey(i % 10), PrimaryKey((i + 1) % 10), PrimaryKey((i + 2) % 10)}
+			thunk := loader.LoadMany(context.Background(), keys...)
+
+			// call the same thunk from multiple go routines
+			inner := sync.WaitGroup{}
+			for j := 0; j < 2; j++ {
+				inner.Add(1)
+				go func() {
+					defer inner.Done()
+					thunk()
+				}()
+			}
+			inner.Wait()
+
+			r := thunk()
+			assert.Equal(t, len(keys), r.Length(), "Expected a result for each key")
+			for _, k := range keys {
+				returned, ok := r.GetValue(k)
+				assert.True(t, ok, "Expected result to have been found")
+				assert.Equal(t, int(k.(PrimaryKey)), returned.Result.(int), "Expected result for key")
+			}
+
+			// callers own the returned result map
+			r.Set(PrimaryKey(100+i), dataloader.Result{Result: i, Err: nil})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, shared.Length(), "Expected batch result map to be unmodified")
+}
+
+// ============================================== test batch IDs =============================================
+
+// TestBatchIDs ensures each call to the batch function is assigned an increasing batch ID which is
+// passed to the batch function and attached to the results.
+func TestBatchIDs(t *testing.T) {
+	// setup
+	var ids []uint64
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		id, ok := dataloader.BatchIDFromContext(ctx)
+		assert.True(t, ok, "Expected batch ID in context")
+		ids = append(ids, id)
+
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			m.Set(k.(PrimaryKey), dataloader.Result{Result: k, Err: nil})
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	assert.Equal(t, uint64(0), loader.LastBatchID(), "Expected no batch ID before batch function called")
+
+	// invoke / assert
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, uint64(1), r.Source.BatchID, "Expected result to reference the first batch")
+	assert.Equal(t, uint64(1), loader.LastBatchID(), "Expected last batch ID")
+
+	m := loader.LoadMany(context.Background(), PrimaryKey(2), PrimaryKey(3))()
+	returned, ok := m.GetValue(PrimaryKey(3))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, uint64(2), returned.Source.BatchID, "Expected result to reference the second batch")
+	assert.Equal(t, uint64(2), loader.LastBatchID(), "Expected last batch ID")
+
+	assert.Equal(t, []uint64{1, 2}, ids, "Expected batch function to receive increasing batch IDs")
+}
+
+// ============================================ test batch validator =========================================
+
+// TestBatchValidatorFailsBatch ensures a validation error resolves each key in the batch with the error
+func TestBatchValidatorFailsBatch(t *testing.T) {
+	// setup
+	result := dataloader.Result{Result: "invalid", Err: nil}
+	expectedErr := errors.New("tenant mismatch")
+	var validated dataloader.Keys
+	validator := func(keys dataloader.Keys, r *dataloader.ResultMap) error {
+		validated = keys
+		if v, ok := r.GetValue(PrimaryKey(1)); ok && v.Result == "invalid" {
+			return expectedErr
+		}
+		return nil
+	}
+
+	batch := getBatchFunction(func() {}, result)
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithBatchValidator(validator),
+	)
+
+	// invoke / assert
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, expectedErr, r.Err, "Expected validation error")
+	assert.Nil(t, r.Result, "Expected no result value")
+	assert.Equal(t, 1, validated.Length(), "Expected validator to be called with the batch keys")
+}
+
+// ============================================ test single use thunks =======================================
+
+// TestSingleUseThunks ensures thunks only return their results once when configured as single use
+func TestSingleUseThunks(t *testing.T) {
+	// setup
+	result := dataloader.Result{Result: "single_use", Err: nil}
+	batch := getBatchFunction(func() {}, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithSingleUseThunks())
+
+	// invoke / assert
+	thunk := loader.Load(context.Background(), PrimaryKey(1))
+	r, ok := thunk()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, result.Result.(string), r.Result.(string), "Expected result from thunk")
+
+	r, ok = thunk()
+	assert.False(t, ok, "Expected no result after first call")
+	assert.Equal(t, dataloader.ErrThunkConsumed, r.Err, "Expected thunk consumed error")
+
+	thunkMany := loader.LoadMany(context.Background(), PrimaryKey(1))
+	assert.Equal(t, 1, thunkMany().Length(), "Expected result from thunk")
+	assert.Equal(t, 0, thunkMany().Length(), "Expected no results after first call")
+}
+
+// ================================================ test prime ===============================================
+
+// TestPrime ensures primed results are returned without calling the batch function
+func TestPrime(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "batch", Err: nil}
+	primed := dataloader.Result{Result: "primed", Err: nil}
+	cb := func() { callCount += 1 }
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	loader.Prime(context.Background(), PrimaryKey(1), primed)
+
+	// invoke / assert
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, primed.Result.(string), r.Result.(string), "Expected primed result")
+
+	m := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	returned, ok := m.GetValue(PrimaryKey(1))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, primed.Result.(string), returned.Result.(string), "Expected primed result")
+	assert.Equal(t, 2, m.Length(), "Expected results for both keys")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called for the unprimed key only")
+}
+
+// ================================================ test clear ===============================================
+
+// TestClear ensures cleared keys call the batch function again
+func TestClear(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "batch", Err: nil}
+	cb := func() { callCount += 1 }
+	cache := newMockCache(2)
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithCache(cache))
+	loader.Prime(context.Background(), PrimaryKey(1), dataloader.Result{Result: "primed", Err: nil})
+	loader.Load(context.Background(), PrimaryKey(2))()
+
+	// invoke / assert
+	loader.Clear(context.Background(), PrimaryKey(1))
+	r, _ := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.Equal(t, result.Result.(string), r.Result.(string), "Expected result from batch function")
+	assert.Equal(t, 2, callCount, "Expected batch function to be called for the cleared key")
+
+	loader.ClearAll(context.Background())
+	loader.Load(context.Background(), PrimaryKey(2))()
+	assert.Equal(t, 3, callCount, "Expected batch function to be called after clearing all keys")
+}
+
+// mockFlushStrategy wraps the mock strategy and counts the calls to Load and Flush
+type mockFlushStrategy struct {
+	mockStrategy
+	loads   int
+	flushes int
+}
+
+func (s *mockFlushStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	s.loads += 1
+	return s.mockStrategy.Load(ctx, key)
+}
+
+func (s *mockFlushStrategy) Flush(ctx context.Context) {
+	s.flushes += 1
+}
+
+// TestSwapStrategy ensures loads after a swap use the new strategy and the previous strategy is drained
+func TestSwapStrategy(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "swapped", Err: nil}
+	batch := getBatchFunction(func() { callCount += 1 }, result)
+
+	first, second := &mockFlushStrategy{}, &mockFlushStrategy{}
+	strategyFunc := func(s *mockFlushStrategy) dataloader.StrategyFunction {
+		return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+			s.batchFunc = batch
+			return s
+		}
+	}
+	loader := dataloader.NewDataLoader(1, batch, strategyFunc(first))
+
+	// invoke
+	thunk := loader.Load(context.Background(), PrimaryKey(1))
+	loader.SwapStrategy(context.Background(), strategyFunc(second))
+	r, ok := loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, 1, first.flushes, "Expected previous strategy to be flushed")
+	assert.Equal(t, 1, first.loads, "Expected load before the swap to use the previous strategy")
+	assert.Equal(t, 1, second.loads, "Expected load after the swap to use the new strategy")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, result.Result, r.Result, "Expected result from new strategy")
+
+	r, ok = thunk()
+	assert.True(t, ok, "Expected pending load to resolve after the swap")
+	assert.Equal(t, 2, callCount, "Expected batch function to be called for each load")
+}
+
+// mockClosingStrategy counts the keys loaded after it was closed
+type mockClosingStrategy struct {
+	mockStrategy
+	closed    int32
+	lateLoads int32
+}
+
+func (s *mockClosingStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	s.checkClosed()
+	return s.mockStrategy.Load(ctx, key)
+}
+
+func (s *mockClosingStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	s.checkClosed()
+	return s.mockStrategy.LoadMany(ctx, keyArr...)
+}
+
+func (s *mockClosingStrategy) LoadNoOp(ctx context.Context) {
+	s.checkClosed()
+}
+
+func (s *mockClosingStrategy) Close() {
+	atomic.StoreInt32(&s.closed, 1)
+}
+
+func (s *mockClosingStrategy) checkClosed() {
+	if atomic.LoadInt32(&s.closed) == 1 {
+		atomic.AddInt32(&s.lateLoads, 1)
+	}
+}
+
+// slowCache misses after a delay, widening the window between a load reading the strategy and enqueuing its key
+type slowCache struct {
+	dataloader.Cache
+}
+
+func (c slowCache) GetResult(ctx context.Context, key dataloader.Key) (dataloader.Result, bool) {
+	time.Sleep(50 * time.Microsecond)
+	return c.Cache.GetResult(ctx, key)
+}
+
+func (c slowCache) GetResultMap(ctx context.Context, keys ...dataloader.Key) (dataloader.ResultMap, bool) {
+	time.Sleep(50 * time.Microsecond)
+	return c.Cache.GetResultMap(ctx, keys...)
+}
+
+// TestSwapStrategyConcurrentLoads ensures loads racing with a swap never enqueue keys in the previous strategy
+// once it is closed
+func TestSwapStrategyConcurrentLoads(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var strategies []*mockClosingStrategy
+	strategyFunc := func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		m.Lock()
+		defer m.Unlock()
+
+		s := &mockClosingStrategy{mockStrategy: mockStrategy{batchFunc: batch}}
+		strategies = append(strategies, s)
+		return s
+	}
+	result := dataloader.Result{Result: "swapped", Err: nil}
+	loader := dataloader.NewDataLoader(
+		1,
+		getBatchFunction(func() {}, result),
+		strategyFunc,
+		dataloader.WithCache(slowCache{dataloader.NewNoOpCache()}),
+	)
+
+	// invoke
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				key := PrimaryKey(i*1000000 + n)
+				if n%2 == 0 {
+					loader.Load(context.Background(), key)()
+				} else {
+					loader.LoadMany(context.Background(), key)()
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 200; i++ {
+		time.Sleep(100 * time.Microsecond) // let the loads race with each swap
+		loader.SwapStrategy(context.Background(), strategyFunc)
+	}
+	close(stop)
+	wg.Wait()
+
+	// assert
+	m.Lock()
+	defer m.Unlock()
+	assert.Len(t, strategies, 201, "Expected a strategy for each swap")
+	for _, s := range strategies {
+		assert.Equal(t, int32(0), atomic.LoadInt32(&s.lateLoads), "Expected no keys loaded after Close")
+	}
+}
+
+// TestBatchFunctionEFailsBatch ensures a batch function error is returned for every key in the batch
+func TestBatchFunctionEFailsBatch(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) (*dataloader.ResultMap, error) {
+		return nil, errors.New("connection refused")
+	}
+	loader := dataloader.NewDataLoader(2, dataloader.FromBatchFunctionE(batch), newMockStrategy())
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected result for each key")
+	for _, k := range []dataloader.Key{PrimaryKey(1), PrimaryKey(2)} {
+		v, ok := r.GetValue(k)
+		assert.True(t, ok, "Expected result to have been found")
+		assert.EqualError(t, v.Err, "connection refused", "Expected batch error for each key")
+	}
+}
+
+// TestCacheWarming ensures unsolicited results returned by the batch function are stored in the caches
+func TestCacheWarming(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		callCount += 1
+		m := dataloader.NewResultMap(2)
+		m.Set(PrimaryKey(1), dataloader.Result{Result: "post", Err: nil})
+		m.Set(PrimaryKey(2), dataloader.Result{Result: "author", Err: nil}) // fetched by a join
+		return &m
+	}
+	cache := newMockCache(0)
+	sibling := newMockCache(0)
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithCache(cache),
+		dataloader.WithCacheWarming(sibling),
+	)
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+	r, ok := loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "author", r.Result, "Expected unsolicited result from the cache")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+
+	r, ok = sibling.GetResult(context.Background(), PrimaryKey(2))
+	assert.True(t, ok, "Expected unsolicited result in the sibling cache")
+	assert.Equal(t, "author", r.Result, "Expected unsolicited result in the sibling cache")
+	_, ok = sibling.GetResult(context.Background(), PrimaryKey(1))
+	assert.False(t, ok, "Expected requested result not to be stored in the sibling cache")
+}
+
+// TestRejectDuplicateKeys ensures duplicate keys resolve to an error without calling the batch function
+func TestRejectDuplicateKeys(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := getBatchFunction(func() { callCount += 1 }, dataloader.Result{Result: "result", Err: nil})
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithDuplicateKeyPolicy(dataloader.RejectDuplicateKeys),
+	)
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(1))()
+
+	// assert
+	assert.Equal(t, 0, callCount, "Expected batch function not to be called")
+	assert.Equal(t, 2, r.Length(), "Expected result for each key")
+	v, ok := r.GetValue(PrimaryKey(2))
+	assert.True(t, ok, "Expected result to have been found")
+	assert.EqualError(t, v.Err, "dataloader: duplicate keys passed to LoadMany: 1", "Expected duplicate keys error")
+}
@@ -0,0 +1,101 @@
+package dataloadertest
+
+import (
+	"testing"
+
+	"github.com/andy9775/dataloader"
+)
+
+// AssertBatchCount reports an error if the batch function wasn't called n times
+func AssertBatchCount(t testing.TB, b *BatchFunction, n int) bool {
+	t.Helper()
+
+	if count := b.CallCount(); count != n {
+		t.Errorf("dataloadertest: expected %d calls to the batch function, got %d", n, count)
+		return false
+	}
+	return true
+}
+
+// AssertBatchedTogether reports an error if the keys weren't passed to the batch function in the same call
+func AssertBatchedTogether(t testing.TB, b *BatchFunction, keyArr ...dataloader.Key) bool {
+	t.Helper()
+
+	for _, c := range b.Calls() {
+		if containsAll(c.Keys, keyArr) {
+			return true
+		}
+	}
+	t.Errorf(
+		"dataloadertest: expected keys %v to be batched together, got batches %v",
+		keyStrings(keyArr),
+		batches(b),
+	)
+	return false
+}
+
+// AssertBatches reports an error if the batch function wasn't called with exactly the batches of keys, in
+// order. The order of the keys within a batch doesn't matter.
+func AssertBatches(t testing.TB, b *BatchFunction, expected ...[]dataloader.Key) bool {
+	t.Helper()
+
+	calls := b.Calls()
+	ok := len(calls) == len(expected)
+	for i := 0; ok && i < len(calls); i++ {
+		ok = len(calls[i].Keys) == len(expected[i]) && containsAll(calls[i].Keys, expected[i])
+	}
+	if !ok {
+		want := make([][]string, 0, len(expected))
+		for _, keyArr := range expected {
+			want = append(want, keyStrings(keyArr))
+		}
+		t.Errorf("dataloadertest: expected batches %v, got %v", want, batches(b))
+	}
+	return ok
+}
+
+// AssertLoadCount reports an error if the strategy didn't receive n calls to Load and LoadMany
+func AssertLoadCount(t testing.TB, s *Strategy, n int) bool {
+	t.Helper()
+
+	if count := s.LoadCount(); count != n {
+		t.Errorf("dataloadertest: expected %d loads, got %d", n, count)
+		return false
+	}
+	return true
+}
+
+// ============================================== helpers =============================================
+
+// containsAll returns true if every key in keyArr is in batch
+func containsAll(batch, keyArr []dataloader.Key) bool {
+	seen := make(map[string]bool, len(batch))
+	for _, key := range batch {
+		seen[key.String()] = true
+	}
+	for _, key := range keyArr {
+		if !seen[key.String()] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyStrings returns the string values of the keys
+func keyStrings(keyArr []dataloader.Key) []string {
+	result := make([]string, 0, len(keyArr))
+	for _, key := range keyArr {
+		result = append(result, key.String())
+	}
+	return result
+}
+
+// batches returns the string values of the keys of each call to the batch function
+func batches(b *BatchFunction) [][]string {
+	calls := b.Calls()
+	result := make([][]string, 0, len(calls))
+	for _, c := range calls {
+		result = append(result, keyStrings(c.Keys))
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+/*
+Package dataloadertest contains fakes for unit testing code which uses a dataloader, e.g. GraphQL resolvers,
+without patching the time package or depending on the timing of a real strategy.
+
+The fake Strategy batches every key loaded since the previous batch once a Thunk or ThunkMany is called (or
+the strategy is flushed), so which keys are batched together only depends on the order of the calls. The
+fake BatchFunction records the keys and contexts it is called with.
+
+	batch := dataloadertest.NewBatchFunction(nil)
+	strategy := dataloadertest.NewStrategy()
+	loader := dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction())
+
+	thunk := loader.Load(ctx, dataloader.StringKey("1"))
+	thunk2 := loader.Load(ctx, dataloader.StringKey("2"))
+	thunk()
+	thunk2()
+
+	dataloadertest.AssertBatchCount(t, batch, 1)
+	dataloadertest.AssertBatchedTogether(t, batch, dataloader.StringKey("1"), dataloader.StringKey("2"))
+*/
+package dataloadertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andy9775/dataloader"
+)
+
+// BatchCall is a recorded call to a batch function
+type BatchCall struct {
+	Ctx  context.Context
+	Keys []dataloader.Key
+}
+
+// BatchFunction is a fake batch function which records each call. It is safe for concurrent use.
+type BatchFunction struct {
+	m       sync.Mutex
+	resolve func(context.Context, dataloader.Key) dataloader.Result
+	calls   []BatchCall
+}
+
+// NewBatchFunction returns a fake batch function which resolves each key with the provided function. A nil
+// function resolves each key to its raw value.
+func NewBatchFunction(resolve func(context.Context, dataloader.Key) dataloader.Result) *BatchFunction {
+	if resolve == nil {
+		resolve = func(_ context.Context, key dataloader.Key) dataloader.Result {
+			return dataloader.Result{Result: key.Raw(), Err: nil}
+		}
+	}
+	return &BatchFunction{resolve: resolve}
+}
+
+// Batch records the call and resolves the keys. Pass it to the loader as its batch function.
+func (b *BatchFunction) Batch(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+	keyArr := keySlice(keys)
+
+	b.m.Lock()
+	b.calls = append(b.calls, BatchCall{Ctx: ctx, Keys: keyArr})
+	b.m.Unlock()
+
+	results := dataloader.NewResultMap(len(keyArr))
+	for _, key := range keyArr {
+		results.Set(key, b.resolve(ctx, key))
+	}
+	return &results
+}
+
+// Calls returns the recorded calls in the order they were made
+func (b *BatchFunction) Calls() []BatchCall {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return append([]BatchCall(nil), b.calls...)
+}
+
+// CallCount returns the number of times the batch function was called
+func (b *BatchFunction) CallCount() int {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return len(b.calls)
+}
+
+// Keys returns the keys of every call in the order they were passed to the batch function
+func (b *BatchFunction) Keys() []dataloader.Key {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	var keyArr []dataloader.Key
+	for _, c := range b.calls {
+		keyArr = append(keyArr, c.Keys...)
+	}
+	return keyArr
+}
+
+// Reset clears the recorded calls
+func (b *BatchFunction) Reset() {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.calls = nil
+}
+
+// ============================================== helpers =============================================
+
+// keySlice returns the keys wrapped by Keys. Keys whose raw value isn't the key itself are rebuilt from
+// their string and raw values, which Keys returns in the same order.
+func keySlice(keys dataloader.Keys) []dataloader.Key {
+	raw, strs := keys.Keys(), keys.StringKeys()
+
+	keyArr := make([]dataloader.Key, 0, len(raw))
+	for i, k := range raw {
+		if key, ok := k.(dataloader.Key); ok && key.String() == strs[i] {
+			keyArr = append(keyArr, key)
+			continue
+		}
+		keyArr = append(keyArr, rawKey{s: strs[i], raw: k})
+	}
+	return keyArr
+}
+
+// rawKey is a key rebuilt from its string and raw values
+type rawKey struct {
+	s   string
+	raw interface{}
+}
+
+func (k rawKey) String() string {
+	return k.s
+}
+
+func (k rawKey) Raw() interface{} {
+	return k.raw
+}
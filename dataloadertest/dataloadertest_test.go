@@ -0,0 +1,141 @@
+package dataloadertest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/cache/lru"
+	"github.com/andy9775/dataloader/dataloadertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+type ctxKey struct{}
+
+// failT records the errors reported by the assertion helpers
+type failT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failT) Helper() {}
+
+func (f *failT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// ================================================== tests ==================================================
+
+// TestBatchesPendingKeys ensures the keys loaded before a thunk is called are batched together
+func TestBatchesPendingKeys(t *testing.T) {
+	// setup
+	batch := dataloadertest.NewBatchFunction(nil)
+	strategy := dataloadertest.NewStrategy()
+	loader := dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction())
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	// invoke
+	thunk := loader.Load(ctx, dataloader.StringKey("1"))
+	thunkMany := loader.LoadMany(ctx, dataloader.StringKey("2"), dataloader.StringKey("3"))
+	r, ok := thunk()
+	rm := thunkMany()
+	r4, _ := loader.Load(ctx, dataloader.StringKey("4"))()
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, dataloader.StringKey("1"), r.Result, "Expected the raw key as the result")
+	assert.Equal(t, 2, rm.Length(), "Expected results for LoadMany keys")
+	assert.Equal(t, dataloader.StringKey("4"), r4.Result, "Expected the raw key as the result")
+
+	dataloadertest.AssertLoadCount(t, strategy, 3)
+	dataloadertest.AssertBatchCount(t, batch, 2)
+	dataloadertest.AssertBatches(
+		t,
+		batch,
+		[]dataloader.Key{dataloader.StringKey("1"), dataloader.StringKey("2"), dataloader.StringKey("3")},
+		[]dataloader.Key{dataloader.StringKey("4")},
+	)
+	for _, c := range batch.Calls() {
+		assert.Equal(t, "request", c.Ctx.Value(ctxKey{}), "Expected the context of the load")
+	}
+	for _, c := range strategy.Loads() {
+		assert.Equal(t, "request", c.Ctx.Value(ctxKey{}), "Expected the context of the load")
+	}
+}
+
+// TestFlush ensures flushing calls the batch function with the pending keys
+func TestFlush(t *testing.T) {
+	// setup
+	batch := dataloadertest.NewBatchFunction(func(_ context.Context, key dataloader.Key) dataloader.Result {
+		return dataloader.Result{Result: "value_" + key.String(), Err: nil}
+	})
+	strategy := dataloadertest.NewStrategy()
+	loader := dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction())
+
+	// invoke
+	thunk := loader.Load(context.Background(), dataloader.StringKey("1"))
+	assert.Equal(t, 1, strategy.PendingKeys(), "Expected the key to be pending")
+	strategy.Flush(context.Background())
+
+	// assert
+	assert.Equal(t, 0, strategy.PendingKeys(), "Expected no pending keys")
+	assert.Equal(t, 1, strategy.FlushCount(), "Expected the flush to be counted")
+	dataloadertest.AssertBatchCount(t, batch, 1)
+	r, _ := thunk()
+	assert.Equal(t, "value_1", r.Result, "Expected result from the batch function")
+	dataloadertest.AssertBatchCount(t, batch, 1)
+}
+
+// TestNoOps ensures loads resolved from the cache are recorded as no ops
+func TestNoOps(t *testing.T) {
+	// setup
+	batch := dataloadertest.NewBatchFunction(nil)
+	strategy := dataloadertest.NewStrategy()
+	loader := dataloader.NewDataLoader(
+		10,
+		batch.Batch,
+		strategy.StrategyFunction(),
+		dataloader.WithCache(lru.New(10)),
+	)
+
+	// invoke
+	loader.Load(context.Background(), dataloader.StringKey("1"))()
+	loader.Load(context.Background(), dataloader.StringKey("1"))()
+
+	// assert
+	dataloadertest.AssertBatchCount(t, batch, 1)
+	dataloadertest.AssertLoadCount(t, strategy, 1)
+	assert.Equal(t, 1, strategy.NoOpCount(), "Expected the cache hit to be recorded")
+}
+
+// TestAssertionFailures ensures the assertion helpers report unexpected batches
+func TestAssertionFailures(t *testing.T) {
+	// setup
+	ft := &failT{TB: t}
+	batch := dataloadertest.NewBatchFunction(nil)
+	strategy := dataloadertest.NewStrategy()
+	loader := dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction())
+
+	// invoke
+	loader.Load(context.Background(), dataloader.StringKey("1"))()
+	loader.Load(context.Background(), dataloader.StringKey("2"))()
+
+	// assert
+	assert.False(t, dataloadertest.AssertBatchCount(ft, batch, 1), "Expected the batch count to fail")
+	assert.False(
+		t,
+		dataloadertest.AssertBatchedTogether(ft, batch, dataloader.StringKey("1"), dataloader.StringKey("2")),
+		"Expected keys loaded in separate batches to fail",
+	)
+	assert.False(
+		t,
+		dataloadertest.AssertBatches(ft, batch, []dataloader.Key{dataloader.StringKey("1")}),
+		"Expected missing batches to fail",
+	)
+	assert.False(t, dataloadertest.AssertLoadCount(ft, strategy, 1), "Expected the load count to fail")
+	assert.Len(t, ft.errors, 4, "Expected each failure to be reported")
+	assert.True(t, dataloadertest.AssertBatchedTogether(ft, batch, dataloader.StringKey("2")), "Expected key")
+}
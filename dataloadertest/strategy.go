@@ -0,0 +1,186 @@
+package dataloadertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andy9775/dataloader"
+)
+
+// LoadCall is a recorded call to Load or LoadMany
+type LoadCall struct {
+	Ctx  context.Context
+	Keys []dataloader.Key
+}
+
+// Strategy is a fake strategy which records each load and batches the keys loaded since the previous batch
+// once a Thunk or ThunkMany is called or the strategy is flushed. It doesn't use timers or background go
+// routines. It is safe for concurrent use.
+type Strategy struct {
+	m     sync.Mutex
+	batch dataloader.BatchFunction
+	round *round // keys loaded since the previous batch
+
+	loads   []LoadCall
+	noOps   int
+	flushes int
+	closes  int
+}
+
+// round is a group of loads which are passed to the batch function together
+type round struct {
+	ctx     context.Context // context of the first load
+	keys    dataloader.Keys
+	once    sync.Once
+	results dataloader.ResultMap
+}
+
+// NewStrategy returns a new fake strategy. Pass StrategyFunction to the loader.
+func NewStrategy() *Strategy {
+	return &Strategy{}
+}
+
+// StrategyFunction returns a strategy function which binds the strategy to the loaders batch function
+func (s *Strategy) StrategyFunction() dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		s.m.Lock()
+		defer s.m.Unlock()
+
+		s.batch = batch
+		return s
+	}
+}
+
+// Load records the call and adds the key to the pending keys
+func (s *Strategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	r := s.add(ctx, key)
+	return func() (dataloader.Result, bool) {
+		return s.resolve(r).GetValue(key)
+	}
+}
+
+// LoadMany records the call and adds the keys to the pending keys
+func (s *Strategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	r := s.add(ctx, keyArr...)
+	return func() dataloader.ResultMap {
+		results := s.resolve(r)
+
+		m := dataloader.NewResultMap(len(keyArr))
+		for _, key := range keyArr {
+			if v, ok := results.GetValue(key); ok {
+				m.Set(key, v)
+			}
+		}
+		return m
+	}
+}
+
+// LoadNoOp records the call
+func (s *Strategy) LoadNoOp(context.Context) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.noOps++
+}
+
+// Flush calls the batch function with the pending keys
+func (s *Strategy) Flush(context.Context) {
+	s.m.Lock()
+	r := s.round
+	s.flushes++
+	s.m.Unlock()
+
+	if r != nil {
+		s.resolve(r)
+	}
+}
+
+// Close records the call. The strategy remains usable so tests can assert on the keys loaded after it.
+func (s *Strategy) Close() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.closes++
+}
+
+// Name returns the name of the strategy
+func (*Strategy) Name() string {
+	return "dataloadertest"
+}
+
+// Loads returns the recorded calls to Load and LoadMany in the order they were made
+func (s *Strategy) Loads() []LoadCall {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]LoadCall(nil), s.loads...)
+}
+
+// LoadCount returns the number of calls to Load and LoadMany
+func (s *Strategy) LoadCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.loads)
+}
+
+// NoOpCount returns the number of calls to LoadNoOp, i.e. the number of loads resolved without the strategy
+func (s *Strategy) NoOpCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.noOps
+}
+
+// FlushCount returns the number of calls to Flush
+func (s *Strategy) FlushCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.flushes
+}
+
+// CloseCount returns the number of calls to Close
+func (s *Strategy) CloseCount() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.closes
+}
+
+// PendingKeys returns the number of keys waiting to be passed to the batch function
+func (s *Strategy) PendingKeys() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.round == nil {
+		return 0
+	}
+	return s.round.keys.Length()
+}
+
+// ============================================== private =============================================
+
+// add records the load and adds the keys to the current round
+func (s *Strategy) add(ctx context.Context, keyArr ...dataloader.Key) *round {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.loads = append(s.loads, LoadCall{Ctx: ctx, Keys: keyArr})
+	if s.round == nil {
+		s.round = &round{ctx: ctx, keys: dataloader.NewKeys(len(keyArr))}
+	}
+	s.round.keys.Append(keyArr...)
+	return s.round
+}
+
+// resolve calls the batch function with the keys of the round once and returns its results. Keys loaded
+// afterwards are added to a new round.
+func (s *Strategy) resolve(r *round) dataloader.ResultMap {
+	r.once.Do(func() {
+		s.m.Lock()
+		if s.round == r {
+			s.round = nil
+		}
+		batch := s.batch
+		s.m.Unlock()
+
+		if result := batch(r.ctx, r.keys); result != nil {
+			r.results = *result
+		}
+	})
+	return r.results
+}
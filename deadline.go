@@ -0,0 +1,29 @@
+package dataloader
+
+import "context"
+
+// DeadlineExceededPolicy determines which results are kept when the batch context's deadline expires
+// before the batch function returns
+type DeadlineExceededPolicy int
+
+// Deadline exceeded policies
+const (
+	// KeepPartialResults keeps the successful results returned by the batch function. The remaining keys
+	// resolve to context.DeadlineExceeded (default).
+	KeepPartialResults DeadlineExceededPolicy = iota
+	// DiscardPartialResults resolves every key in the batch to context.DeadlineExceeded
+	DiscardPartialResults
+)
+
+// deadlineExceeded applies the policy to the results of a batch whose context deadline expired
+func deadlineExceeded(keys Keys, r ResultMap, policy DeadlineExceededPolicy) *ResultMap {
+	result := NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		if v, ok := r[k]; ok && v.Err == nil && policy == KeepPartialResults {
+			result[k] = v
+			continue
+		}
+		result[k] = Result{Result: nil, Err: context.DeadlineExceeded}
+	}
+	return &result
+}
@@ -0,0 +1,72 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// getSlowBatchFunction returns a batch function which resolves the first key after the context is done
+func getSlowBatchFunction() dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		<-ctx.Done()
+		r := dataloader.NewResultMap(1)
+		r.Set(PrimaryKey(1), dataloader.Result{Result: "partial", Err: nil})
+		return &r
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestDeadlineExceededKeepsPartialResults ensures successful results are kept and remaining keys time out
+func TestDeadlineExceededKeepsPartialResults(t *testing.T) {
+	// setup
+	metrics := dataloader.NewHistogramMetrics()
+	loader := dataloader.NewDataLoader(2, getSlowBatchFunction(), newMockStrategy(), dataloader.WithMetrics(metrics))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// invoke
+	r := loader.LoadMany(ctx, PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	v, _ := r.GetValue(PrimaryKey(1))
+	assert.Equal(t, "partial", v.Result, "Expected partial result to be kept")
+	v, ok := r.GetValue(PrimaryKey(2))
+	assert.True(t, ok, "Expected result for the missing key")
+	assert.Equal(t, context.DeadlineExceeded, v.Err, "Expected deadline error for the missing key")
+	assert.Equal(
+		t,
+		map[string]uint64{dataloader.ErrorClassTimeout: 1},
+		metrics.ErrorClasses(false),
+		"Expected deadline to be recorded as a timeout",
+	)
+}
+
+// TestDeadlineExceededDiscardsPartialResults ensures every key times out under the discard policy
+func TestDeadlineExceededDiscardsPartialResults(t *testing.T) {
+	// setup
+	loader := dataloader.NewDataLoader(
+		2,
+		getSlowBatchFunction(),
+		newMockStrategy(),
+		dataloader.WithDeadlineExceededPolicy(dataloader.DiscardPartialResults),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// invoke
+	r := loader.LoadMany(ctx, PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	for _, k := range []dataloader.Key{PrimaryKey(1), PrimaryKey(2)} {
+		v, ok := r.GetValue(k)
+		assert.True(t, ok, "Expected result for each key")
+		assert.Equal(t, context.DeadlineExceeded, v.Err, "Expected deadline error for each key")
+	}
+}
@@ -0,0 +1,48 @@
+package dataloader
+
+import (
+	"context"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Executor provides an interface for running batch functions on an application provided
+// worker pool. Executors allow applications to control the scheduling and isolation of
+// loader work, for example by bounding the number of concurrently executing batch functions.
+type Executor interface {
+	// Submit schedules the function for execution. Submit should return an error if the
+	// function can not be scheduled, for example if the executors queue is full.
+	Submit(func()) error
+}
+
+// executeBatch calls the batch function on the provided executor and blocks until it returns.
+// If the executor rejects the batch function or the context is cancelled before the batch
+// function returns, each key resolves to a result containing the error. A panic in the batch
+// function on the executor resolves each key to a PanicError.
+func executeBatch(ctx context.Context, e Executor, batch BatchFunction, keys Keys, l logger.Logger) *ResultMap {
+	if e == nil {
+		return batch(ctx, keys)
+	}
+
+	resultChan := make(chan *ResultMap, 1) // buffered channel won't block the executor
+	run := recoverBatch(batch, l)
+	if err := e.Submit(func() { resultChan <- run(ctx, keys) }); err != nil {
+		return errorResultMap(keys, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return errorResultMap(keys, ctx.Err())
+	case r := <-resultChan:
+		return r
+	}
+}
+
+// errorResultMap returns a ResultMap which contains the provided error for each key
+func errorResultMap(keys Keys, err error) *ResultMap {
+	r := NewResultMap(keys.Length())
+	for _, k := range keys.StringKeys() {
+		r[k] = Result{Result: nil, Err: err}
+	}
+	return &r
+}
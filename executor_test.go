@@ -0,0 +1,87 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== mock executor ==============================================
+type mockExecutor struct {
+	calls int
+	err   error
+}
+
+func (e *mockExecutor) Submit(fn func()) error {
+	e.calls += 1
+	if e.err != nil {
+		return e.err
+	}
+
+	go fn()
+	return nil
+}
+
+// ================================================== tests ==================================================
+
+// TestExecutorRunsBatch ensures the batch function is submitted to the executor
+func TestExecutorRunsBatch(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "executor", Err: nil}
+	cb := func() { callCount += 1 }
+	executor := &mockExecutor{}
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithExecutor(executor))
+
+	// invoke / assert
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, result.Result.(string), r.Result.(string), "Expected result from thunk")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called")
+	assert.Equal(t, 1, executor.calls, "Expected batch function to be submitted to the executor")
+}
+
+// TestExecutorRejectsBatch ensures an error submitting the batch function resolves each key with the error
+func TestExecutorRejectsBatch(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "executor", Err: nil}
+	cb := func() { callCount += 1 }
+	expectedErr := errors.New("queue full")
+	executor := &mockExecutor{err: expectedErr}
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithExecutor(executor))
+
+	// invoke / assert
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range []PrimaryKey{1, 2} {
+		returned, ok := r.GetValue(k)
+		assert.True(t, ok, "Expected result to have been found")
+		assert.Equal(t, expectedErr, returned.Err, "Expected executor error")
+	}
+	assert.Equal(t, 0, callCount, "Expected batch function not to be called")
+}
+
+// TestExecutorPanicRecovered ensures a panic in the batch function on the executor resolves each key to a
+// PanicError
+func TestExecutorPanicRecovered(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		panic("executor panic")
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithExecutor(&mockExecutor{}))
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.EqualError(t, r.Err, "dataloader: batch function panicked: executor panic", "Expected panic error")
+}
@@ -0,0 +1,66 @@
+package dataloader
+
+// Future is the eventual result of a Thunk. The Thunk is resolved in the background so that transformations
+// can be attached with Then and Catch before the batch resolves. Each Then and Catch returns a new Future
+// which resolves once the previous Future resolved.
+type Future struct {
+	done   chan struct{}
+	result Result
+}
+
+// NewFuture returns a Future resolving the thunk of the key. A key without a result resolves to a
+// NotFoundError.
+func NewFuture(key Key, thunk Thunk) *Future {
+	return newFuture(func() Result {
+		return resolve(key, thunk)
+	})
+}
+
+// ===================================== public methods =====================================
+
+// Then returns a Future resolving to the result of the function called with the result of this Future.
+// The function isn't called if this Future resolves to an error, which is passed on instead.
+func (f *Future) Then(fn func(Result) Result) *Future {
+	return newFuture(func() Result {
+		r := f.Get()
+		if r.Err != nil {
+			return r
+		}
+		return fn(r)
+	})
+}
+
+// Catch returns a Future resolving to the result of this Future. The function is called with the error if
+// this Future resolves to an error.
+func (f *Future) Catch(fn func(error)) *Future {
+	return newFuture(func() Result {
+		r := f.Get()
+		if r.Err != nil {
+			fn(r.Err)
+		}
+		return r
+	})
+}
+
+// Get blocks until the Future resolves and returns its result
+func (f *Future) Get() Result {
+	<-f.done
+	return f.result
+}
+
+// Done returns a channel which is closed once the Future resolved, e.g. to select on it with other channels
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// ===================================== private =====================================
+
+// newFuture returns a Future resolving to the result of the function called in a new go routine
+func newFuture(fn func() Result) *Future {
+	f := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.result = fn()
+	}()
+	return f
+}
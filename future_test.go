@@ -0,0 +1,64 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFutureThen ensures the transformations attached before the batch resolves are applied in order
+func TestFutureThen(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	batch := getBatchFunction(func() { <-release }, dataloader.Result{Result: 1, Err: nil})
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	var caught bool
+
+	// invoke
+	future := dataloader.NewFuture(PrimaryKey(1), loader.Load(context.Background(), PrimaryKey(1))).
+		Then(func(r dataloader.Result) dataloader.Result {
+			return dataloader.Result{Result: r.Result.(int) + 1, Err: nil}
+		}).
+		Then(func(r dataloader.Result) dataloader.Result {
+			return dataloader.Result{Result: r.Result.(int) * 10, Err: nil}
+		}).
+		Catch(func(error) { caught = true })
+
+	// assert
+	select {
+	case <-future.Done():
+		assert.Fail(t, "Expected the future to wait for the batch")
+	default:
+	}
+	close(release)
+
+	assert.Equal(t, 20, future.Get().Result, "Expected the transformations to be applied in order")
+	assert.False(t, caught, "Expected Catch not to be called without an error")
+}
+
+// TestFutureCatch ensures an error skips the following transformations and is passed to Catch
+func TestFutureCatch(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: nil, Err: expectedErr})
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	var caught error
+	var called bool
+
+	// invoke
+	r := dataloader.NewFuture(PrimaryKey(1), loader.Load(context.Background(), PrimaryKey(1))).
+		Then(func(r dataloader.Result) dataloader.Result {
+			called = true
+			return r
+		}).
+		Catch(func(err error) { caught = err }).
+		Get()
+
+	// assert
+	assert.False(t, called, "Expected Then to be skipped after an error")
+	assert.Equal(t, expectedErr, caught, "Expected the error to be passed to Catch")
+	assert.Equal(t, expectedErr, r.Err, "Expected the error to be passed on")
+}
@@ -0,0 +1,128 @@
+/*
+Package graphgophers integrates the loaders with graph-gophers/graphql-go.
+
+graphql-go passes the context of the request to each resolver. NewContext registers the loaders of a
+request in that context and Load and LoadMany return thunks with the signatures of the graph-gophers
+dataloader, so resolvers can be migrated from it one loader at a time:
+
+	ctx = graphgophers.NewContext(r.Context(), map[string]dataloader.DataLoader{
+		"users": dataloader.NewDataLoader(100, userBatch, standard.NewStandardStrategy()),
+	})
+	schema.Exec(ctx, query, operationName, variables)
+
+	func (r *resolver) Author(ctx context.Context) (*userResolver, error) {
+		v, err := graphgophers.Load(ctx, "users", dataloader.StringKey(r.post.AuthorID))()
+		if err != nil {
+			return nil, err
+		}
+		return &userResolver{v.(*User)}, nil
+	}
+*/
+package graphgophers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andy9775/dataloader"
+)
+
+// Thunk returns the value of the key it was generated for. It has the signature of the Thunk of the
+// graph-gophers dataloader.
+type Thunk func() (interface{}, error)
+
+// ThunkMany returns the values of the keys it was generated for in the order of the keys. It has the
+// signature of the ThunkMany of the graph-gophers dataloader.
+type ThunkMany func() ([]interface{}, []error)
+
+// MissingLoaderError is returned by the thunks of a loader which isn't registered in the context
+type MissingLoaderError struct {
+	Name string
+}
+
+func (e *MissingLoaderError) Error() string {
+	return fmt.Sprintf("graphgophers: no loader registered as %q", e.Name)
+}
+
+type loadersKey struct{}
+
+// NewContext returns a copy of the context with the loaders registered by name. Loaders registered in a
+// parent context remain available unless a loader is registered with the same name.
+func NewContext(ctx context.Context, loaders map[string]dataloader.DataLoader) context.Context {
+	merged := make(map[string]dataloader.DataLoader, len(loaders))
+	if parent, ok := ctx.Value(loadersKey{}).(map[string]dataloader.DataLoader); ok {
+		for name, l := range parent {
+			merged[name] = l
+		}
+	}
+	for name, l := range loaders {
+		merged[name] = l
+	}
+	return context.WithValue(ctx, loadersKey{}, merged)
+}
+
+// FromContext returns the loader registered in the context under the name
+func FromContext(ctx context.Context, name string) (dataloader.DataLoader, bool) {
+	loaders, _ := ctx.Value(loadersKey{}).(map[string]dataloader.DataLoader)
+	l, ok := loaders[name]
+	return l, ok
+}
+
+// Load loads the key with the loader registered in the context under the name and returns a Thunk which
+// blocks until the value of the key is resolved. A key without a result resolves to an error.
+func Load(ctx context.Context, name string, key dataloader.Key) Thunk {
+	l, ok := FromContext(ctx, name)
+	if !ok {
+		return func() (interface{}, error) { return nil, &MissingLoaderError{Name: name} }
+	}
+	return FromThunk(key, l.Load(ctx, key))
+}
+
+// LoadMany loads the keys with the loader registered in the context under the name and returns a ThunkMany
+// which blocks until the values of the keys are resolved.
+func LoadMany(ctx context.Context, name string, keys ...dataloader.Key) ThunkMany {
+	l, ok := FromContext(ctx, name)
+	if !ok {
+		return func() ([]interface{}, []error) {
+			errs := make([]error, len(keys))
+			for i := range errs {
+				errs[i] = &MissingLoaderError{Name: name}
+			}
+			return make([]interface{}, len(keys)), errs
+		}
+	}
+	return FromOrdered(l.LoadManyOrdered(ctx, keys...))
+}
+
+// FromThunk adapts the Thunk of a loader to a graph-gophers Thunk. A key without a result resolves to a
+// dataloader.NotFoundError.
+func FromThunk(key dataloader.Key, thunk dataloader.Thunk) Thunk {
+	return func() (interface{}, error) {
+		r, ok := thunk()
+		if !ok && !dataloader.IsNotFound(r.Err) {
+			return nil, &dataloader.NotFoundError{Key: key.String()}
+		}
+		return r.Result, r.Err
+	}
+}
+
+// FromOrdered adapts the function returned by LoadManyOrdered to a graph-gophers ThunkMany. The errors are
+// in the order of the keys with a nil error for each resolved key, and are nil if every key resolved.
+func FromOrdered(fn func() ([]dataloader.Result, error)) ThunkMany {
+	return func() ([]interface{}, []error) {
+		results, err := fn()
+
+		values := make([]interface{}, len(results))
+		var errs []error
+		if err != nil {
+			errs = make([]error, len(results))
+		}
+		for i, r := range results {
+			values[i] = r.Result
+			if errs != nil {
+				errs[i] = r.Err
+			}
+		}
+		return values, errs
+	}
+}
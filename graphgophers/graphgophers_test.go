@@ -0,0 +1,119 @@
+package graphgophers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/dataloadertest"
+	"github.com/andy9775/dataloader/graphgophers"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+var errDisabled = errors.New("user is disabled")
+
+// newLoader returns a loader which resolves each key to its raw value except key "2" which resolves to an
+// error
+func newLoader() (dataloader.DataLoader, *dataloadertest.BatchFunction) {
+	batch := dataloadertest.NewBatchFunction(func(_ context.Context, key dataloader.Key) dataloader.Result {
+		if key.String() == "2" {
+			return dataloader.Result{Result: nil, Err: errDisabled}
+		}
+		return dataloader.Result{Result: key.String(), Err: nil}
+	})
+	return dataloader.NewDataLoader(10, batch.Batch, dataloadertest.NewStrategy().StrategyFunction()), batch
+}
+
+// ================================================== tests ==================================================
+
+// TestLoad ensures Load resolves the key with the loader registered in the context
+func TestLoad(t *testing.T) {
+	// setup
+	loader, _ := newLoader()
+	ctx := graphgophers.NewContext(context.Background(), map[string]dataloader.DataLoader{"users": loader})
+
+	// invoke
+	v, err := graphgophers.Load(ctx, "users", dataloader.StringKey("1"))()
+	_, errV := graphgophers.Load(ctx, "users", dataloader.StringKey("2"))()
+
+	// assert
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, "1", v, "Expected the value of the key")
+	assert.Equal(t, errDisabled, errV, "Expected the error of the key")
+}
+
+// TestLoadMany ensures LoadMany resolves the keys in order with an error for each failed key
+func TestLoadMany(t *testing.T) {
+	// setup
+	loader, batch := newLoader()
+	ctx := graphgophers.NewContext(context.Background(), map[string]dataloader.DataLoader{"users": loader})
+
+	// invoke
+	values, errs := graphgophers.LoadMany(
+		ctx,
+		"users",
+		dataloader.StringKey("3"), dataloader.StringKey("2"), dataloader.StringKey("1"),
+	)()
+
+	// assert
+	assert.Equal(t, []interface{}{"3", nil, "1"}, values, "Expected the values in the order of the keys")
+	assert.Equal(t, []error{nil, errDisabled, nil}, errs, "Expected the errors in the order of the keys")
+	dataloadertest.AssertBatchCount(t, batch, 1)
+}
+
+// TestLoadManyNoErrors ensures LoadMany returns nil errors when every key resolves
+func TestLoadManyNoErrors(t *testing.T) {
+	// setup
+	loader, _ := newLoader()
+	ctx := graphgophers.NewContext(context.Background(), map[string]dataloader.DataLoader{"users": loader})
+
+	// invoke
+	values, errs := graphgophers.LoadMany(ctx, "users", dataloader.StringKey("1"), dataloader.StringKey("3"))()
+
+	// assert
+	assert.Equal(t, []interface{}{"1", "3"}, values, "Expected the values in the order of the keys")
+	assert.Nil(t, errs, "Expected no errors")
+}
+
+// TestMissingLoader ensures the thunks of an unregistered loader resolve to a MissingLoaderError
+func TestMissingLoader(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	expected := &graphgophers.MissingLoaderError{Name: "users"}
+
+	// invoke
+	_, err := graphgophers.Load(ctx, "users", dataloader.StringKey("1"))()
+	values, errs := graphgophers.LoadMany(ctx, "users", dataloader.StringKey("1"), dataloader.StringKey("2"))()
+
+	// assert
+	assert.Equal(t, expected, err, "Expected a missing loader error")
+	assert.Equal(t, []interface{}{nil, nil}, values, "Expected no values")
+	assert.Equal(t, []error{expected, expected}, errs, "Expected a missing loader error for each key")
+}
+
+// TestNewContext ensures loaders registered in a parent context remain available
+func TestNewContext(t *testing.T) {
+	// setup
+	users, _ := newLoader()
+	posts, _ := newLoader()
+	replaced, _ := newLoader()
+	parent := graphgophers.NewContext(
+		context.Background(),
+		map[string]dataloader.DataLoader{"users": users, "posts": replaced},
+	)
+
+	// invoke
+	ctx := graphgophers.NewContext(parent, map[string]dataloader.DataLoader{"posts": posts})
+
+	// assert
+	l, ok := graphgophers.FromContext(ctx, "users")
+	assert.True(t, ok, "Expected the loader of the parent context")
+	assert.Equal(t, users, l, "Expected the loader of the parent context")
+	l, _ = graphgophers.FromContext(ctx, "posts")
+	assert.True(t, l == posts, "Expected the loader registered with the same name to be replaced")
+	l, _ = graphgophers.FromContext(parent, "posts")
+	assert.True(t, l == replaced, "Expected the parent context to be unchanged")
+}
@@ -0,0 +1,38 @@
+package dataloader
+
+import "context"
+
+// hedge returns a Thunk which waits for the provided thunk and, if it hasn't returned after the hedging
+// delay, calls the user's batch function directly with the single key. The first result to return is used.
+// The hedged call bypasses the loaders batch function so that it doesn't forget the in flight load of the
+// key or count towards the stats, metrics and batch ids.
+func (d *dataloader) hedge(ctx context.Context, key Key, thunk Thunk) Thunk {
+	type data struct {
+		r  Result
+		ok bool
+	}
+
+	return func() (Result, bool) {
+		resultChan := make(chan data, 2) // buffered channel won't block the slower fetch
+
+		go func() {
+			r, ok := thunk()
+			resultChan <- data{r, ok}
+		}()
+
+		select {
+		case result := <-resultChan:
+			return result.r, result.ok
+		case <-d.clock.After(d.hedgeDelay):
+		}
+
+		d.logger.Debug("hedging key", "key", key.String())
+		go func() {
+			r, ok := (*recoverBatch(d.userBatch, d.logger)(ctx, NewKeysWith(key))).GetValue(key)
+			resultChan <- data{r, ok}
+		}()
+
+		result := <-resultChan
+		return result.r, result.ok
+	}
+}
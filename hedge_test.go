@@ -0,0 +1,137 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================================================== tests ==================================================
+
+// TestKeyHedgingReturnsFirstResult ensures a critical key stuck behind a slow batch is fetched directly
+func TestKeyHedgingReturnsFirstResult(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var sizes []int
+	release := make(chan struct{})
+	defer close(release)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		sizes = append(sizes, keys.Length())
+		m.Unlock()
+
+		if keys.Length() > 1 {
+			<-release // slow batch
+		}
+
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: k.(PrimaryKey).String(), Err: nil})
+		}
+		return &r
+	}
+	critical := func(key dataloader.Key) bool { return key.(PrimaryKey) == PrimaryKey(1) }
+	strategy := func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		return &mockBatchingStrategy{mockStrategy{batchFunc: batch}}
+	}
+
+	loader := dataloader.NewDataLoader(
+		2,
+		batch,
+		strategy,
+		dataloader.WithKeyHedging(10*time.Millisecond, critical),
+	)
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1", r.Result, "Expected result from the hedged fetch")
+	m.Lock()
+	assert.Contains(t, sizes, 1, "Expected the key to be fetched directly")
+	m.Unlock()
+}
+
+// TestKeyHedgingBypassesLoader ensures the hedged fetch doesn't count as a batch of the loader or forget the
+// in flight load of the key, which later loads keep joining
+func TestKeyHedgingBypassesLoader(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var sizes []int
+	started, release := make(chan struct{}), make(chan struct{})
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m.Lock()
+		sizes = append(sizes, keys.Length())
+		m.Unlock()
+
+		if keys.Length() > 1 {
+			close(started)
+			<-release // slow batch
+		}
+
+		r := dataloader.NewResultMap(keys.Length())
+		keys.ForEach(func(k dataloader.Key) bool {
+			r.Set(k, dataloader.Result{Result: k.String(), Err: nil})
+			return true
+		})
+		return &r
+	}
+	critical := func(key dataloader.Key) bool { return key.(PrimaryKey) == PrimaryKey(1) }
+	strategy := func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		return &mockBatchingStrategy{mockStrategy{batchFunc: batch}}
+	}
+
+	clk := clock.NewMock()
+	loader := dataloader.NewDataLoader(
+		2,
+		batch,
+		strategy,
+		dataloader.WithKeyHedging(10*time.Millisecond, critical),
+		dataloader.WithClock(clk),
+	)
+
+	// invoke
+	hedged := make(chan dataloader.Result)
+	go func() {
+		r, _ := loader.Load(context.Background(), PrimaryKey(1))()
+		hedged <- r
+	}()
+	clk.BlockUntil(1)
+	<-started // the slow batch is in flight
+	clk.Add(10 * time.Millisecond)
+	r := <-hedged
+	stats := loader.Stats()
+
+	joined := loader.Load(context.Background(), PrimaryKey(1))
+	close(release)
+	joinedResult, ok := joined()
+
+	// assert
+	assert.Equal(t, "1", r.Result, "Expected result from the hedged fetch")
+	assert.Equal(t, uint64(0), stats.Batches, "Expected the hedged fetch not to count as a batch")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1", joinedResult.Result, "Expected result from the in flight load")
+	m.Lock()
+	assert.ElementsMatch(t, []int{2, 1}, sizes, "Expected the later load to join the in flight load")
+	m.Unlock()
+}
+
+// mockBatchingStrategy calls the batch function with the key and a sibling key on every load
+type mockBatchingStrategy struct {
+	mockStrategy
+}
+
+func (s *mockBatchingStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	return func() (dataloader.Result, bool) {
+		r := s.batchFunc(ctx, dataloader.NewKeysWith(key, PrimaryKey(100)))
+		return (*r).GetValue(key)
+	}
+}
@@ -0,0 +1,93 @@
+package dataloader
+
+import (
+	"context"
+	"time"
+)
+
+// BatchInfo describes a call to the batch function
+type BatchInfo struct {
+	// BatchID is the ID of the batch (see BatchIDFromContext). Zero when reported by a strategy which is
+	// used outside of a DataLoader.
+	BatchID uint64
+	// Keys are the keys passed to the batch function
+	Keys Keys
+	// Size is the number of keys passed to the batch function
+	Size int
+	// Strategy is the name of the strategy which called the batch function (see Named). Empty when
+	// reported by a strategy which is used outside of a DataLoader.
+	Strategy string
+	// Duration is the time the batch function took to return. Only set for OnBatchEnd.
+	Duration time.Duration
+	// Errors is the number of keys which resolved to an error. Only set for OnBatchEnd.
+	Errors int
+}
+
+// Hooks contains callbacks invoked at points of the load lifecycle, allowing instrumentation without
+// modifying the loader or strategies. Nil callbacks are ignored. Callbacks are called synchronously and
+// must be safe for concurrent use.
+type Hooks struct {
+	// OnBatchStart is called before the batch function is called
+	OnBatchStart func(context.Context, BatchInfo)
+	// OnBatchEnd is called after the batch function returns
+	OnBatchEnd func(context.Context, BatchInfo)
+	// OnCacheHit is called when a key is resolved from the primed results or the cache
+	OnCacheHit func(context.Context, Key)
+	// OnCacheMiss is called when a key is passed to the strategy to be resolved by the batch function
+	OnCacheMiss func(context.Context, Key)
+}
+
+// BatchStart calls OnBatchStart if set
+func (h Hooks) BatchStart(ctx context.Context, info BatchInfo) {
+	if h.OnBatchStart != nil {
+		h.OnBatchStart(ctx, info)
+	}
+}
+
+// BatchEnd calls OnBatchEnd if set
+func (h Hooks) BatchEnd(ctx context.Context, info BatchInfo) {
+	if h.OnBatchEnd != nil {
+		h.OnBatchEnd(ctx, info)
+	}
+}
+
+// CacheHit calls OnCacheHit if set
+func (h Hooks) CacheHit(ctx context.Context, key Key) {
+	if h.OnCacheHit != nil {
+		h.OnCacheHit(ctx, key)
+	}
+}
+
+// CacheMiss calls OnCacheMiss if set
+func (h Hooks) CacheMiss(ctx context.Context, key Key) {
+	if h.OnCacheMiss != nil {
+		h.OnCacheMiss(ctx, key)
+	}
+}
+
+// CombineHooks returns hooks which call each of the provided hooks in order, allowing multiple
+// instrumentations (e.g. metrics and hot key tracking) to be passed to WithHooks.
+func CombineHooks(hooks ...Hooks) Hooks {
+	return Hooks{
+		OnBatchStart: func(ctx context.Context, info BatchInfo) {
+			for _, h := range hooks {
+				h.BatchStart(ctx, info)
+			}
+		},
+		OnBatchEnd: func(ctx context.Context, info BatchInfo) {
+			for _, h := range hooks {
+				h.BatchEnd(ctx, info)
+			}
+		},
+		OnCacheHit: func(ctx context.Context, key Key) {
+			for _, h := range hooks {
+				h.CacheHit(ctx, key)
+			}
+		},
+		OnCacheMiss: func(ctx context.Context, key Key) {
+			for _, h := range hooks {
+				h.CacheMiss(ctx, key)
+			}
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================================================== tests ==================================================
+
+// TestHooks ensures hooks are invoked for cache hits, misses and batches
+func TestHooks(t *testing.T) {
+	// setup
+	var hits, misses []dataloader.Key
+	var starts, ends []dataloader.BatchInfo
+	hooks := dataloader.Hooks{
+		OnBatchStart: func(ctx context.Context, info dataloader.BatchInfo) { starts = append(starts, info) },
+		OnBatchEnd:   func(ctx context.Context, info dataloader.BatchInfo) { ends = append(ends, info) },
+		OnCacheHit:   func(ctx context.Context, key dataloader.Key) { hits = append(hits, key) },
+		OnCacheMiss:  func(ctx context.Context, key dataloader.Key) { misses = append(misses, key) },
+	}
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: "hooks", Err: nil})
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithCache(newMockCache(1)),
+		dataloader.WithHooks(hooks),
+	)
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+	loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.Equal(t, []dataloader.Key{PrimaryKey(1)}, misses, "Expected first load to miss the cache")
+	assert.Equal(t, []dataloader.Key{PrimaryKey(1)}, hits, "Expected second load to hit the cache")
+	assert.Len(t, starts, 1, "Expected batch start hook to be called once")
+	assert.Len(t, ends, 1, "Expected batch end hook to be called once")
+	assert.Equal(t, uint64(1), ends[0].BatchID, "Expected batch ID")
+	assert.Equal(t, 1, ends[0].Size, "Expected batch size")
+	assert.Equal(t, 0, ends[0].Errors, "Expected no errors")
+	assert.Equal(t, "*dataloader_test.mockStrategy", ends[0].Strategy, "Expected the strategy type as its name")
+}
+
+// TestBatchInfoFromContext ensures the batch function context carries the batch info
+func TestBatchInfoFromContext(t *testing.T) {
+	// setup
+	var info dataloader.BatchInfo
+	var ok bool
+	result := dataloader.Result{Result: "info", Err: nil}
+	batch := getBatchFunction(func() {}, result)
+	loader := dataloader.NewDataLoader(
+		1,
+		func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+			info, ok = dataloader.BatchInfoFromContext(ctx)
+			return batch(ctx, keys)
+		},
+		newMockStrategy(),
+	)
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected the context to carry the batch info")
+	assert.Equal(t, uint64(1), info.BatchID, "Expected batch ID")
+	assert.Equal(t, 1, info.Size, "Expected batch size")
+	assert.Equal(t, "*dataloader_test.mockStrategy", info.Strategy, "Expected the strategy name")
+
+	_, ok = dataloader.BatchInfoFromContext(context.Background())
+	assert.False(t, ok, "Expected no batch info outside of a batch")
+}
@@ -0,0 +1,117 @@
+package dataloader
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyCount is the number of times a key was loaded
+type KeyCount struct {
+	Key   Key
+	Count uint64
+}
+
+// HotKeyTracker counts how often each key is loaded within a rolling window and reports the hottest
+// keys. Hot keys are candidates for longer cache TTLs, pre-warming or sharing via Prime. Pass the
+// trackers Hooks to the loader (see WithHooks and CombineHooks) to count every call to Load and LoadMany,
+// whether the key is resolved from the cache or by the batch function.
+type HotKeyTracker struct {
+	window  time.Duration
+	maxKeys int
+
+	m        sync.Mutex
+	started  time.Time // start of the current window
+	current  map[string]*KeyCount
+	previous map[string]*KeyCount
+}
+
+// NewHotKeyTracker returns a tracker which counts loads over the current and the previous window. At most
+// maxKeys distinct keys are tracked per window, loads of further keys are not counted until the window
+// rolls over. A maxKeys of 0 or less tracks every key. A window of 0 or less counts every load since the
+// tracker was created.
+func NewHotKeyTracker(window time.Duration, maxKeys int) *HotKeyTracker {
+	return &HotKeyTracker{
+		window:   window,
+		maxKeys:  maxKeys,
+		started:  time.Now(),
+		current:  make(map[string]*KeyCount),
+		previous: make(map[string]*KeyCount),
+	}
+}
+
+// Record counts a load of the key
+func (t *HotKeyTracker) Record(key Key) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.roll(time.Now())
+
+	k := key.String()
+	if c, ok := t.current[k]; ok {
+		c.Count++
+		return
+	}
+	if t.maxKeys > 0 && len(t.current) >= t.maxKeys {
+		return
+	}
+	t.current[k] = &KeyCount{Key: key, Count: 1}
+}
+
+// Top returns up to n keys with the most loads over the current and previous window, hottest first
+func (t *HotKeyTracker) Top(n int) []KeyCount {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.roll(time.Now())
+
+	counts := make(map[string]KeyCount, len(t.current)+len(t.previous))
+	for _, window := range []map[string]*KeyCount{t.previous, t.current} {
+		for k, c := range window {
+			total := counts[k]
+			total.Key = c.Key
+			total.Count += c.Count
+			counts[k] = total
+		}
+	}
+
+	top := make([]KeyCount, 0, len(counts))
+	for _, c := range counts {
+		top = append(top, c)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Key.String() < top[j].Key.String()
+	})
+
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Hooks returns callbacks which record each key passed to Load or LoadMany
+func (t *HotKeyTracker) Hooks() Hooks {
+	record := func(_ context.Context, key Key) { t.Record(key) }
+	return Hooks{OnCacheHit: record, OnCacheMiss: record}
+}
+
+// roll starts a new window if the current window has elapsed. The current window becomes the previous
+// window, unless more than a full window has passed without a roll.
+func (t *HotKeyTracker) roll(now time.Time) {
+	elapsed := now.Sub(t.started)
+	if t.window <= 0 || elapsed < t.window {
+		return
+	}
+
+	if elapsed < 2*t.window {
+		t.previous = t.current
+	} else {
+		t.previous = make(map[string]*KeyCount)
+	}
+	t.current = make(map[string]*KeyCount, len(t.previous))
+	t.started = now.Add(-(elapsed % t.window))
+}
@@ -0,0 +1,75 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHotKeyTrackerTop ensures the hottest keys are reported, hottest first
+func TestHotKeyTrackerTop(t *testing.T) {
+	// setup
+	tracker := dataloader.NewHotKeyTracker(time.Hour, 0)
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: "hot", Err: nil})
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithCache(newMockCache(3)),
+		dataloader.WithHooks(tracker.Hooks()),
+	)
+
+	// invoke
+	for i := 0; i < 3; i++ {
+		loader.Load(context.Background(), PrimaryKey(1))() // cache hits are counted
+	}
+	loader.LoadMany(context.Background(), PrimaryKey(2), PrimaryKey(3))()
+	loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	top := tracker.Top(2)
+	assert.Equal(t, []dataloader.KeyCount{
+		{Key: PrimaryKey(1), Count: 3},
+		{Key: PrimaryKey(2), Count: 2},
+	}, top, "Expected the two hottest keys")
+	assert.Len(t, tracker.Top(10), 3, "Expected every tracked key")
+}
+
+// TestHotKeyTrackerWindow ensures loads outside of the window are not reported and the number of tracked
+// keys is bounded
+func TestHotKeyTrackerWindow(t *testing.T) {
+	// setup
+	tracker := dataloader.NewHotKeyTracker(20*time.Millisecond, 1)
+
+	// invoke
+	tracker.Record(PrimaryKey(1))
+	tracker.Record(PrimaryKey(2)) // exceeds the tracked keys
+
+	// assert
+	assert.Equal(t, []dataloader.KeyCount{{Key: PrimaryKey(1), Count: 1}}, tracker.Top(10),
+		"Expected only the first key to be tracked")
+
+	time.Sleep(50 * time.Millisecond) // more than two windows
+	assert.Empty(t, tracker.Top(10), "Expected loads outside the window to expire")
+}
+
+// TestCombineHooks ensures each of the combined hooks is called
+func TestCombineHooks(t *testing.T) {
+	// setup
+	var first, second int
+	hooks := dataloader.CombineHooks(
+		dataloader.Hooks{OnCacheMiss: func(context.Context, dataloader.Key) { first++ }},
+		dataloader.Hooks{OnCacheMiss: func(context.Context, dataloader.Key) { second++ }},
+	)
+
+	// invoke
+	hooks.CacheMiss(context.Background(), PrimaryKey(1))
+	hooks.CacheHit(context.Background(), PrimaryKey(1)) // nil callbacks are ignored
+
+	// assert
+	assert.Equal(t, 1, first, "Expected the first hook to be called")
+	assert.Equal(t, 1, second, "Expected the second hook to be called")
+}
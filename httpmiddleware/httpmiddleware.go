@@ -0,0 +1,134 @@
+/*
+Package httpmiddleware scopes loaders to the requests of a net/http server.
+
+The middleware builds a fresh set of loaders for each incoming request, so results are never shared between
+requests, and stores them in the context of the request. When the handler returns, the pending keys of each
+loader are flushed, the loaders are closed (see dataloader.Closer) and the context of the request is
+cancelled, releasing the strategies workers:
+
+	mw := httpmiddleware.New(func(r *http.Request) map[string]dataloader.DataLoader {
+		return map[string]dataloader.DataLoader{
+			"users": dataloader.NewDataLoader(100, userBatch, standard.NewStandardStrategy()),
+		}
+	})
+	http.Handle("/graphql", mw(handler))
+
+	func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+		users, _ := httpmiddleware.FromContext(r.Context(), "users")
+		...
+	}
+*/
+package httpmiddleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Factory builds the loaders of a request by name
+type Factory func(r *http.Request) map[string]dataloader.DataLoader
+
+type loadersKey struct{}
+
+// options contains the configuration of the loader middleware
+type options struct {
+	drainTimeout time.Duration
+	logger       logger.Logger
+}
+
+// Option accepts the middleware options and sets an option on them.
+type Option func(*options)
+
+// New returns a middleware which builds the loaders of each request with the factory and tears them down when
+// the wrapped handler returns.
+func New(factory Factory, opts ...Option) func(http.Handler) http.Handler {
+	// default options
+	o := options{}
+	formatOptions(&o)
+
+	// format options
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			loaders := factory(r.WithContext(ctx))
+			defer o.teardown(ctx, cancel, loaders)
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, loadersKey{}, loaders)))
+		})
+	}
+}
+
+// FromContext returns the loader of the request registered under the name
+func FromContext(ctx context.Context, name string) (dataloader.DataLoader, bool) {
+	loaders, _ := ctx.Value(loadersKey{}).(map[string]dataloader.DataLoader)
+	l, ok := loaders[name]
+	return l, ok
+}
+
+// ============================================== option setters =============================================
+
+// WithDrainTimeout configures the middleware to drain the loaders of a request for up to the timeout before
+// its context is cancelled, so batches started by the handler complete. Defaults to 0 which flushes the
+// loaders without waiting.
+func WithDrainTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.drainTimeout = t
+	}
+}
+
+// WithLogger configures the logger for the middleware. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ============================================== private =============================================
+
+// teardown flushes or drains the loaders of a request, closes them and cancels its context
+func (o options) teardown(ctx context.Context, cancel context.CancelFunc, loaders map[string]dataloader.DataLoader) {
+	defer cancel()
+	defer closeLoaders(loaders)
+
+	if o.drainTimeout <= 0 {
+		for _, l := range loaders {
+			l.Flush(ctx)
+		}
+		return
+	}
+
+	drainCtx, drainCancel := context.WithTimeout(ctx, o.drainTimeout)
+	defer drainCancel()
+
+	for name, l := range loaders {
+		if err := l.Drain(drainCtx); err != nil {
+			o.logger.Error("loader failed to drain", "loader", name, "error", err)
+		}
+	}
+}
+
+// ============================================== helpers =============================================
+
+// closeLoaders closes the loaders which implement dataloader.Closer, e.g. to unsubscribe idle strategies
+// from a tracker shared between requests
+func closeLoaders(loaders map[string]dataloader.DataLoader) {
+	for _, l := range loaders {
+		if c, ok := l.(dataloader.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// formatOptions configures default values for the middleware options
+func formatOptions(opts *options) {
+	opts.logger = logger.Noop()
+}
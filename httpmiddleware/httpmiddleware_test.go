@@ -0,0 +1,138 @@
+package httpmiddleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/dataloadertest"
+	"github.com/andy9775/dataloader/httpmiddleware"
+	"github.com/andy9775/dataloader/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// stuckLoader is a loader which fails to drain
+type stuckLoader struct {
+	dataloader.DataLoader
+}
+
+func (stuckLoader) Drain(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// factory returns a factory which builds a users loader with a new strategy for each request and records the
+// strategies
+func factory(strategies *[]*dataloadertest.Strategy) httpmiddleware.Factory {
+	return func(*http.Request) map[string]dataloader.DataLoader {
+		strategy := dataloadertest.NewStrategy()
+		*strategies = append(*strategies, strategy)
+		batch := dataloadertest.NewBatchFunction(nil)
+		return map[string]dataloader.DataLoader{
+			"users": dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction()),
+		}
+	}
+}
+
+// serve sends a request to the handler wrapped with the middleware
+func serve(mw func(http.Handler) http.Handler, handler http.HandlerFunc) {
+	mw(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+// ================================================== tests ==================================================
+
+// TestLoadersPerRequest ensures each request receives new loaders through its context
+func TestLoadersPerRequest(t *testing.T) {
+	// setup
+	var strategies []*dataloadertest.Strategy
+	var loaders []dataloader.DataLoader
+	mw := httpmiddleware.New(factory(&strategies))
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		l, ok := httpmiddleware.FromContext(r.Context(), "users")
+		assert.True(t, ok, "Expected the loader in the request context")
+		loaders = append(loaders, l)
+	}
+
+	// invoke
+	serve(mw, handler)
+	serve(mw, handler)
+
+	// assert
+	assert.Len(t, strategies, 2, "Expected the loaders to be built for each request")
+	assert.Len(t, loaders, 2, "Expected a loader for each request")
+	assert.False(t, loaders[0] == loaders[1], "Expected a new loader for each request")
+}
+
+// TestTeardown ensures the pending keys are flushed and the request context is cancelled when the handler
+// returns
+func TestTeardown(t *testing.T) {
+	// setup
+	var strategies []*dataloadertest.Strategy
+	var ctx context.Context
+	var thunk dataloader.Thunk
+	mw := httpmiddleware.New(factory(&strategies))
+
+	// invoke
+	serve(mw, func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+		l, _ := httpmiddleware.FromContext(ctx, "users")
+		thunk = l.Load(ctx, dataloader.StringKey("1"))
+	})
+
+	// assert
+	assert.Equal(t, 1, strategies[0].FlushCount(), "Expected the loader to be flushed")
+	assert.Equal(t, 1, strategies[0].CloseCount(), "Expected the loader to be closed")
+	assert.Empty(t, strategies[0].PendingKeys(), "Expected no pending keys")
+	assert.Equal(t, context.Canceled, ctx.Err(), "Expected the request context to be cancelled")
+	r, ok := thunk()
+	assert.True(t, ok, "Expected the flushed key to be resolved")
+	assert.Equal(t, dataloader.StringKey("1"), r.Result, "Expected the result of the key")
+}
+
+// TestDrainTimeout ensures loaders which fail to drain within the timeout are logged
+func TestDrainTimeout(t *testing.T) {
+	// setup
+	log := logger.Capture()
+	mw := httpmiddleware.New(
+		func(*http.Request) map[string]dataloader.DataLoader {
+			return map[string]dataloader.DataLoader{"users": stuckLoader{}}
+		},
+		httpmiddleware.WithDrainTimeout(10*time.Millisecond),
+		httpmiddleware.WithLogger(log),
+	)
+
+	// invoke
+	start := time.Now()
+	serve(mw, func(http.ResponseWriter, *http.Request) {})
+
+	// assert
+	assert.True(t, time.Since(start) >= 10*time.Millisecond, "Expected the middleware to wait for the drain")
+	assert.True(t, log.Contains("loader failed to drain"), "Expected the drain failure to be logged")
+}
+
+// TestTeardownOnPanic ensures the request context is cancelled when the handler panics
+func TestTeardownOnPanic(t *testing.T) {
+	// setup
+	var strategies []*dataloadertest.Strategy
+	var ctx context.Context
+	mw := httpmiddleware.New(factory(&strategies))
+
+	// invoke
+	assert.Panics(t, func() {
+		serve(mw, func(w http.ResponseWriter, r *http.Request) {
+			ctx = r.Context()
+			panic(errors.New("handler failed"))
+		})
+	}, "Expected the panic to propagate")
+
+	// assert
+	assert.Equal(t, context.Canceled, ctx.Err(), "Expected the request context to be cancelled")
+	assert.Equal(t, 1, strategies[0].FlushCount(), "Expected the loader to be flushed")
+	assert.Equal(t, 1, strategies[0].CloseCount(), "Expected the loader to be closed")
+}
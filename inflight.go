@@ -0,0 +1,233 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// call is a load of a key which is pending or executing in the strategy. Loads of the same key made while
+// the call is in flight with the same load options join it and share its result instead of passing the key to
+// the strategy again.
+type call struct {
+	ctx     context.Context // context of the load which created the call
+	opts    LoadOptions
+	ready   chan struct{} // closed once resolve is set
+	resolve func() (Result, bool)
+
+	once   sync.Once
+	result Result
+	ok     bool
+}
+
+// wait resolves the call once and returns the shared result
+func (c *call) wait() (Result, bool) {
+	<-c.ready
+	c.once.Do(func() {
+		c.result, c.ok = c.resolve()
+	})
+	return c.result, c.ok
+}
+
+// cancelledFor returns true if the call resolved to the error of its own context, which was cancelled or
+// timed out independently of ctx. Loads with ctx which joined the call must load the key again.
+func (c *call) cancelledFor(ctx context.Context) bool {
+	return ctx != c.ctx && cancelled(c.ctx, c.result)
+}
+
+// ============================================== private =============================================
+
+// join returns the in flight calls for the keys made with the same load options as ctx and registers new
+// calls for the other keys. The new calls must be resolved and their ready channel closed.
+func (d *dataloader) join(ctx context.Context, keyArr ...Key) (joined map[string]*call, created map[string]*call) {
+	d.callsMutex.Lock()
+	defer d.callsMutex.Unlock()
+
+	if d.calls == nil {
+		d.calls = make(map[string]*call)
+	}
+
+	opts, _ := LoadOptionsFromContext(ctx)
+	joined, created = make(map[string]*call), make(map[string]*call)
+	for _, key := range keyArr {
+		k := key.String()
+		if _, ok := created[k]; ok { // duplicate key within the same call
+			continue
+		}
+		if c, ok := d.calls[k]; ok && c.opts == opts {
+			joined[k] = c
+			continue
+		}
+
+		c := &call{ctx: ctx, opts: opts, ready: make(chan struct{})}
+		d.calls[k] = c
+		created[k] = c
+	}
+	return joined, created
+}
+
+// inflightCalls returns the calls in flight for the keys when a batch containing them starts
+func (d *dataloader) inflightCalls(keys Keys) map[string]*call {
+	d.callsMutex.Lock()
+	defer d.callsMutex.Unlock()
+
+	calls := make(map[string]*call, keys.Length())
+	for _, k := range keys.StringKeys() {
+		if c, ok := d.calls[k]; ok {
+			calls[k] = c
+		}
+	}
+	return calls
+}
+
+// forget removes the calls returned by inflightCalls once the batch containing them has returned, so that
+// later loads which miss the cache call the batch function again. Calls registered for the keys since the
+// batch started belong to a newer load and are kept.
+func (d *dataloader) forget(calls map[string]*call) {
+	d.callsMutex.Lock()
+	defer d.callsMutex.Unlock()
+
+	for k, c := range calls {
+		if d.calls[k] == c {
+			delete(d.calls, k)
+		}
+	}
+}
+
+// forgetCall removes the call for the key if it is still in flight, e.g. after it failed to resolve because
+// its context was cancelled
+func (d *dataloader) forgetCall(key string, c *call) {
+	d.callsMutex.Lock()
+	defer d.callsMutex.Unlock()
+
+	if d.calls[key] == c {
+		delete(d.calls, key)
+	}
+}
+
+// loadInflight returns a thunk which resolves the key through the strategy, or through the in flight call
+// for the key if the key is already pending or executing. If the joined call fails because its context is
+// done, the key is loaded again with ctx through the current strategy.
+func (d *dataloader) loadInflight(ctx context.Context, strategy Strategy, key Key, thunk func(Strategy) Thunk) Thunk {
+	joined, created := d.join(ctx, key)
+	if c, ok := joined[key.String()]; ok {
+		d.logger.Debug("joining in flight load", "key", key.String())
+		strategy.LoadNoOp(ctx) // count the call towards the strategies capacity
+		return func() (Result, bool) {
+			if r, ok := c.wait(); !c.cancelledFor(ctx) {
+				return r, ok
+			}
+			d.logger.Debug("reloading key of cancelled in flight load", "key", key.String())
+			return d.reloadInflight(ctx, key, thunk)()
+		}
+	}
+
+	c := created[key.String()]
+	c.resolve = d.forgetOnFailure(ctx, key.String(), c, thunk(strategy))
+	close(c.ready)
+	return c.wait
+}
+
+// loadManyInflight returns a function which resolves each key through the strategy, or through the in flight
+// call for the key if the key is already pending or executing. load is called with the keys which aren't in
+// flight. Keys of joined calls which fail because their context is done are loaded again with ctx through
+// the current strategy.
+func (d *dataloader) loadManyInflight(
+	ctx context.Context,
+	strategy Strategy,
+	keyArr []Key,
+	load func(Strategy, ...Key) ThunkMany,
+) func() ResultMap {
+	joined, created := d.join(ctx, keyArr...)
+
+	if len(created) == 0 {
+		d.logger.Debug("joining in flight loads", "keys", len(joined))
+		strategy.LoadNoOp(ctx) // count the call towards the strategies capacity
+	} else {
+		newKeys := make([]Key, 0, len(created))
+		seen := make(map[string]bool, len(created))
+		for _, key := range keyArr {
+			if _, ok := created[key.String()]; ok && !seen[key.String()] {
+				seen[key.String()] = true
+				newKeys = append(newKeys, key)
+			}
+		}
+
+		var once sync.Once
+		var r ResultMap
+		thunkMany := load(strategy, newKeys...)
+		shared := func() ResultMap {
+			once.Do(func() { r = thunkMany() })
+			return r
+		}
+
+		for _, key := range newKeys {
+			k, c := key.String(), created[key.String()]
+			c.resolve = d.forgetOnFailure(ctx, k, c, func() (Result, bool) {
+				v, ok := shared()[k]
+				return v, ok
+			})
+			close(c.ready)
+		}
+	}
+
+	return func() ResultMap {
+		r := NewResultMap(len(keyArr))
+		var reload []Key
+		for _, key := range keyArr {
+			c, ok := created[key.String()]
+			if !ok {
+				c = joined[key.String()]
+			}
+			v, ok := c.wait()
+			if c.cancelledFor(ctx) {
+				reload = append(reload, key)
+				continue
+			}
+			if ok {
+				r[key.String()] = v
+			}
+		}
+
+		if len(reload) > 0 {
+			d.logger.Debug("reloading keys of cancelled in flight loads", "keys", len(reload))
+			for k, v := range d.reloadManyInflight(ctx, reload, load)() {
+				r[k] = v
+			}
+		}
+		return r
+	}
+}
+
+// reloadInflight loads the key like loadInflight through the current strategy, which may have been swapped
+// since the key was first loaded
+func (d *dataloader) reloadInflight(ctx context.Context, key Key, thunk func(Strategy) Thunk) Thunk {
+	strategy, release := d.acquireStrategy()
+	defer release()
+
+	return d.loadInflight(ctx, strategy, key, thunk)
+}
+
+// reloadManyInflight loads the keys like loadManyInflight through the current strategy, which may have been
+// swapped since the keys were first loaded
+func (d *dataloader) reloadManyInflight(
+	ctx context.Context,
+	keyArr []Key,
+	load func(Strategy, ...Key) ThunkMany,
+) func() ResultMap {
+	strategy, release := d.acquireStrategy()
+	defer release()
+
+	return d.loadManyInflight(ctx, strategy, keyArr, load)
+}
+
+// forgetOnFailure returns a thunk which removes the call if the thunk fails to resolve the key, so that later
+// loads don't join a call which failed, e.g. because its context was cancelled
+func (d *dataloader) forgetOnFailure(ctx context.Context, key string, c *call, thunk Thunk) Thunk {
+	return func() (Result, bool) {
+		r, ok := thunk()
+		if !ok || cancelled(ctx, r) {
+			d.forgetCall(key, c)
+		}
+		return r, ok
+	}
+}
@@ -0,0 +1,234 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies/standard"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInflightLoadsShareResult ensures loads of a pending key share the pending result
+func TestInflightLoadsShareResult(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "inflight", Err: nil}
+	batch := getBatchFunction(func() { callCount++ }, result)
+	loader := dataloader.NewDataLoader(2, batch, newMockStrategy())
+
+	// invoke
+	first := loader.Load(context.Background(), PrimaryKey(1))
+	second := loader.Load(context.Background(), PrimaryKey(1))
+	many := loader.LoadMany(context.Background(), PrimaryKey(1))
+
+	r1, ok1 := first()
+	r2, ok2 := second()
+	m := many()
+
+	// assert
+	assert.True(t, ok1 && ok2, "Expected both loads to resolve")
+	assert.Equal(t, "inflight", r1.Result, "Expected the result")
+	assert.Equal(t, r1.Result, r2.Result, "Expected the loads to share the result")
+	assert.Equal(t, "inflight", m.GetValueForString("1").Result, "Expected LoadMany to share the result")
+	assert.Equal(t, 1, callCount, "Expected the batch function to be called once")
+
+	loader.Load(context.Background(), PrimaryKey(1))()
+	assert.Equal(t, 2, callCount, "Expected a load after the batch returned to call the batch function")
+}
+
+// TestInflightCancelledLoad ensures a load which was cancelled is neither joined nor cached for later loads
+func TestInflightCancelledLoad(t *testing.T) {
+	// setup
+	result := dataloader.Result{Result: "retried", Err: nil}
+	batch := getBatchFunction(func() {}, result)
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newCancellableStrategy(),
+		dataloader.WithCache(newMockCache(1)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// invoke
+	cancelled, cancelledOK := loader.Load(ctx, PrimaryKey(1))()
+	cancelledMany := loader.LoadMany(ctx, PrimaryKey(2))()
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	m := loader.LoadMany(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.True(t, cancelledOK, "Expected the cancelled load to resolve")
+	assert.Equal(t, context.Canceled, cancelled.Err, "Expected the context error")
+	assert.Equal(t, context.Canceled, cancelledMany.GetValueForString("2").Err, "Expected the context error")
+	assert.True(t, ok, "Expected the later load to call the batch function")
+	assert.Equal(t, "retried", r.Result, "Expected the result")
+	assert.Equal(t, "retried", m.GetValueForString("2").Result, "Expected the result")
+}
+
+// TestInflightJoinedCancelledLoad ensures a load which joined a call cancelled by the context of another load
+// loads the key again with its own context
+func TestInflightJoinedCancelledLoad(t *testing.T) {
+	// setup
+	var callCount int32
+	result := dataloader.Result{Result: "live", Err: nil}
+	batch := getBatchFunction(func() { atomic.AddInt32(&callCount, 1) }, result)
+	loader := dataloader.NewDataLoader(
+		3,
+		batch,
+		standard.NewStandardStrategy(standard.WithTimeout(20*time.Millisecond)),
+	)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB := context.Background()
+
+	// invoke
+	a := loader.Load(ctxA, PrimaryKey(1))
+	b := loader.Load(ctxB, PrimaryKey(1))
+	bMany := loader.LoadMany(ctxB, PrimaryKey(1))
+	cancelA()
+
+	a()
+	r, ok := b()
+	m := bMany()
+
+	// assert
+	assert.True(t, ok, "Expected the load to resolve")
+	assert.Equal(t, "live", r.Result, "Expected the load to be resolved with its own context")
+	assert.Equal(t, "live", m.GetValueForString("1").Result, "Expected LoadMany to be resolved with its own context")
+	assert.True(t, atomic.LoadInt32(&callCount) >= 1, "Expected the batch function to be called")
+}
+
+// TestInflightLoadOptionsNotJoined ensures loads with different load options don't share a call
+func TestInflightLoadOptionsNotJoined(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "inflight", Err: nil}
+	batch := getBatchFunction(func() { callCount++ }, result)
+	loader := dataloader.NewDataLoader(2, batch, newMockStrategy())
+
+	// invoke
+	first := loader.Load(context.Background(), PrimaryKey(1))
+	second := loader.Load(context.Background(), PrimaryKey(1), dataloader.WithFlush())
+	third := loader.Load(context.Background(), PrimaryKey(1), dataloader.WithFlush())
+	first()
+	second()
+	third()
+
+	// assert
+	assert.Equal(t, 2, callCount, "Expected loads with the same options to share a call")
+}
+
+// TestInflightLateBatchKeepsNewerLoad ensures a batch which returns after a newer load of the same key started
+// doesn't forget the call of the newer load, which later loads keep joining
+func TestInflightLateBatchKeepsNewerLoad(t *testing.T) {
+	// setup
+	var callCount int32
+	gates := []chan struct{}{make(chan struct{}), make(chan struct{})}
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if n := atomic.AddInt32(&callCount, 1); int(n) <= len(gates) {
+			<-gates[n-1]
+		}
+		r := dataloader.NewResultMap(keys.Length())
+		keys.ForEach(func(k dataloader.Key) bool {
+			r.Set(k, dataloader.Result{Result: "inflight", Err: nil})
+			return true
+		})
+		return &r
+	}
+	loader := dataloader.NewDataLoader(1, batch, newAsyncStrategy())
+	ctx, cancel := context.WithCancel(context.Background())
+	called := func(n int32) func() bool {
+		return func() bool { return atomic.LoadInt32(&callCount) == n }
+	}
+
+	// invoke
+	cancelled := loader.Load(ctx, PrimaryKey(1))
+	assert.Eventually(t, called(1), time.Second, time.Millisecond, "Expected the first batch to start")
+	cancel()
+	cancelled()
+
+	newer := loader.Load(context.Background(), PrimaryKey(1))
+	assert.Eventually(t, called(2), time.Second, time.Millisecond, "Expected the newer load to start a batch")
+	close(gates[0])
+	assert.Eventually(t, func() bool { return loader.Stats().Batches == 1 }, time.Second, time.Millisecond,
+		"Expected the first batch to return")
+
+	joined := loader.Load(context.Background(), PrimaryKey(1))
+	close(gates[1])
+	r, ok := newer()
+	joinedResult, joinedOK := joined()
+
+	// assert
+	assert.True(t, ok && joinedOK, "Expected both loads to resolve")
+	assert.Equal(t, "inflight", r.Result, "Expected the result")
+	assert.Equal(t, "inflight", joinedResult.Result, "Expected the result")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&callCount), "Expected the later load to join the newer load")
+}
+
+// ========================= cancellable strategy =========================
+
+// cancellableStrategy resolves keys like the mock strategy unless the context is done
+type cancellableStrategy struct {
+	mockStrategy
+}
+
+func newCancellableStrategy() func(int, dataloader.BatchFunction) dataloader.Strategy {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		return &cancellableStrategy{mockStrategy{batchFunc: batch}}
+	}
+}
+
+func (s *cancellableStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	thunk := s.mockStrategy.Load(ctx, key)
+	return func() (dataloader.Result, bool) {
+		if ctx.Err() != nil {
+			return dataloader.Result{Result: nil, Err: ctx.Err()}, true
+		}
+		return thunk()
+	}
+}
+
+func (s *cancellableStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	thunkMany := s.mockStrategy.LoadMany(ctx, keyArr...)
+	return func() dataloader.ResultMap {
+		if ctx.Err() != nil {
+			r := dataloader.NewResultMap(len(keyArr))
+			for _, k := range keyArr {
+				r.Set(k, dataloader.Result{Result: nil, Err: ctx.Err()})
+			}
+			return r
+		}
+		return thunkMany()
+	}
+}
+
+// ========================= async strategy =========================
+
+// asyncStrategy calls the batch function in a go routine on every load, like a strategy whose worker runs
+// the batch while callers wait. Thunks resolve to the context error once the context is done.
+type asyncStrategy struct {
+	mockStrategy
+}
+
+func newAsyncStrategy() func(int, dataloader.BatchFunction) dataloader.Strategy {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		return &asyncStrategy{mockStrategy{batchFunc: batch}}
+	}
+}
+
+func (s *asyncStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := make(chan dataloader.ResultMap, 1)
+	go func() { resultChan <- *s.batchFunc(context.Background(), dataloader.NewKeysWith(key)) }()
+
+	return func() (dataloader.Result, bool) {
+		select {
+		case <-ctx.Done():
+			return dataloader.Result{Result: nil, Err: ctx.Err()}, true
+		case r := <-resultChan:
+			return r.GetValue(key)
+		}
+	}
+}
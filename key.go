@@ -1,5 +1,20 @@
 package dataloader
 
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// compositeSeparator separates the fields of a CompositeKey
+	compositeSeparator = ':'
+	// compositeEscape escapes separators within the fields of a CompositeKey
+	compositeEscape = '\\'
+)
+
 // Key is an interface each element identifier must implement in order to be stored and cached
 // in the ResultsMap
 type Key interface {
@@ -22,8 +37,142 @@ func (k StringKey) Raw() interface{} {
 	return k
 }
 
-// Keys wraps an array of keys and contains accessor methods
+// IntKey is a Key for int identifiers
+type IntKey int
+
+func (k IntKey) String() string {
+	return strconv.Itoa(int(k))
+}
+
+func (k IntKey) Raw() interface{} {
+	return k
+}
+
+// Int64Key is a Key for int64 identifiers, e.g. database sequences
+type Int64Key int64
+
+func (k Int64Key) String() string {
+	return strconv.FormatInt(int64(k), 10)
+}
+
+func (k Int64Key) Raw() interface{} {
+	return k
+}
+
+// UUIDKey is a Key for UUID identifiers. It is backed by the 16 bytes of the UUID, so the byte arrays of
+// UUID libraries convert to it directly, e.g. UUIDKey(uuid.New()).
+type UUIDKey [16]byte
+
+// ParseUUIDKey parses a UUID in the canonical xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx form
+func ParseUUIDKey(s string) (UUIDKey, error) {
+	var k UUIDKey
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return k, fmt.Errorf("dataloader: invalid UUID %q", s)
+	}
+
+	src := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(k[:], []byte(src)); err != nil {
+		return k, fmt.Errorf("dataloader: invalid UUID %q", s)
+	}
+	return k, nil
+}
+
+// String returns the UUID in the canonical lowercase xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx form
+func (k UUIDKey) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], k[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], k[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], k[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], k[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], k[10:])
+	return string(buf[:])
+}
+
+func (k UUIDKey) Raw() interface{} {
+	return k
+}
+
+// CompositeKey is a Key made of multiple fields, e.g. a tenant and a user ID, for batch functions which
+// don't key on a single scalar. The fields are formatted with fmt.Sprint and joined with ":", escaping ":"
+// and a backslash within a field, so the String() of a key is stable and unique for its fields.
+type CompositeKey struct {
+	encoded string
+}
+
+// NewCompositeKey returns a CompositeKey of the fields
+func NewCompositeKey(fields ...interface{}) CompositeKey {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(compositeSeparator)
+		}
+		for _, c := range []byte(fmt.Sprint(f)) {
+			if c == compositeSeparator || c == compositeEscape {
+				b.WriteByte(compositeEscape)
+			}
+			b.WriteByte(c)
+		}
+	}
+	return CompositeKey{encoded: b.String()}
+}
+
+func (k CompositeKey) String() string {
+	return k.encoded
+}
+
+// Raw returns the CompositeKey so its fields can be read by the batch function
+func (k CompositeKey) Raw() interface{} {
+	return k
+}
+
+// Fields returns the formatted fields of the key in the order they were passed to NewCompositeKey
+func (k CompositeKey) Fields() []string {
+	fields := []string{}
+	var field []byte
+	for i := 0; i < len(k.encoded); i++ {
+		switch c := k.encoded[i]; {
+		case c == compositeEscape && i+1 < len(k.encoded):
+			i++
+			field = append(field, k.encoded[i])
+		case c == compositeSeparator:
+			fields = append(fields, string(field))
+			field = field[:0]
+		default:
+			field = append(field, c)
+		}
+	}
+	return append(fields, string(field))
+}
+
+// Field returns the formatted field at the index, or an empty string if the key has fewer fields
+func (k CompositeKey) Field(i int) string {
+	fields := k.Fields()
+	if i < 0 || i >= len(fields) {
+		return ""
+	}
+	return fields[i]
+}
+
+// MarshalText encodes the key as its String() value, e.g. for the TextKeyCodec of the codec package
+func (k CompositeKey) MarshalText() ([]byte, error) {
+	return []byte(k.encoded), nil
+}
+
+// UnmarshalText decodes a key encoded by MarshalText
+func (k *CompositeKey) UnmarshalText(text []byte) error {
+	k.encoded = string(text)
+	return nil
+}
+
+// Keys wraps an array of keys and contains accessor methods. The Keys returned by NewKeys and NewKeysWith
+// are safe for concurrent use, e.g. when a strategies worker appends keys while a batch function started
+// in the background reads them.
 type Keys interface {
+	// Append appends the keys, skipping nil keys and duplicates of the keys which were already appended
 	Append(...Key)
 	Capacity() int
 	Length() int
@@ -32,77 +181,250 @@ type Keys interface {
 	Keys() []interface{}
 	StringKeys() []string
 	IsEmpty() bool
+	// ForEach calls the function with each key in the order they were appended until it returns false
+	ForEach(func(Key) bool)
+	// Filter returns new Keys containing the keys for which the function returns true
+	Filter(func(Key) bool) Keys
+	// Partition splits the keys into at most n Keys of near equal length, e.g. to spread a batch across n
+	// backends
+	Partition(n int) []Keys
+	// Chunk splits the keys into Keys of at most size keys, e.g. to respect the parameter limit of a query
+	Chunk(size int) []Keys
 }
 
+// KeyDedup determines which keys appended to Keys are duplicates
+type KeyDedup int
+
+// key deduplication modes
+const (
+	// DedupByKey skips keys which are equal (==) to an appended key (default). Pointer keys are only equal
+	// when they point to the same value.
+	DedupByKey KeyDedup = iota
+	// DedupByString skips keys with the same String() as an appended key
+	DedupByString
+	// DedupNone keeps duplicate keys
+	DedupNone
+)
+
+// KeysOption accepts the keys and sets an option on them
+type KeysOption func(*keys)
+
 type keys struct {
-	keys []Key
+	m     sync.RWMutex
+	keys  []Key
+	dedup KeyDedup
+	seen  map[interface{}]struct{}
 }
 
 // NewKeys returns a new instance of the Keys array with the provided capacity.
-func NewKeys(capacity int) Keys {
-	return &keys{
+func NewKeys(capacity int, opts ...KeysOption) Keys {
+	k := &keys{
 		keys: make([]Key, 0, capacity),
 	}
+	for _, apply := range opts {
+		apply(k)
+	}
+	return k
 }
 
 // NewKeysWith is a helper method for returning a new keys array which includes the
 // the provided keys
 func NewKeysWith(key ...Key) Keys {
-	return &keys{
-		keys: key,
+	k := NewKeys(len(key))
+	k.Append(key...)
+	return k
+}
+
+// WithKeysDedup sets how the keys detect duplicates. Defaults to DedupByKey.
+func WithKeysDedup(dedup KeyDedup) KeysOption {
+	return func(k *keys) {
+		k.dedup = dedup
 	}
 }
 
 // ================================== public methods ==================================
 
 func (k *keys) Append(keys ...Key) {
+	k.m.Lock()
+	defer k.m.Unlock()
+
 	for _, key := range keys {
 		if key != nil && key.Raw() != nil { // don't track nil keys
-			k.keys = append(k.keys, key)
+			k.append(key)
 		}
 	}
 }
 
 func (k *keys) Capacity() int {
+	k.m.RLock()
+	defer k.m.RUnlock()
+
 	return cap(k.keys)
 }
 
 func (k *keys) Length() int {
+	k.m.RLock()
+	defer k.m.RUnlock()
+
 	return len(k.keys)
 }
 
 func (k *keys) ClearAll() {
+	k.m.Lock()
+	defer k.m.Unlock()
+
 	k.keys = make([]Key, 0, len(k.keys))
+	k.seen = nil
 }
 
 func (k *keys) Keys() []interface{} {
-	result := make([]interface{}, 0, k.Length())
-	temp := make(map[Key]bool, k.Length())
+	k.m.RLock()
+	defer k.m.RUnlock()
 
+	result := make([]interface{}, 0, len(k.keys))
 	for _, val := range k.keys {
-		if _, ok := temp[val]; !ok {
-			temp[val] = true
-			result = append(result, val.Raw())
-		}
+		result = append(result, val.Raw())
 	}
-
 	return result
 }
 
 func (k *keys) StringKeys() []string {
-	result := make([]string, 0, k.Length())
-	temp := make(map[Key]bool, k.Length())
+	k.m.RLock()
+	defer k.m.RUnlock()
 
+	result := make([]string, 0, len(k.keys))
 	for _, val := range k.keys {
-		if _, ok := temp[val]; !ok {
-			temp[val] = true
-			result = append(result, val.String())
-		}
+		result = append(result, val.String())
 	}
-
 	return result
 }
 
 func (k *keys) IsEmpty() bool {
+	k.m.RLock()
+	defer k.m.RUnlock()
+
 	return len(k.keys) == 0
 }
+
+func (k *keys) ForEach(fn func(Key) bool) {
+	for _, key := range k.snapshot() {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (k *keys) Filter(fn func(Key) bool) Keys {
+	keyArr := k.snapshot()
+
+	result := k.empty(len(keyArr))
+	for _, key := range keyArr {
+		if fn(key) {
+			result.append(key)
+		}
+	}
+	return result
+}
+
+func (k *keys) Partition(n int) []Keys {
+	keyArr := k.snapshot()
+	if n <= 1 || len(keyArr) <= 1 {
+		return []Keys{k.with(keyArr)}
+	}
+	if n > len(keyArr) {
+		n = len(keyArr)
+	}
+
+	// the first len % n partitions hold one extra key
+	size, extra := len(keyArr)/n, len(keyArr)%n
+	partitions := make([]Keys, 0, n)
+	for start := 0; start < len(keyArr); {
+		end := start + size
+		if len(partitions) < extra {
+			end++
+		}
+		partitions = append(partitions, k.with(keyArr[start:end]))
+		start = end
+	}
+	return partitions
+}
+
+func (k *keys) Chunk(size int) []Keys {
+	keyArr := k.snapshot()
+	if size <= 0 || len(keyArr) <= size {
+		return []Keys{k.with(keyArr)}
+	}
+
+	chunks := make([]Keys, 0, (len(keyArr)+size-1)/size)
+	for start := 0; start < len(keyArr); start += size {
+		end := start + size
+		if end > len(keyArr) {
+			end = len(keyArr)
+		}
+		chunks = append(chunks, k.with(keyArr[start:end]))
+	}
+	return chunks
+}
+
+// ================================== private ==================================
+
+// snapshot returns a copy of the keys so they can be iterated without holding the lock
+func (k *keys) snapshot() []Key {
+	k.m.RLock()
+	defer k.m.RUnlock()
+
+	result := make([]Key, len(k.keys))
+	copy(result, k.keys)
+	return result
+}
+
+// empty returns new keys with the same deduplication mode
+func (k *keys) empty(capacity int) *keys {
+	return &keys{keys: make([]Key, 0, capacity), dedup: k.dedup}
+}
+
+// with returns new keys with the same deduplication mode containing the keys
+func (k *keys) with(keyArr []Key) *keys {
+	result := k.empty(len(keyArr))
+	for _, key := range keyArr {
+		result.append(key)
+	}
+	return result
+}
+
+// append appends the key unless it is a duplicate of an appended key. The caller must hold the lock.
+func (k *keys) append(key Key) {
+	if id, ok := k.identity(key); ok {
+		if _, dup := k.seen[id]; dup {
+			return
+		}
+		if k.seen == nil {
+			k.seen = make(map[interface{}]struct{}, cap(k.keys))
+		}
+		k.seen[id] = struct{}{}
+	}
+	k.keys = append(k.keys, key)
+}
+
+// keySlice returns the keys of Keys created by NewKeys or NewKeysWith. The second return value is false for
+// other Keys implementations.
+func keySlice(k Keys) ([]Key, bool) {
+	kArr, ok := k.(*keys)
+	if !ok {
+		return nil, false
+	}
+
+	return kArr.snapshot(), true
+}
+
+// identity returns the value identifying duplicates of the key. The second return value is false if
+// duplicates are kept.
+func (k *keys) identity(key Key) (interface{}, bool) {
+	switch k.dedup {
+	case DedupByString:
+		return key.String(), true
+	case DedupNone:
+		return nil, false
+	}
+	return key, true
+}
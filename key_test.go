@@ -0,0 +1,410 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompositeKey ensures the fields of a composite key are encoded in a stable string
+func TestCompositeKey(t *testing.T) {
+	// setup
+	key := dataloader.NewCompositeKey("acme", 42)
+
+	// invoke / assert
+	assert.Equal(t, "acme:42", key.String(), "Expected the fields joined by the separator")
+	assert.Equal(t, key, key.Raw(), "Expected the raw value to be the key")
+	assert.Equal(t, []string{"acme", "42"}, key.Fields(), "Expected the formatted fields")
+	assert.Equal(t, "42", key.Field(1), "Expected the field at the index")
+	assert.Equal(t, "", key.Field(2), "Expected an empty field past the last field")
+	assert.Equal(t, dataloader.NewCompositeKey("acme", 42), key, "Expected equal keys for equal fields")
+}
+
+// TestCompositeKeyEscaping ensures separators within a field don't collide with the keys of other fields
+func TestCompositeKeyEscaping(t *testing.T) {
+	// setup
+	a := dataloader.NewCompositeKey("a:b", "c")
+	b := dataloader.NewCompositeKey("a", "b:c")
+	c := dataloader.NewCompositeKey(`a\`, "b")
+
+	// invoke / assert
+	assert.NotEqual(t, a.String(), b.String(), "Expected distinct strings for distinct fields")
+	assert.Equal(t, []string{"a:b", "c"}, a.Fields(), "Expected the separator within the field")
+	assert.Equal(t, []string{"a", "b:c"}, b.Fields(), "Expected the separator within the field")
+	assert.Equal(t, []string{`a\`, "b"}, c.Fields(), "Expected the escape character within the field")
+}
+
+// TestCompositeKeyLoad ensures composite keys are deduplicated and resolved by the loader
+func TestCompositeKeyLoad(t *testing.T) {
+	// setup
+	var received []interface{}
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		received = keys.Keys()
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(dataloader.CompositeKey)
+			r.Set(key, dataloader.Result{Result: key.Field(0) + "/" + key.Field(1), Err: nil})
+		}
+		return &r
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy())
+
+	// invoke
+	r := loader.LoadMany(
+		context.Background(),
+		dataloader.NewCompositeKey("acme", 1),
+		dataloader.NewCompositeKey("acme", 1),
+		dataloader.NewCompositeKey("initech", 1),
+	)()
+
+	// assert
+	assert.Len(t, received, 2, "Expected the duplicate key to be removed")
+	v, ok := r.GetValue(dataloader.NewCompositeKey("initech", 1))
+	assert.True(t, ok, "Expected a result for the key")
+	assert.Equal(t, "initech/1", v.Result, "Expected the result of the key")
+}
+
+// TestCompositeKeyCodec ensures composite keys survive the text key codec
+func TestCompositeKeyCodec(t *testing.T) {
+	// setup
+	c := codec.TextKeyCodec(func() codec.TextUnmarshalerKey { return &dataloader.CompositeKey{} })
+	key := dataloader.NewCompositeKey("a:b", 2)
+
+	// invoke
+	encoded, err := c.EncodeKey(key)
+	decoded, decodeErr := c.DecodeKey(encoded)
+
+	// assert
+	assert.NoError(t, err, "Expected no error")
+	assert.NoError(t, decodeErr, "Expected no error")
+	assert.Equal(t, key.Fields(), decoded.(*dataloader.CompositeKey).Fields(), "Expected the decoded fields")
+}
+
+// TestIntKeys ensures the integer keys are encoded in base 10
+func TestIntKeys(t *testing.T) {
+	// invoke / assert
+	assert.Equal(t, "42", dataloader.IntKey(42).String(), "Expected the base 10 string")
+	assert.Equal(t, "-7", dataloader.IntKey(-7).String(), "Expected the base 10 string")
+	assert.Equal(t, dataloader.IntKey(42), dataloader.IntKey(42).Raw(), "Expected the raw value to be the key")
+	assert.Equal(t, "9223372036854775807", dataloader.Int64Key(1<<63-1).String(), "Expected the base 10 string")
+	assert.Equal(t, dataloader.Int64Key(3), dataloader.Int64Key(3).Raw(), "Expected the raw value to be the key")
+}
+
+// TestUUIDKey ensures UUID keys are encoded in the canonical form and parsed back
+func TestUUIDKey(t *testing.T) {
+	// setup
+	key := dataloader.UUIDKey{
+		0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00,
+	}
+
+	// invoke
+	parsed, err := dataloader.ParseUUIDKey("123E4567-e89b-12d3-a456-426614174000")
+
+	// assert
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", key.String(), "Expected the canonical form")
+	assert.Equal(t, key, key.Raw(), "Expected the raw value to be the key")
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, key, parsed, "Expected the parsed key")
+}
+
+// TestParseUUIDKeyInvalid ensures malformed UUIDs return an error
+func TestParseUUIDKeyInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"123e4567e89b12d3a456426614174000",
+		"123e4567-e89b-12d3-a456-42661417400",
+		"123e4567-e89b-12d3-a456-42661417400z",
+		"123e4567+e89b-12d3-a456-426614174000",
+	} {
+		// invoke
+		_, err := dataloader.ParseUUIDKey(s)
+
+		// assert
+		assert.Error(t, err, "Expected an error for %q", s)
+	}
+}
+
+type userQuery struct {
+	TenantID string
+	UserID   int
+	Active   *bool
+	Filter   interface{}
+}
+
+// TestStructKey ensures equal structs share the same identity and different structs don't
+func TestStructKey(t *testing.T) {
+	// setup
+	active, alsoActive, inactive := true, true, false
+	query := userQuery{TenantID: "acme", UserID: 1, Active: &active, Filter: "name"}
+
+	// invoke
+	key := dataloader.StructKey(query)
+
+	// assert
+	assert.Equal(t, query, key.Raw(), "Expected the raw value to be the struct")
+	assert.Contains(t, key.String(), "dataloader_test.userQuery#", "Expected the type in the identity")
+	assert.Equal(
+		t,
+		key.String(),
+		dataloader.StructKey(userQuery{TenantID: "acme", UserID: 1, Active: &alsoActive, Filter: "name"}).String(),
+		"Expected equal structs to share the identity",
+	)
+	for _, other := range []userQuery{
+		{TenantID: "acme", UserID: 2, Active: &active, Filter: "name"},
+		{TenantID: "acme", UserID: 1, Active: &inactive, Filter: "name"},
+		{TenantID: "acme", UserID: 1, Active: nil, Filter: "name"},
+		{TenantID: "acme", UserID: 1, Active: &active, Filter: 1},
+		{TenantID: "acm", UserID: 1, Active: &active, Filter: "ename"},
+	} {
+		assert.NotEqual(t, key.String(), dataloader.StructKey(other).String(), "Expected a distinct identity")
+	}
+}
+
+// TestStructKeyPanics ensures values which aren't comparable structs are rejected
+func TestStructKeyPanics(t *testing.T) {
+	// invoke / assert
+	assert.Panics(t, func() { dataloader.StructKey("acme") }, "Expected a panic for a non struct")
+	assert.Panics(t, func() { dataloader.StructKey(nil) }, "Expected a panic for nil")
+	assert.Panics(t, func() { dataloader.StructKey(struct{ IDs []int }{}) }, "Expected a panic for a slice field")
+}
+
+// TestStructKeyLoad ensures struct keys are deduplicated and resolved by the loader
+func TestStructKeyLoad(t *testing.T) {
+	// setup
+	var received []interface{}
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		received = keys.Keys()
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			q := k.(userQuery)
+			r.Set(dataloader.StructKey(q), dataloader.Result{Result: q.TenantID, Err: nil})
+		}
+		return &r
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy())
+
+	// invoke
+	r := loader.LoadMany(
+		context.Background(),
+		dataloader.StructKey(userQuery{TenantID: "acme", UserID: 1}),
+		dataloader.StructKey(userQuery{TenantID: "acme", UserID: 1}),
+		dataloader.StructKey(userQuery{TenantID: "initech", UserID: 1}),
+	)()
+
+	// assert
+	assert.Len(t, received, 2, "Expected the duplicate key to be removed")
+	v, ok := r.GetValue(dataloader.StructKey(userQuery{TenantID: "initech", UserID: 1}))
+	assert.True(t, ok, "Expected a result for the key")
+	assert.Equal(t, "initech", v.Result, "Expected the result of the key")
+}
+
+// TestKeysConcurrent ensures keys can be appended while they are read from other go routines
+func TestKeysConcurrent(t *testing.T) {
+	// setup
+	keys := dataloader.NewKeys(10)
+	var wg sync.WaitGroup
+
+	// invoke
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			keys.Append(dataloader.IntKey(i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			keys.Keys()
+			keys.StringKeys()
+			keys.Length()
+			keys.IsEmpty()
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 10, keys.Length(), "Expected each appended key")
+	assert.Len(t, keys.StringKeys(), 10, "Expected each appended key")
+
+	keys.ClearAll()
+	assert.True(t, keys.IsEmpty(), "Expected the keys to be cleared")
+}
+
+// ptrKey is a pointer key which is only equal to itself
+type ptrKey struct {
+	id string
+}
+
+func (k *ptrKey) String() string   { return k.id }
+func (k *ptrKey) Raw() interface{} { return k }
+
+// TestKeysAppendDuplicates ensures Append skips only the duplicates and keeps the remaining keys of the call
+func TestKeysAppendDuplicates(t *testing.T) {
+	// setup
+	keys := dataloader.NewKeys(5)
+
+	// invoke
+	keys.Append(dataloader.IntKey(1), dataloader.IntKey(2), dataloader.IntKey(1), nil, dataloader.IntKey(3))
+	keys.Append(dataloader.IntKey(3), dataloader.IntKey(4))
+
+	// assert
+	assert.Equal(t, 4, keys.Length(), "Expected the unique keys")
+	assert.Equal(t, []string{"1", "2", "3", "4"}, keys.StringKeys(), "Expected the keys in the order appended")
+}
+
+// TestKeysDedupByString ensures keys with the same string are duplicates when deduplicating by string
+func TestKeysDedupByString(t *testing.T) {
+	// setup
+	byKey := dataloader.NewKeys(2)
+	byString := dataloader.NewKeys(2, dataloader.WithKeysDedup(dataloader.DedupByString))
+
+	// invoke
+	byKey.Append(&ptrKey{id: "1"}, &ptrKey{id: "1"})
+	byString.Append(&ptrKey{id: "1"}, &ptrKey{id: "1"}, dataloader.StringKey("1"))
+
+	// assert
+	assert.Equal(t, 2, byKey.Length(), "Expected distinct pointers to be distinct keys")
+	assert.Equal(t, 1, byString.Length(), "Expected keys with the same string to be duplicates")
+}
+
+// TestKeysDedupNone ensures duplicates are kept when deduplication is disabled
+func TestKeysDedupNone(t *testing.T) {
+	// setup
+	keys := dataloader.NewKeys(3, dataloader.WithKeysDedup(dataloader.DedupNone))
+
+	// invoke
+	keys.Append(dataloader.IntKey(1), dataloader.IntKey(1), dataloader.IntKey(2))
+
+	// assert
+	assert.Equal(t, 3, keys.Length(), "Expected the duplicate to be kept")
+	assert.Equal(
+		t,
+		[]interface{}{dataloader.IntKey(1), dataloader.IntKey(1), dataloader.IntKey(2)},
+		keys.Keys(),
+		"Expected each appended key",
+	)
+}
+
+// TestKeysClearAllDedup ensures keys can be appended again after the keys are cleared
+func TestKeysClearAllDedup(t *testing.T) {
+	// setup
+	keys := dataloader.NewKeys(1)
+	keys.Append(dataloader.IntKey(1))
+
+	// invoke
+	keys.ClearAll()
+	keys.Append(dataloader.IntKey(1))
+
+	// assert
+	assert.Equal(t, 1, keys.Length(), "Expected the key to be appended after clearing")
+}
+
+// newIntKeys returns Keys with an IntKey for each of the ids
+func newIntKeys(ids ...int) dataloader.Keys {
+	keys := dataloader.NewKeys(len(ids))
+	for _, id := range ids {
+		keys.Append(dataloader.IntKey(id))
+	}
+	return keys
+}
+
+// TestKeysForEach ensures each key is visited in order until the function returns false
+func TestKeysForEach(t *testing.T) {
+	// setup
+	keys := newIntKeys(1, 2, 3, 4)
+	var all, stopped []string
+
+	// invoke
+	keys.ForEach(func(k dataloader.Key) bool {
+		all = append(all, k.String())
+		return true
+	})
+	keys.ForEach(func(k dataloader.Key) bool {
+		stopped = append(stopped, k.String())
+		return k.String() != "2"
+	})
+
+	// assert
+	assert.Equal(t, []string{"1", "2", "3", "4"}, all, "Expected each key in order")
+	assert.Equal(t, []string{"1", "2"}, stopped, "Expected the iteration to stop")
+}
+
+// TestKeysFilter ensures the keys matching the function are returned without changing the keys
+func TestKeysFilter(t *testing.T) {
+	// setup
+	keys := newIntKeys(1, 2, 3, 4)
+
+	// invoke
+	even := keys.Filter(func(k dataloader.Key) bool { return k.(dataloader.IntKey)%2 == 0 })
+	even.Append(dataloader.IntKey(2), dataloader.IntKey(6))
+
+	// assert
+	assert.Equal(t, []string{"2", "4", "6"}, even.StringKeys(), "Expected the matching keys without duplicates")
+	assert.Equal(t, 4, keys.Length(), "Expected the keys to be unchanged")
+}
+
+// TestKeysPartition ensures the keys are split into partitions of near equal length
+func TestKeysPartition(t *testing.T) {
+	for _, tc := range []struct {
+		n        int
+		expected [][]string
+	}{
+		{n: 2, expected: [][]string{{"1", "2", "3"}, {"4", "5"}}},
+		{n: 3, expected: [][]string{{"1", "2"}, {"3", "4"}, {"5"}}},
+		{n: 8, expected: [][]string{{"1"}, {"2"}, {"3"}, {"4"}, {"5"}}},
+		{n: 0, expected: [][]string{{"1", "2", "3", "4", "5"}}},
+	} {
+		// invoke
+		partitions := newIntKeys(1, 2, 3, 4, 5).Partition(tc.n)
+
+		// assert
+		actual := make([][]string, 0, len(partitions))
+		for _, p := range partitions {
+			actual = append(actual, p.StringKeys())
+		}
+		assert.Equal(t, tc.expected, actual, "Expected the partitions for n = %d", tc.n)
+	}
+}
+
+// TestKeysChunk ensures the keys are split into chunks of at most size keys
+func TestKeysChunk(t *testing.T) {
+	for _, tc := range []struct {
+		size     int
+		expected [][]string
+	}{
+		{size: 2, expected: [][]string{{"1", "2"}, {"3", "4"}, {"5"}}},
+		{size: 5, expected: [][]string{{"1", "2", "3", "4", "5"}}},
+		{size: 0, expected: [][]string{{"1", "2", "3", "4", "5"}}},
+	} {
+		// invoke
+		chunks := newIntKeys(1, 2, 3, 4, 5).Chunk(tc.size)
+
+		// assert
+		actual := make([][]string, 0, len(chunks))
+		for _, c := range chunks {
+			actual = append(actual, c.StringKeys())
+		}
+		assert.Equal(t, tc.expected, actual, "Expected the chunks for size %d", tc.size)
+	}
+}
+
+// TestKeysPartitionEmpty ensures empty keys are returned as a single empty partition and chunk
+func TestKeysPartitionEmpty(t *testing.T) {
+	// setup
+	keys := dataloader.NewKeys(0)
+
+	// invoke
+	partitions := keys.Partition(3)
+	chunks := keys.Chunk(3)
+
+	// assert
+	if assert.Len(t, partitions, 1, "Expected a single partition") {
+		assert.True(t, partitions[0].IsEmpty(), "Expected an empty partition")
+	}
+	if assert.Len(t, chunks, 1, "Expected a single chunk") {
+		assert.True(t, chunks[0].IsEmpty(), "Expected an empty chunk")
+	}
+}
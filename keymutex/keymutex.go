@@ -0,0 +1,86 @@
+/*
+Package keymutex contains a per-key mutex.
+
+A KeyMutex allows callers to lock a single key without blocking callers working
+against other keys. The ttl cache uses TryLock to allow at most one refresh of a
+stale key at a time. Applications can use it to perform read-modify-write
+operations against results returned by a dataloader without resorting to a
+global lock.
+*/
+package keymutex
+
+import "sync"
+
+// KeyMutex provides mutual exclusion for individual keys.
+type KeyMutex interface {
+	// Lock locks the provided key. If the key is already locked, the caller blocks
+	// until the key is available.
+	Lock(string)
+	// TryLock locks the provided key if it is available and reports whether it did. It doesn't block.
+	TryLock(string) bool
+	// Unlock unlocks the provided key. It is a run-time error if the key is not locked.
+	Unlock(string)
+}
+
+// New returns a new instance of a KeyMutex
+func New() KeyMutex {
+	return &keyMutex{
+		locks: make(map[string]*lock),
+	}
+}
+
+type keyMutex struct {
+	m     sync.Mutex
+	locks map[string]*lock
+}
+
+// lock tracks the number of callers holding or waiting on a key so that it can be
+// removed once no longer referenced.
+type lock struct {
+	sync.Mutex
+	refs int
+}
+
+func (k *keyMutex) Lock(key string) {
+	k.m.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &lock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.m.Unlock()
+
+	l.Lock()
+}
+
+func (k *keyMutex) TryLock(key string) bool {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	// a key is only tracked while a caller holds or waits on it
+	if _, ok := k.locks[key]; ok {
+		return false
+	}
+
+	l := &lock{refs: 1}
+	l.Lock()
+	k.locks[key] = l
+	return true
+}
+
+func (k *keyMutex) Unlock(key string) {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	l, ok := k.locks[key]
+	if !ok {
+		panic("keymutex: unlock of unlocked key " + key)
+	}
+
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+	l.Unlock()
+}
@@ -0,0 +1,80 @@
+package keymutex_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/keymutex"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLockSameKey ensures that callers locking the same key are serialized
+func TestLockSameKey(t *testing.T) {
+	// setup
+	km := keymutex.New()
+	count := 0
+	wg := sync.WaitGroup{}
+
+	// invoke
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			km.Lock("key")
+			defer km.Unlock("key")
+			count++
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 100, count, "Expected each caller to increment the count")
+}
+
+// TestLockDifferentKeys ensures that locking one key does not block callers locking another key
+func TestLockDifferentKeys(t *testing.T) {
+	// setup
+	km := keymutex.New()
+	km.Lock("key_1")
+	defer km.Unlock("key_1")
+
+	done := make(chan struct{})
+
+	// invoke
+	go func() {
+		km.Lock("key_2")
+		km.Unlock("key_2")
+		close(done)
+	}()
+
+	// assert
+	select {
+	case <-done:
+	case <-time.After(time.Millisecond * 500):
+		assert.Fail(t, "Expected lock on key_2 not to block")
+	}
+}
+
+// TestTryLock ensures TryLock only locks available keys
+func TestTryLock(t *testing.T) {
+	// setup
+	km := keymutex.New()
+
+	// invoke / assert
+	assert.True(t, km.TryLock("key_1"), "Expected the available key to be locked")
+	assert.False(t, km.TryLock("key_1"), "Expected the locked key not to be locked again")
+	assert.True(t, km.TryLock("key_2"), "Expected other keys to be available")
+
+	km.Unlock("key_1")
+	assert.True(t, km.TryLock("key_1"), "Expected the unlocked key to be locked")
+	km.Unlock("key_1")
+	km.Unlock("key_2")
+}
+
+// TestUnlockUnlockedKey ensures unlocking a key which isn't locked panics
+func TestUnlockUnlockedKey(t *testing.T) {
+	km := keymutex.New()
+
+	assert.Panics(t, func() { km.Unlock("key") }, "Expected unlocking an unlocked key to panic")
+}
@@ -0,0 +1,26 @@
+package dataloader
+
+import "context"
+
+// LoadMap loads the keys like LoadMany and blocks until they resolve. It returns the values of the resolved
+// keys mapped by the keys identifier (Key.String()), and a KeyErrors error containing the error for each
+// key which failed or has no result.
+func (d *dataloader) LoadMap(ctx context.Context, keyArr ...Key) (map[string]interface{}, error) {
+	r := d.LoadMany(ctx, keyArr...)()
+
+	errs := KeyErrors{}
+	values := make(map[string]interface{}, len(keyArr))
+	for _, key := range keyArr {
+		result, _ := r.GetValue(key) // missing keys resolve to a NotFoundError
+		if result.Err != nil {
+			errs[key.String()] = result.Err
+			continue
+		}
+		values[key.String()] = result.Result
+	}
+
+	if len(errs) > 0 {
+		return values, errs
+	}
+	return values, nil
+}
@@ -0,0 +1,55 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadMap ensures the values of the resolved keys are returned with the errors of the failed keys
+func TestLoadMap(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			switch key := k.(PrimaryKey); key {
+			case 1:
+				m.Set(key, dataloader.Result{Result: "one", Err: nil})
+			case 2:
+				m.Set(key, dataloader.Result{Result: nil, Err: expectedErr})
+			} // key 3 is missing
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy())
+
+	// invoke
+	values, err := loader.LoadMap(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))
+
+	// assert
+	assert.Equal(t, map[string]interface{}{"1": "one"}, values, "Expected the values of the resolved keys")
+	if assert.IsType(t, dataloader.KeyErrors{}, err, "Expected a KeyErrors error") {
+		errs := err.(dataloader.KeyErrors)
+		assert.Equal(t, 2, len(errs), "Expected an error for each failed key")
+		assert.Equal(t, expectedErr, errs["2"], "Expected the error of the failed key")
+		assert.True(t, dataloader.IsNotFound(errs["3"]), "Expected a not found error for the missing key")
+	}
+}
+
+// TestLoadMapNoErrors ensures a nil error is returned when every key resolved
+func TestLoadMapNoErrors(t *testing.T) {
+	// setup
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: "one", Err: nil})
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+
+	// invoke
+	values, err := loader.LoadMap(context.Background(), PrimaryKey(1))
+
+	// assert
+	assert.Nil(t, err, "Expected no error")
+	assert.Equal(t, map[string]interface{}{"1": "one"}, values, "Expected the value of the key")
+}
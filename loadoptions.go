@@ -0,0 +1,68 @@
+package dataloader
+
+import (
+	"context"
+	"time"
+)
+
+// LoadOptions overrides the loader and strategy configuration for a single call to Load
+type LoadOptions struct {
+	// Timeout is the maximum duration the key waits in the strategy before the batch function is called.
+	// Keys share a batch so the timeout can only bring the batch forward. Zero uses the strategies timeout.
+	Timeout time.Duration
+	// SkipCache calls the batch function for the key even if a primed or cached result exists. The result
+	// returned by the batch function replaces the cached result.
+	SkipCache bool
+	// Flush calls the batch function once the key is passed to the strategy if the strategy implements
+	// Flusher, instead of waiting for capacity or a timeout
+	Flush bool
+}
+
+// LoadOption accepts the load options and sets an option on them
+type LoadOption func(*LoadOptions)
+
+// WithLoadTimeout sets the maximum duration the key waits in the strategy before the batch function is
+// called. Strategies read the timeout from the context passed to the strategy (see LoadOptionsFromContext).
+func WithLoadTimeout(d time.Duration) LoadOption {
+	return func(o *LoadOptions) {
+		o.Timeout = d
+	}
+}
+
+// WithSkipCache bypasses primed and cached results for the key
+func WithSkipCache() LoadOption {
+	return func(o *LoadOptions) {
+		o.SkipCache = true
+	}
+}
+
+// WithFlush calls the batch function once the key is passed to the strategy
+func WithFlush() LoadOption {
+	return func(o *LoadOptions) {
+		o.Flush = true
+	}
+}
+
+type loadOptionsKey struct{}
+
+// LoadOptionsFromContext returns the options of the call to Load which the context was created for. The
+// context passed to the strategy carries the options if any were provided.
+func LoadOptionsFromContext(ctx context.Context) (LoadOptions, bool) {
+	o, ok := ctx.Value(loadOptionsKey{}).(LoadOptions)
+	return o, ok
+}
+
+// ============================================== private =============================================
+
+// loadOptions applies the options and returns a context carrying them for the strategy
+func loadOptions(ctx context.Context, opts []LoadOption) (context.Context, LoadOptions) {
+	var o LoadOptions
+	if len(opts) == 0 {
+		return ctx, o
+	}
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+	return context.WithValue(ctx, loadOptionsKey{}, o), o
+}
@@ -0,0 +1,89 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadSkipCache ensures a load skipping the cache calls the batch function and replaces the cached result
+func TestLoadSkipCache(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "fresh", Err: nil}
+	batch := getBatchFunction(func() { callCount += 1 }, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithCache(newMockCache(1)))
+	loader.Load(context.Background(), PrimaryKey(1))()
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1), dataloader.WithSkipCache())()
+	loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "fresh", r.Result, "Expected result from the batch function")
+	assert.Equal(t, 2, callCount, "Expected the batch function to be called once per load skipping the cache")
+}
+
+// TestLoadFlush ensures a flush-triggering load flushes the strategy
+func TestLoadFlush(t *testing.T) {
+	// setup
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: "flushed", Err: nil})
+	strategy := &mockFlushStrategy{}
+	loader := dataloader.NewDataLoader(1, batch, func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		strategy.batchFunc = batch
+		return strategy
+	})
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))
+	loader.Load(context.Background(), PrimaryKey(2), dataloader.WithFlush())
+
+	// assert
+	assert.Equal(t, 2, strategy.loads, "Expected both keys to be passed to the strategy")
+	assert.Equal(t, 1, strategy.flushes, "Expected only the flush-triggering load to flush")
+}
+
+// TestLoadOptionsFromContext ensures the strategy receives the load options through the context
+func TestLoadOptionsFromContext(t *testing.T) {
+	// setup
+	var withOptions, withoutOptions bool
+	var options dataloader.LoadOptions
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(1)
+		return &r
+	}
+	strategy := &ctxStrategy{mockStrategy{batchFunc: batch}, func(ctx context.Context) {
+		if o, ok := dataloader.LoadOptionsFromContext(ctx); ok {
+			withOptions, options = true, o
+		} else {
+			withoutOptions = true
+		}
+	}}
+	loader := dataloader.NewDataLoader(1, batch, func(int, dataloader.BatchFunction) dataloader.Strategy {
+		return strategy
+	})
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))
+	loader.Load(context.Background(), PrimaryKey(2), dataloader.WithLoadTimeout(time.Millisecond))
+
+	// assert
+	assert.True(t, withoutOptions, "Expected no options for loads without options")
+	assert.True(t, withOptions, "Expected options for loads with options")
+	assert.Equal(t, time.Millisecond, options.Timeout, "Expected the load timeout")
+}
+
+// ctxStrategy wraps the mock strategy and passes the context of each load to a callback
+type ctxStrategy struct {
+	mockStrategy
+	cb func(context.Context)
+}
+
+func (s *ctxStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	s.cb(ctx)
+	return s.mockStrategy.Load(ctx, key)
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+)
+
+// Entry is a log entry recorded by a CaptureLogger
+type Entry struct {
+	Level   Level
+	Msg     string
+	Keyvals []interface{}
+}
+
+// String formats the entry as "level=info msg key=value"
+func (e Entry) String() string {
+	return "level=" + e.Level.String() + " " + Format(e.Msg, e.Keyvals...)
+}
+
+// CaptureLogger records entries in memory so that tests can assert what was logged. It is safe for
+// concurrent use. Loggers returned by With record into the same entries.
+type CaptureLogger struct {
+	store   *captureStore
+	keyvals []interface{}
+}
+
+type captureStore struct {
+	m       sync.Mutex
+	entries []Entry
+}
+
+// Capture returns a logger which records every entry
+func Capture() *CaptureLogger {
+	return &CaptureLogger{store: &captureStore{}}
+}
+
+// Debug records the entry at the debug level
+func (c *CaptureLogger) Debug(msg string, keyvals ...interface{}) {
+	c.log(DebugLevel, msg, keyvals)
+}
+
+// Info records the entry at the info level
+func (c *CaptureLogger) Info(msg string, keyvals ...interface{}) {
+	c.log(InfoLevel, msg, keyvals)
+}
+
+// Error records the entry at the error level
+func (c *CaptureLogger) Error(msg string, keyvals ...interface{}) {
+	c.log(ErrorLevel, msg, keyvals)
+}
+
+// With returns a logger which adds the key-value pairs to every entry it records
+func (c *CaptureLogger) With(keyvals ...interface{}) Logger {
+	return &CaptureLogger{store: c.store, keyvals: append(c.fields(), keyvals...)}
+}
+
+// Entries returns a copy of the recorded entries in the order they were logged
+func (c *CaptureLogger) Entries() []Entry {
+	c.store.m.Lock()
+	defer c.store.m.Unlock()
+
+	return append([]Entry(nil), c.store.entries...)
+}
+
+// Messages returns the message of each recorded entry in the order they were logged
+func (c *CaptureLogger) Messages() []string {
+	entries := c.Entries()
+	msgs := make([]string, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.Msg
+	}
+	return msgs
+}
+
+// Level returns the recorded entries of the level
+func (c *CaptureLogger) Level(level Level) []Entry {
+	var result []Entry
+	for _, e := range c.Entries() {
+		if e.Level == level {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Contains returns true if a recorded entry, formatted as by Entry.String, contains the substring
+func (c *CaptureLogger) Contains(substr string) bool {
+	for _, e := range c.Entries() {
+		if strings.Contains(e.String(), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Last returns the most recently recorded entry and false if no entry was recorded
+func (c *CaptureLogger) Last() (Entry, bool) {
+	c.store.m.Lock()
+	defer c.store.m.Unlock()
+
+	if len(c.store.entries) == 0 {
+		return Entry{}, false
+	}
+	return c.store.entries[len(c.store.entries)-1], true
+}
+
+// Reset discards the recorded entries
+func (c *CaptureLogger) Reset() {
+	c.store.m.Lock()
+	defer c.store.m.Unlock()
+
+	c.store.entries = nil
+}
+
+func (c *CaptureLogger) log(level Level, msg string, keyvals []interface{}) {
+	c.store.m.Lock()
+	defer c.store.m.Unlock()
+
+	c.store.entries = append(c.store.entries, Entry{Level: level, Msg: msg, Keyvals: append(c.fields(), keyvals...)})
+}
+
+// fields returns a copy of the loggers key-value pairs which may be appended to
+func (c *CaptureLogger) fields() []interface{} {
+	return append(make([]interface{}, 0, len(c.keyvals)), c.keyvals...)
+}
+
+// ================================================ test logger ================================================
+
+// TB is the subset of testing.TB used by the test logger
+type TB interface {
+	Logf(format string, args ...interface{})
+}
+
+// Test returns a logger which writes every entry to the test log (t.Logf), so that entries are only shown
+// for failed or verbose tests
+func Test(t TB) Logger {
+	return &testLogger{t: t}
+}
+
+type testLogger struct {
+	t       TB
+	keyvals []interface{}
+}
+
+func (l *testLogger) Debug(msg string, keyvals ...interface{}) {
+	l.log(DebugLevel, msg, keyvals)
+}
+
+func (l *testLogger) Info(msg string, keyvals ...interface{}) {
+	l.log(InfoLevel, msg, keyvals)
+}
+
+func (l *testLogger) Error(msg string, keyvals ...interface{}) {
+	l.log(ErrorLevel, msg, keyvals)
+}
+
+func (l *testLogger) With(keyvals ...interface{}) Logger {
+	fields := append(make([]interface{}, 0, len(l.keyvals)), l.keyvals...)
+	return &testLogger{t: l.t, keyvals: append(fields, keyvals...)}
+}
+
+func (l *testLogger) log(level Level, msg string, keyvals []interface{}) {
+	fields := append(make([]interface{}, 0, len(l.keyvals)), l.keyvals...)
+	l.t.Logf("%s", Entry{Level: level, Msg: msg, Keyvals: append(fields, keyvals...)})
+}
@@ -0,0 +1,63 @@
+package logger_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCapture ensures entries are recorded with their level and fields and can be queried
+func TestCapture(t *testing.T) {
+	// setup
+	log := logger.Capture()
+	named := log.With("loader", "users")
+
+	// invoke
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			named.Debug("worker starting")
+		}()
+	}
+	wg.Wait()
+	log.Error("unable to get result", "key", 1)
+
+	// assert
+	assert.Equal(t, 11, len(log.Entries()), "Expected every entry to be recorded")
+	assert.Equal(t, 10, len(log.Level(logger.DebugLevel)), "Expected the debug entries")
+	assert.True(t, log.Contains("loader=users"), "Expected the fields of the named logger")
+	assert.True(t, log.Contains("level=error unable to get result key=1"), "Expected the formatted entry")
+	assert.False(t, log.Contains("worker exiting"), "Expected no match")
+
+	last, ok := log.Last()
+	assert.True(t, ok, "Expected an entry")
+	assert.Equal(t, "unable to get result", last.Msg, "Expected the last entry")
+
+	log.Reset()
+	assert.Equal(t, 0, len(log.Messages()), "Expected the entries to be discarded")
+}
+
+type mockTB struct {
+	lines []string
+}
+
+func (tb *mockTB) Logf(format string, args ...interface{}) {
+	tb.lines = append(tb.lines, fmt.Sprintf(format, args...))
+}
+
+// TestTestLogger ensures entries are written to the test log
+func TestTestLogger(t *testing.T) {
+	// setup
+	tb := &mockTB{}
+
+	// invoke
+	logger.Test(tb).With("loader", "users").Info("batch retried", "attempt", 1)
+
+	// assert
+	assert.Equal(t, []string{"level=info batch retried loader=users attempt=1"}, tb.lines, "Expected the entry")
+}
@@ -0,0 +1,118 @@
+/*
+Package logger contains the leveled, structured logging interface used by the dataloader, its strategies
+and caches, together with adapters for the standard library loggers.
+
+Entries consist of a message and alternating key-value pairs:
+
+	l.Info("worker timing out", "keys", 12, "timeout", 16*time.Millisecond)
+
+Use Std to log via a *log.Logger or Slog (Go 1.21+) to log via a *slog.Logger. Tests can record entries
+with Capture or write them to the test log with Test.
+*/
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Logger is a leveled logger which accepts a message and alternating key-value pairs
+type Logger interface {
+	// Debug logs detailed information, such as the progress of a strategies worker
+	Debug(msg string, keyvals ...interface{})
+	// Info logs notable events, such as a circuit breaker opening
+	Info(msg string, keyvals ...interface{})
+	// Error logs failures, such as a cache which is unavailable
+	Error(msg string, keyvals ...interface{})
+	// With returns a logger which adds the key-value pairs to every entry, e.g. to name the logger of
+	// a loader
+	With(keyvals ...interface{}) Logger
+}
+
+// Level is the severity of a log entry
+type Level int
+
+// Log levels in increasing order of severity
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	ErrorLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case ErrorLevel:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// Namer is implemented by loggers which support named sub-loggers natively (e.g. zap)
+type Namer interface {
+	// Named returns a sub-logger with the name
+	Named(name string) Logger
+}
+
+// Named returns a sub-logger for the named loader or component, e.g. Named(l, "users"). Loggers which
+// implement Namer are named natively, other loggers add the name as the "logger" field.
+func Named(l Logger, name string) Logger {
+	if n, ok := l.(Namer); ok {
+		return n.Named(name)
+	}
+	return l.With("logger", name)
+}
+
+// ========================================= no-op logger implementation =========================================
+
+// NoopLogger is a logger which discards all entries. It is the default logger of the loader, its strategies
+// and caches.
+type NoopLogger struct{}
+
+// Noop returns a logger which discards all entries
+func Noop() Logger {
+	return NoopLogger{}
+}
+
+// Debug discards the entry
+func (NoopLogger) Debug(string, ...interface{}) {}
+
+// Info discards the entry
+func (NoopLogger) Info(string, ...interface{}) {}
+
+// Error discards the entry
+func (NoopLogger) Error(string, ...interface{}) {}
+
+// With returns the no-op logger
+func (n NoopLogger) With(...interface{}) Logger { return n }
+
+// ================================================= helpers =================================================
+
+// Format returns the message followed by the key-value pairs formatted as key=value. A key without a
+// value is paired with "(MISSING)".
+func Format(msg string, keyvals ...interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%s", keyvals[i], formatValue(value))
+	}
+	return b.String()
+}
+
+// formatValue quotes values which contain spaces so entries remain parseable
+func formatValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/andy9775/dataloader/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStdLevels ensures entries below the minimum level are discarded and fields are formatted
+func TestStdLevels(t *testing.T) {
+	// setup
+	var buf bytes.Buffer
+	l := logger.Std(log.New(&buf, "", 0), logger.InfoLevel).With("loader", "users")
+
+	// invoke
+	l.Debug("starting new worker", "capacity", 10)
+	l.Error("unable to get result", "key", "1", "error", errors.New("connection refused"))
+
+	// assert
+	assert.Equal(
+		t,
+		"level=error unable to get result loader=users key=1 error=\"connection refused\"\n",
+		buf.String(),
+		"Expected only the error entry with its fields",
+	)
+}
+
+// TestFormatMissingValue ensures a key without a value is formatted
+func TestFormatMissingValue(t *testing.T) {
+	assert.Equal(t, "msg a=1 b=(MISSING)", logger.Format("msg", "a", 1, "b"), "Expected missing value marker")
+}
+
+// TestNamed ensures loggers without native names add the name as a field
+func TestNamed(t *testing.T) {
+	// setup
+	var buf bytes.Buffer
+	l := logger.Named(logger.Std(log.New(&buf, "", 0), logger.DebugLevel), "users")
+
+	// invoke
+	l.Info("worker cancelled")
+
+	// assert
+	assert.Equal(t, "level=info worker cancelled logger=users\n", buf.String(), "Expected the name as a field")
+}
@@ -0,0 +1,37 @@
+//go:build go1.21
+// +build go1.21
+
+package logger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/andy9775/dataloader/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSlog ensures entries are written to the slog logger at the matching level
+func TestSlog(t *testing.T) {
+	// setup
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	l := logger.Slog(slog.New(handler)).With("loader", "users")
+
+	// invoke
+	l.Debug("starting new worker", "capacity", 10)
+	l.Info("worker cancelled", "keys", 2)
+
+	// assert
+	assert.Equal(t, "level=INFO msg=\"worker cancelled\" loader=users keys=2\n", buf.String(),
+		"Expected only the info entry with its fields")
+}
@@ -0,0 +1,49 @@
+package logger
+
+import "log"
+
+// Std returns a logger which writes entries of at least the minimum level to the standard library logger
+// formatted as "level=info msg key=value". A nil logger writes to the standard logger (log.Printf).
+func Std(l *log.Logger, min Level) Logger {
+	return &stdLogger{logger: l, min: min}
+}
+
+type stdLogger struct {
+	logger  *log.Logger
+	min     Level
+	keyvals []interface{}
+}
+
+func (s *stdLogger) Debug(msg string, keyvals ...interface{}) {
+	s.log(DebugLevel, msg, keyvals)
+}
+
+func (s *stdLogger) Info(msg string, keyvals ...interface{}) {
+	s.log(InfoLevel, msg, keyvals)
+}
+
+func (s *stdLogger) Error(msg string, keyvals ...interface{}) {
+	s.log(ErrorLevel, msg, keyvals)
+}
+
+func (s *stdLogger) With(keyvals ...interface{}) Logger {
+	return &stdLogger{logger: s.logger, min: s.min, keyvals: append(s.fields(), keyvals...)}
+}
+
+func (s *stdLogger) log(level Level, msg string, keyvals []interface{}) {
+	if level < s.min {
+		return
+	}
+
+	entry := "level=" + level.String() + " " + Format(msg, append(s.fields(), keyvals...)...)
+	if s.logger == nil {
+		log.Print(entry)
+		return
+	}
+	s.logger.Print(entry)
+}
+
+// fields returns a copy of the loggers key-value pairs which may be appended to
+func (s *stdLogger) fields() []interface{} {
+	return append(make([]interface{}, 0, len(s.keyvals)), s.keyvals...)
+}
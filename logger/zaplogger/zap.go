@@ -0,0 +1,49 @@
+/*
+Package zaplogger adapts a zap logger to the dataloader Logger interface.
+
+	l := zaplogger.New(zapLogger)
+	loader := dataloader.NewDataLoader(
+		100,
+		batch,
+		standard.NewStandardStrategy(standard.WithLogger(logger.Named(l, "users"))),
+		dataloader.WithLogger(logger.Named(l, "users")),
+	)
+*/
+package zaplogger
+
+import (
+	"github.com/andy9775/dataloader/logger"
+
+	"go.uber.org/zap"
+)
+
+// New returns a logger which writes entries to the zap logger. Key-value pairs are logged as zap fields
+// and Named creates a named zap sub-logger (see logger.Named).
+func New(l *zap.Logger) logger.Logger {
+	return &zapLogger{logger: l.Sugar()}
+}
+
+type zapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+func (z *zapLogger) Debug(msg string, keyvals ...interface{}) {
+	z.logger.Debugw(msg, keyvals...)
+}
+
+func (z *zapLogger) Info(msg string, keyvals ...interface{}) {
+	z.logger.Infow(msg, keyvals...)
+}
+
+func (z *zapLogger) Error(msg string, keyvals ...interface{}) {
+	z.logger.Errorw(msg, keyvals...)
+}
+
+func (z *zapLogger) With(keyvals ...interface{}) logger.Logger {
+	return &zapLogger{logger: z.logger.With(keyvals...)}
+}
+
+// Named returns a named zap sub-logger
+func (z *zapLogger) Named(name string) logger.Logger {
+	return &zapLogger{logger: z.logger.Named(name)}
+}
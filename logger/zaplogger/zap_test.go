@@ -0,0 +1,35 @@
+package zaplogger_test
+
+import (
+	"testing"
+
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/logger/zaplogger"
+	"github.com/stretchr/testify/assert"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestZapLogger ensures entries are written at the matching level with their fields and name
+func TestZapLogger(t *testing.T) {
+	// setup
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := logger.Named(zaplogger.New(zap.New(core)), "users")
+
+	// invoke
+	l.Debug("starting new worker", "capacity", 10)
+	l.With("batch_id", 1).Error("batch failed validation", "keys", 2)
+
+	// assert
+	entries := logs.All()
+	if !assert.Len(t, entries, 1, "Expected only the error entry") {
+		return
+	}
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level, "Expected the error level")
+	assert.Equal(t, "users", entries[0].LoggerName, "Expected the named sub-logger")
+	assert.Equal(t, "batch failed validation", entries[0].Message, "Expected the message")
+	assert.Equal(t, map[string]interface{}{"batch_id": int64(1), "keys": int64(2)}, entries[0].ContextMap(),
+		"Expected the fields")
+}
@@ -0,0 +1,44 @@
+/*
+Package zerologger adapts a zerolog logger to the dataloader Logger interface.
+
+	l := zerologger.New(zerolog.New(os.Stderr))
+	loader := dataloader.NewDataLoader(
+		100,
+		batch,
+		standard.NewStandardStrategy(),
+		dataloader.WithLogger(logger.Named(l, "users")),
+	)
+*/
+package zerologger
+
+import (
+	"github.com/andy9775/dataloader/logger"
+
+	"github.com/rs/zerolog"
+)
+
+// New returns a logger which writes entries to the zerolog logger. Key-value pairs are logged as fields.
+// Named sub-loggers (see logger.Named) add the name as the "logger" field.
+func New(l zerolog.Logger) logger.Logger {
+	return &zeroLogger{logger: l}
+}
+
+type zeroLogger struct {
+	logger zerolog.Logger
+}
+
+func (z *zeroLogger) Debug(msg string, keyvals ...interface{}) {
+	z.logger.Debug().Fields(keyvals).Msg(msg)
+}
+
+func (z *zeroLogger) Info(msg string, keyvals ...interface{}) {
+	z.logger.Info().Fields(keyvals).Msg(msg)
+}
+
+func (z *zeroLogger) Error(msg string, keyvals ...interface{}) {
+	z.logger.Error().Fields(keyvals).Msg(msg)
+}
+
+func (z *zeroLogger) With(keyvals ...interface{}) logger.Logger {
+	return &zeroLogger{logger: z.logger.With().Fields(keyvals).Logger()}
+}
@@ -0,0 +1,34 @@
+package zerologger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/logger/zerologger"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rs/zerolog"
+)
+
+// TestZeroLogger ensures entries are written at the matching level with their fields and name
+func TestZeroLogger(t *testing.T) {
+	// setup
+	var buf bytes.Buffer
+	l := logger.Named(zerologger.New(zerolog.New(&buf).Level(zerolog.InfoLevel)), "users")
+
+	// invoke
+	l.Debug("starting new worker", "capacity", 10)
+	l.Info("worker cancelled", "keys", 2)
+
+	// assert
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry), "Expected a single JSON entry")
+	assert.Equal(t, map[string]interface{}{
+		"level":   "info",
+		"logger":  "users",
+		"keys":    float64(2),
+		"message": "worker cancelled",
+	}, entry, "Expected the info entry with its fields")
+}
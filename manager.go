@@ -0,0 +1,184 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// drainInterval is how often Drain checks whether the loader is idle
+const drainInterval = 5 * time.Millisecond
+
+// DrainError is returned by Manager.Drain with the names of the loaders which didn't drain before the deadline
+type DrainError struct {
+	Loaders []string
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("dataloader: loaders failed to drain: %s", strings.Join(e.Loaders, ", "))
+}
+
+// Manager tracks long lived loaders, e.g. loaders scoped to a daemon or a subscription rather than a request,
+// and drains them when the process shuts down so that pending loads are resolved before it exits.
+type Manager struct {
+	logger logger.Logger
+
+	m       sync.Mutex
+	loaders map[string]DataLoader
+}
+
+// ManagerOption accepts the manager and sets an option on it
+type ManagerOption func(*Manager)
+
+// NewManager returns a manager without registered loaders
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		logger:  logger.Noop(),
+		loaders: make(map[string]DataLoader),
+	}
+	for _, apply := range opts {
+		apply(m)
+	}
+	return m
+}
+
+// WithManagerLogger sets the logger used to report loaders which failed to drain. The default is a no op
+// logger
+func WithManagerLogger(l logger.Logger) ManagerOption {
+	return func(m *Manager) {
+		m.logger = l
+	}
+}
+
+// Register tracks the loader under the name, replacing any loader registered with the same name
+func (m *Manager) Register(name string, loader DataLoader) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.loaders[name] = loader
+}
+
+// Unregister stops tracking the loader registered under the name
+func (m *Manager) Unregister(name string) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	delete(m.loaders, name)
+}
+
+// Drain drains every registered loader in parallel. If any loader hasn't drained when the context is done,
+// Drain returns a *DrainError with the names of those loaders.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.m.Lock()
+	loaders := make(map[string]DataLoader, len(m.loaders))
+	for name, l := range m.loaders {
+		loaders[name] = l
+	}
+	m.m.Unlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed []string
+	for name, l := range loaders {
+		wg.Add(1)
+		go func(name string, l DataLoader) {
+			defer wg.Done()
+
+			if err := l.Drain(ctx); err != nil {
+				m.logger.Error("loader failed to drain", "loader", name, "error", err)
+				mu.Lock()
+				failed = append(failed, name)
+				mu.Unlock()
+			}
+		}(name, l)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return &DrainError{Loaders: failed}
+	}
+	return nil
+}
+
+// Close drains the registered loaders like Drain and then closes the loaders which implement Closer, e.g.
+// to unsubscribe idle strategies from a shared Tracker. The loaders are closed even if they failed to drain.
+func (m *Manager) Close(ctx context.Context) error {
+	err := m.Drain(ctx)
+
+	m.m.Lock()
+	loaders := make([]DataLoader, 0, len(m.loaders))
+	for _, l := range m.loaders {
+		loaders = append(loaders, l)
+	}
+	m.m.Unlock()
+
+	for _, l := range loaders {
+		if c, ok := l.(Closer); ok {
+			c.Close()
+		}
+	}
+	return err
+}
+
+// DrainOnSignal blocks until the process receives one of the signals (SIGTERM and os.Interrupt if none are
+// provided) or the context is done, then drains and closes the registered loaders, allowing them up to the
+// timeout. It returns the result of Close, e.g. from a go routine started alongside the server:
+//
+//	go func() { errs <- manager.DrainOnSignal(ctx, 10*time.Second) }()
+func (m *Manager) DrainOnSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	select {
+	case sig := <-ch:
+		m.logger.Info("draining loaders", "signal", sig.String())
+	case <-ctx.Done():
+		m.logger.Info("draining loaders", "error", ctx.Err())
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.Close(drainCtx)
+}
+
+// Drain flushes the pending keys and waits for the strategy and the batch function to become idle
+func (d *dataloader) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		d.Flush(ctx)
+		if d.idle() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// idle returns true if the strategy has no pending keys and no call to the batch function is in flight
+func (d *dataloader) idle() bool {
+	if state, ok := d.StrategyState(); ok && state.PendingKeys > 0 {
+		return false
+	}
+	return atomic.LoadInt64(&d.inflight) == 0
+}
@@ -0,0 +1,68 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/dataloadertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestManagerDrain ensures loaders with an in flight batch are reported until the batch returns
+func TestManagerDrain(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	result := dataloader.Result{Result: "drain", Err: nil}
+	slow := dataloader.NewDataLoader(1, getBatchFunction(func() { <-release }, result), newMockStrategy())
+	idle := dataloader.NewDataLoader(1, getBatchFunction(func() {}, result), newMockStrategy())
+
+	manager := dataloader.NewManager()
+	manager.Register("slow", slow)
+	manager.Register("idle", idle)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		slow.Load(context.Background(), PrimaryKey(1))()
+	}()
+	for slow.LastBatchID() == 0 { // wait for the batch function to be called
+		time.Sleep(time.Millisecond)
+	}
+
+	// invoke / assert
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := manager.Drain(ctx)
+	if assert.IsType(t, &dataloader.DrainError{}, err, "Expected a drain error") {
+		assert.Equal(t, []string{"slow"}, err.(*dataloader.DrainError).Loaders, "Expected the busy loader")
+	}
+
+	close(release)
+	<-done
+	assert.Nil(t, manager.Drain(context.Background()), "Expected every loader to drain")
+}
+
+// TestManagerClose ensures Close drains the registered loaders before closing their strategies
+func TestManagerClose(t *testing.T) {
+	// setup
+	strategy := dataloadertest.NewStrategy()
+	batch := dataloadertest.NewBatchFunction(nil)
+	loader := dataloader.NewDataLoader(10, batch.Batch, strategy.StrategyFunction())
+
+	manager := dataloader.NewManager()
+	manager.Register("users", loader)
+	thunk := loader.Load(context.Background(), dataloader.StringKey("1"))
+
+	// invoke
+	err := manager.Close(context.Background())
+
+	// assert
+	assert.Nil(t, err, "Expected the loader to drain")
+	assert.Equal(t, 0, strategy.PendingKeys(), "Expected the pending key to be flushed")
+	assert.Equal(t, 1, strategy.CloseCount(), "Expected the loader to be closed")
+	r, ok := thunk()
+	assert.True(t, ok, "Expected the flushed key to be resolved")
+	assert.Equal(t, dataloader.StringKey("1"), r.Result, "Expected the result of the key")
+}
@@ -0,0 +1,214 @@
+package dataloader
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics is an interface that may be used to record metrics about the loader.
+type Metrics interface {
+	// LoadLatency records the latency for a key resolved by the batch function. The latency is split
+	// into the time spent waiting for the batch function to be called after the call to Load or LoadMany
+	// (queueWait) and the time the batch function took to execute (execution).
+	LoadLatency(key Key, queueWait, execution time.Duration)
+
+	// BatchExecuted records the duration of a call to the batch function and the number of keys which
+	// resolved to an error. canary is true if the batch was routed to the canary batch function (see
+	// WithCanary).
+	BatchExecuted(canary bool, duration time.Duration, errors int)
+
+	// ClassifiedErrors records the number of keys in a batch which resolved to an error of the class
+	// returned by the configured ErrorClassifier (see WithErrorClassifier). It is called once per class
+	// after BatchExecuted.
+	ClassifiedErrors(canary bool, class string, count int)
+}
+
+// ======================================= no-op metrics implementation ======================================
+
+// NewNoOpMetrics returns an instance of a metrics recorder which discards all metrics
+func NewNoOpMetrics() Metrics {
+	return &noOpMetrics{}
+}
+
+type noOpMetrics struct{}
+
+func (*noOpMetrics) LoadLatency(Key, time.Duration, time.Duration) {}
+
+func (*noOpMetrics) BatchExecuted(bool, time.Duration, int) {}
+
+func (*noOpMetrics) ClassifiedErrors(bool, string, int) {}
+
+// ===================================== histogram metrics implementation ====================================
+
+// DefaultLatencyBuckets are the histogram bucket upper bounds used when none are provided
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// HistogramMetrics records load latencies in in-memory histograms which may be exported by the application,
+// for example via expvar.
+type HistogramMetrics interface {
+	Metrics
+	// QueueWait returns a snapshot of the histogram of time keys spent waiting for the batch function
+	QueueWait() Histogram
+	// Execution returns a snapshot of the histogram of time keys spent in the batch function
+	Execution() Histogram
+	// BatchDuration returns a snapshot of the histogram of batch function durations for either the
+	// canary or the primary batch function
+	BatchDuration(canary bool) Histogram
+	// BatchErrors returns the number of keys which resolved to an error for either the canary or the
+	// primary batch function
+	BatchErrors(canary bool) uint64
+	// ErrorClasses returns the number of keys which resolved to an error by error class for either the
+	// canary or the primary batch function
+	ErrorClasses(canary bool) map[string]uint64
+}
+
+// Histogram is a snapshot of a latency histogram. Counts[i] contains the number of observations which
+// are less than or equal to Buckets[i]. The final element in Counts contains the number of observations
+// greater than the largest bucket.
+type Histogram struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// NewHistogramMetrics returns a metrics recorder which tracks load latencies in histograms with the
+// provided bucket upper bounds (sorted ascending). DefaultLatencyBuckets are used if no buckets are
+// provided.
+func NewHistogramMetrics(buckets ...time.Duration) HistogramMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	return &histogramMetrics{
+		queueWait: newHistogram(buckets),
+		execution: newHistogram(buckets),
+		batches:   [2]Histogram{newHistogram(buckets), newHistogram(buckets)},
+		errorClasses: [2]map[string]uint64{
+			make(map[string]uint64),
+			make(map[string]uint64),
+		},
+	}
+}
+
+type histogramMetrics struct {
+	m         sync.Mutex
+	queueWait Histogram
+	execution Histogram
+
+	// batches and batchErrors are indexed by variant (0 - primary, 1 - canary)
+	batches     [2]Histogram
+	batchErrors [2]uint64
+
+	// errorClasses is indexed by variant (0 - primary, 1 - canary)
+	errorClasses [2]map[string]uint64
+}
+
+func (h *histogramMetrics) LoadLatency(_ Key, queueWait, execution time.Duration) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.queueWait.observe(queueWait)
+	h.execution.observe(execution)
+}
+
+func (h *histogramMetrics) BatchExecuted(canary bool, duration time.Duration, errors int) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.batches[variant(canary)].observe(duration)
+	h.batchErrors[variant(canary)] += uint64(errors)
+}
+
+func (h *histogramMetrics) ClassifiedErrors(canary bool, class string, count int) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.errorClasses[variant(canary)][class] += uint64(count)
+}
+
+func (h *histogramMetrics) QueueWait() Histogram {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.queueWait.snapshot()
+}
+
+func (h *histogramMetrics) Execution() Histogram {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.execution.snapshot()
+}
+
+func (h *histogramMetrics) BatchDuration(canary bool) Histogram {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.batches[variant(canary)].snapshot()
+}
+
+func (h *histogramMetrics) BatchErrors(canary bool) uint64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.batchErrors[variant(canary)]
+}
+
+func (h *histogramMetrics) ErrorClasses(canary bool) map[string]uint64 {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	classes := make(map[string]uint64, len(h.errorClasses[variant(canary)]))
+	for class, count := range h.errorClasses[variant(canary)] {
+		classes[class] = count
+	}
+	return classes
+}
+
+// ================================================= helpers =================================================
+
+func variant(canary bool) int {
+	if canary {
+		return 1
+	}
+	return 0
+}
+
+func newHistogram(buckets []time.Duration) Histogram {
+	return Histogram{
+		Buckets: buckets,
+		Counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i := 0
+	for ; i < len(h.Buckets); i++ {
+		if d <= h.Buckets[i] {
+			break
+		}
+	}
+
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h Histogram) snapshot() Histogram {
+	counts := make([]uint64, len(h.Counts))
+	copy(counts, h.Counts)
+	h.Counts = counts
+	return h
+}
@@ -0,0 +1,176 @@
+/*
+Package prometheus contains a metrics recorder which exports loader metrics to prometheus.
+
+The Collector implements the dataloader.Metrics interface for batch and load latencies and
+provides Hooks for the batch size distribution and the cache hit ratio. The number of keys
+pending in a strategy may be exported by watching a loader (see WatchPendingKeys).
+
+	c := prometheus.New("app", prom.DefaultRegisterer)
+	loader := dataloader.NewDataLoader(
+		100,
+		batch,
+		standard.NewStandardStrategy(),
+		dataloader.WithMetrics(c),
+		dataloader.WithHooks(c.Hooks()),
+	)
+	c.WatchPendingKeys("users", loader)
+*/
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/andy9775/dataloader"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const subsystem = "dataloader"
+
+// DefaultSizeBuckets are the histogram bucket upper bounds used for the batch size distribution
+var DefaultSizeBuckets = prom.ExponentialBuckets(1, 2, 11) // 1 - 1024 keys
+
+// Collector records loader metrics in prometheus counters and histograms
+type Collector struct {
+	namespace  string
+	registerer prom.Registerer
+
+	batches      *prom.CounterVec
+	batchErrors  *prom.CounterVec
+	batchLatency *prom.HistogramVec
+	batchSize    prom.Histogram
+	loadLatency  *prom.HistogramVec
+	cacheHits    prom.Counter
+	cacheMisses  prom.Counter
+}
+
+// New returns a Collector which registers its metrics with the provided registerer under the
+// namespace. Registration panics if the metrics are already registered (see prometheus.MustRegister).
+func New(namespace string, registerer prom.Registerer) *Collector {
+	c := &Collector{
+		namespace:  namespace,
+		registerer: registerer,
+
+		batches: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batches_total",
+			Help:      "Number of calls to the batch function.",
+		}, []string{"variant"}),
+		batchErrors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batch_errors_total",
+			Help:      "Number of keys which resolved to an error by error class.",
+		}, []string{"variant", "class"}),
+		batchLatency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batch_duration_seconds",
+			Help:      "Time taken by the batch function to return.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"variant"}),
+		batchSize: prom.NewHistogram(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batch_size",
+			Help:      "Number of keys passed to the batch function.",
+			Buckets:   DefaultSizeBuckets,
+		}),
+		loadLatency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "load_duration_seconds",
+			Help:      "Time a key spent waiting for (queue_wait) and in (execution) the batch function.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"phase"}),
+		cacheHits: prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "cache_hits_total",
+			Help:      "Number of keys resolved from the primed results or the cache.",
+		}),
+		cacheMisses: prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "cache_misses_total",
+			Help:      "Number of keys passed to the strategy to be resolved by the batch function.",
+		}),
+	}
+
+	registerer.MustRegister(
+		c.batches,
+		c.batchErrors,
+		c.batchLatency,
+		c.batchSize,
+		c.loadLatency,
+		c.cacheHits,
+		c.cacheMisses,
+	)
+
+	return c
+}
+
+// ========================================= dataloader.Metrics =========================================
+
+// LoadLatency records the queue wait and execution time for a key
+func (c *Collector) LoadLatency(_ dataloader.Key, queueWait, execution time.Duration) {
+	c.loadLatency.WithLabelValues("queue_wait").Observe(queueWait.Seconds())
+	c.loadLatency.WithLabelValues("execution").Observe(execution.Seconds())
+}
+
+// BatchExecuted records a call to the batch function and its duration
+func (c *Collector) BatchExecuted(canary bool, duration time.Duration, _ int) {
+	c.batches.WithLabelValues(variant(canary)).Inc()
+	c.batchLatency.WithLabelValues(variant(canary)).Observe(duration.Seconds())
+}
+
+// ClassifiedErrors records the number of keys which resolved to an error of the class
+func (c *Collector) ClassifiedErrors(canary bool, class string, count int) {
+	c.batchErrors.WithLabelValues(variant(canary), class).Add(float64(count))
+}
+
+// ============================================== hooks ==============================================
+
+// Hooks returns callbacks which record the batch size distribution and cache hits and misses. The
+// hooks should be passed to the loader via dataloader.WithHooks.
+func (c *Collector) Hooks() dataloader.Hooks {
+	return dataloader.Hooks{
+		OnBatchStart: func(_ context.Context, info dataloader.BatchInfo) {
+			c.batchSize.Observe(float64(info.Size))
+		},
+		OnCacheHit: func(context.Context, dataloader.Key) {
+			c.cacheHits.Inc()
+		},
+		OnCacheMiss: func(context.Context, dataloader.Key) {
+			c.cacheMisses.Inc()
+		},
+	}
+}
+
+// WatchPendingKeys registers a gauge which reports the number of keys pending in the loaders strategy
+// each time metrics are collected. The loader label distinguishes multiple loaders. An error is
+// returned if the gauge could not be registered, for example if a loader with the same name is
+// already watched. The gauge reports 0 if the strategy is not introspectable.
+func (c *Collector) WatchPendingKeys(loader string, l dataloader.DataLoader) error {
+	return c.registerer.Register(prom.NewGaugeFunc(prom.GaugeOpts{
+		Namespace:   c.namespace,
+		Subsystem:   subsystem,
+		Name:        "pending_keys",
+		Help:        "Number of keys waiting for the batch function to be called.",
+		ConstLabels: prom.Labels{"loader": loader},
+	}, func() float64 {
+		state, _ := l.StrategyState()
+		return float64(state.PendingKeys)
+	}))
+}
+
+// ============================================= helpers =============================================
+
+func variant(canary bool) string {
+	if canary {
+		return "canary"
+	}
+	return "primary"
+}
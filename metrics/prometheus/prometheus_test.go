@@ -0,0 +1,113 @@
+package prometheus_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/metrics/prometheus"
+	"github.com/andy9775/dataloader/strategies/standard"
+	"github.com/stretchr/testify/assert"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// ================================================== tests ==================================================
+
+// TestCollectorRecordsBatchesAndCache ensures batches and cache lookups are exported
+func TestCollectorRecordsBatchesAndCache(t *testing.T) {
+	// setup
+	reg := prom.NewRegistry()
+	c := prometheus.New("app", reg)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			m.Set(k.(dataloader.Key), dataloader.Result{Result: k.(dataloader.Key).String(), Err: nil})
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(
+		2, // the cache hit and the miss share a batch
+		batch,
+		standard.NewStandardStrategy(),
+		dataloader.WithMetrics(c),
+		dataloader.WithHooks(c.Hooks()),
+	)
+	loader.Prime(context.Background(), PrimaryKey(1), dataloader.Result{Result: "primed", Err: nil})
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+	loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	expected := `
+		# HELP app_dataloader_batches_total Number of calls to the batch function.
+		# TYPE app_dataloader_batches_total counter
+		app_dataloader_batches_total{variant="primary"} 1
+		# HELP app_dataloader_cache_hits_total Number of keys resolved from the primed results or the cache.
+		# TYPE app_dataloader_cache_hits_total counter
+		app_dataloader_cache_hits_total 1
+		# HELP app_dataloader_cache_misses_total Number of keys passed to the strategy to be resolved by the batch function.
+		# TYPE app_dataloader_cache_misses_total counter
+		app_dataloader_cache_misses_total 1
+	`
+	err := testutil.GatherAndCompare(
+		reg,
+		strings.NewReader(expected),
+		"app_dataloader_batches_total",
+		"app_dataloader_cache_hits_total",
+		"app_dataloader_cache_misses_total",
+	)
+	assert.NoError(t, err, "Expected batch and cache metrics to be exported")
+}
+
+// TestWatchPendingKeys ensures the pending keys of a strategy are exported
+func TestWatchPendingKeys(t *testing.T) {
+	// setup
+	reg := prom.NewRegistry()
+	c := prometheus.New("app", reg)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		return &m
+	}
+	loader := dataloader.NewDataLoader(
+		2,
+		batch,
+		standard.NewStandardStrategy(standard.WithTimeout(time.Second)),
+	)
+	err := c.WatchPendingKeys("users", loader)
+	assert.NoError(t, err, "Expected the pending keys gauge to register")
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))
+	time.Sleep(10 * time.Millisecond) // allow the worker to receive the key
+
+	// assert
+	expected := `
+		# HELP app_dataloader_pending_keys Number of keys waiting for the batch function to be called.
+		# TYPE app_dataloader_pending_keys gauge
+		app_dataloader_pending_keys{loader="users"} 1
+	`
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_dataloader_pending_keys")
+	assert.NoError(t, err, "Expected the pending keys to be exported")
+
+	err = c.WatchPendingKeys("users", loader)
+	assert.Error(t, err, "Expected an error watching a loader with the same name twice")
+}
@@ -0,0 +1,159 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHistogramMetricsBuckets ensures observations are counted in the correct bucket
+func TestHistogramMetricsBuckets(t *testing.T) {
+	// setup
+	metrics := dataloader.NewHistogramMetrics(time.Millisecond, 10*time.Millisecond)
+
+	// invoke
+	metrics.LoadLatency(PrimaryKey(1), time.Microsecond, 5*time.Millisecond)
+	metrics.LoadLatency(PrimaryKey(2), time.Millisecond, time.Second)
+
+	// assert
+	queueWait := metrics.QueueWait()
+	assert.Equal(t, []uint64{2, 0, 0}, queueWait.Counts, "Expected queue wait bucket counts")
+	assert.Equal(t, uint64(2), queueWait.Count, "Expected queue wait observation count")
+
+	execution := metrics.Execution()
+	assert.Equal(t, []uint64{0, 1, 1}, execution.Counts, "Expected execution bucket counts")
+	assert.Equal(t, 5*time.Millisecond+time.Second, execution.Sum, "Expected execution sum")
+}
+
+// TestLoadRecordsLatency ensures the loader records latency for keys resolved by the batch function
+func TestLoadRecordsLatency(t *testing.T) {
+	// setup
+	result := dataloader.Result{Result: "latency", Err: nil}
+	cb := func() { time.Sleep(10 * time.Millisecond) }
+	metrics := dataloader.NewHistogramMetrics()
+	cache := newMockCache(1)
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithMetrics(metrics),
+		dataloader.WithCache(cache),
+	)
+
+	// invoke
+	thunk := loader.Load(context.Background(), PrimaryKey(1))
+	thunk()
+	thunk()                                            // double call records once
+	loader.Load(context.Background(), PrimaryKey(1))() // cache hit is not recorded
+
+	// assert
+	execution := metrics.Execution()
+	assert.Equal(t, uint64(1), execution.Count, "Expected a single latency observation")
+	assert.True(t, execution.Sum >= 10*time.Millisecond, "Expected execution latency to include batch duration")
+	assert.Equal(t, uint64(1), metrics.QueueWait().Count, "Expected a single queue wait observation")
+}
+
+// TestCanaryRecordsBatchMetrics ensures batches routed to the canary are recorded separately
+func TestCanaryRecordsBatchMetrics(t *testing.T) {
+	// setup
+	primaryCount, canaryCount := 0, 0
+	primary := getBatchFunction(func() { primaryCount += 1 }, dataloader.Result{Result: "primary", Err: nil})
+	canary := getBatchFunction(
+		func() { canaryCount += 1 },
+		dataloader.Result{Result: nil, Err: errors.New("canary failure")},
+	)
+	metrics := dataloader.NewHistogramMetrics()
+
+	loader := dataloader.NewDataLoader(
+		1,
+		primary,
+		newMockStrategy(),
+		dataloader.WithMetrics(metrics),
+		dataloader.WithCanary(1, canary), // route every batch to the canary
+	)
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Error(t, r.Err, "Expected result from canary")
+	assert.Equal(t, 0, primaryCount, "Expected primary batch function not to be called")
+	assert.Equal(t, 1, canaryCount, "Expected canary batch function to be called")
+	assert.Equal(t, uint64(1), metrics.BatchDuration(true).Count, "Expected canary batch to be recorded")
+	assert.Equal(t, uint64(0), metrics.BatchDuration(false).Count, "Expected no primary batch to be recorded")
+	assert.Equal(t, uint64(1), metrics.BatchErrors(true), "Expected canary error to be recorded")
+}
+
+// TestErrorClassifierLabelsErrors ensures batch errors are recorded by the class returned by the classifier
+func TestErrorClassifierLabelsErrors(t *testing.T) {
+	// setup
+	errThrottled := errors.New("throttled")
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		m.Set(PrimaryKey(1), dataloader.Result{Result: nil, Err: errThrottled})
+		m.Set(PrimaryKey(2), dataloader.Result{Result: nil, Err: context.DeadlineExceeded})
+		m.Set(PrimaryKey(3), dataloader.Result{Result: "3", Err: nil})
+		return &m
+	}
+	classifier := func(err error) string {
+		if err == errThrottled {
+			return "throttled"
+		}
+		return dataloader.DefaultErrorClassifier(err)
+	}
+	metrics := dataloader.NewHistogramMetrics()
+
+	loader := dataloader.NewDataLoader(
+		3,
+		batch,
+		newMockStrategy(),
+		dataloader.WithMetrics(metrics),
+		dataloader.WithErrorClassifier(classifier),
+	)
+
+	// invoke
+	loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))()
+
+	// assert
+	assert.Equal(
+		t,
+		map[string]uint64{"throttled": 1, dataloader.ErrorClassTimeout: 1},
+		metrics.ErrorClasses(false),
+		"Expected errors to be recorded by class",
+	)
+	assert.Equal(t, uint64(2), metrics.BatchErrors(false), "Expected total errors to be recorded")
+	assert.Equal(t, dataloader.ErrorClassCanceled, dataloader.DefaultErrorClassifier(context.Canceled))
+	assert.Equal(t, dataloader.ErrorClassInternal, dataloader.DefaultErrorClassifier(errors.New("failure")))
+}
+
+// TestDefaultErrorClassifierWrapped ensures wrapped errors are classified by the errors they wrap
+func TestDefaultErrorClassifierWrapped(t *testing.T) {
+	// setup
+	deadline := fmt.Errorf("query users: %w", context.DeadlineExceeded)
+	canceled := fmt.Errorf("query users: %w", context.Canceled)
+	timeout := fmt.Errorf("dial: %w", &net.DNSError{Err: "timeout", IsTimeout: true})
+
+	// invoke
+	classes := []string{
+		dataloader.DefaultErrorClassifier(deadline),
+		dataloader.DefaultErrorClassifier(canceled),
+		dataloader.DefaultErrorClassifier(timeout),
+	}
+
+	// assert
+	assert.Equal(
+		t,
+		[]string{dataloader.ErrorClassTimeout, dataloader.ErrorClassCanceled, dataloader.ErrorClassTimeout},
+		classes,
+		"Expected wrapped errors to be classified by the wrapped error",
+	)
+}
@@ -0,0 +1,13 @@
+package dataloader
+
+// BatchMiddleware decorates a batch function, e.g. with logging, metrics, retries or caching
+type BatchMiddleware func(BatchFunction) BatchFunction
+
+// Chain returns the batch function decorated by the middlewares. The first middleware is the outermost,
+// it is called first and receives the results last.
+func Chain(batch BatchFunction, middlewares ...BatchMiddleware) BatchFunction {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		batch = middlewares[i](batch)
+	}
+	return batch
+}
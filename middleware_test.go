@@ -0,0 +1,55 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// recordingMiddleware returns a middleware which records when it is entered and exited
+func recordingMiddleware(name string, calls *[]string) dataloader.BatchMiddleware {
+	return func(next dataloader.BatchFunction) dataloader.BatchFunction {
+		return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+			*calls = append(*calls, name+" start")
+			r := next(ctx, keys)
+			*calls = append(*calls, name+" end")
+			return r
+		}
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestUseMiddlewares ensures middlewares are called around the batch function in order
+func TestUseMiddlewares(t *testing.T) {
+	// setup
+	var calls []string
+	batch := getBatchFunction(
+		func() { calls = append(calls, "batch") },
+		dataloader.Result{Result: "middleware", Err: nil},
+	)
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.Use(recordingMiddleware("first", &calls)),
+		dataloader.Use(recordingMiddleware("second", &calls)),
+	)
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "middleware", r.Result, "Expected result from the batch function")
+	assert.Equal(
+		t,
+		[]string{"first start", "second start", "batch", "second end", "first end"},
+		calls,
+		"Expected the first middleware to be the outermost",
+	)
+}
@@ -0,0 +1,62 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+)
+
+// LoadManyOrdered loads the keys like LoadMany and returns a function which returns the results in the
+// order of the keys. Keys without a result resolve to a Result containing a NotFoundError. If any key failed to
+// resolve, the function also returns a KeyErrors error containing the error for each failed key.
+func (d *dataloader) LoadManyOrdered(ctx context.Context, keyArr ...Key) func() ([]Result, error) {
+	thunkMany := d.LoadMany(ctx, keyArr...)
+
+	return func() ([]Result, error) {
+		r := thunkMany()
+
+		errs := KeyErrors{}
+		results := make([]Result, len(keyArr))
+		for i, key := range keyArr {
+			result, _ := r.GetValue(key) // missing keys resolve to a NotFoundError
+			if result.Err != nil {
+				errs[key.String()] = result.Err
+			}
+			results[i] = result
+		}
+
+		if len(errs) > 0 {
+			return results, errs
+		}
+		return results, nil
+	}
+}
+
+// OrderedBatchFunction is a batch function which returns a result for each key in the order of the keys,
+// e.g. a batch function written for graph-gophers/dataloader
+type OrderedBatchFunction func(context.Context, []Key) []Result
+
+// FromOrderedBatchFunction adapts an OrderedBatchFunction to a BatchFunction. Each result is mapped to the key
+// at the same index. If the batch function returns a different number of results than keys, each key
+// resolves to a result containing an error.
+func FromOrderedBatchFunction(batch OrderedBatchFunction) BatchFunction {
+	return func(ctx context.Context, keys Keys) *ResultMap {
+		keyArr, ok := keySlice(keys)
+		if !ok {
+			return errorResultMap(keys, fmt.Errorf("dataloader: unable to order keys of type %T", keys))
+		}
+
+		results := batch(ctx, keyArr)
+		if len(results) != len(keyArr) {
+			return errorResultMap(
+				keys,
+				fmt.Errorf("dataloader: ordered batch function returned %d results for %d keys", len(results), len(keyArr)),
+			)
+		}
+
+		r := NewResultMap(len(keyArr))
+		for i, key := range keyArr {
+			r.Set(key, results[i])
+		}
+		return &r
+	}
+}
@@ -0,0 +1,89 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadManyOrdered ensures results are returned in the order of the keys with an aggregate error
+func TestLoadManyOrdered(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			if key != 2 { // key 2 is missing
+				m.Set(key, dataloader.Result{Result: key.String(), Err: nil})
+			}
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy())
+
+	// invoke
+	results, err := loader.LoadManyOrdered(context.Background(), PrimaryKey(3), PrimaryKey(2), PrimaryKey(1))()
+
+	// assert
+	if !assert.Equal(t, 3, len(results), "Expected a result for each key") {
+		return
+	}
+	assert.Equal(t, "3", results[0].Result, "Expected the results in the order of the keys")
+	assert.NotNil(t, results[1].Err, "Expected an error for the missing key")
+	assert.Equal(t, "1", results[2].Result, "Expected the results in the order of the keys")
+
+	if assert.IsType(t, dataloader.KeyErrors{}, err, "Expected a KeyErrors error") {
+		errs := err.(dataloader.KeyErrors)
+		assert.Equal(t, 1, len(errs), "Expected a single failed key")
+		assert.Equal(t, results[1].Err, errs["2"], "Expected the error for the missing key")
+	}
+}
+
+// TestFromOrderedBatchFunction ensures the results are mapped to the keys at the same index
+func TestFromOrderedBatchFunction(t *testing.T) {
+	// setup
+	var received []dataloader.Key
+	batch := dataloader.FromOrderedBatchFunction(func(ctx context.Context, keys []dataloader.Key) []dataloader.Result {
+		received = keys
+		results := make([]dataloader.Result, 0, len(keys))
+		for _, key := range keys {
+			results = append(results, dataloader.Result{Result: "value_" + key.String(), Err: nil})
+		}
+		return results
+	})
+
+	// invoke
+	r := batch(context.Background(), dataloader.NewKeysWith(PrimaryKey(3), PrimaryKey(1), PrimaryKey(3)))
+
+	// assert
+	assert.Equal(t, []dataloader.Key{PrimaryKey(3), PrimaryKey(1)}, received, "Expected the unique keys in order")
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	assert.Equal(t, "value_3", r.GetValueForString("3").Result, "Expected the result at the index of the key")
+	assert.Equal(t, "value_1", r.GetValueForString("1").Result, "Expected the result at the index of the key")
+}
+
+// TestFromOrderedBatchFunctionLengthMismatch ensures each key resolves to an error if the number of results
+// doesn't match the number of keys
+func TestFromOrderedBatchFunctionLengthMismatch(t *testing.T) {
+	// setup
+	batch := dataloader.FromOrderedBatchFunction(func(ctx context.Context, keys []dataloader.Key) []dataloader.Result {
+		return []dataloader.Result{{Result: "value", Err: nil}}
+	})
+	loader := dataloader.NewDataLoader(2, batch, newMockStrategy())
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.EqualError(
+			t,
+			r.GetValueForString(k).Err,
+			"dataloader: ordered batch function returned 1 results for 2 keys",
+			"Expected the length mismatch error",
+		)
+	}
+}
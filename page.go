@@ -0,0 +1,216 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Cursor identifies a position within a parents collection. The empty cursor identifies the start of
+// the collection.
+type Cursor string
+
+// PageKey identifies a page of up to Limit items following Cursor in the collection belonging to Parent
+// (e.g. the first 10 comments of a post).
+type PageKey struct {
+	Parent Key
+	Cursor Cursor
+	Limit  int
+}
+
+// String returns a unique identifier for the page
+func (k PageKey) String() string {
+	return fmt.Sprintf("%s|%s|%d", strconv.Quote(k.Parent.String()), strconv.Quote(string(k.Cursor)), k.Limit)
+}
+
+// Raw returns the PageKey
+func (k PageKey) Raw() interface{} {
+	return k
+}
+
+// Edge is a single item within a page along with the cursor identifying its position
+type Edge struct {
+	Cursor Cursor
+	Node   interface{}
+}
+
+// Page contains the items following a cursor
+type Page struct {
+	Edges       []Edge
+	HasNextPage bool
+}
+
+// EndCursor returns the cursor of the last item in the page, or an empty cursor if the page is empty
+func (p Page) EndCursor() Cursor {
+	if len(p.Edges) == 0 {
+		return ""
+	}
+	return p.Edges[len(p.Edges)-1].Cursor
+}
+
+// PageBatchFunction is called with the page keys to resolve and must return a page for each key in the
+// same order as the provided keys. A non-nil error is returned for every key in the batch.
+type PageBatchFunction func(context.Context, []PageKey) ([]Page, error)
+
+// PageThunk returns the page for the key that it was generated for.
+// Calling the PageThunk function will block until the page is returned from the batch function.
+type PageThunk func() (Page, error)
+
+// PageLoader loads pages of a parents collection. The PageLoader keeps the largest page fetched for each
+// parent and cursor and serves requests for pages which overlap with an already fetched page (e.g. a
+// smaller limit from the same cursor, or a page starting at a cursor within a fetched page) without
+// calling the batch function. Use Forget or ClearAll to release the pages of parents which are no longer
+// needed, e.g. after a mutation of the collection.
+type PageLoader struct {
+	loader DataLoader
+
+	m     sync.Mutex
+	pages map[string]map[Cursor]Page    // parent -> start cursor -> page
+	keys  map[string]map[string]PageKey // parent -> loaded page keys, cleared from the loader by Forget
+}
+
+// NewPageLoader returns a new instance of a PageLoader. The capacity, strategy and options behave the
+// same as those passed to NewDataLoader.
+func NewPageLoader(capacity int, batch PageBatchFunction, fn StrategyFunction, opts ...Option) *PageLoader {
+	batchFunc := func(ctx context.Context, keys Keys) *ResultMap {
+		raw := keys.Keys()
+		pageKeys := make([]PageKey, 0, len(raw))
+		for _, k := range raw {
+			pageKeys = append(pageKeys, k.(PageKey))
+		}
+
+		pages, err := batch(ctx, pageKeys)
+		if err == nil && len(pages) != len(pageKeys) {
+			err = fmt.Errorf("dataloader: page batch function returned %d pages for %d keys", len(pages), len(pageKeys))
+		}
+
+		r := NewResultMap(len(pageKeys))
+		for i, k := range pageKeys {
+			if err != nil {
+				r.Set(k, Result{Result: nil, Err: err})
+				continue
+			}
+			r.Set(k, Result{Result: pages[i], Err: nil})
+		}
+		return &r
+	}
+
+	return &PageLoader{
+		loader: NewDataLoader(capacity, batchFunc, fn, opts...),
+		pages:  make(map[string]map[Cursor]Page),
+		keys:   make(map[string]map[string]PageKey),
+	}
+}
+
+// LoadPage returns a PageThunk for the page of up to limit items following the cursor in the parents
+// collection. LoadPage does not block the caller.
+func (p *PageLoader) LoadPage(ctx context.Context, parent Key, cursor Cursor, limit int) PageThunk {
+	if page, ok := p.cached(parent, cursor, limit); ok {
+		return func() (Page, error) {
+			return page, nil
+		}
+	}
+
+	key := PageKey{Parent: parent, Cursor: cursor, Limit: limit}
+	thunk := p.loader.Load(ctx, key)
+
+	return func() (Page, error) {
+		r, ok := thunk()
+		if !ok {
+			return Page{}, fmt.Errorf("dataloader: no page found for key %s", key.String())
+		}
+		if r.Err != nil {
+			return Page{}, r.Err
+		}
+
+		page, ok := r.Result.(Page)
+		if !ok {
+			return Page{}, fmt.Errorf("dataloader: result for key %s has type %T, expected Page", key.String(), r.Result)
+		}
+
+		p.store(key, page)
+		return page, nil
+	}
+}
+
+// Forget removes the fetched pages of the parent, including the results cached by the underlying loader, so
+// that the next LoadPage for the parent calls the batch function.
+func (p *PageLoader) Forget(ctx context.Context, parent Key) {
+	p.m.Lock()
+	keys := p.keys[parent.String()]
+	delete(p.pages, parent.String())
+	delete(p.keys, parent.String())
+	p.m.Unlock()
+
+	for _, key := range keys {
+		p.loader.Clear(ctx, key)
+	}
+}
+
+// ClearAll removes the fetched pages of every parent and the results cached by the underlying loader
+func (p *PageLoader) ClearAll(ctx context.Context) {
+	p.m.Lock()
+	p.pages = make(map[string]map[Cursor]Page)
+	p.keys = make(map[string]map[string]PageKey)
+	p.m.Unlock()
+
+	p.loader.ClearAll(ctx)
+}
+
+// ============================================== private =============================================
+
+// cached returns the page for the cursor and limit if it can be served from a fetched page
+func (p *PageLoader) cached(parent Key, cursor Cursor, limit int) (Page, bool) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	for start, page := range p.pages[parent.String()] {
+		offset := -1
+		if start == cursor {
+			offset = 0
+		} else {
+			for i, e := range page.Edges {
+				if e.Cursor == cursor {
+					offset = i + 1
+					break
+				}
+			}
+		}
+		if offset < 0 {
+			continue
+		}
+
+		remaining := page.Edges[offset:]
+		switch {
+		case len(remaining) >= limit:
+			return Page{
+				Edges:       remaining[:limit:limit],
+				HasNextPage: len(remaining) > limit || page.HasNextPage,
+			}, true
+		case !page.HasNextPage: // end of the collection
+			return Page{Edges: remaining[:len(remaining):len(remaining)], HasNextPage: false}, true
+		}
+	}
+
+	return Page{}, false
+}
+
+// store keeps the page if it is larger than the page already fetched for the cursor
+func (p *PageLoader) store(key PageKey, page Page) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	parent := key.Parent.String()
+	pages, ok := p.pages[parent]
+	if !ok {
+		pages = make(map[Cursor]Page)
+		p.pages[parent] = pages
+		p.keys[parent] = make(map[string]PageKey)
+	}
+	p.keys[parent][key.String()] = key
+
+	if existing, ok := pages[key.Cursor]; !ok || len(existing.Edges) < len(page.Edges) {
+		pages[key.Cursor] = page
+	}
+}
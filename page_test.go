@@ -0,0 +1,103 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// getPageBatchFunction returns a page batch function serving a collection of size items per parent
+func getPageBatchFunction(cb func(), size int) dataloader.PageBatchFunction {
+	return func(ctx context.Context, keys []dataloader.PageKey) ([]dataloader.Page, error) {
+		cb()
+		pages := make([]dataloader.Page, 0, len(keys))
+		for _, k := range keys {
+			start := 0
+			if k.Cursor != "" {
+				fmt.Sscanf(string(k.Cursor), "%d", &start)
+				start++
+			}
+
+			page := dataloader.Page{}
+			for i := start; i < size && i < start+k.Limit; i++ {
+				page.Edges = append(page.Edges, dataloader.Edge{Cursor: dataloader.Cursor(fmt.Sprint(i)), Node: i})
+			}
+			page.HasNextPage = start+k.Limit < size
+			pages = append(pages, page)
+		}
+		return pages, nil
+	}
+}
+
+// TestPageLoaderServesOverlappingPages ensures pages overlapping a fetched page don't call the batch function
+func TestPageLoaderServesOverlappingPages(t *testing.T) {
+	// setup
+	callCount := 0
+	cb := func() { callCount += 1 }
+	loader := dataloader.NewPageLoader(1, getPageBatchFunction(cb, 12), newMockStrategy())
+	ctx := context.Background()
+	parent := PrimaryKey(1)
+
+	// invoke / assert
+	page, err := loader.LoadPage(ctx, parent, "", 10)()
+	assert.NoError(t, err, "Expected no error")
+	assert.Len(t, page.Edges, 10, "Expected full page")
+	assert.True(t, page.HasNextPage, "Expected next page")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called")
+
+	page, err = loader.LoadPage(ctx, parent, "", 5)()
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, dataloader.Cursor("4"), page.EndCursor(), "Expected smaller page from fetched page")
+	assert.True(t, page.HasNextPage, "Expected next page")
+
+	page, err = loader.LoadPage(ctx, parent, page.EndCursor(), 5)()
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, 5, page.Edges[0].Node, "Expected page starting after the cursor")
+	assert.Equal(t, dataloader.Cursor("9"), page.EndCursor(), "Expected page from fetched page")
+	assert.Equal(t, 1, callCount, "Expected overlapping pages not to call the batch function")
+
+	page, err = loader.LoadPage(ctx, parent, page.EndCursor(), 5)()
+	assert.NoError(t, err, "Expected no error")
+	assert.Len(t, page.Edges, 2, "Expected the remaining items")
+	assert.False(t, page.HasNextPage, "Expected no next page")
+	assert.Equal(t, 2, callCount, "Expected page beyond fetched pages to call the batch function")
+
+	page, err = loader.LoadPage(ctx, parent, "9", 10)()
+	assert.NoError(t, err, "Expected no error")
+	assert.Len(t, page.Edges, 2, "Expected the remaining items from the end of the collection")
+	assert.Equal(t, 2, callCount, "Expected end of collection to be served from fetched pages")
+}
+
+// TestPageLoaderForget ensures forgotten pages are fetched again while the pages of other parents are kept
+func TestPageLoaderForget(t *testing.T) {
+	// setup
+	callCount := 0
+	cb := func() { callCount += 1 }
+	loader := dataloader.NewPageLoader(
+		1,
+		getPageBatchFunction(cb, 12),
+		newMockStrategy(),
+		dataloader.WithCache(newMockCache(10)),
+	)
+	ctx := context.Background()
+	loader.LoadPage(ctx, PrimaryKey(1), "", 5)()
+	loader.LoadPage(ctx, PrimaryKey(2), "", 5)()
+
+	// invoke
+	loader.Forget(ctx, PrimaryKey(1))
+	loader.LoadPage(ctx, PrimaryKey(1), "", 5)()
+	loader.LoadPage(ctx, PrimaryKey(2), "", 5)()
+
+	// assert
+	assert.Equal(t, 3, callCount, "Expected only the forgotten parent to call the batch function")
+
+	// invoke
+	loader.ClearAll(ctx)
+	loader.LoadPage(ctx, PrimaryKey(2), "", 5)()
+
+	// assert
+	assert.Equal(t, 4, callCount, "Expected the pages to be fetched after ClearAll")
+}
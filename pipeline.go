@@ -0,0 +1,232 @@
+package dataloader
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Stage resolves keys on the loaders read path. The loader passes each key through the stages of its
+// pipeline (see WithPipeline) in order. Stages before BatchStage short-circuit the pipeline, a key
+// resolved by one of them is not passed to the strategy. Stages after BatchStage are fallbacks, they are
+// only consulted for keys which the batch function failed to resolve or resolved to an error.
+type Stage interface {
+	// Resolve returns the result for the key and true, or false to pass the key to the next stage
+	Resolve(context.Context, Key) (Result, bool)
+	// Store is called with results resolved by a later stage before the batch stage (e.g. to populate
+	// a local cache from a remote cache) or by the batch function. It is not called for fallbacks.
+	Store(context.Context, Key, Result)
+}
+
+// StageFunc adapts a function to a Stage which does not store results. This is useful for custom stages
+// such as feature flag gating, where a disabled key resolves to an error without calling the batch
+// function.
+type StageFunc func(context.Context, Key) (Result, bool)
+
+// Resolve calls f
+func (f StageFunc) Resolve(ctx context.Context, key Key) (Result, bool) {
+	return f(ctx, key)
+}
+
+// Store discards the result
+func (StageFunc) Store(context.Context, Key, Result) {}
+
+// Built in stages which are bound to the loader they are passed to
+var (
+	// IdentityMapStage resolves keys stored via Prime
+	IdentityMapStage Stage = identityMapStage{}
+	// LoaderCacheStage resolves keys from the cache set with WithCache
+	LoaderCacheStage Stage = loaderCacheStage{}
+	// BatchStage passes keys to the strategy to be resolved by the batch function
+	BatchStage Stage = batchStage{}
+)
+
+// CacheStage returns a stage which resolves keys from the provided cache, for example a remote cache
+// shared by multiple loaders. The cache is cleared by Clear and ClearAll.
+func CacheStage(cache Cache) Stage {
+	return &cacheStage{cache: cache}
+}
+
+// DefaultPipeline is the pipeline used when WithPipeline is not set
+var DefaultPipeline = []Stage{IdentityMapStage, LoaderCacheStage, BatchStage}
+
+// ============================================== stages =============================================
+
+// identityMapStage, loaderCacheStage and batchStage are placeholders which are replaced when the pipeline
+// is bound to a loader
+type identityMapStage struct{ d *dataloader }
+
+func (s identityMapStage) Resolve(ctx context.Context, key Key) (Result, bool) {
+	s.d.primeMutex.RLock()
+	defer s.d.primeMutex.RUnlock()
+
+	return s.d.primed.GetValue(key)
+}
+
+func (identityMapStage) Store(context.Context, Key, Result) {}
+
+// loaderCacheStage references the loaders cache at call time as the cache may be replaced after the
+// loader is built (see NewStaticLoader)
+type loaderCacheStage struct{ d *dataloader }
+
+func (s loaderCacheStage) Resolve(ctx context.Context, key Key) (Result, bool) {
+	return s.d.cache.GetResult(ctx, key)
+}
+
+func (s loaderCacheStage) Store(ctx context.Context, key Key, result Result) {
+	s.d.cache.SetResult(ctx, key, result)
+}
+
+func (s loaderCacheStage) storeMany(ctx context.Context, r ResultMap) {
+	s.d.cache.SetResultMap(ctx, r)
+}
+
+type batchStage struct{}
+
+func (batchStage) Resolve(context.Context, Key) (Result, bool) { return Result{}, false }
+
+func (batchStage) Store(context.Context, Key, Result) {}
+
+type cacheStage struct {
+	cache Cache
+}
+
+func (s *cacheStage) Resolve(ctx context.Context, key Key) (Result, bool) {
+	return s.cache.GetResult(ctx, key)
+}
+
+func (s *cacheStage) Store(ctx context.Context, key Key, result Result) {
+	s.cache.SetResult(ctx, key, result)
+}
+
+func (s *cacheStage) storeMany(ctx context.Context, r ResultMap) {
+	s.cache.SetResultMap(ctx, r)
+}
+
+func (s *cacheStage) clear(ctx context.Context, key Key) {
+	s.cache.Delete(ctx, key)
+}
+
+func (s *cacheStage) clearAll(ctx context.Context) {
+	s.cache.ClearAll(ctx)
+}
+
+// manyStorer is implemented by stages which store a ResultMap in a single call
+type manyStorer interface {
+	storeMany(context.Context, ResultMap)
+}
+
+// clearer is implemented by stages which are cleared by Clear and ClearAll
+type clearer interface {
+	clear(context.Context, Key)
+	clearAll(context.Context)
+}
+
+// ============================================== private =============================================
+
+// bindPipeline binds the built in stages to the loader and returns the stages before and after the batch
+// stage. BatchStage is appended if it is missing.
+func (d *dataloader) bindPipeline(stages []Stage) (lookups, fallbacks []Stage) {
+	batched := false
+	for _, stage := range stages {
+		switch stage.(type) {
+		case identityMapStage:
+			stage = identityMapStage{d: d}
+		case loaderCacheStage:
+			stage = loaderCacheStage{d: d}
+		case batchStage:
+			if !batched {
+				batched = true
+				continue
+			}
+			d.logger.Info("ignoring duplicate batch stage in pipeline")
+			continue
+		}
+
+		if batched {
+			fallbacks = append(fallbacks, stage)
+		} else {
+			lookups = append(lookups, stage)
+		}
+	}
+
+	if !batched {
+		d.logger.Info("pipeline has no batch stage, appending it")
+	}
+	return lookups, fallbacks
+}
+
+// lookup passes the key through the stages before the batch stage. Results are stored in the stages
+// preceding the stage which resolved the key.
+func (d *dataloader) lookup(ctx context.Context, key Key) (Result, bool) {
+	for i, stage := range d.lookups {
+		if r, ok := stage.Resolve(ctx, key); ok {
+			d.logger.Debug("pipeline stage resolved key", "stage", i, "key", key.String())
+			atomic.AddUint64(&d.cacheHits, 1)
+			d.hooks.CacheHit(ctx, key)
+
+			for _, s := range d.lookups[:i] {
+				s.Store(ctx, key, r)
+			}
+			return r, ok
+		}
+	}
+
+	atomic.AddUint64(&d.cacheMisses, 1)
+	d.hooks.CacheMiss(ctx, key)
+	return Result{}, false
+}
+
+// store stores a result returned by the batch function in the stages before the batch stage
+func (d *dataloader) store(ctx context.Context, key Key, result Result) {
+	for _, stage := range d.lookups {
+		stage.Store(ctx, key, result)
+	}
+}
+
+// storeMany stores the results of the keys returned by the batch function in the stages before the batch
+// stage. Stages are passed the keys of the caller rather than their string values.
+func (d *dataloader) storeMany(ctx context.Context, keyArr []Key, r ResultMap) {
+	if len(keyArr) == 0 {
+		return
+	}
+
+	for _, stage := range d.lookups {
+		if s, ok := stage.(manyStorer); ok {
+			s.storeMany(ctx, r)
+			continue
+		}
+		for _, key := range keyArr {
+			if v, ok := r.GetValue(key); ok {
+				stage.Store(ctx, key, v)
+			}
+		}
+	}
+}
+
+// storable reports whether a result returned by the batch function is stored in the stages before the batch
+// stage. Cancelled results are never stored and failed results only with WithErrorStorage.
+func (d *dataloader) storable(ctx context.Context, r Result) bool {
+	return !cancelled(ctx, r) && (r.Err == nil || d.storeErrors)
+}
+
+// fallback passes a key which the batch function failed to resolve through the stages after the batch
+// stage. The original result is returned if no stage resolves the key.
+func (d *dataloader) fallback(ctx context.Context, key Key, result Result, ok bool) (Result, bool, bool) {
+	if ok && result.Err == nil {
+		return result, ok, false
+	}
+
+	for i, stage := range d.fallbacks {
+		if r, resolved := stage.Resolve(ctx, key); resolved {
+			d.logger.Debug("fallback stage resolved key", "stage", i, "key", key.String())
+			return r, true, true
+		}
+	}
+	return result, ok, false
+}
+
+// cancelled reports whether the result holds the error of the load's cancelled context. Cancelled results
+// are returned to the caller but aren't stored or shared with other loads.
+func cancelled(ctx context.Context, r Result) bool {
+	return r.Err != nil && r.Err == ctx.Err()
+}
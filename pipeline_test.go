@@ -0,0 +1,161 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPipelineBackfillsEarlierStages ensures a key resolved by a later stage is stored in the earlier
+// stages without calling the batch function
+func TestPipelineBackfillsEarlierStages(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := getBatchFunction(func() { callCount++ }, dataloader.Result{Result: "batch", Err: nil})
+	local, remote := newMockCache(1), newMockCache(1)
+	remote.SetResult(context.Background(), PrimaryKey(1), dataloader.Result{Result: "remote", Err: nil})
+
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithPipeline(
+			dataloader.CacheStage(local),
+			dataloader.CacheStage(remote),
+			dataloader.BatchStage,
+		),
+	)
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.True(t, ok, "Expected the key to resolve")
+	assert.Equal(t, "remote", r.Result, "Expected the result from the remote cache")
+	assert.Equal(t, 1, callCount, "Expected the batch function to be called for the missing key only")
+
+	r, ok = local.GetResult(context.Background(), PrimaryKey(1))
+	assert.True(t, ok, "Expected the local cache to be backfilled")
+	assert.Equal(t, "remote", r.Result, "Expected the backfilled result")
+
+	_, ok = remote.GetResult(context.Background(), PrimaryKey(2))
+	assert.True(t, ok, "Expected the batch result to be stored in every cache stage")
+
+	loader.ClearAll(context.Background())
+	_, ok = remote.GetResult(context.Background(), PrimaryKey(2))
+	assert.False(t, ok, "Expected ClearAll to clear the cache stages")
+}
+
+// TestPipelineCustomStageShortCircuits ensures a custom stage placed before the batch stage can resolve
+// keys without calling the batch function
+func TestPipelineCustomStageShortCircuits(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := getBatchFunction(func() { callCount++ }, dataloader.Result{Result: "batch", Err: nil})
+	errDisabled := errors.New("feature disabled")
+	gate := dataloader.StageFunc(func(ctx context.Context, key dataloader.Key) (dataloader.Result, bool) {
+		if key.(PrimaryKey) > 10 {
+			return dataloader.Result{Result: nil, Err: errDisabled}, true
+		}
+		return dataloader.Result{}, false
+	})
+
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithPipeline(gate))
+
+	// invoke
+	gated := loader.LoadMany(context.Background(), PrimaryKey(11), PrimaryKey(12))()
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.Equal(t, errDisabled, gated.GetValueForString("11").Err, "Expected the gated key to fail")
+	assert.Equal(t, errDisabled, gated.GetValueForString("12").Err, "Expected the gated key to fail")
+	assert.True(t, ok, "Expected the ungated key to resolve")
+	assert.Equal(t, "batch", r.Result, "Expected the ungated key to be resolved by the batch function")
+	assert.Equal(t, 1, callCount, "Expected the batch function to be called once")
+}
+
+// TestPipelineFallback ensures stages after the batch stage resolve keys which failed in the batch
+// function and that fallback results are not cached
+func TestPipelineFallback(t *testing.T) {
+	// setup
+	batch := getBatchFunction(func() {}, dataloader.Result{Result: nil, Err: errors.New("unavailable")})
+	stale := newMockCache(1)
+	stale.SetResult(context.Background(), PrimaryKey(1), dataloader.Result{Result: "stale", Err: nil})
+	cache := newMockCache(1)
+
+	loader := dataloader.NewDataLoader(
+		1,
+		batch,
+		newMockStrategy(),
+		dataloader.WithCache(cache),
+		dataloader.WithPipeline(dataloader.LoaderCacheStage, dataloader.BatchStage, dataloader.CacheStage(stale)),
+	)
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	m := loader.LoadMany(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected the key to resolve")
+	assert.Equal(t, "stale", r.Result, "Expected the fallback result")
+	assert.Equal(t, "stale", m.GetValueForString("1").Result, "Expected the fallback result for LoadMany")
+	assert.Nil(t, m.GetValueForString("1").Err, "Expected no error for the fallback result")
+
+	_, ok = cache.GetResult(context.Background(), PrimaryKey(1))
+	assert.False(t, ok, "Expected the failed batch result not to be cached")
+}
+
+// TestPipelineStoresCallerKeys ensures stages are passed the keys of the caller and failed results are only
+// stored with WithErrorStorage
+func TestPipelineStoresCallerKeys(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			if k.(PrimaryKey) == 2 {
+				r.Set(k.(PrimaryKey), dataloader.Result{Result: nil, Err: expectedErr})
+			} else {
+				r.Set(k.(PrimaryKey), dataloader.Result{Result: "batch", Err: nil})
+			}
+		}
+		return &r
+	}
+	newLoader := func(stage *recordingStage, opts ...dataloader.Option) dataloader.DataLoader {
+		opts = append(opts, dataloader.WithPipeline(stage, dataloader.BatchStage))
+		return dataloader.NewDataLoader(2, batch, newMockStrategy(), opts...)
+	}
+	stage, errorStage := &recordingStage{}, &recordingStage{}
+
+	// invoke
+	newLoader(stage).LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	newLoader(errorStage, dataloader.WithErrorStorage()).LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, []dataloader.Key{PrimaryKey(1)}, stage.keys, "Expected the successful result with the callers key")
+	assert.ElementsMatch(
+		t,
+		[]dataloader.Key{PrimaryKey(1), PrimaryKey(2)},
+		errorStage.keys,
+		"Expected the failed result to be stored with WithErrorStorage",
+	)
+}
+
+// ========================= recording stage =========================
+
+// recordingStage records the keys of the stored results
+type recordingStage struct {
+	keys []dataloader.Key
+}
+
+func (s *recordingStage) Resolve(context.Context, dataloader.Key) (dataloader.Result, bool) {
+	return dataloader.Result{}, false
+}
+
+func (s *recordingStage) Store(ctx context.Context, key dataloader.Key, r dataloader.Result) {
+	s.keys = append(s.keys, key)
+}
@@ -0,0 +1,59 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================ mock rate limiter ============================================
+type mockRateLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *mockRateLimiter) Wait(ctx context.Context) error {
+	l.waits += 1
+	return l.err
+}
+
+// ================================================== tests ==================================================
+
+// TestRateLimiterThrottlesBatch ensures each batch waits for the rate limiter
+func TestRateLimiterThrottlesBatch(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := getBatchFunction(func() { callCount += 1 }, dataloader.Result{Result: "limited", Err: nil})
+	limiter := &mockRateLimiter{}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithRateLimiter(limiter))
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+	r, ok := loader.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "limited", r.Result, "Expected result from the batch function")
+	assert.Equal(t, 2, limiter.waits, "Expected each batch to wait for the rate limiter")
+	assert.Equal(t, 2, callCount, "Expected batch function to be called for each batch")
+}
+
+// TestRateLimiterErrorFailsBatch ensures a rate limiter error resolves each key to the error
+func TestRateLimiterErrorFailsBatch(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := getBatchFunction(func() { callCount += 1 }, dataloader.Result{Result: "limited", Err: nil})
+	limiter := &mockRateLimiter{err: errors.New("rate: Wait(n=1) would exceed context deadline")}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithRateLimiter(limiter))
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Error(t, r.Err, "Expected rate limiter error")
+	assert.Equal(t, 0, callCount, "Expected batch function not to be called")
+}
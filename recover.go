@@ -0,0 +1,38 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// PanicError is returned for each key in a batch when the batch function panics
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the go routine which panicked
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("dataloader: batch function panicked: %v", e.Value)
+}
+
+// recoverBatch returns a batch function which recovers from a panic in the batch function, logs it and
+// resolves each key to a PanicError. The loader calls batch functions on its own go routines (chunks,
+// routes, hedged keys, executors and coalesced windows) where a panic can't reach the strategy and would
+// crash the program.
+func recoverBatch(batch BatchFunction, l logger.Logger) BatchFunction {
+	return func(ctx context.Context, keys Keys) (r *ResultMap) {
+		defer func() {
+			if v := recover(); v != nil {
+				l.Error("recovered from panic in batch function", "keys", keys.Length(), "panic", v)
+				r = errorResultMap(keys, &PanicError{Value: v, Stack: debug.Stack()})
+			}
+		}()
+
+		return batch(ctx, keys)
+	}
+}
@@ -0,0 +1,134 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoRegistryScope is returned by Registry.Loader for a context which wasn't returned by the registries
+// NewContext
+var ErrNoRegistryScope = errors.New("dataloader: context has no loader registry scope")
+
+// UnknownLoaderError is returned by Registry.Loader for a name without a registered factory
+type UnknownLoaderError struct {
+	Name string
+}
+
+func (e *UnknownLoaderError) Error() string {
+	return fmt.Sprintf("dataloader: no loader registered as %q", e.Name)
+}
+
+// LoaderFactory builds a new loader for the context of a request
+type LoaderFactory func(context.Context) DataLoader
+
+// Registry holds the named loader factories of an application. The factories are registered once, e.g. at
+// startup, and each request retrieves its own instances by name from the context returned by NewContext:
+//
+//	registry := dataloader.NewRegistry()
+//	registry.Register("users", func(ctx context.Context) dataloader.DataLoader {
+//		return dataloader.NewDataLoader(100, userBatch, standard.NewStandardStrategy())
+//	})
+//
+//	ctx = registry.NewContext(r.Context())
+//	users, err := registry.Loader(ctx, "users")
+//
+// Loaders are instantiated lazily on the first retrieval within a context, so a request only pays for the
+// loaders it uses.
+type Registry struct {
+	m         sync.RWMutex
+	factories map[string]func(context.Context) interface{}
+}
+
+// registryScope holds the loaders instantiated for a context
+type registryScope struct {
+	m         sync.Mutex
+	instances map[string]interface{}
+}
+
+type registryScopeKey struct {
+	registry *Registry
+}
+
+// NewRegistry returns a registry without registered factories
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]func(context.Context) interface{})}
+}
+
+// Register registers the factory under the name, replacing any factory registered with the same name.
+// Contexts which already instantiated the loader keep their instance.
+func (r *Registry) Register(name string, factory LoaderFactory) {
+	r.register(name, func(ctx context.Context) interface{} { return factory(ctx) })
+}
+
+// NewContext returns a copy of the context which scopes the loaders of the registry to it. The loaders are
+// instantiated on the first call to Loader with the context, or a context derived from it.
+func (r *Registry) NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, registryScopeKey{r}, &registryScope{instances: make(map[string]interface{})})
+}
+
+// Loader returns the loader registered under the name for the scope of the context, instantiating it on the
+// first call. Loader returns an *UnknownLoaderError if no factory is registered under the name and
+// ErrNoRegistryScope if the context wasn't returned by NewContext.
+func (r *Registry) Loader(ctx context.Context, name string) (DataLoader, error) {
+	instance, err := r.instance(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := instance.(type) {
+	case DataLoader:
+		return l, nil
+	case interface{ DataLoader() DataLoader }:
+		return l.DataLoader(), nil
+	}
+	return nil, fmt.Errorf("dataloader: loader %q of type %T is not a DataLoader", name, instance)
+}
+
+// Names returns the names of the registered factories
+func (r *Registry) Names() []string {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ============================================== private =============================================
+
+// register registers a factory returning a DataLoader or a type safe Loader under the name
+func (r *Registry) register(name string, factory func(context.Context) interface{}) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.factories[name] = factory
+}
+
+// instance returns the loader instantiated for the scope of the context
+func (r *Registry) instance(ctx context.Context, name string) (interface{}, error) {
+	scope, ok := ctx.Value(registryScopeKey{r}).(*registryScope)
+	if !ok {
+		return nil, ErrNoRegistryScope
+	}
+
+	r.m.RLock()
+	factory, ok := r.factories[name]
+	r.m.RUnlock()
+	if !ok {
+		return nil, &UnknownLoaderError{Name: name}
+	}
+
+	scope.m.Lock()
+	defer scope.m.Unlock()
+
+	if instance, ok := scope.instances[name]; ok {
+		return instance, nil
+	}
+	instance := factory(ctx)
+	scope.instances[name] = instance
+	return instance, nil
+}
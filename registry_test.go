@@ -0,0 +1,127 @@
+package dataloader_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCountingFactory returns a factory which counts the loaders it builds
+func newCountingFactory(count *int32) dataloader.LoaderFactory {
+	return func(ctx context.Context) dataloader.DataLoader {
+		atomic.AddInt32(count, 1)
+		result := dataloader.Result{Result: "registry", Err: nil}
+		return dataloader.NewDataLoader(1, getBatchFunction(func() {}, result), newMockStrategy())
+	}
+}
+
+// TestRegistryLoader ensures a loader is instantiated once per context on the first retrieval
+func TestRegistryLoader(t *testing.T) {
+	// setup
+	var users, posts int32
+	registry := dataloader.NewRegistry()
+	registry.Register("users", newCountingFactory(&users))
+	registry.Register("posts", newCountingFactory(&posts))
+	first := registry.NewContext(context.Background())
+	second := registry.NewContext(context.Background())
+
+	// invoke
+	a, errA := registry.Loader(first, "users")
+	b, errB := registry.Loader(first, "users")
+	c, errC := registry.Loader(second, "users")
+
+	// assert
+	assert.NoError(t, errA, "Expected no error")
+	assert.NoError(t, errB, "Expected no error")
+	assert.NoError(t, errC, "Expected no error")
+	assert.True(t, a == b, "Expected the same loader within a context")
+	assert.False(t, a == c, "Expected a new loader for each context")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&users), "Expected a loader to be built for each context")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&posts), "Expected unused loaders not to be built")
+
+	r, ok := a.Load(first, PrimaryKey(1))()
+	assert.True(t, ok, "Expected a result")
+	assert.Equal(t, "registry", r.Result, "Expected the result of the batch function")
+}
+
+// TestRegistryLoaderDerivedContext ensures contexts derived from the scoped context share its loaders
+func TestRegistryLoaderDerivedContext(t *testing.T) {
+	// setup
+	var count int32
+	registry := dataloader.NewRegistry()
+	registry.Register("users", newCountingFactory(&count))
+	ctx := registry.NewContext(context.Background())
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// invoke
+	a, _ := registry.Loader(ctx, "users")
+	b, _ := registry.Loader(derived, "users")
+
+	// assert
+	assert.True(t, a == b, "Expected the derived context to share the loader")
+}
+
+// TestRegistryLoaderConcurrent ensures concurrent retrievals instantiate a single loader
+func TestRegistryLoaderConcurrent(t *testing.T) {
+	// setup
+	var count int32
+	registry := dataloader.NewRegistry()
+	registry.Register("users", newCountingFactory(&count))
+	ctx := registry.NewContext(context.Background())
+
+	// invoke
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.Loader(ctx, "users")
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count), "Expected a single loader to be built")
+}
+
+// TestRegistryLoaderErrors ensures unknown names and unscoped contexts return errors
+func TestRegistryLoaderErrors(t *testing.T) {
+	// setup
+	var count int32
+	registry := dataloader.NewRegistry()
+	registry.Register("users", newCountingFactory(&count))
+	other := dataloader.NewRegistry().NewContext(context.Background())
+
+	// invoke
+	_, unknownErr := registry.Loader(registry.NewContext(context.Background()), "posts")
+	_, unscopedErr := registry.Loader(context.Background(), "users")
+	_, otherErr := registry.Loader(other, "users")
+
+	// assert
+	assert.Equal(t, &dataloader.UnknownLoaderError{Name: "posts"}, unknownErr, "Expected an unknown loader error")
+	assert.Equal(t, dataloader.ErrNoRegistryScope, unscopedErr, "Expected a missing scope error")
+	assert.Equal(t, dataloader.ErrNoRegistryScope, otherErr, "Expected the scope of another registry to be ignored")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count), "Expected no loader to be built")
+}
+
+// TestRegistryNames ensures the names of the registered factories are returned
+func TestRegistryNames(t *testing.T) {
+	// setup
+	var count int32
+	registry := dataloader.NewRegistry()
+	registry.Register("users", newCountingFactory(&count))
+	registry.Register("posts", newCountingFactory(&count))
+
+	// invoke
+	names := registry.Names()
+
+	// assert
+	sort.Strings(names)
+	assert.Equal(t, []string{"posts", "users"}, names, "Expected the registered names")
+}
@@ -0,0 +1,28 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+)
+
+// RegisterLoader registers the factory of a type safe Loader under the name in the registry, replacing any
+// factory registered with the same name. Registry.Loader returns the DataLoader of the instances.
+func RegisterLoader[K comparable, V any](r *Registry, name string, factory func(context.Context) *Loader[K, V]) {
+	r.register(name, func(ctx context.Context) interface{} { return factory(ctx) })
+}
+
+// GetLoader returns the type safe Loader registered under the name for the scope of the context,
+// instantiating it on the first call. GetLoader returns an error if the loader registered under the name
+// isn't a Loader[K, V].
+func GetLoader[K comparable, V any](r *Registry, ctx context.Context, name string) (*Loader[K, V], error) {
+	instance, err := r.instance(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	l, ok := instance.(*Loader[K, V])
+	if !ok {
+		return nil, fmt.Errorf("dataloader: loader %q is a %T, not a %T", name, instance, l)
+	}
+	return l, nil
+}
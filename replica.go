@@ -0,0 +1,106 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type maxStalenessKey struct{}
+
+// WithMaxStaleness returns a context which marks the loads made with it as tolerating results up to the
+// provided age. Loads which tolerate less staleness than the replicas maximum staleness (see
+// WithReplicaBatch) are routed to the primary batch function.
+func WithMaxStaleness(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, maxStalenessKey{}, d)
+}
+
+// FreshRead returns a context which routes the loads made with it to the primary batch function
+func FreshRead(ctx context.Context) context.Context {
+	return WithMaxStaleness(ctx, 0)
+}
+
+// requiresPrimary returns true if the loads made with the context don't tolerate the replicas staleness
+func (d *dataloader) requiresPrimary(ctx context.Context) bool {
+	staleness, ok := ctx.Value(maxStalenessKey{}).(time.Duration)
+	return ok && staleness < d.replicaStaleness
+}
+
+// markPrimary flags the keys to be routed to the primary batch function while the loads requiring the primary
+// are pending. The returned function removes the flags and must be called once the loads resolve, whether or
+// not the keys were passed to the batch function (e.g. the loads joined an in flight load or were cancelled).
+func (d *dataloader) markPrimary(keyArr ...Key) (unmark func()) {
+	d.replicaMutex.Lock()
+	defer d.replicaMutex.Unlock()
+
+	for _, key := range keyArr {
+		d.primaryKeys[key.String()]++
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.replicaMutex.Lock()
+			defer d.replicaMutex.Unlock()
+
+			for _, key := range keyArr {
+				if d.primaryKeys[key.String()]--; d.primaryKeys[key.String()] <= 0 {
+					delete(d.primaryKeys, key.String())
+				}
+			}
+		})
+	}
+}
+
+// partition splits the keys into keys flagged for the primary and keys which may be read from the replica
+func (d *dataloader) partition(k Keys) (primary Keys, replica Keys) {
+	keyArr, ok := keySlice(k)
+	if !ok {
+		return k, NewKeys(0) // unable to inspect the keys, route the batch to the primary
+	}
+
+	d.replicaMutex.Lock()
+	defer d.replicaMutex.Unlock()
+
+	primary, replica = NewKeys(len(keyArr)), NewKeys(len(keyArr))
+	for _, key := range keyArr {
+		if d.primaryKeys[key.String()] > 0 {
+			primary.Append(key)
+		} else {
+			replica.Append(key)
+		}
+	}
+	return primary, replica
+}
+
+// routeReplica returns a batch function which passes the keys tolerating staleness to the replica batch
+// function and the remaining keys to the primary batch function. Keys which the replica fails to resolve
+// fall back to the primary.
+func (d *dataloader) routeReplica(primaryBatch BatchFunction) BatchFunction {
+	return func(ctx context.Context, keys Keys) *ResultMap {
+		primary, replica := d.partition(keys)
+		result := NewResultMap(keys.Length())
+
+		if !replica.IsEmpty() {
+			r := d.replicaBatch(ctx, replica)
+			replicaKeys, _ := keySlice(replica)
+			for _, key := range replicaKeys {
+				if r != nil {
+					if v, ok := r.GetValue(key); ok && v.Err == nil {
+						result.Set(key, v)
+						continue
+					}
+				}
+				primary.Append(key) // fall back to the primary
+			}
+			d.logger.Debug("replica resolved keys", "resolved", len(result), "keys", replica.Length())
+		}
+
+		if !primary.IsEmpty() {
+			if r := primaryBatch(ctx, primary); r != nil {
+				result.Merge(*r)
+			}
+		}
+		return &result
+	}
+}
@@ -0,0 +1,108 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================== test helpers ==============================================
+
+// getSourceBatchFunction returns a batch function which resolves every key except missing to the source
+func getSourceBatchFunction(source string, calls *[]int, missing ...dataloader.Key) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		*calls = append(*calls, keys.Length())
+		r := dataloader.NewResultMap(keys.Length())
+	keys:
+		for _, k := range keys.Keys() {
+			for _, m := range missing {
+				if k == m.Raw() {
+					continue keys
+				}
+			}
+			r.Set(k.(PrimaryKey), dataloader.Result{Result: source, Err: nil})
+		}
+		return &r
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestReplicaBatchRoutesByFreshness ensures fresh reads use the primary and other reads use the replica
+func TestReplicaBatchRoutesByFreshness(t *testing.T) {
+	// setup
+	var primaryCalls, replicaCalls []int
+	loader := dataloader.NewDataLoader(
+		1,
+		getSourceBatchFunction("primary", &primaryCalls),
+		newMockStrategy(),
+		dataloader.WithReplicaBatch(getSourceBatchFunction("replica", &replicaCalls), time.Second),
+	)
+	ctx := context.Background()
+
+	// invoke / assert
+	r, _ := loader.Load(ctx, PrimaryKey(1))()
+	assert.Equal(t, "replica", r.Result, "Expected load to be served by the replica")
+
+	r, _ = loader.Load(dataloader.FreshRead(ctx), PrimaryKey(2))()
+	assert.Equal(t, "primary", r.Result, "Expected fresh read to be served by the primary")
+
+	r, _ = loader.Load(dataloader.WithMaxStaleness(ctx, time.Minute), PrimaryKey(3))()
+	assert.Equal(t, "replica", r.Result, "Expected stale tolerant load to be served by the replica")
+
+	assert.Equal(t, []int{1}, primaryCalls, "Expected primary to be called once")
+	assert.Equal(t, []int{1, 1}, replicaCalls, "Expected replica to be called twice")
+}
+
+// TestReplicaBatchFallsBackToPrimary ensures keys the replica fails to resolve are loaded from the primary
+func TestReplicaBatchFallsBackToPrimary(t *testing.T) {
+	// setup
+	var primaryCalls, replicaCalls []int
+	loader := dataloader.NewDataLoader(
+		2,
+		getSourceBatchFunction("primary", &primaryCalls),
+		newMockStrategy(),
+		dataloader.WithReplicaBatch(getSourceBatchFunction("replica", &replicaCalls, PrimaryKey(2)), time.Second),
+	)
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	v, _ := r.GetValue(PrimaryKey(1))
+	assert.Equal(t, "replica", v.Result, "Expected key to be served by the replica")
+	v, _ = r.GetValue(PrimaryKey(2))
+	assert.Equal(t, "primary", v.Result, "Expected missing replica key to fall back to the primary")
+	assert.Equal(t, []int{1}, primaryCalls, "Expected primary to be called with the missing key")
+}
+
+// TestReplicaBatchReleasesCancelledFreshRead ensures a fresh read which resolves without calling the batch
+// function doesn't route later loads of the key to the primary
+func TestReplicaBatchReleasesCancelledFreshRead(t *testing.T) {
+	// setup
+	var primaryCalls, replicaCalls []int
+	loader := dataloader.NewDataLoader(
+		1,
+		getSourceBatchFunction("primary", &primaryCalls),
+		newCancellableStrategy(),
+		dataloader.WithReplicaBatch(getSourceBatchFunction("replica", &replicaCalls), time.Second),
+	)
+	ctx, cancel := context.WithCancel(dataloader.FreshRead(context.Background()))
+	cancel()
+
+	// invoke
+	cancelled, _ := loader.Load(ctx, PrimaryKey(1))()
+	cancelledMany := loader.LoadMany(ctx, PrimaryKey(2))()
+	r, _ := loader.Load(context.Background(), PrimaryKey(1))()
+	m := loader.LoadMany(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, context.Canceled, cancelled.Err, "Expected the context error")
+	assert.Equal(t, context.Canceled, cancelledMany.GetValueForString("2").Err, "Expected the context error")
+	assert.Equal(t, "replica", r.Result, "Expected later load to be served by the replica")
+	assert.Equal(t, "replica", m.GetValueForString("2").Result, "Expected later load to be served by the replica")
+	assert.Empty(t, primaryCalls, "Expected primary not to be called")
+}
@@ -1,12 +1,106 @@
 package dataloader
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
 // Result is an alias for the resolved data by the batch loader
 type Result struct {
 	Result interface{}
 	Err    error
+	// Source is set by the DataLoader and describes how the result was resolved
+	Source Source
+}
+
+// Source contains metadata describing the origin of a Result
+type Source struct {
+	// BatchID is the ID of the batch function call which returned the result
+	BatchID uint64
+	// Started is the time the batch function was called
+	Started time.Time
+	// Finished is the time the batch function returned
+	Finished time.Time
+}
+
+// KeyErrors is an error which aggregates the errors for multiple keys. It maps each keys identifier
+// (Key.String()) to the error for that key.
+type KeyErrors map[string]error
+
+// Error returns the errors for each key sorted by the keys identifier
+func (e KeyErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", k, e[k]))
+	}
+
+	return fmt.Sprintf("dataloader: %d error(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ErrNotFound is the error of a key without a result. Check for it with IsNotFound, which also matches
+// the NotFoundError carrying the key.
+var ErrNotFound = errors.New("dataloader: no result found")
+
+// NotFoundError is the error of a key the batch function returned no result for. It unwraps to ErrNotFound.
+type NotFoundError struct {
+	Key string
 }
 
-// ResultMap maps each loaded elements Result against the elements unique identifier (Key)
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("dataloader: no result found for key %s", e.Key)
+}
+
+// Unwrap returns ErrNotFound
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// IsNotFound returns true if the error, or an error it wraps, is ErrNotFound or a NotFoundError. A
+// DecodedError matches if it was encoded from either of them.
+func IsNotFound(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *NotFoundError:
+			return true
+		case *DecodedError:
+			return e.Type == fmt.Sprintf("%T", &NotFoundError{}) || e.Message == ErrNotFound.Error()
+		}
+		if err == ErrNotFound {
+			return true
+		}
+
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// notFoundResult returns the result of a key without a result
+func notFoundResult(key Key) Result {
+	if key == nil {
+		return Result{Result: nil, Err: ErrNotFound}
+	}
+	return Result{Result: nil, Err: &NotFoundError{Key: key.String()}}
+}
+
+// ResultMap maps each loaded elements Result against the elements unique identifier (Key).
+// ResultMap is not go routine safe. The loader gives each caller of a ThunkMany its own ResultMap, so
+// only ResultMaps which are shared by the caller, e.g. by a batch function setting results from multiple
+// go routines, need to be synchronized. Use a SyncResultMap for those.
 type ResultMap map[string]Result
 
 // NewResultMap returns a new instance of the result map with the provided capacity.
@@ -24,29 +118,276 @@ func (r ResultMap) Set(identifier Key, value Result) {
 }
 
 // GetValue returns the value from the results for the provided key and true
-// if the value was found, otherwise false.
+// if the value was found, otherwise a result holding a NotFoundError and false.
 func (r ResultMap) GetValue(key Key) (Result, bool) {
 	if key == nil {
-		return Result{}, false
+		return notFoundResult(nil), false
 	}
 
 	result, ok := r[key.String()]
+	if !ok {
+		return notFoundResult(key), false
+	}
 	return result, ok
 }
 
+// GetValueForString returns the value from the results for the provided identifier, or a result holding a
+// NotFoundError if it has none.
 func (r ResultMap) GetValueForString(key string) Result {
-	// No need to check ok, missing value from map[Any]interface{} is nil by default.
-	return r[key]
+	result, ok := r[key]
+	if !ok {
+		return notFoundResult(StringKey(key))
+	}
+	return result
 }
 
+// Keys returns the identifiers of the results sorted in increasing order
 func (r ResultMap) Keys() []string {
 	res := make([]string, 0, len(r))
-	for k, _ := range r {
+	for k := range r {
 		res = append(res, k)
 	}
+	sort.Strings(res)
 	return res
 }
 
+// Errors returns the error of each key whose result failed, mapped by the keys identifier. It returns nil if
+// no result failed. Convert it to a KeyErrors to return the failures as a single error.
+func (r ResultMap) Errors() map[string]error {
+	var errs map[string]error
+	for k, v := range r {
+		if v.Err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[k] = v.Err
+		}
+	}
+	return errs
+}
+
+// HasErrors returns true if any result failed
+func (r ResultMap) HasErrors() bool {
+	for _, v := range r {
+		if v.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge copies the results of the other result map into the result map, replacing the results of keys
+// found in both, e.g. to combine the results of chunked batches or fallback sources
+func (r ResultMap) Merge(other ResultMap) {
+	for k, v := range other {
+		r[k] = v
+	}
+}
+
+// MissingKeys returns the keys without a result in the result map, e.g. the keys to load from the next
+// source after a cache lookup
+func (r ResultMap) MissingKeys(keys Keys) Keys {
+	return keys.Filter(func(k Key) bool {
+		_, ok := r[k.String()]
+		return !ok
+	})
+}
+
+// Values returns the results in the order of the identifiers returned by Keys
+func (r ResultMap) Values() []Result {
+	keys := r.Keys()
+	res := make([]Result, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, r[k])
+	}
+	return res
+}
+
+// ValuesFor returns the result of each key in the order of the keys, e.g. the keys passed to LoadMany
+// for a GraphQL list resolver. Keys without a result return a result holding a NotFoundError.
+func (r ResultMap) ValuesFor(keys ...Key) []Result {
+	res := make([]Result, 0, len(keys))
+	for _, k := range keys {
+		v, _ := r.GetValue(k)
+		res = append(res, v)
+	}
+	return res
+}
+
+// ForEach calls the function with each key in the order of the keys, its result and whether the result
+// was found, until the function returns false. Since ResultMap is a map, it doesn't retain the order the
+// results were set in; pass the keys passed to LoadMany to iterate in the request order.
+func (r ResultMap) ForEach(keys []Key, fn func(Key, Result, bool) bool) {
+	for _, k := range keys {
+		v, ok := r.GetValue(k)
+		if !fn(k, v, ok) {
+			return
+		}
+	}
+}
+
 func (r ResultMap) Length() int {
 	return len(r)
 }
+
+// ===================================== sync result map =====================================
+
+// SyncResultMap is a ResultMap which is safe for concurrent use, e.g. by a batch function which resolves
+// the keys of a batch in multiple go routines. Call ResultMap to obtain the ResultMap to return from the
+// batch function.
+type SyncResultMap struct {
+	m       sync.RWMutex
+	results ResultMap
+}
+
+// NewSyncResultMap returns a new instance of the sync result map with the provided capacity
+func NewSyncResultMap(capacity int) *SyncResultMap {
+	return &SyncResultMap{results: NewResultMap(capacity)}
+}
+
+// Set adds the value to the result set
+func (r *SyncResultMap) Set(identifier Key, value Result) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.results.Set(identifier, value)
+}
+
+// GetValue returns the value from the results for the provided key and true if the value was found,
+// otherwise false.
+func (r *SyncResultMap) GetValue(key Key) (Result, bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.results.GetValue(key)
+}
+
+// GetValueForString returns the value for the keys identifier
+func (r *SyncResultMap) GetValueForString(key string) Result {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.results.GetValueForString(key)
+}
+
+// Keys returns the identifiers of the keys in the result set
+func (r *SyncResultMap) Keys() []string {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.results.Keys()
+}
+
+// Length returns the number of results
+func (r *SyncResultMap) Length() int {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	return r.results.Length()
+}
+
+// Range calls the function with each identifier and result until it returns false. The function must not
+// call Set.
+func (r *SyncResultMap) Range(fn func(string, Result) bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	for k, v := range r.results {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// ResultMap returns a copy of the results
+func (r *SyncResultMap) ResultMap() ResultMap {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	results := NewResultMap(len(r.results))
+	for k, v := range r.results {
+		results[k] = v
+	}
+	return results
+}
+
+// ===================================== json =====================================
+
+// DecodedError is the error of a Result decoded from JSON. It holds the message and the type of the
+// original error, e.g. to distinguish a not found error from a timeout in a remote process.
+type DecodedError struct {
+	Message string `json:"message"`
+	Type    string `json:"type,omitempty"`
+}
+
+func (e *DecodedError) Error() string {
+	return e.Message
+}
+
+// jsonResult is the JSON representation of a Result
+type jsonResult struct {
+	Result interface{}   `json:"result"`
+	Err    *DecodedError `json:"error,omitempty"`
+	Source *jsonSource   `json:"source,omitempty"`
+}
+
+// jsonSource is the JSON representation of a Source
+type jsonSource struct {
+	BatchID  uint64    `json:"batchId"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+}
+
+// MarshalJSON encodes the result as {"result": ..., "error": {"message": ..., "type": ...}, "source": ...}.
+// The error and source are omitted when they aren't set.
+func (r Result) MarshalJSON() ([]byte, error) {
+	j := jsonResult{Result: r.Result}
+	if r.Err != nil {
+		if d, ok := r.Err.(*DecodedError); ok {
+			j.Err = d
+		} else {
+			j.Err = &DecodedError{Message: r.Err.Error(), Type: fmt.Sprintf("%T", r.Err)}
+		}
+	}
+	if r.Source != (Source{}) {
+		j.Source = &jsonSource{BatchID: r.Source.BatchID, Started: r.Source.Started, Finished: r.Source.Finished}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a result encoded by MarshalJSON. The value is decoded into its generic JSON
+// representation (e.g. map[string]interface{} for objects, float64 for numbers) and the error into a
+// *DecodedError.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var j jsonResult
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*r = Result{Result: j.Result}
+	if j.Err != nil {
+		r.Err = j.Err
+	}
+	if j.Source != nil {
+		r.Source = Source{BatchID: j.Source.BatchID, Started: j.Source.Started, Finished: j.Source.Finished}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the result map as an object mapping each identifier to its result
+func (r ResultMap) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]Result(r))
+}
+
+// UnmarshalJSON decodes a result map encoded by MarshalJSON
+func (r *ResultMap) UnmarshalJSON(data []byte) error {
+	m := make(map[string]Result)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*r = m
+	return nil
+}
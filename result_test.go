@@ -1,7 +1,12 @@
 package dataloader_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/andy9775/dataloader"
 	"github.com/stretchr/testify/assert"
@@ -33,4 +38,305 @@ func TestEnsureNotOKForResult(t *testing.T) {
 	result, ok := rmap.GetValue(key2)
 	assert.False(t, ok, "Expected valid result to have been found")
 	assert.Nil(t, result.Result, "Expected nil result")
+	assert.True(t, dataloader.IsNotFound(result.Err), "Expected a not found error")
+	assert.EqualError(t, result.Err, "dataloader: no result found for key 2", "Expected the missing key")
+}
+
+// TestIsNotFound ensures the not found errors are detected through wrapping errors and JSON encoding
+func TestIsNotFound(t *testing.T) {
+	// setup
+	var decoded dataloader.Result
+	data, err := json.Marshal(dataloader.Result{Err: &dataloader.NotFoundError{Key: "1"}})
+	assert.Nil(t, err, "Expected the result to be encoded")
+	assert.Nil(t, json.Unmarshal(data, &decoded), "Expected the result to be decoded")
+
+	// invoke/assert
+	assert.True(t, dataloader.IsNotFound(dataloader.ErrNotFound), "Expected ErrNotFound")
+	assert.True(t, dataloader.IsNotFound(&dataloader.NotFoundError{Key: "1"}), "Expected a NotFoundError")
+	assert.True(t, dataloader.IsNotFound(wrappedError{dataloader.ErrNotFound}), "Expected a wrapped ErrNotFound")
+	assert.True(t, dataloader.IsNotFound(decoded.Err), "Expected a decoded NotFoundError")
+	assert.False(t, dataloader.IsNotFound(errors.New("failed")), "Expected other errors not to match")
+	assert.False(t, dataloader.IsNotFound(nil), "Expected a nil error not to match")
+}
+
+// wrappedError wraps an error
+type wrappedError struct {
+	err error
+}
+
+func (e wrappedError) Error() string { return "wrapped: " + e.err.Error() }
+
+func (e wrappedError) Unwrap() error { return e.err }
+
+// TestSyncResultMapConcurrent ensures results can be set and read from multiple go routines
+func TestSyncResultMapConcurrent(t *testing.T) {
+	// setup
+	rmap := dataloader.NewSyncResultMap(10)
+	var wg sync.WaitGroup
+
+	// invoke
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			rmap.Set(PrimaryKey(i), dataloader.Result{Result: i, Err: nil})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			rmap.GetValue(PrimaryKey(i))
+			rmap.Length()
+			rmap.Keys()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 10, rmap.Length(), "Expected a result for each key")
+	result, ok := rmap.GetValue(PrimaryKey(3))
+	assert.True(t, ok, "Expected the result to be found")
+	assert.Equal(t, 3, result.Result, "Expected the result of the key")
+	assert.Equal(t, 3, rmap.GetValueForString("3").Result, "Expected the result of the identifier")
+
+	count := 0
+	rmap.Range(func(string, dataloader.Result) bool {
+		count++
+		return count < 2
+	})
+	assert.Equal(t, 2, count, "Expected the iteration to stop")
+}
+
+// TestSyncResultMapBatchFunction ensures the ResultMap of a sync result map resolves the keys of a batch
+func TestSyncResultMapBatchFunction(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		rmap := dataloader.NewSyncResultMap(keys.Length())
+		var wg sync.WaitGroup
+		for _, k := range keys.Keys() {
+			wg.Add(1)
+			go func(key PrimaryKey) {
+				defer wg.Done()
+				rmap.Set(key, dataloader.Result{Result: int(key) * 10, Err: nil})
+			}(k.(PrimaryKey))
+		}
+		wg.Wait()
+
+		r := rmap.ResultMap()
+		return &r
+	}
+	loader := dataloader.NewDataLoader(2, batch, newMockStrategy())
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	assert.Equal(t, 20, r.GetValueForString("2").Result, "Expected the result of the key")
+}
+
+// TestSyncResultMapCopy ensures the returned ResultMap is not affected by later changes
+func TestSyncResultMapCopy(t *testing.T) {
+	// setup
+	rmap := dataloader.NewSyncResultMap(2)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+
+	// invoke
+	r := rmap.ResultMap()
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: 2, Err: nil})
+
+	// assert
+	assert.Equal(t, 1, r.Length(), "Expected the copy to be unchanged")
+	assert.Equal(t, 2, rmap.Length(), "Expected the sync result map to contain both results")
+}
+
+// TestResultMapKeysValues ensures the keys are sorted and the values follow the order of the keys
+func TestResultMapKeysValues(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(3)
+	rmap.Set(PrimaryKey(3), dataloader.Result{Result: 30, Err: nil})
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: 10, Err: nil})
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: 20, Err: nil})
+
+	// invoke
+	keys := rmap.Keys()
+	values := rmap.Values()
+
+	// assert
+	assert.Equal(t, []string{"1", "2", "3"}, keys, "Expected the sorted identifiers")
+	assert.Equal(
+		t,
+		[]dataloader.Result{{Result: 10}, {Result: 20}, {Result: 30}},
+		values,
+		"Expected the values in the order of the keys",
+	)
+}
+
+// TestResultMapValuesFor ensures the results are returned in the order of the requested keys
+func TestResultMapValuesFor(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(2)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: 10, Err: nil})
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: 20, Err: nil})
+
+	// invoke
+	values := rmap.ValuesFor(PrimaryKey(2), PrimaryKey(3), PrimaryKey(1))
+
+	// assert
+	assert.Equal(
+		t,
+		[]dataloader.Result{{Result: 20}, {Err: &dataloader.NotFoundError{Key: "3"}}, {Result: 10}},
+		values,
+		"Expected the results in the order of the keys",
+	)
+}
+
+// TestResultMapForEach ensures the results are visited in the order of the requested keys until the function
+// returns false
+func TestResultMapForEach(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(2)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: 10, Err: nil})
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: 20, Err: nil})
+	var visited []interface{}
+	var found []bool
+
+	// invoke
+	rmap.ForEach(
+		[]dataloader.Key{PrimaryKey(2), PrimaryKey(3), PrimaryKey(1), PrimaryKey(2)},
+		func(k dataloader.Key, r dataloader.Result, ok bool) bool {
+			visited = append(visited, r.Result)
+			found = append(found, ok)
+			return k != PrimaryKey(1)
+		},
+	)
+
+	// assert
+	assert.Equal(t, []interface{}{20, nil, 10}, visited, "Expected the results in the order of the keys")
+	assert.Equal(t, []bool{true, false, true}, found, "Expected whether each result was found")
+}
+
+// TestResultJSON ensures results survive JSON encoding with their error as a structured field
+func TestResultJSON(t *testing.T) {
+	// setup
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := dataloader.Result{
+		Result: map[string]interface{}{"name": "alice"},
+		Err:    errors.New("stale"),
+		Source: dataloader.Source{BatchID: 7, Started: started, Finished: started.Add(time.Second)},
+	}
+
+	// invoke
+	data, err := json.Marshal(r)
+	var decoded dataloader.Result
+	decodeErr := json.Unmarshal(data, &decoded)
+
+	// assert
+	assert.NoError(t, err, "Expected no error encoding the result")
+	assert.NoError(t, decodeErr, "Expected no error decoding the result")
+	assert.Contains(
+		t,
+		string(data),
+		`"error":{"message":"stale","type":"*errors.errorString"}`,
+		"Expected the structured error",
+	)
+	assert.Equal(t, r.Result, decoded.Result, "Expected the decoded value")
+	assert.Equal(
+		t,
+		&dataloader.DecodedError{Message: "stale", Type: "*errors.errorString"},
+		decoded.Err,
+		"Expected the decoded error",
+	)
+	assert.Equal(t, uint64(7), decoded.Source.BatchID, "Expected the decoded source")
+	assert.True(t, r.Source.Finished.Equal(decoded.Source.Finished), "Expected the decoded source")
+
+	again, _ := json.Marshal(decoded)
+	assert.Equal(t, string(data), string(again), "Expected a decoded error to keep its type")
+}
+
+// TestResultJSONOmitsEmpty ensures the error and source are omitted when they aren't set
+func TestResultJSONOmitsEmpty(t *testing.T) {
+	// invoke
+	data, err := json.Marshal(dataloader.Result{Result: 1, Err: nil})
+
+	// assert
+	assert.NoError(t, err, "Expected no error encoding the result")
+	assert.Equal(t, `{"result":1}`, string(data), "Expected only the value")
+}
+
+// TestResultMapJSON ensures result maps survive JSON encoding
+func TestResultMapJSON(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(2)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: "one", Err: nil})
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: nil, Err: errors.New("missing")})
+
+	// invoke
+	data, err := json.Marshal(rmap)
+	var decoded dataloader.ResultMap
+	decodeErr := json.Unmarshal(data, &decoded)
+	empty, _ := json.Marshal(dataloader.ResultMap(nil))
+
+	// assert
+	assert.NoError(t, err, "Expected no error encoding the result map")
+	assert.NoError(t, decodeErr, "Expected no error decoding the result map")
+	assert.Equal(t, 2, decoded.Length(), "Expected a result for each key")
+	assert.Equal(t, "one", decoded.GetValueForString("1").Result, "Expected the decoded value")
+	assert.EqualError(t, decoded.GetValueForString("2").Err, "missing", "Expected the decoded error")
+	assert.Equal(t, "{}", string(empty), "Expected an empty object for a nil result map")
+}
+
+// TestResultMapMerge ensures the results of the other result map are copied and replace existing results
+func TestResultMapMerge(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(2)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: "stale", Err: nil})
+	other := dataloader.NewResultMap(2)
+	other.Set(PrimaryKey(1), dataloader.Result{Result: "fresh", Err: nil})
+	other.Set(PrimaryKey(2), dataloader.Result{Result: "new", Err: nil})
+
+	// invoke
+	rmap.Merge(other)
+
+	// assert
+	assert.Equal(t, 2, rmap.Length(), "Expected the results of both maps")
+	assert.Equal(t, "fresh", rmap.GetValueForString("1").Result, "Expected the result to be replaced")
+	assert.Equal(t, "new", rmap.GetValueForString("2").Result, "Expected the new result")
+	assert.Equal(t, 2, other.Length(), "Expected the other map to be unchanged")
+}
+
+// TestResultMapMissingKeys ensures the keys without a result are returned in order
+func TestResultMapMissingKeys(t *testing.T) {
+	// setup
+	rmap := dataloader.NewResultMap(2)
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: nil, Err: errors.New("failed")})
+	keys := dataloader.NewKeysWith(PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))
+
+	// invoke
+	missing := rmap.MissingKeys(keys)
+
+	// assert
+	assert.Equal(t, []string{"1", "3"}, missing.StringKeys(), "Expected the keys without a result")
+	assert.Equal(t, 3, keys.Length(), "Expected the keys to be unchanged")
+}
+
+// TestResultMapErrors ensures the errors of the failed results are returned by key
+func TestResultMapErrors(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+	rmap := dataloader.NewResultMap(3)
+	rmap.Set(PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+	rmap.Set(PrimaryKey(2), dataloader.Result{Result: nil, Err: expectedErr})
+	ok := dataloader.NewResultMap(1)
+	ok.Set(PrimaryKey(1), dataloader.Result{Result: 1, Err: nil})
+
+	// invoke / assert
+	assert.True(t, rmap.HasErrors(), "Expected the failed result to be reported")
+	assert.Equal(t, map[string]error{"2": expectedErr}, rmap.Errors(), "Expected the error of the failed key")
+	assert.EqualError(
+		t,
+		dataloader.KeyErrors(rmap.Errors()),
+		"dataloader: 1 error(s): 2: failed",
+		"Expected the errors to convert to KeyErrors",
+	)
+	assert.False(t, ok.HasErrors(), "Expected no failed results")
+	assert.Nil(t, ok.Errors(), "Expected no errors")
 }
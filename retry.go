@@ -0,0 +1,17 @@
+package dataloader
+
+import "time"
+
+// Backoff returns the duration to wait before the provided retry attempt (starting at 1). The Delay method
+// of a backoff.Exponential may be used as a Backoff.
+type Backoff func(attempt int) time.Duration
+
+// batchFailed returns true if the ResultMap doesn't contain a successful result for any of the keys
+func batchFailed(keys Keys, r ResultMap) bool {
+	for _, k := range keys.StringKeys() {
+		if v, ok := r[k]; ok && v.Err == nil {
+			return false
+		}
+	}
+	return !keys.IsEmpty()
+}
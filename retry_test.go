@@ -0,0 +1,61 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================================================== tests ==================================================
+
+// TestRetryFailedBatch ensures a failed batch is retried until it succeeds
+func TestRetryFailedBatch(t *testing.T) {
+	// setup
+	callCount := 0
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		callCount += 1
+		m := dataloader.NewResultMap(1)
+		if callCount < 3 {
+			return &m // all keys missing
+		}
+		m.Set(PrimaryKey(1), dataloader.Result{Result: "retried", Err: nil})
+		return &m
+	}
+	var waits []int
+	backoff := func(attempt int) time.Duration {
+		waits = append(waits, attempt)
+		return time.Millisecond
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithRetry(3, backoff))
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "retried", r.Result, "Expected result from the retried batch")
+	assert.Equal(t, 3, callCount, "Expected batch function to be retried twice")
+	assert.Equal(t, []int{1, 2}, waits, "Expected backoff before each retry")
+}
+
+// TestRetryExhausted ensures the failure is returned once the retries are exhausted
+func TestRetryExhausted(t *testing.T) {
+	// setup
+	callCount := 0
+	cb := func() { callCount += 1 }
+	batch := getBatchFunction(cb, dataloader.Result{Result: nil, Err: errors.New("unavailable")})
+	backoff := func(int) time.Duration { return time.Millisecond }
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithRetry(2, backoff))
+
+	// invoke
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.EqualError(t, r.Err, "unavailable", "Expected error from the last attempt")
+	assert.Equal(t, 3, callCount, "Expected initial call and two retries")
+}
@@ -0,0 +1,74 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchID identifies one of the batch functions passed to WithBatchRouter. It is unrelated to the
+// sequential ID assigned to each call to the batch function (see LastBatchID).
+type BatchID string
+
+// BatchRouter returns the ID of the batch function which resolves the key
+type BatchRouter func(Key) BatchID
+
+// route identifies the batch function the keys of a batch are passed to. Keys routed to an ID without a
+// batch function take the default route, which can't collide with a batch function registered for any ID.
+type route struct {
+	id        BatchID
+	defaultFn bool
+}
+
+// routeBatch returns a batch function which splits the keys by the batch function the router selects for
+// each key and calls the batch functions concurrently. Keys routed to an ID without a batch function are
+// passed to the default batch function. A panic in a batch function resolves its keys to a PanicError.
+func (d *dataloader) routeBatch(defaultBatch BatchFunction) BatchFunction {
+	return func(ctx context.Context, keys Keys) *ResultMap {
+		keyArr, ok := keySlice(keys)
+		if !ok {
+			return defaultBatch(ctx, keys) // unable to inspect the keys, route the batch to the default
+		}
+
+		routes := make(map[route]Keys)
+		for _, key := range keyArr {
+			r := route{id: d.batchRouter(key)}
+			if _, ok := d.batchFunctions[r.id]; !ok {
+				r = route{defaultFn: true}
+			}
+			if _, ok := routes[r]; !ok {
+				routes[r] = NewKeys(len(keyArr))
+			}
+			routes[r].Append(key)
+		}
+
+		var wg sync.WaitGroup
+		var m sync.Mutex
+		result := NewResultMap(keys.Length())
+		for r, routed := range routes {
+			id, fn := r.id, d.batchFunctions[r.id]
+			if r.defaultFn {
+				fn = defaultBatch
+			}
+
+			wg.Add(1)
+			go func(id BatchID, fn BatchFunction, routed Keys) {
+				defer wg.Done()
+
+				d.logger.Debug("routing keys to batch function", "batch_function", string(id), "keys", routed.Length())
+				r := recoverBatch(fn, d.logger)(ctx, routed)
+				if r == nil {
+					return
+				}
+
+				m.Lock()
+				defer m.Unlock()
+				for k, v := range *r {
+					result[k] = v
+				}
+			}(id, fn, routed)
+		}
+		wg.Wait()
+
+		return &result
+	}
+}
@@ -0,0 +1,95 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBatchRouter ensures keys are resolved by the batch function selected by the router
+func TestBatchRouter(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	calls := make(map[string][]string)
+	batchFor := func(name string) dataloader.BatchFunction {
+		return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+			result := dataloader.NewResultMap(keys.Length())
+			m.Lock()
+			defer m.Unlock()
+			for _, k := range keys.Keys() {
+				key := k.(dataloader.Key)
+				calls[name] = append(calls[name], key.String())
+				result.Set(key, dataloader.Result{Result: name, Err: nil})
+			}
+			return &result
+		}
+	}
+	router := func(key dataloader.Key) dataloader.BatchID {
+		switch key.(PrimaryKey) {
+		case 1:
+			return "hot"
+		case 2:
+			return "unknown"
+		}
+		return "cold"
+	}
+	loader := dataloader.NewDataLoader(
+		3,
+		batchFor("default"),
+		newMockStrategy(),
+		dataloader.WithBatchRouter(router, map[dataloader.BatchID]dataloader.BatchFunction{
+			"hot":  batchFor("hot"),
+			"cold": batchFor("cold"),
+		}),
+	)
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))()
+
+	// assert
+	assert.Equal(t, "hot", r.GetValueForString("1").Result, "Expected the hot batch function to resolve the key")
+	assert.Equal(t, "default", r.GetValueForString("2").Result, "Expected unknown routes to use the default")
+	assert.Equal(t, "cold", r.GetValueForString("3").Result, "Expected the cold batch function to resolve the key")
+	assert.Equal(t, []string{"1"}, calls["hot"], "Expected the hot batch function to be called with its keys")
+	assert.Equal(t, []string{"3"}, calls["cold"], "Expected the cold batch function to be called with its keys")
+}
+
+// TestBatchRouterEmptyID ensures a batch function registered under the empty ID only resolves the keys routed
+// to it, while keys routed to an unknown ID are passed to the default batch function
+func TestBatchRouterEmptyID(t *testing.T) {
+	// setup
+	batchFor := func(name string) dataloader.BatchFunction {
+		return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+			result := dataloader.NewResultMap(keys.Length())
+			keys.ForEach(func(key dataloader.Key) bool {
+				result.Set(key, dataloader.Result{Result: name, Err: nil})
+				return true
+			})
+			return &result
+		}
+	}
+	router := func(key dataloader.Key) dataloader.BatchID {
+		if key.(PrimaryKey) == 1 {
+			return ""
+		}
+		return "unknown"
+	}
+	loader := dataloader.NewDataLoader(
+		2,
+		batchFor("default"),
+		newMockStrategy(),
+		dataloader.WithBatchRouter(router, map[dataloader.BatchID]dataloader.BatchFunction{
+			"": batchFor("empty"),
+		}),
+	)
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, "empty", r.GetValueForString("1").Result, "Expected the empty ID batch function to resolve the key")
+	assert.Equal(t, "default", r.GetValueForString("2").Result, "Expected unknown routes to use the default")
+}
@@ -0,0 +1,110 @@
+package dataloader
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// spilledResult is the gob encoded form of a Result without an error
+type spilledResult struct {
+	Result interface{}
+	Source Source
+}
+
+// spill is a ResultMap stored in a temporary file. Results with an error are kept in memory so that callers
+// can still match the error (e.g. with errors.Is). The file is removed once the spill is garbage collected.
+type spill struct {
+	path   string
+	failed ResultMap
+}
+
+// spillResultMap writes the results of the result map without an error to a temporary file in the directory
+func spillResultMap(dir string, r ResultMap) (*spill, error) {
+	f, err := ioutil.TempFile(dir, "dataloader-spill-")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	encoded := make(map[string]spilledResult, len(r))
+	failed := NewResultMap(0)
+	for k, v := range r {
+		if v.Err != nil {
+			failed[k] = v
+			continue
+		}
+		encoded[k] = spilledResult{Result: v.Result, Source: v.Source}
+	}
+	if err := gob.NewEncoder(f).Encode(encoded); err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	s := &spill{path: f.Name(), failed: failed}
+	runtime.SetFinalizer(s, func(s *spill) { os.Remove(s.path) })
+	return s, nil
+}
+
+// read decodes the spilled result map from the temporary file and adds the results with an error
+func (s *spill) read() (ResultMap, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var decoded map[string]spilledResult
+	if err := gob.NewDecoder(f).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	r := NewResultMap(len(decoded) + len(s.failed))
+	for k, v := range decoded {
+		r[k] = Result{Result: v.Result, Source: v.Source}
+	}
+	for k, v := range s.failed {
+		r[k] = v
+	}
+	return r, nil
+}
+
+// spillThunkMany returns a ThunkMany which resolves the thunk and, if the result map holds more than
+// threshold results, spills it to a temporary file and releases the thunk. The first call returns the
+// resolved result map; later calls read it back from the file instead of it being retained by the thunk.
+func (d *dataloader) spillThunkMany(keyArr []Key, thunk ThunkMany) ThunkMany {
+	var m sync.Mutex
+	var s *spill
+	return func() ResultMap {
+		m.Lock()
+		defer m.Unlock()
+
+		if s != nil {
+			r, err := s.read()
+			if err != nil {
+				d.logger.Error("failed to read spilled results", "path", s.path, "error", err)
+				r = NewResultMap(len(keyArr))
+				for _, key := range keyArr {
+					r.Set(key, Result{Result: nil, Err: err})
+				}
+			}
+			return r
+		}
+
+		r := thunk()
+		if len(r) <= d.spillThreshold {
+			return r
+		}
+
+		spilled, err := spillResultMap(d.spillDir, r)
+		if err != nil {
+			d.logger.Error("failed to spill results, keeping them in memory", "keys", len(r), "error", err)
+			return r
+		}
+		d.logger.Debug("spilled results", "keys", len(r), "path", spilled.path)
+		s, thunk = spilled, nil // release the result map held by the thunk
+		return r
+	}
+}
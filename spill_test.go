@@ -0,0 +1,107 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// spillBatch resolves each key to its string value, except key 3 which resolves to an error
+func spillBatch(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+	m := dataloader.NewResultMap(keys.Length())
+	for _, k := range keys.Keys() {
+		key := k.(PrimaryKey)
+		if key == 3 {
+			m.Set(key, dataloader.Result{Result: nil, Err: errors.New("not found")})
+			continue
+		}
+		m.Set(key, dataloader.Result{Result: key.String(), Err: nil})
+	}
+	return &m
+}
+
+// TestResultSpill ensures results beyond the threshold are read back from the spill file
+func TestResultSpill(t *testing.T) {
+	// setup
+	dir, err := ioutil.TempDir("", "spill")
+	if !assert.Nil(t, err, "Expected a temporary directory") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	loader := dataloader.NewDataLoader(3, spillBatch, newMockStrategy(), dataloader.WithResultSpill(dir, 2))
+
+	// invoke
+	thunk := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))
+	first := thunk()
+	files, _ := ioutil.ReadDir(dir)
+	second := thunk()
+
+	// assert
+	assert.Equal(t, 1, len(files), "Expected the results to be spilled")
+	assert.Equal(t, 3, second.Length(), "Expected every result to be read back")
+	assert.Equal(t, first.GetValueForString("1").Result, second.GetValueForString("1").Result,
+		"Expected the spilled result")
+	assert.Equal(t, first.GetValueForString("1").Source.BatchID, second.GetValueForString("1").Source.BatchID,
+		"Expected the spilled source")
+	assert.EqualError(t, second.GetValueForString("3").Err, "not found", "Expected the spilled error")
+}
+
+// TestResultSpillBelowThreshold ensures results within the threshold are not spilled
+func TestResultSpillBelowThreshold(t *testing.T) {
+	// setup
+	dir, err := ioutil.TempDir("", "spill")
+	if !assert.Nil(t, err, "Expected a temporary directory") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	loader := dataloader.NewDataLoader(2, spillBatch, newMockStrategy(), dataloader.WithResultSpill(dir, 2))
+
+	// invoke
+	r := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	files, _ := ioutil.ReadDir(dir)
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected both results")
+	assert.Equal(t, 0, len(files), "Expected the results not to be spilled")
+}
+
+// TestResultSpillErrorIdentity ensures errors read back from a spill still match the errors of the first call
+func TestResultSpillErrorIdentity(t *testing.T) {
+	// setup
+	dir, err := ioutil.TempDir("", "spill")
+	if !assert.Nil(t, err, "Expected a temporary directory") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		m.Set(PrimaryKey(1), dataloader.Result{Result: "1", Err: nil})
+		m.Set(PrimaryKey(2), dataloader.Result{Result: nil, Err: dataloader.ErrNotFound})
+		m.Set(PrimaryKey(3), dataloader.Result{Result: nil, Err: fmt.Errorf("load: %w", context.Canceled)})
+		return &m
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy(), dataloader.WithResultSpill(dir, 2))
+
+	// invoke
+	thunk := loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))
+	first := thunk()
+	second := thunk()
+
+	// assert
+	for i, r := range []dataloader.ResultMap{first, second} {
+		assert.Equal(t, "1", r.GetValueForString("1").Result, "Expected the result of call %d", i+1)
+		assert.ErrorIs(t, r.GetValueForString("2").Err, dataloader.ErrNotFound,
+			"Expected the not found error of call %d", i+1)
+		assert.ErrorIs(t, r.GetValueForString("3").Err, context.Canceled,
+			"Expected the cancelled error of call %d", i+1)
+	}
+}
@@ -0,0 +1,78 @@
+package dataloader
+
+import "context"
+
+// NewStaticLoader returns a DataLoader which serves results from the provided data set. The data set maps
+// each keys identifier (Key.String()) to its result. Only keys missing from the data set are passed to the
+// strategy and resolved by the fallback batch function. This is useful for reference data shipped with the
+// binary or loaded at startup. The data set is copied and is never modified by the loader.
+func NewStaticLoader(
+	data map[string]Result,
+	capacity int,
+	fallback BatchFunction,
+	fn StrategyFunction,
+	opts ...Option,
+) DataLoader {
+	loader := newDataLoader(capacity, fallback, fn, opts...)
+
+	static := NewResultMap(len(data))
+	for k, v := range data {
+		static[k] = v
+	}
+	loader.cache = &staticCache{data: static, cache: loader.cache}
+
+	return loader
+}
+
+// staticCache serves results from a read only data set before deferring to the wrapped cache
+type staticCache struct {
+	data  ResultMap
+	cache Cache
+}
+
+func (c *staticCache) SetResult(ctx context.Context, key Key, result Result) {
+	c.cache.SetResult(ctx, key, result)
+}
+
+func (c *staticCache) SetResultMap(ctx context.Context, resultMap ResultMap) {
+	c.cache.SetResultMap(ctx, resultMap)
+}
+
+func (c *staticCache) GetResult(ctx context.Context, key Key) (Result, bool) {
+	if r, ok := c.data.GetValue(key); ok {
+		return r, ok
+	}
+	return c.cache.GetResult(ctx, key)
+}
+
+func (c *staticCache) GetResultMap(ctx context.Context, keys ...Key) (ResultMap, bool) {
+	var missed []Key
+	result := NewResultMap(len(keys))
+	for _, key := range keys {
+		if r, ok := c.data.GetValue(key); ok {
+			result.Set(key, r)
+		} else {
+			missed = append(missed, key)
+		}
+	}
+
+	if len(missed) == 0 {
+		return result, true
+	}
+
+	r, ok := c.cache.GetResultMap(ctx, missed...)
+	for k, v := range r {
+		result[k] = v
+	}
+	return result, ok
+}
+
+// Delete removes the key from the wrapped cache. The static data set is not modified.
+func (c *staticCache) Delete(ctx context.Context, key Key) bool {
+	return c.cache.Delete(ctx, key)
+}
+
+// ClearAll clears the wrapped cache. The static data set is not modified.
+func (c *staticCache) ClearAll(ctx context.Context) bool {
+	return c.cache.ClearAll(ctx)
+}
@@ -0,0 +1,34 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStaticLoaderServesData ensures keys in the data set do not call the fallback batch function
+func TestStaticLoaderServesData(t *testing.T) {
+	// setup
+	callCount := 0
+	result := dataloader.Result{Result: "fallback", Err: nil}
+	cb := func() { callCount += 1 }
+	data := map[string]dataloader.Result{
+		"1": {Result: "static", Err: nil},
+	}
+
+	batch := getBatchFunction(cb, result)
+	loader := dataloader.NewStaticLoader(data, 1, batch, newMockStrategy())
+
+	// invoke / assert
+	r, ok := loader.Load(context.Background(), PrimaryKey(1))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "static", r.Result.(string), "Expected result from data set")
+	assert.Equal(t, 0, callCount, "Expected fallback batch function not to be called")
+
+	r, ok = loader.Load(context.Background(), PrimaryKey(2))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "fallback", r.Result.(string), "Expected result from fallback")
+	assert.Equal(t, 1, callCount, "Expected fallback batch function to be called")
+}
@@ -0,0 +1,42 @@
+package dataloader
+
+import "sync/atomic"
+
+// Stats is a snapshot of the loaders counters since it was created, e.g. to expose via expvar
+type Stats struct {
+	// Loads is the number of keys passed to Load and LoadMany
+	Loads uint64
+	// Batches is the number of calls to the batch function which have completed
+	Batches uint64
+	// AverageBatchSize is the average number of keys passed to the batch function. Zero if no batch
+	// has completed.
+	AverageBatchSize float64
+	// CacheHits is the number of keys resolved without calling the batch function
+	CacheHits uint64
+	// CacheMisses is the number of keys passed to the strategy
+	CacheMisses uint64
+	// Timeouts is the number of batches the current strategy executed because its timeout expired. Zero
+	// if the strategy doesn't implement Introspectable.
+	Timeouts uint64
+	// Saturations is the number of loads which found the key channel of the current strategies worker full.
+	// Zero if the strategy doesn't implement Introspectable.
+	Saturations uint64
+}
+
+// Stats returns a snapshot of the loaders counters. Stats is safe to call concurrently with loads.
+func (d *dataloader) Stats() Stats {
+	stats := Stats{
+		Loads:       atomic.LoadUint64(&d.loads),
+		Batches:     atomic.LoadUint64(&d.batches),
+		CacheHits:   atomic.LoadUint64(&d.cacheHits),
+		CacheMisses: atomic.LoadUint64(&d.cacheMisses),
+	}
+	if stats.Batches > 0 {
+		stats.AverageBatchSize = float64(atomic.LoadUint64(&d.batchedKeys)) / float64(stats.Batches)
+	}
+	if state, ok := d.StrategyState(); ok {
+		stats.Timeouts = state.Timeouts
+		stats.Saturations = state.Saturations
+	}
+	return stats
+}
@@ -0,0 +1,30 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStats ensures loads, batches and cache hits are counted
+func TestStats(t *testing.T) {
+	// setup
+	result := dataloader.Result{Result: "stats", Err: nil}
+	batch := getBatchFunction(func() {}, result)
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy(), dataloader.WithCache(newMockCache(5)))
+
+	// invoke
+	loader.Load(context.Background(), PrimaryKey(1))()
+	loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))()
+
+	// assert
+	stats := loader.Stats()
+	assert.Equal(t, uint64(4), stats.Loads, "Expected each key to be counted as a load")
+	assert.Equal(t, uint64(2), stats.Batches, "Expected two batches")
+	assert.Equal(t, 1.5, stats.AverageBatchSize, "Expected the average batch size")
+	assert.Equal(t, uint64(1), stats.CacheHits, "Expected the cached key to be counted as a hit")
+	assert.Equal(t, uint64(3), stats.CacheMisses, "Expected the uncached keys to be counted as misses")
+	assert.Equal(t, uint64(0), stats.Timeouts, "Expected no timeouts without an introspectable strategy")
+}
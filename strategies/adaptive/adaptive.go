@@ -0,0 +1,329 @@
+/*
+Package adaptive contains the implementation details for the adaptive strategy.
+
+The adaptive strategy tunes the number of keys which trigger the batch function to the
+observed load. After each call to the batch function the strategy updates a moving average
+of the rate at which keys arrive and of the batch function's latency. The capacity becomes
+the number of keys expected to arrive while a batch executes, so it grows under heavy load
+and shrinks under light load. A timeout bounds how long keys wait for the capacity to be hit.
+*/
+package adaptive
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// smoothing is the weight of the latest observation in the moving averages
+const smoothing = 0.3
+
+// options contains the configuration of the adaptive strategy
+type options struct {
+	timeout     time.Duration
+	budget      time.Duration
+	minCapacity int
+	maxCapacity int
+	clock       clock.Clock
+	logger      logger.Logger
+	hooks       dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewAdaptiveStrategy returns a new instance of the adaptive strategy.
+// The capacity passed to the strategy is the initial capacity, it is adjusted after each call to the batch
+// function within the bounds set by WithCapacityBounds. The batch function is called once the pending keys
+// hit the current capacity or after the timeout, whichever happens first.
+func NewAdaptiveStrategy(opts ...Option) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+
+		s := &adaptiveStrategy{
+			options: o,
+		}
+		s.capacity = s.clamp(float64(capacity))
+		s.keys = dataloader.NewKeys(s.capacity)
+		s.batchFunc = strategies.RecoverBatch(strategies.HookBatch(batch, o.hooks), o.logger)
+
+		return s
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithTimeout sets the maximum duration keys wait for the capacity to be hit. Defaults to 16 milliseconds.
+func WithTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.timeout = t
+	}
+}
+
+// WithCapacityBounds sets the minimum and maximum capacity. Defaults to 1 and 1000.
+func WithCapacityBounds(min, max int) Option {
+	return func(o *options) {
+		o.minCapacity = min
+		o.maxCapacity = max
+	}
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return func(o *options) {
+		o.budget = d
+	}
+}
+
+// WithClock sets the time source of the timeout, e.g. a clock.Mock in tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// ===========================================================================================================
+
+type adaptiveStrategy struct {
+	batchFunc dataloader.BatchFunction
+
+	m sync.Mutex
+	// capacity is the current number of keys which trigger the batch function
+	capacity int
+	// moving averages of the keys per second and the batch function latency (seconds)
+	rate    float64
+	latency float64
+
+	// pending keys and the channels of the callers waiting for them
+	keys        dataloader.Keys
+	subscribers []chan dataloader.ResultMap
+	ctxs        []context.Context // contexts of the pending loads
+	started     time.Time         // time of the first pending load
+	timer       *strategies.Timer
+	flushBy     time.Time // time the pending keys are flushed by
+	lastFlush   time.Time
+	timeouts    uint64
+
+	options options
+}
+
+// Load returns a Thunk for the specified Key. Calling the Thunk blocks until the batch function returns.
+func (s *adaptiveStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := s.enqueue(ctx, key)
+
+	var result dataloader.Result
+	var ok bool
+	var once sync.Once
+	return func() (dataloader.Result, bool) {
+		once.Do(func() {
+			result, ok = strategies.Wait(ctx, resultChan, key).GetValue(key)
+		})
+		return result, ok
+	}
+}
+
+// LoadMany returns a ThunkMany for the specified keys. Calling the ThunkMany blocks until the batch function
+// returns.
+func (s *adaptiveStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	resultChan := s.enqueue(ctx, keyArr...)
+
+	var resultMap dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			resultMap = strategies.BuildResultMap(keyArr, strategies.Wait(ctx, resultChan, keyArr...))
+		})
+		return resultMap
+	}
+}
+
+// LoadNoOp has no internal implementation since the adaptive strategy measures its capacity in keys
+func (*adaptiveStrategy) LoadNoOp(context.Context) {}
+
+// Flush calls the batch function with the pending keys without waiting for the capacity or the timeout
+func (s *adaptiveStrategy) Flush(context.Context) {
+	s.options.logger.Debug("flushing on demand")
+	s.flush()
+}
+
+// State returns a snapshot of the strategies internal state. Capacity is the current capacity. The adaptive
+// strategy has no background worker, the worker state is reported as running while keys are pending.
+func (s *adaptiveStrategy) State() dataloader.StrategyState {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	state := dataloader.WorkerNotRunning
+	switch {
+	case len(s.subscribers) > 0:
+		state = dataloader.WorkerRunning
+	case !s.lastFlush.IsZero():
+		state = dataloader.WorkerRan
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  s.keys.Length(),
+		Capacity:     s.capacity,
+		CounterValue: s.keys.Length(), // capacity is measured in keys
+		WorkerState:  state,
+		LastFlush:    s.lastFlush,
+		Timeouts:     s.timeouts,
+	}
+}
+
+// Name returns the name of the strategy
+func (*adaptiveStrategy) Name() string {
+	return "adaptive"
+}
+
+// ============================================== private =============================================
+
+// enqueue adds the keys to the pending batch and returns the channel on which the result is delivered
+func (s *adaptiveStrategy) enqueue(ctx context.Context, keyArr ...dataloader.Key) chan dataloader.ResultMap {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the flush
+
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.started = s.options.clock.Now()
+		s.flushBy = s.options.clock.Now().Add(s.options.timeout)
+		s.timer = strategies.AfterFunc(s.options.clock, s.options.timeout, func() {
+			s.options.logger.Debug("flushing after timeout", "timeout", s.options.timeout)
+			s.m.Lock()
+			s.timeouts++
+			s.m.Unlock()
+			s.flush()
+		})
+	}
+	if flushBy := strategies.EarliestFlush(ctx, s.options.clock, s.flushBy, s.options.budget); flushBy.Before(s.flushBy) {
+		// flush earlier to leave the batch function time to complete before the deadline of the load
+		s.flushBy = flushBy
+		if s.timer.Stop() { // unless the timer already fired
+			s.timer.Reset(flushBy.Sub(s.options.clock.Now()))
+		}
+	}
+	s.ctxs = append(s.ctxs, ctx)
+	s.keys.Append(keyArr...)
+	s.subscribers = append(s.subscribers, resultChan)
+	full := s.keys.Length() >= s.capacity
+	s.m.Unlock()
+
+	if full {
+		s.flush()
+	}
+
+	return resultChan
+}
+
+// flush calls the batch function in a background go routine with the pending keys and adjusts the
+// capacity once the batch function returns. The batch context stays live while any of the pending loads is
+// waiting (see strategies.BatchContext).
+func (s *adaptiveStrategy) flush() {
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.m.Unlock()
+		return
+	}
+
+	keys, subscribers, ctxs := s.keys, s.subscribers, s.ctxs
+	now := s.options.clock.Now()
+	waited := now.Sub(s.started)
+	s.keys = dataloader.NewKeys(s.capacity)
+	s.subscribers = nil
+	s.ctxs = nil
+	s.timer.Stop()
+	s.lastFlush = now
+	s.m.Unlock()
+
+	s.options.logger.Debug("flushing", "keys", keys.Length())
+	go func() {
+		ctx, cancel := strategies.BatchContext(ctxs...)
+		defer cancel()
+
+		started := s.options.clock.Now()
+		r := s.batchFunc(ctx, keys)
+		s.adjust(keys.Length(), waited, s.options.clock.Now().Sub(started))
+
+		for _, ch := range subscribers {
+			ch <- *r
+			close(ch)
+		}
+	}()
+}
+
+// adjust updates the moving averages with the observed batch and sets the capacity to the number of keys
+// expected to arrive during a call to the batch function
+func (s *adaptiveStrategy) adjust(size int, waited, latency time.Duration) {
+	// keys which arrived faster than the clock resolution are assumed to have arrived within a millisecond
+	if waited < time.Millisecond {
+		waited = time.Millisecond
+	}
+	rate := float64(size) / waited.Seconds()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.rate == 0 && s.latency == 0 { // first observation
+		s.rate, s.latency = rate, latency.Seconds()
+	} else {
+		s.rate = smoothing*rate + (1-smoothing)*s.rate
+		s.latency = smoothing*latency.Seconds() + (1-smoothing)*s.latency
+	}
+
+	capacity := s.clamp(math.Ceil(s.rate * s.latency))
+	if capacity != s.capacity {
+		s.options.logger.Debug("adjusting capacity", "from", s.capacity, "to", capacity)
+		s.capacity = capacity
+	}
+}
+
+// clamp bounds the capacity to the configured minimum and maximum
+func (s *adaptiveStrategy) clamp(capacity float64) int {
+	if capacity < float64(s.options.minCapacity) {
+		return s.options.minCapacity
+	}
+	if capacity > float64(s.options.maxCapacity) {
+		return s.options.maxCapacity
+	}
+	return int(capacity)
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.timeout = 16 * time.Millisecond
+	opts.budget = 5 * time.Millisecond
+	opts.minCapacity = 1
+	opts.maxCapacity = 1000
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
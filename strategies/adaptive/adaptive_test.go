@@ -0,0 +1,122 @@
+package adaptive_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies/adaptive"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== test constants =============================================
+const TEST_TIMEOUT time.Duration = time.Millisecond * 500
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a generic batch function which returns the provided result after the provided delay
+func getBatchFunction(delay time.Duration, result string) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		time.Sleep(delay)
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			m.Set(key, dataloader.Result{Result: fmt.Sprintf("%s_%s", key, result), Err: nil})
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestLoadTriggeredByCapacity ensures the batch function is called once the pending keys hit the capacity
+func TestLoadTriggeredByCapacity(t *testing.T) {
+	// setup
+	batch := getBatchFunction(0, "capacity")
+	strategy := adaptive.NewAdaptiveStrategy(adaptive.WithTimeout(TEST_TIMEOUT*5))(2, batch)
+
+	// invoke
+	start := time.Now()
+	strategy.Load(context.Background(), PrimaryKey(1))
+	r, ok := strategy.Load(context.Background(), PrimaryKey(2))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "2_capacity", r.Result, "Expected the result for the key")
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected the batch function to be called on capacity")
+}
+
+// TestStateGrowsCapacity ensures the capacity grows when many keys arrive during a slow batch
+func TestStateGrowsCapacity(t *testing.T) {
+	// setup
+	batch := getBatchFunction(20*time.Millisecond, "grow")
+	strategy := adaptive.NewAdaptiveStrategy(
+		adaptive.WithTimeout(TEST_TIMEOUT),
+		adaptive.WithCapacityBounds(1, 100),
+	)(1, batch)
+
+	keys := make([]dataloader.Key, 50)
+	for i := range keys {
+		keys[i] = PrimaryKey(i)
+	}
+
+	// invoke
+	r := strategy.LoadMany(context.Background(), keys...)()
+
+	// assert
+	assert.Equal(t, 50, r.Length(), "Expected results for every key")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.True(t, state.Capacity > 1, "Expected the capacity to grow")
+	assert.True(t, state.Capacity <= 100, "Expected the capacity to be bounded")
+}
+
+// TestStateShrinksCapacity ensures the capacity shrinks when few keys arrive before the timeout
+func TestStateShrinksCapacity(t *testing.T) {
+	// setup
+	batch := getBatchFunction(0, "shrink")
+	strategy := adaptive.NewAdaptiveStrategy(adaptive.WithTimeout(10*time.Millisecond))(50, batch)
+
+	// invoke
+	_, ok := strategy.Load(context.Background(), PrimaryKey(1))()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, 1, state.Capacity, "Expected the capacity to shrink to the minimum")
+	assert.Equal(t, uint64(1), state.Timeouts, "Expected the batch function to be called after the timeout")
+}
+
+// TestFlushOnClock ensures the timeout is measured by the clock passed to WithClock
+func TestFlushOnClock(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	batch := getBatchFunction(0, "clock")
+	strategy := adaptive.NewAdaptiveStrategy(adaptive.WithTimeout(time.Second), adaptive.WithClock(clk))(10, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	clk.BlockUntil(1) // wait for the timeout to be scheduled
+	pending := strategy.(dataloader.Introspectable).State().PendingKeys
+	clk.Add(time.Second)
+	r, ok := thunk()
+
+	// assert
+	assert.Equal(t, 1, pending, "Expected the key to be pending until the clock advanced")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1_clock", r.Result, "Expected the result for the key")
+}
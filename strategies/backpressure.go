@@ -0,0 +1,20 @@
+package strategies
+
+import (
+	"errors"
+
+	"github.com/andy9775/dataloader"
+)
+
+// ErrKeyChannelFull is the error of the keys dropped because the key channel of the strategies worker was
+// full (see options.Drop)
+var ErrKeyChannelFull = errors.New("dataloader: strategy key channel is full")
+
+// DroppedResultMap returns a ResultMap which resolves every key to ErrKeyChannelFull
+func DroppedResultMap(keyArr ...dataloader.Key) dataloader.ResultMap {
+	results := dataloader.NewResultMap(len(keyArr))
+	for _, k := range keyArr {
+		results.Set(k, dataloader.Result{Result: nil, Err: ErrKeyChannelFull})
+	}
+	return results
+}
@@ -0,0 +1,22 @@
+package strategies
+
+import (
+	"context"
+
+	"github.com/andy9775/dataloader"
+)
+
+// CancelledResult returns the result of a key whose load was cancelled. The result holds the error of the
+// context so that callers can distinguish cancelled loads from missing keys.
+func CancelledResult(ctx context.Context) dataloader.Result {
+	return dataloader.Result{Result: nil, Err: ctx.Err()}
+}
+
+// CancelledResultMap returns a ResultMap which resolves every key to the error of the cancelled context
+func CancelledResultMap(ctx context.Context, keyArr ...dataloader.Key) dataloader.ResultMap {
+	results := dataloader.NewResultMap(len(keyArr))
+	for _, k := range keyArr {
+		results.Set(k, CancelledResult(ctx))
+	}
+	return results
+}
@@ -0,0 +1,26 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCancelledResultMap ensures every key resolves to the error of the cancelled context
+func TestCancelledResultMap(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// invoke
+	r := strategies.CancelledResultMap(ctx, dataloader.StringKey("1"), dataloader.StringKey("2"))
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected a result for each key")
+	for _, k := range r.Keys() {
+		assert.Equal(t, context.Canceled, r.GetValueForString(k).Err, "Expected the context error")
+	}
+}
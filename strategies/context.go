@@ -0,0 +1,61 @@
+package strategies
+
+import (
+	"context"
+	"time"
+)
+
+// BatchContext returns the context to call the batch function with on behalf of loads made with the provided
+// contexts. The context carries the values of the first context and is done once every context is done, so
+// that a cancelled load doesn't fail the batch of the loads which are still waiting. If every context has a
+// deadline, the context has the latest of them. The cancel function must be called once the batch function
+// returns to release the resources of the context.
+func BatchContext(ctxs ...context.Context) (context.Context, context.CancelFunc) {
+	if len(ctxs) == 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	var latest time.Time
+	for _, c := range ctxs {
+		deadline, ok := c.Deadline()
+		if !ok {
+			latest = time.Time{}
+			break
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if latest.IsZero() {
+		ctx, cancel = context.WithCancel(detachedContext{ctxs[0]})
+	} else {
+		ctx, cancel = context.WithDeadline(detachedContext{ctxs[0]}, latest)
+	}
+
+	go func() {
+		for _, c := range ctxs {
+			select {
+			case <-c.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel() // every load is done
+	}()
+	return ctx, cancel
+}
+
+// ============================================== helpers =============================================
+
+// detachedContext carries the values of its parent without its deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
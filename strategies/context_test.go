@@ -0,0 +1,56 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+// TestBatchContextLive ensures the batch context stays live while any of the contexts is live
+func TestBatchContextLive(t *testing.T) {
+	// setup
+	first, cancelFirst := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "first"))
+	second, cancelSecond := context.WithCancel(context.Background())
+
+	// invoke
+	ctx, cancel := strategies.BatchContext(first, second)
+	defer cancel()
+	cancelFirst()
+
+	// assert
+	assert.Equal(t, "first", ctx.Value(ctxKey{}), "Expected the values of the first context")
+	assert.Never(t, func() bool { return ctx.Err() != nil }, 20*time.Millisecond, time.Millisecond,
+		"Expected the batch context to stay live")
+
+	cancelSecond()
+	assert.Eventually(t, func() bool { return ctx.Err() == context.Canceled }, time.Second, time.Millisecond,
+		"Expected the batch context to be cancelled once every context is done")
+}
+
+// TestBatchContextDeadline ensures the batch context has the latest deadline when every context has one
+func TestBatchContextDeadline(t *testing.T) {
+	// setup
+	now := time.Now()
+	first, cancelFirst := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancelFirst()
+	second, cancelSecond := context.WithDeadline(context.Background(), now.Add(2*time.Hour))
+	defer cancelSecond()
+
+	// invoke
+	ctx, cancel := strategies.BatchContext(first, second)
+	defer cancel()
+	withoutDeadline, cancelWithout := strategies.BatchContext(first, context.Background())
+	defer cancelWithout()
+
+	// assert
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok, "Expected a deadline")
+	assert.Equal(t, now.Add(2*time.Hour), deadline, "Expected the latest deadline")
+	_, ok = withoutDeadline.Deadline()
+	assert.False(t, ok, "Expected no deadline while a context has none")
+}
@@ -1,5 +1,7 @@
 package strategies
 
+import "sync/atomic"
+
 // Counter provides the interface to a Load call counter.
 // A Load call counter provides helper methods to track the
 // number of increments and identify when the increments equal
@@ -10,26 +12,32 @@ type Counter interface {
 	Increment() bool
 	// ResetCount resets the Load call counter back to 0
 	ResetCount()
+	// Count returns the current value of the Load call counter. Count is safe to call
+	// concurrently with Increment and ResetCount.
+	Count() int
 }
 
 // NewCounter returns a new instance of a Load call counter
 func NewCounter(capacity int) Counter {
 	return &counter{
-		capacity:  capacity,
+		capacity:  int64(capacity),
 		loadCalls: 0,
 	}
 }
 
 type counter struct {
-	loadCalls int
-	capacity  int
+	loadCalls int64 // accessed atomically
+	capacity  int64
 }
 
 func (c *counter) Increment() bool {
-	c.loadCalls++
-	return c.loadCalls >= c.capacity
+	return atomic.AddInt64(&c.loadCalls, 1) >= c.capacity
 }
 
 func (c *counter) ResetCount() {
-	c.loadCalls = 0
+	atomic.StoreInt64(&c.loadCalls, 0)
+}
+
+func (c *counter) Count() int {
+	return int(atomic.LoadInt64(&c.loadCalls))
 }
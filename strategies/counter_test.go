@@ -19,8 +19,10 @@ func TestCounter(t *testing.T) {
 	assert.False(t, counter.Increment(), "Counter should not have hit capacity")
 	assert.False(t, counter.Increment(), "Counter should not have hit capacity")
 	assert.True(t, counter.Increment(), "Counter should  have hit capacity")
+	assert.Equal(t, 3, counter.Count(), "Counter should have been incremented 3 times")
 
 	counter.ResetCount() // zero out
+	assert.Equal(t, 0, counter.Count(), "Counter should have been reset")
 
 	// capacity is 3, third increment should return true
 	assert.False(t, counter.Increment(), "Counter should not have hit capacity")
@@ -0,0 +1,55 @@
+package strategies
+
+import (
+	"context"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+)
+
+// FlushBy returns the latest time the keys loaded with the context can be passed to the batch function
+// while leaving the budget for the batch function to complete before the context deadline, and no later
+// than the timeout of the load (see dataloader.WithLoadTimeout). The time is relative to the clock of the
+// strategy, so context deadlines are expected in the same time frame, e.g. derived from a clock.Mock in
+// tests. FlushBy returns false if the context has neither.
+func FlushBy(ctx context.Context, clk clock.Clock, budget time.Duration) (time.Time, bool) {
+	var at time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		at = deadline.Add(-budget)
+	}
+	if o, ok := dataloader.LoadOptionsFromContext(ctx); ok && o.Timeout > 0 {
+		if timeout := clk.Now().Add(o.Timeout); at.IsZero() || timeout.Before(at) {
+			at = timeout
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// FlushTimeout returns the duration to wait before calling the batch function. The timeout is shortened so
+// that the batch function is called no later than flushBy according to the clock. A zero flushBy returns the
+// timeout unchanged.
+func FlushTimeout(clk clock.Clock, timeout time.Duration, flushBy time.Time) time.Duration {
+	if flushBy.IsZero() {
+		return timeout
+	}
+
+	left := flushBy.Sub(clk.Now())
+	if left < 0 {
+		return 0
+	}
+	if left < timeout {
+		return left
+	}
+	return timeout
+}
+
+// EarliestFlush returns the earlier of the current flush time and the flush time of keys loaded with the
+// context. A zero current flush time is treated as unset.
+func EarliestFlush(ctx context.Context, clk clock.Clock, current time.Time, budget time.Duration) time.Time {
+	at, ok := FlushBy(ctx, clk, budget)
+	if !ok || (!current.IsZero() && current.Before(at)) {
+		return current
+	}
+	return at
+}
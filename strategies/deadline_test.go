@@ -0,0 +1,95 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFlushTimeout ensures the timeout is shortened to meet the flush time of the clock
+func TestFlushTimeout(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+
+	// invoke / assert
+	assert.Equal(t, time.Second, strategies.FlushTimeout(clk, time.Second, time.Time{}), "Expected the timeout")
+	assert.Equal(
+		t,
+		time.Second,
+		strategies.FlushTimeout(clk, time.Second, clk.Now().Add(time.Hour)),
+		"Expected the timeout before a distant flush time",
+	)
+	assert.Equal(
+		t,
+		time.Duration(0),
+		strategies.FlushTimeout(clk, time.Second, clk.Now().Add(-time.Second)),
+		"Expected an immediate flush after the flush time",
+	)
+
+	flushBy := clk.Now().Add(100 * time.Millisecond)
+	clk.Add(40 * time.Millisecond)
+	assert.Equal(
+		t,
+		60*time.Millisecond,
+		strategies.FlushTimeout(clk, time.Second, flushBy),
+		"Expected the timeout to be shortened by the time left on the clock",
+	)
+}
+
+// TestEarliestFlush ensures the budget is reserved before the earliest context deadline
+func TestEarliestFlush(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	deadline := clk.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	later := deadline.Add(time.Second)
+
+	// invoke / assert
+	assert.Equal(
+		t,
+		deadline.Add(-10*time.Millisecond),
+		strategies.EarliestFlush(ctx, clk, time.Time{}, 10*time.Millisecond),
+		"Expected the flush time to leave the budget",
+	)
+	assert.Equal(
+		t,
+		deadline.Add(-10*time.Millisecond),
+		strategies.EarliestFlush(ctx, clk, later, 10*time.Millisecond),
+		"Expected the earlier flush time",
+	)
+	assert.Equal(
+		t,
+		time.Unix(1, 0),
+		strategies.EarliestFlush(ctx, clk, time.Unix(1, 0), 10*time.Millisecond),
+		"Expected the current flush time",
+	)
+	assert.Equal(
+		t,
+		later,
+		strategies.EarliestFlush(context.Background(), clk, later, 10*time.Millisecond),
+		"Expected contexts without a deadline to be ignored",
+	)
+}
+
+// TestFlushBy ensures the budget is reserved before the context deadline
+func TestFlushBy(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	deadline := clk.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	// invoke
+	at, ok := strategies.FlushBy(ctx, clk, 10*time.Millisecond)
+	_, noDeadline := strategies.FlushBy(context.Background(), clk, 10*time.Millisecond)
+
+	// assert
+	assert.True(t, ok, "Expected a flush time")
+	assert.Equal(t, deadline.Add(-10*time.Millisecond), at, "Expected the flush time to leave the budget")
+	assert.False(t, noDeadline, "Expected no flush time without a deadline or load timeout")
+}
@@ -0,0 +1,106 @@
+/*
+Package fallback contains the implementation details for the fallback strategy.
+
+The fallback strategy chains batch sources, e.g. cache only → database → remote API. The
+loaders batch function is the primary source. Keys which a source returns missing or with an
+error are passed to the next source, and the results of every source are merged into one
+ResultMap. Batching is delegated to the wrapped strategy, so the fallback sources are called
+once per batch with the keys the previous sources failed to resolve.
+*/
+package fallback
+
+import (
+	"context"
+
+	"github.com/andy9775/dataloader"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// options contains the configuration of the fallback strategy
+type options struct {
+	logger logger.Logger
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewFallbackStrategy returns a strategy which batches keys with the provided strategy and resolves each
+// batch with the loaders batch function, passing the keys it failed to resolve to each of the fallbacks in
+// order. A key which no source resolves keeps the last error returned for it, if any.
+func NewFallbackStrategy(
+	strategy dataloader.StrategyFunction,
+	fallbacks []dataloader.BatchFunction,
+	opts ...Option,
+) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+
+		return strategy(capacity, chain(batch, fallbacks, o.logger))
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// ============================================== private =============================================
+
+// chain returns a batch function which calls the primary batch function then each fallback with the keys
+// which the previous sources didn't resolve
+func chain(
+	primary dataloader.BatchFunction,
+	fallbacks []dataloader.BatchFunction,
+	l logger.Logger,
+) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		result := dataloader.NewResultMap(keys.Length())
+		merge(result, primary(ctx, keys))
+
+		for i, fallback := range fallbacks {
+			unresolved := failedKeys(keys, result)
+			if unresolved.IsEmpty() {
+				break
+			}
+
+			l.Debug("falling back", "source", i+1, "keys", unresolved.Length())
+			merge(result, fallback(ctx, unresolved))
+		}
+		return &result
+	}
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.logger = logger.Noop()
+}
+
+// merge copies the results into the result map
+func merge(result dataloader.ResultMap, r *dataloader.ResultMap) {
+	if r == nil {
+		return
+	}
+	result.Merge(*r)
+}
+
+// failedKeys returns the keys which are missing from the result map or resolved to an error
+func failedKeys(keys dataloader.Keys, result dataloader.ResultMap) dataloader.Keys {
+	return keys.Filter(func(key dataloader.Key) bool {
+		v, ok := result.GetValue(key)
+		return !ok || v.Err != nil
+	})
+}
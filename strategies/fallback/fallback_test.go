@@ -0,0 +1,75 @@
+package fallback_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies/fallback"
+	"github.com/andy9775/dataloader/strategies/standard"
+	"github.com/stretchr/testify/assert"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a batch function which resolves the keys in the results map, records the keys it
+// was called with and resolves every other key to the error, if any
+func getBatchFunction(results map[PrimaryKey]string, err error, called *[]string) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			*called = append(*called, key.String())
+			if v, ok := results[key]; ok {
+				m.Set(key, dataloader.Result{Result: v, Err: nil})
+			} else if err != nil {
+				m.Set(key, dataloader.Result{Result: nil, Err: err})
+			}
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestFallbackChain ensures keys missing or failed in a source are resolved by the next source
+func TestFallbackChain(t *testing.T) {
+	// setup
+	var cacheCalls, dbCalls, apiCalls []string
+	cache := getBatchFunction(map[PrimaryKey]string{1: "cache"}, nil, &cacheCalls)
+	db := getBatchFunction(map[PrimaryKey]string{2: "db"}, errors.New("db unavailable"), &dbCalls)
+	api := getBatchFunction(map[PrimaryKey]string{3: "api"}, nil, &apiCalls)
+
+	strategy := fallback.NewFallbackStrategy(
+		standard.NewStandardStrategy(standard.WithTimeout(10*time.Millisecond)),
+		[]dataloader.BatchFunction{db, api},
+	)(4, cache)
+
+	// invoke
+	r := strategy.LoadMany(
+		context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3), PrimaryKey(4),
+	)()
+
+	// assert
+	assert.Equal(t, "cache", r.GetValueForString("1").Result, "Expected the primary result")
+	assert.Equal(t, "db", r.GetValueForString("2").Result, "Expected the first fallbacks result")
+	assert.Equal(t, "api", r.GetValueForString("3").Result, "Expected the second fallbacks result")
+	assert.EqualError(t, r.GetValueForString("4").Err, "db unavailable", "Expected the last error")
+
+	assert.ElementsMatch(t, []string{"2", "3", "4"}, dbCalls, "Expected the keys missing from the cache")
+	assert.ElementsMatch(t, []string{"3", "4"}, apiCalls, "Expected the keys the db failed to resolve")
+}
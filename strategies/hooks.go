@@ -0,0 +1,43 @@
+package strategies
+
+import (
+	"context"
+	"time"
+
+	"github.com/andy9775/dataloader"
+)
+
+// HookBatch returns a batch function which calls the OnBatchStart and OnBatchEnd hooks around the batch
+// function. Strategies wrap the batch function with HookBatch when configured with hooks so that
+// strategies used outside of a DataLoader can be instrumented. The batch ID is reported if the batch
+// context carries one (see dataloader.BatchIDFromContext).
+func HookBatch(batch dataloader.BatchFunction, hooks dataloader.Hooks) dataloader.BatchFunction {
+	if hooks.OnBatchStart == nil && hooks.OnBatchEnd == nil {
+		return batch
+	}
+
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		id, _ := dataloader.BatchIDFromContext(ctx)
+		hooks.BatchStart(ctx, dataloader.BatchInfo{BatchID: id, Keys: keys, Size: keys.Length()})
+
+		started := time.Now()
+		r := batch(ctx, keys)
+
+		errCount := 0
+		if r != nil {
+			for _, v := range *r {
+				if v.Err != nil {
+					errCount++
+				}
+			}
+		}
+		hooks.BatchEnd(ctx, dataloader.BatchInfo{
+			BatchID:  id,
+			Keys:     keys,
+			Size:     keys.Length(),
+			Duration: time.Since(started),
+			Errors:   errCount,
+		})
+		return r
+	}
+}
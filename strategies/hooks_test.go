@@ -0,0 +1,42 @@
+package strategies_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHookBatch ensures the batch hooks are called around the batch function
+func TestHookBatch(t *testing.T) {
+	// setup
+	var calls []string
+	var end dataloader.BatchInfo
+	hooks := dataloader.Hooks{
+		OnBatchStart: func(ctx context.Context, info dataloader.BatchInfo) { calls = append(calls, "start") },
+		OnBatchEnd: func(ctx context.Context, info dataloader.BatchInfo) {
+			calls = append(calls, "end")
+			end = info
+		},
+	}
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		calls = append(calls, "batch")
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.StringKeys() {
+			r[k] = dataloader.Result{Result: nil, Err: errors.New("failure")}
+		}
+		return &r
+	}
+	keys := dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2"))
+
+	// invoke
+	strategies.HookBatch(batch, hooks)(context.Background(), keys)
+
+	// assert
+	assert.Equal(t, []string{"start", "batch", "end"}, calls, "Expected hooks around the batch function")
+	assert.Equal(t, 2, end.Size, "Expected batch size")
+	assert.Equal(t, 2, end.Errors, "Expected errors to be counted")
+}
@@ -0,0 +1,293 @@
+/*
+Package idle contains the implementation details for the idle strategy.
+
+The idle strategy approximates the event loop batching semantics of the javascript
+dataloader. Go routines which load data register with a Tracker and the strategy
+calls the batch function as soon as every registered go routine is blocked waiting
+on a Thunk or ThunkMany, rather than waiting for a capacity or a timeout to be hit.
+A timeout is still used as a fallback in case a participant never blocks or
+unregisters.
+*/
+package idle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// options contains the configuration of the idle strategy
+type options struct {
+	timeout time.Duration
+	budget  time.Duration
+	clock   clock.Clock
+	logger  logger.Logger
+	hooks   dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewIdleStrategy returns a new instance of the idle strategy.
+// The idle strategy calls the batch function once all participants registered with the tracker are blocked
+// waiting on a result, once the number of pending keys hits the capacity, or after the timeout, whichever
+// happens first. The strategy stays subscribed to the tracker until it is closed, so it must be closed (see
+// dataloader.Closer) once its loader is no longer used if the tracker outlives the loader.
+func NewIdleStrategy(tracker *Tracker, opts ...Option) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+
+		s := &idleStrategy{
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.hooks), o.logger),
+			tracker:   tracker,
+			capacity:  capacity,
+			options:   o,
+
+			keys: dataloader.NewKeys(capacity),
+		}
+		s.unsubscribe = tracker.subscribe(s.flush)
+
+		return s
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithTimeout sets the maximum duration keys wait for the participants to become idle. Defaults to 16
+// milliseconds.
+func WithTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.timeout = t
+	}
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return func(o *options) {
+		o.budget = d
+	}
+}
+
+// WithClock sets the time source of the timeout, e.g. a clock.Mock in tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// ===========================================================================================================
+
+type idleStrategy struct {
+	batchFunc   dataloader.BatchFunction
+	tracker     *Tracker
+	unsubscribe func() // removes the strategy from the tracker
+	capacity    int
+
+	m sync.Mutex
+	// pending keys and the channels of the callers waiting for them
+	keys        dataloader.Keys
+	subscribers []chan dataloader.ResultMap
+	ctxs        []context.Context // contexts of the pending loads
+	timer       *strategies.Timer
+	flushBy     time.Time // time the pending keys are flushed by
+	lastFlush   time.Time
+	timeouts    uint64
+
+	options options
+}
+
+// Load returns a Thunk for the specified Key. Calling the Thunk parks the caller with the tracker and blocks
+// until the batch function returns.
+func (s *idleStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := s.enqueue(ctx, key)
+
+	var result dataloader.Result
+	var ok bool
+	var once sync.Once
+	return func() (dataloader.Result, bool) {
+		once.Do(func() {
+			result, ok = s.wait(ctx, resultChan, key).GetValue(key)
+		})
+		return result, ok
+	}
+}
+
+// LoadMany returns a ThunkMany for the specified keys. Calling the ThunkMany parks the caller with the tracker
+// and blocks until the batch function returns.
+func (s *idleStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	resultChan := s.enqueue(ctx, keyArr...)
+
+	var resultMap dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			resultMap = strategies.BuildResultMap(keyArr, s.wait(ctx, resultChan, keyArr...))
+		})
+		return resultMap
+	}
+}
+
+// LoadNoOp has no internal implementation since the idle strategy doesn't track the number of calls to
+// Load or LoadMany
+func (*idleStrategy) LoadNoOp(context.Context) {}
+
+// Flush calls the batch function with the pending keys without waiting for the participants to block
+func (s *idleStrategy) Flush(context.Context) {
+	s.options.logger.Debug("flushing on demand")
+	s.flush()
+}
+
+// Close flushes the pending keys and unsubscribes the strategy from the tracker, so that a tracker shared
+// between loaders doesn't keep notifying strategies which are no longer used (e.g. after SwapStrategy)
+func (s *idleStrategy) Close() {
+	s.flush()
+	s.unsubscribe()
+}
+
+// State returns a snapshot of the strategies internal state. The idle strategy has no background worker,
+// the worker state is reported as running while keys are pending.
+func (s *idleStrategy) State() dataloader.StrategyState {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	state := dataloader.WorkerNotRunning
+	switch {
+	case len(s.subscribers) > 0:
+		state = dataloader.WorkerRunning
+	case !s.lastFlush.IsZero():
+		state = dataloader.WorkerRan
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  s.keys.Length(),
+		Capacity:     s.capacity,
+		CounterValue: s.keys.Length(), // capacity is measured in keys
+		WorkerState:  state,
+		LastFlush:    s.lastFlush,
+		Timeouts:     s.timeouts,
+	}
+}
+
+// Name returns the name of the strategy
+func (*idleStrategy) Name() string {
+	return "idle"
+}
+
+// ============================================== private =============================================
+
+// enqueue adds the keys to the pending batch and returns the channel on which the result is delivered
+func (s *idleStrategy) enqueue(ctx context.Context, keyArr ...dataloader.Key) chan dataloader.ResultMap {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the flush
+
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.flushBy = s.options.clock.Now().Add(s.options.timeout)
+		s.timer = strategies.AfterFunc(s.options.clock, s.options.timeout, func() {
+			s.options.logger.Debug("flushing after timeout", "timeout", s.options.timeout)
+			s.m.Lock()
+			s.timeouts++
+			s.m.Unlock()
+			s.flush()
+		})
+	}
+	if flushBy := strategies.EarliestFlush(ctx, s.options.clock, s.flushBy, s.options.budget); flushBy.Before(s.flushBy) {
+		// flush earlier to leave the batch function time to complete before the deadline of the load
+		s.flushBy = flushBy
+		if s.timer.Stop() { // unless the timer already fired
+			s.timer.Reset(flushBy.Sub(s.options.clock.Now()))
+		}
+	}
+	s.ctxs = append(s.ctxs, ctx)
+	s.keys.Append(keyArr...)
+	s.subscribers = append(s.subscribers, resultChan)
+	full := s.keys.Length() >= s.capacity
+	s.m.Unlock()
+
+	if full {
+		s.flush()
+	}
+
+	return resultChan
+}
+
+// wait parks the caller with the tracker until a result is delivered or the context is cancelled. The
+// keys resolve to the error of the context if it is cancelled.
+func (s *idleStrategy) wait(
+	ctx context.Context,
+	resultChan chan dataloader.ResultMap,
+	keyArr ...dataloader.Key,
+) dataloader.ResultMap {
+	s.tracker.park()
+	defer s.tracker.unpark()
+
+	return strategies.Wait(ctx, resultChan, keyArr...)
+}
+
+// flush calls the batch function in a background go routine with the pending keys. The batch context stays
+// live while any of the pending loads is waiting (see strategies.BatchContext).
+func (s *idleStrategy) flush() {
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.m.Unlock()
+		return
+	}
+
+	keys, subscribers, ctxs := s.keys, s.subscribers, s.ctxs
+	s.keys = dataloader.NewKeys(s.capacity)
+	s.subscribers = nil
+	s.ctxs = nil
+	s.timer.Stop()
+	s.lastFlush = s.options.clock.Now()
+	s.m.Unlock()
+
+	s.options.logger.Debug("flushing", "keys", keys.Length())
+	go func() {
+		ctx, cancel := strategies.BatchContext(ctxs...)
+		defer cancel()
+
+		r := s.batchFunc(ctx, keys)
+		for _, ch := range subscribers {
+			ch <- *r
+			close(ch)
+		}
+	}()
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.timeout = 16 * time.Millisecond
+	opts.budget = 5 * time.Millisecond
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
@@ -0,0 +1,252 @@
+package idle_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies/idle"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== test constants =============================================
+const TEST_TIMEOUT time.Duration = time.Millisecond * 500
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a generic batch function which returns the provided result and calls the provided
+// callback function
+func getBatchFunction(cb func(dataloader.Keys), result string) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		cb(keys)
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			m.Set(key, dataloader.Result{Result: fmt.Sprintf("%s_%s", key, result), Err: nil})
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestFlushWhenIdle ensures the batch function is called once every registered participant is blocked
+func TestFlushWhenIdle(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var calls []int
+	cb := func(keys dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		calls = append(calls, keys.Length())
+	}
+
+	tracker := idle.NewTracker()
+	batch := getBatchFunction(cb, "idle")
+	// timeout ensures the flush is not triggered by the fallback timer
+	strategy := idle.NewIdleStrategy(tracker, idle.WithTimeout(TEST_TIMEOUT*5))(10, batch)
+
+	// invoke
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	results := make([]dataloader.Result, 3)
+	for i := 0; i < 3; i++ {
+		done := tracker.Register()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer done()
+
+			thunk := strategy.Load(context.Background(), PrimaryKey(i))
+			results[i], _ = thunk()
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected batch to be called before the timeout")
+	assert.Equal(t, []int{3}, calls, "Expected a single batch with every key")
+	for i, r := range results {
+		assert.Equal(t, fmt.Sprintf("%d_idle", i), r.Result, "Expected result for key")
+	}
+}
+
+// TestFlushOnTimeout ensures the batch function is called after the timeout if participants never block
+func TestFlushOnTimeout(t *testing.T) {
+	// setup
+	tracker := idle.NewTracker()
+	done := tracker.Register()
+	defer done()
+	tracker.Register() // participant which never blocks
+
+	callCount := 0
+	batch := getBatchFunction(func(dataloader.Keys) { callCount += 1 }, "timeout")
+	strategy := idle.NewIdleStrategy(tracker, idle.WithTimeout(10*time.Millisecond))(10, batch)
+
+	// invoke
+	r := strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected results for both keys")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+}
+
+// TestFlushBeforeDeadline ensures the batch function is called with the budget left before the earliest
+// deadline of the pending loads rather than after the timeout
+func TestFlushBeforeDeadline(t *testing.T) {
+	// setup
+	tracker := idle.NewTracker()
+	tracker.Register() // participant which never blocks
+
+	callCount := 0
+	batch := getBatchFunction(func(dataloader.Keys) { callCount += 1 }, "deadline")
+	strategy := idle.NewIdleStrategy(
+		tracker,
+		idle.WithTimeout(TEST_TIMEOUT*5),
+		idle.WithDeadlineBudget(50*time.Millisecond),
+	)(10, batch)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// invoke
+	start := time.Now()
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	thunkMany := strategy.LoadMany(ctx, PrimaryKey(2))
+	r, ok := thunk()
+	rm := thunkMany()
+
+	// assert
+	assert.True(t, time.Since(start) < 100*time.Millisecond, "Expected batch to be called before the deadline")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_deadline", r.Result, "Expected result from the batch")
+	assert.Equal(t, 1, rm.Length(), "Expected result for LoadMany key")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+}
+
+// TestFlushOnDemand ensures flushing calls the batch function without waiting for participants to block
+func TestFlushOnDemand(t *testing.T) {
+	// setup
+	tracker := idle.NewTracker()
+	tracker.Register() // participant which never blocks
+
+	callCount := 0
+	batch := getBatchFunction(func(dataloader.Keys) { callCount += 1 }, "flushed")
+	strategy := idle.NewIdleStrategy(tracker, idle.WithTimeout(TEST_TIMEOUT*5))(10, batch)
+
+	// invoke
+	start := time.Now()
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	r, ok := thunk()
+
+	// assert
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected batch to be called before the timeout")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_flushed", r.Result, "Expected flushed result")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+}
+
+// TestState ensures the strategy reports pending keys and the last flush
+func TestState(t *testing.T) {
+	// setup
+	tracker := idle.NewTracker()
+	tracker.Register() // participant which never blocks
+
+	batch := getBatchFunction(func(dataloader.Keys) {}, "state")
+	strategy := idle.NewIdleStrategy(tracker, idle.WithTimeout(TEST_TIMEOUT*5))(10, batch)
+	introspectable := strategy.(dataloader.Introspectable)
+
+	// invoke / assert
+	state := introspectable.State()
+	assert.Equal(t, dataloader.WorkerNotRunning, state.WorkerState, "Expected no pending keys")
+	assert.Equal(t, 10, state.Capacity, "Expected capacity")
+
+	thunk := strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))
+	state = introspectable.State()
+	assert.Equal(t, 2, state.PendingKeys, "Expected pending keys")
+	assert.Equal(t, dataloader.WorkerRunning, state.WorkerState, "Expected pending batch")
+	assert.True(t, state.LastFlush.IsZero(), "Expected no flush")
+
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	thunk()
+	state = introspectable.State()
+	assert.Equal(t, 0, state.PendingKeys, "Expected keys to be flushed")
+	assert.Equal(t, dataloader.WorkerRan, state.WorkerState, "Expected batch to have run")
+	assert.False(t, state.LastFlush.IsZero(), "Expected flush time")
+}
+
+// TestCloseUnsubscribes ensures a closed strategy flushes its pending keys and is no longer notified by the
+// tracker it shares with other strategies
+func TestCloseUnsubscribes(t *testing.T) {
+	// setup
+	tracker := idle.NewTracker()
+	var m sync.Mutex
+	callCount := 0
+	batch := getBatchFunction(func(dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		callCount += 1
+	}, "closed")
+	closed := idle.NewIdleStrategy(tracker, idle.WithTimeout(TEST_TIMEOUT*5))(10, batch)
+	idle.NewIdleStrategy(tracker, idle.WithTimeout(TEST_TIMEOUT*5))(10, batch) // remains subscribed
+
+	// invoke
+	pending := closed.Load(context.Background(), PrimaryKey(1))
+	closed.(dataloader.Closer).Close()
+	r, ok := pending()
+
+	done := tracker.Register()
+	thunk := closed.Load(context.Background(), PrimaryKey(2))
+	resolved := make(chan struct{})
+	go func() {
+		thunk() // parks the only participant, which notifies the subscribed strategies
+		close(resolved)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	state := closed.(dataloader.Introspectable).State()
+	closed.(dataloader.Flusher).Flush(context.Background())
+	<-resolved
+	done()
+
+	// assert
+	assert.True(t, ok, "Expected the pending key to be flushed by Close")
+	assert.Equal(t, "1_closed", r.Result, "Expected flushed result")
+	assert.Equal(t, 1, state.PendingKeys, "Expected the closed strategy not to be flushed by the tracker")
+	assert.Equal(t, 2, callCount, "Expected batch function to be called by Close and Flush")
+}
+
+// TestFlushOnClock ensures the timeout is measured by the clock passed to WithClock
+func TestFlushOnClock(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	batch := getBatchFunction(func(dataloader.Keys) {}, "clock")
+	strategy := idle.NewIdleStrategy(idle.NewTracker(), idle.WithTimeout(time.Second), idle.WithClock(clk))(10, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	clk.BlockUntil(1) // wait for the timeout to be scheduled
+	pending := strategy.(dataloader.Introspectable).State().PendingKeys
+	clk.Add(time.Second)
+	r, ok := thunk()
+
+	// assert
+	assert.Equal(t, 1, pending, "Expected the key to be pending until the clock advanced")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1_clock", r.Result, "Expected the result for the key")
+}
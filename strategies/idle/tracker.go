@@ -0,0 +1,106 @@
+package idle
+
+import "sync"
+
+// Tracker tracks the go routines participating in the resolution of a request (for example the
+// resolvers of a GraphQL query). Each participant registers with the tracker and is considered
+// parked while blocked in a Thunk or ThunkMany returned by an idle strategy. Once every registered
+// participant is parked, the tracker notifies each idle strategy using it to flush its pending keys.
+// A Tracker is safe for concurrent use and may be shared between multiple loaders.
+type Tracker struct {
+	m          sync.Mutex
+	registered int
+	parked     int
+	listeners  []*listener
+}
+
+// listener is a subscription to the tracker. Listeners are compared by pointer when unsubscribed.
+type listener struct {
+	fn func()
+}
+
+// NewTracker returns a new instance of a Tracker
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Register registers the calling go routine as a participant. The returned function must be called once
+// the participant is done loading data. Calling the returned function more than once has no effect.
+func (t *Tracker) Register() func() {
+	t.m.Lock()
+	t.registered++
+	t.m.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.m.Lock()
+			t.registered--
+			listeners := t.idleListeners()
+			t.m.Unlock()
+
+			notify(listeners)
+		})
+	}
+}
+
+// ============================================== private =============================================
+
+// subscribe adds a listener which is called when all participants are parked. The returned function removes
+// the listener, calling it more than once has no effect.
+func (t *Tracker) subscribe(fn func()) (unsubscribe func()) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	l := &listener{fn: fn}
+	t.listeners = append(t.listeners, l)
+	return func() {
+		t.m.Lock()
+		defer t.m.Unlock()
+
+		for i, other := range t.listeners {
+			if other == l {
+				t.listeners = append(t.listeners[:i:i], t.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// park marks a participant as blocked waiting on a result
+func (t *Tracker) park() {
+	t.m.Lock()
+	t.parked++
+	listeners := t.idleListeners()
+	t.m.Unlock()
+
+	notify(listeners)
+}
+
+// unpark marks a participant as no longer blocked
+func (t *Tracker) unpark() {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.parked--
+}
+
+// idleListeners returns the listeners to notify if all participants are parked. Must be called with the
+// lock held.
+func (t *Tracker) idleListeners() []func() {
+	if t.parked == 0 || t.parked < t.registered {
+		return nil
+	}
+
+	listeners := make([]func(), len(t.listeners))
+	for i, l := range t.listeners {
+		listeners[i] = l.fn
+	}
+	return listeners
+}
+
+func notify(listeners []func()) {
+	for _, fn := range listeners {
+		fn()
+	}
+}
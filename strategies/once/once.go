@@ -10,62 +10,78 @@ package once
 
 import (
 	"context"
+	"sync"
+	"time"
 
-	"github.com/go-log/log"
+	"github.com/andy9775/dataloader/logger"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/andy9775/dataloader/strategies/options"
 )
 
-// Options contains the strategy configuration
-type options struct {
-	inBackground bool
-	logger       log.Logger
-}
-
-// Option accepts the dataloader and sets an option on it.
-type Option func(*options)
+// Option accepts the strategy options and sets an option on them. Options from the options package are
+// accepted by the standard, sozu and once strategies.
+type Option = options.Option
 
 // NewOnceStrategy returns a new instance of the once strategy.
 // The Once strategy calls the batch function for each call to the Thunk if InBackground is false.
 // Otherwise it runs the batch function in a background go routine and blocks calls to Thunk or
 // ThunkMany if the result is not yet fetched.
 func NewOnceStrategy(opts ...Option) dataloader.StrategyFunction {
-	return func(_ int, batch dataloader.BatchFunction) dataloader.Strategy {
-		o := options{}
-		formatOptions(&o)
-
-		// format options
-		for _, apply := range opts {
-			apply(&o)
-		}
-
-		return &onceStrategy{
-			batchFunc: batch,
-			options:   o,
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// the batch function isn't bounded unless a timeout is configured
+		o := options.New(append([]Option{options.WithTimeout(0)}, opts...)...)
+
+		s := &onceStrategy{options: o}
+		s.batchFunc = strategies.RecoverBatch(strategies.HookBatch(batch, o.Hooks), o.Logger)
+		if o.Memoize {
+			s.memo = dataloader.NewResultMap(capacity)
+			s.batchFunc = s.memoize(s.batchFunc)
 		}
+		return s
 	}
 }
 
 type onceStrategy struct {
 	batchFunc dataloader.BatchFunction
 
-	options options
+	// results of the keys loaded by the strategy if memoization is enabled
+	memoMutex sync.Mutex
+	memo      dataloader.ResultMap
+
+	options options.Options
 }
 
 // ============================================== option setters =============================================
 
 // WithInBackground configures the strategy to load in the background
 func WithInBackground() Option {
-	return func(o *options) {
-		o.inBackground = true
-	}
+	return options.WithInBackground()
+}
+
+// WithTimeout bounds how long the Thunk and ThunkMany wait for the batch function when it is called in the
+// background. The batch function's context is cancelled and the keys resolve to context.DeadlineExceeded once
+// the timeout expires. Defaults to no timeout.
+func WithTimeout(t time.Duration) Option {
+	return options.WithTimeout(t)
+}
+
+// WithMemoization configures the strategy to return the result of a key which it already loaded instead
+// of calling the batch function again, e.g. for repeated loads of a key during a request. Results of
+// cancelled loads aren't memoized.
+func WithMemoization() Option {
+	return options.WithMemoization()
 }
 
 // WithLogger configures the logger for the strategy. Default is a no op logger.
-func WithLogger(l log.Logger) Option {
-	return func(o *options) {
-		o.logger = l
-	}
+func WithLogger(l logger.Logger) Option {
+	return options.WithLogger(l)
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return options.WithHooks(h)
 }
 
 // ===========================================================================================================
@@ -74,6 +90,14 @@ func WithLogger(l log.Logger) Option {
 // background go routine (blocking if no data is available). Note that if the strategy is configured to
 // run in the background, calling Load again will spin up another background go routine.
 func (s *onceStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	if s.options.InBackground {
+		thunkMany := s.background(ctx, key)
+
+		// call batch in background and block util it returns
+		return func() (dataloader.Result, bool) {
+			return thunkMany().GetValue(key)
+		}
+	}
 
 	type data struct {
 		r  dataloader.Result
@@ -81,26 +105,6 @@ func (s *onceStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.
 	}
 	var result data
 
-	if s.options.inBackground {
-		resultChan := make(chan data)
-
-		// don't check if result is nil before starting in case a new key is passed in
-		go func() {
-			r, ok := (*s.batchFunc(ctx, dataloader.NewKeysWith(key))).GetValue(key)
-			resultChan <- data{r, ok}
-		}()
-
-		// call batch in background and block util it returns
-		return func() (dataloader.Result, bool) {
-			if result.r.Result != nil || result.r.Err != nil {
-				return result.r, result.ok
-			}
-
-			result = <-resultChan
-			return result.r, result.ok
-		}
-	}
-
 	// call batch when thunk is called
 	return func() (dataloader.Result, bool) {
 		if result.ok {
@@ -116,32 +120,12 @@ func (s *onceStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.
 // a background go routine (blocking if no data is available). Note that calling load many again if configured
 // to run in the background will cause the background worker to execute once more.
 func (s *onceStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
-	var result dataloader.ResultMap
-
-	if s.options.inBackground {
-		resultChan := make(chan dataloader.ResultMap)
-
-		// don't check if result is nil before starting in case a new key is passed in
-		go func() {
-			resultChan <- *s.batchFunc(ctx, dataloader.NewKeysWith(keyArr...))
-		}()
-
-		// call batch in background and block util it returnsS
-		return func() dataloader.ResultMap {
-			if result != nil {
-				return result
-			}
-
-			select {
-			case <-ctx.Done():
-				s.options.logger.Log("worker cancelled")
-				return dataloader.NewResultMap(0)
-			case result = <-resultChan:
-				return result
-			}
-		}
+	if s.options.InBackground {
+		return s.background(ctx, keyArr...)
 	}
 
+	var result dataloader.ResultMap
+
 	// call batch when thunk is called
 	return func() dataloader.ResultMap {
 		if result != nil {
@@ -158,10 +142,94 @@ func (s *onceStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) d
 // Load or Loadmany
 func (*onceStrategy) LoadNoOp(context.Context) {}
 
-// ================================================= helpers =================================================
+// Name returns the name of the strategy
+func (*onceStrategy) Name() string {
+	return "once"
+}
+
+// ============================================== private =============================================
+
+// background calls the batch function in a background go routine and returns a ThunkMany which blocks until
+// the batch function returns. The keys resolve to the error of the context if the context is done or the
+// timeout expires first.
+func (s *onceStrategy) background(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	batchCtx, cancel := context.WithCancel(ctx)
+	if s.options.Timeout > 0 {
+		batchCtx, cancel = context.WithTimeout(ctx, s.options.Timeout)
+	}
+
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block if the caller gave up
+	s.options.Logger.Debug("calling batch function in background", "keys", len(keyArr))
+	go func() {
+		resultChan <- *s.batchFunc(batchCtx, dataloader.NewKeysWith(keyArr...))
+	}()
+
+	var result dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			defer cancel()
+
+			// return a result which is already available unless the caller's context is done
+			if ctx.Err() == nil {
+				select {
+				case result = <-resultChan:
+					return
+				default:
+				}
+			}
 
-// formatOptions configures the default values for the loader
-func formatOptions(opts *options) {
-	opts.inBackground = false
-	opts.logger = log.DefaultLogger
+			select {
+			case result = <-resultChan:
+			case <-batchCtx.Done():
+				if ctx.Err() != nil {
+					s.options.Logger.Info("worker cancelled", "keys", len(keyArr), "error", ctx.Err())
+				} else {
+					s.options.Logger.Info("worker timed out", "keys", len(keyArr), "timeout", s.options.Timeout)
+				}
+				result = strategies.CancelledResultMap(batchCtx, keyArr...)
+			}
+		})
+		return result
+	}
+}
+
+// memoize returns a batch function which returns the memoized results of the keys which were already loaded
+// and calls the batch function with the remaining keys
+func (s *onceStrategy) memoize(batch dataloader.BatchFunction) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		results := dataloader.NewResultMap(keys.Length())
+		missing := dataloader.NewKeys(keys.Length())
+
+		s.memoMutex.Lock()
+		keys.ForEach(func(key dataloader.Key) bool {
+			if r, ok := s.memo.GetValue(key); ok {
+				results.Set(key, r)
+			} else {
+				missing.Append(key)
+			}
+			return true
+		})
+		s.memoMutex.Unlock()
+
+		if missing.IsEmpty() {
+			s.options.Logger.Debug("returning memoized results", "keys", keys.Length())
+			return &results
+		}
+
+		r := batch(ctx, missing)
+		if r == nil {
+			return &results
+		}
+
+		s.memoMutex.Lock()
+		defer s.memoMutex.Unlock()
+		for k, v := range *r {
+			results[k] = v
+			if v.Err == nil || v.Err != ctx.Err() { // don't memoize cancelled loads
+				s.memo[k] = v
+			}
+		}
+		return &results
+	}
 }
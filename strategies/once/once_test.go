@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/logger"
 	"github.com/andy9775/dataloader/strategies/once"
 	"github.com/stretchr/testify/assert"
 )
@@ -55,41 +56,6 @@ func timeout(t *testing.T, timeoutChannel chan struct{}, after time.Duration) {
 	}()
 }
 
-type mockLogger struct {
-	logMsgs []string
-	m       sync.Mutex
-}
-
-func (l *mockLogger) Log(v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	for _, value := range v {
-		switch i := value.(type) {
-		case string:
-			l.logMsgs = append(l.logMsgs, i)
-		default:
-			panic("mock logger only takes single log string")
-		}
-	}
-}
-
-func (l *mockLogger) Logf(format string, v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	l.logMsgs = append(l.logMsgs, fmt.Sprintf(format, v...))
-}
-
-func (l *mockLogger) Messages() []string {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	result := make([]string, len(l.logMsgs))
-	copy(result, l.logMsgs)
-	return result
-}
-
 // ================================================== tests ==================================================
 
 // ========================= foreground calls =========================
@@ -157,6 +123,124 @@ func TestBatchLoadManyInForegroundCalled(t *testing.T) {
 	assert.Equal(t, expectedResult, returned.Result.(string), "Expected result from batch function")
 }
 
+// TestMemoization asserts that the once strategy returns the result of a key it already loaded without
+// calling the batch function again when memoization is enabled
+func TestMemoization(t *testing.T) {
+	// setup
+	callCount := 0
+	expectedResult := "result_memoized"
+	cb := func() { callCount += 1 }
+
+	key := PrimaryKey(1)
+	result := dataloader.Result{Result: expectedResult, Err: nil}
+
+	batch := getBatchFunction(cb, result)
+	strategy := once.NewOnceStrategy(once.WithMemoization())(5, batch)
+
+	// invoke/assert
+
+	r, ok := strategy.Load(context.Background(), key)()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, callCount, "Batch function expected to be called on thunk()")
+	assert.Equal(t, expectedResult, r.Result.(string), "Expected result from batch function")
+
+	r, ok = strategy.Load(context.Background(), key)()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, callCount, "Batch function not expected to be called for a memoized key")
+	assert.Equal(t, expectedResult, r.Result.(string), "Expected memoized result")
+
+	returned, ok := strategy.LoadMany(context.Background(), key)().GetValue(key)
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, callCount, "Batch function not expected to be called for a memoized key")
+	assert.Equal(t, expectedResult, returned.Result.(string), "Expected memoized result")
+
+	r, ok = strategy.Load(context.Background(), PrimaryKey(2))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 2, callCount, "Batch function expected to be called for a new key")
+}
+
+// TestMemoizationStructKey asserts that the once strategy passes and memoizes keys whose raw value isn't a
+// Key, e.g. a StructKey
+func TestMemoizationStructKey(t *testing.T) {
+	// setup
+	type query struct{ ID int }
+	callCount := 0
+	key := dataloader.StructKey(query{ID: 1})
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		callCount += 1
+		m := dataloader.NewResultMap(keys.Length())
+		keys.ForEach(func(k dataloader.Key) bool {
+			m.Set(k, dataloader.Result{Result: k.Raw().(query).ID, Err: nil})
+			return true
+		})
+		return &m
+	}
+	strategy := once.NewOnceStrategy(once.WithMemoization())(5, batch)
+
+	// invoke/assert
+	r, ok := strategy.Load(context.Background(), key)()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, callCount, "Batch function expected to be called on thunk()")
+	assert.Equal(t, 1, r.Result, "Expected result from batch function")
+
+	r, ok = strategy.Load(context.Background(), dataloader.StructKey(query{ID: 1}))()
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, 1, callCount, "Batch function not expected to be called for a memoized key")
+	assert.Equal(t, 1, r.Result, "Expected memoized result")
+}
+
+// TestMemoizationDisabled asserts that the once strategy calls the batch function on every load of a key
+// by default
+func TestMemoizationDisabled(t *testing.T) {
+	// setup
+	callCount := 0
+	cb := func() { callCount += 1 }
+
+	key := PrimaryKey(1)
+	result := dataloader.Result{Result: "result_not_memoized", Err: nil}
+
+	batch := getBatchFunction(cb, result)
+	strategy := once.NewOnceStrategy()(5, batch)
+
+	// invoke
+	strategy.Load(context.Background(), key)()
+	strategy.Load(context.Background(), key)()
+
+	// assert
+	assert.Equal(t, 2, callCount, "Batch function expected to be called on every load")
+}
+
+// TestMemoizationCancelled asserts that the once strategy doesn't memoize the result of a cancelled load
+func TestMemoizationCancelled(t *testing.T) {
+	// setup
+	callCount := 0
+	cb := func() { callCount += 1 }
+
+	key := PrimaryKey(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		cb()
+		m := dataloader.NewResultMap(1)
+		m.Set(key, dataloader.Result{Result: "result", Err: ctx.Err()})
+		return &m
+	}
+	strategy := once.NewOnceStrategy(once.WithMemoization())(5, batch)
+
+	// invoke
+	r, _ := strategy.Load(ctx, key)()
+	assert.Equal(t, context.Canceled, r.Err, "Expected the cancelled result")
+
+	r, ok := strategy.Load(context.Background(), key)()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Nil(t, r.Err, "Expected the cancelled result not to be memoized")
+	assert.Equal(t, 2, callCount, "Batch function expected to be called again after a cancelled load")
+}
+
 // ========================= background calls =========================
 /*
 blockWG is used as a shortcut to assert that the Load/LoadMany calls don't block the caller and do return a
@@ -266,10 +350,10 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	/*
 		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
 	*/
-	log := mockLogger{logMsgs: make([]string, 2), m: sync.Mutex{}}
+	log := logger.Capture()
 	batch := getBatchFunction(cb, result)
 	strategy := once.NewOnceStrategy(
-		once.WithLogger(&log),
+		once.WithLogger(log),
 		once.WithInBackground(),
 	)(2, batch) // expected 2 load calls
 	ctx, cancel := context.WithCancel(context.Background())
@@ -285,6 +369,66 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	assert.Equal(t, "worker cancelled", m[len(m)-1], "Expected worker to cancel and log exit")
 }
 
+// TestCancellableContextLoad ensures that a call to cancel the context resolves the key to the context error
+func TestCancellableContextLoad(t *testing.T) {
+	// setup
+	block := make(chan struct{})
+	defer close(block)
+
+	log := logger.Capture()
+	batch := getBatchFunction(func() { <-block }, dataloader.Result{Result: "cancel_via_context", Err: nil})
+	strategy := once.NewOnceStrategy(
+		once.WithLogger(log),
+		once.WithInBackground(),
+	)(2, batch)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// invoke
+	thunk := strategy.Load(ctx, PrimaryKey(1))
+	cancel()
+	r, ok := thunk()
+
+	// assert
+	assert.True(t, ok, "Expected the cancelled key to resolve")
+	assert.Equal(t, context.Canceled, r.Err, "Expected the context error")
+	assert.Contains(t, log.Messages(), "worker cancelled", "Expected worker to cancel and log exit")
+}
+
+// TestBackgroundTimeout ensures the timeout bounds the batch function called in the background
+func TestBackgroundTimeout(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batchCtx := make(chan context.Context, 2)
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		batchCtx <- ctx
+		<-ctx.Done()
+		m := dataloader.NewResultMap(0)
+		return &m
+	}
+	log := logger.Capture()
+	strategy := once.NewOnceStrategy(
+		once.WithLogger(log),
+		once.WithInBackground(),
+		once.WithTimeout(10*time.Millisecond),
+	)(2, batch)
+
+	// invoke
+	r, ok := strategy.Load(context.Background(), PrimaryKey(1))()
+	rm := strategy.LoadMany(context.Background(), PrimaryKey(2))()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected the timed out key to resolve")
+	assert.Equal(t, context.DeadlineExceeded, r.Err, "Expected the deadline error")
+	v, ok := rm.GetValue(PrimaryKey(2))
+	assert.True(t, ok, "Expected the timed out key to resolve")
+	assert.Equal(t, context.DeadlineExceeded, v.Err, "Expected the deadline error")
+	assert.Error(t, (<-batchCtx).Err(), "Expected the batch context to be done")
+	assert.Contains(t, log.Messages(), "worker timed out", "Expected the timeout to be logged")
+}
+
 // =============================================== result keys ===============================================
 // TestKeyHandling ensures that processed and unprocessed keys by the batch function are handled correctly
 // This test accomplishes this by skipping processing a single key and then asserts that they skipped key
@@ -0,0 +1,185 @@
+/*
+Package options contains the functional options shared by the standard, sozu and once strategies.
+
+The strategies accept the same Option type so that a configuration can be passed to any of them,
+e.g. when swapping strategies, without being rewritten. Options which don't apply to a strategy
+are ignored by it, e.g. the once strategy ignores the timeout.
+*/
+package options
+
+import (
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/logger"
+)
+
+// Options contains the strategy configuration
+type Options struct {
+	// Timeout is the maximum duration keys wait before the batch function is called
+	Timeout time.Duration
+	// DeadlineBudget is the time left for the batch function to complete before the earliest deadline of
+	// the pending loads
+	DeadlineBudget time.Duration
+	// InBackground calls the batch function in a background go routine instead of when the Thunk or
+	// ThunkMany is called
+	InBackground bool
+	// Memoize returns the result of a key which was already loaded by the strategy instead of calling the
+	// batch function again
+	Memoize bool
+	// FlushThreshold is the number of load calls which trigger the batch function in the rounds following
+	// the first one. Zero uses the capacity.
+	FlushThreshold int
+	// IdleTimeout is the maximum duration the worker waits for another load call before calling the batch
+	// function with the pending keys. Zero disables it.
+	IdleTimeout time.Duration
+	// Backpressure is the policy applied to loads made while the key channel of the worker is full
+	Backpressure Backpressure
+	// KeyDedup determines which keys of a batch are duplicates
+	KeyDedup dataloader.KeyDedup
+	// Clock is the time source of the strategies timeouts
+	Clock clock.Clock
+	// Logger receives the strategies log entries
+	Logger logger.Logger
+	// Hooks are invoked around each call to the batch function
+	Hooks dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*Options)
+
+// Backpressure is the policy applied to loads made while the key channel of the strategies worker is full,
+// e.g. because the worker is calling the batch function
+type Backpressure int
+
+// backpressure policies
+const (
+	// Block blocks the caller of Load until the worker receives the keys
+	Block Backpressure = iota
+	// Grow buffers the keys until the worker receives them without blocking the caller
+	Grow
+	// Drop resolves the keys to strategies.ErrKeyChannelFull without calling the batch function
+	Drop
+	// Flush calls the batch function immediately with the keys of the load
+	Flush
+)
+
+// String returns the name of the policy
+func (b Backpressure) String() string {
+	switch b {
+	case Block:
+		return "block"
+	case Grow:
+		return "grow"
+	case Drop:
+		return "drop"
+	case Flush:
+		return "flush"
+	default:
+		return "unknown"
+	}
+}
+
+// New returns the default options with the provided options applied
+func New(opts ...Option) Options {
+	o := Options{
+		Timeout:        16 * time.Millisecond,
+		DeadlineBudget: 5 * time.Millisecond,
+		Clock:          clock.New(),
+		Logger:         logger.Noop(),
+	}
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+	return o
+}
+
+// ============================================== option setters =============================================
+
+// WithTimeout sets the timeout value for the strategy. Defaults to 16 milliseconds.
+func WithTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = t
+	}
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return func(o *Options) {
+		o.DeadlineBudget = d
+	}
+}
+
+// WithInBackground configures the strategy to load in the background
+func WithInBackground() Option {
+	return func(o *Options) {
+		o.InBackground = true
+	}
+}
+
+// WithMemoization configures the strategy to return the result of a key which it already loaded instead
+// of calling the batch function again
+func WithMemoization() Option {
+	return func(o *Options) {
+		o.Memoize = true
+	}
+}
+
+// WithFlushThreshold sets the number of load calls which trigger the batch function in the rounds following
+// the first one, e.g. for the stragglers of a request. The threshold is capped at the capacity. Defaults to
+// the capacity.
+func WithFlushThreshold(n int) Option {
+	return func(o *Options) {
+		o.FlushThreshold = n
+	}
+}
+
+// WithIdleTimeout sets the maximum duration the worker waits for another load call before calling the
+// batch function with the pending keys. Defaults to no idle timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.IdleTimeout = d
+	}
+}
+
+// WithBackpressure sets the policy applied to loads made while the key channel of the worker is full.
+// Defaults to Block.
+func WithBackpressure(b Backpressure) Option {
+	return func(o *Options) {
+		o.Backpressure = b
+	}
+}
+
+// WithKeyDedup sets how the worker detects duplicate keys in a batch, e.g. dataloader.DedupByString for
+// pointer keys. Defaults to dataloader.DedupByKey.
+func WithKeyDedup(d dataloader.KeyDedup) Option {
+	return func(o *Options) {
+		o.KeyDedup = d
+	}
+}
+
+// WithClock sets the time source of the strategies timeouts, e.g. a clock.Mock in tests. Defaults to the
+// system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *Options) {
+		o.Hooks = h
+	}
+}
@@ -0,0 +1,73 @@
+package options_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/strategies/once"
+	"github.com/andy9775/dataloader/strategies/options"
+	"github.com/andy9775/dataloader/strategies/sozu"
+	"github.com/andy9775/dataloader/strategies/standard"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNew ensures the defaults are applied before the provided options
+func TestNew(t *testing.T) {
+	// setup
+	log := logger.Capture()
+
+	// invoke
+	defaults := options.New()
+	o := options.New(
+		options.WithTimeout(time.Second),
+		options.WithDeadlineBudget(time.Millisecond),
+		options.WithInBackground(),
+		options.WithLogger(log),
+	)
+
+	// assert
+	assert.Equal(t, 16*time.Millisecond, defaults.Timeout, "Expected the default timeout")
+	assert.Equal(t, 5*time.Millisecond, defaults.DeadlineBudget, "Expected the default deadline budget")
+	assert.False(t, defaults.InBackground, "Expected the batch function not to be called in the background")
+	assert.NotNil(t, defaults.Logger, "Expected the no op logger")
+	assert.Equal(t, time.Second, o.Timeout, "Expected the timeout")
+	assert.Equal(t, time.Millisecond, o.DeadlineBudget, "Expected the deadline budget")
+	assert.True(t, o.InBackground, "Expected the batch function to be called in the background")
+	assert.Equal(t, log, o.Logger, "Expected the logger")
+}
+
+// TestSharedOptions ensures the same options configure each strategy
+func TestSharedOptions(t *testing.T) {
+	// setup
+	var calls int
+	opts := []options.Option{
+		options.WithTimeout(time.Millisecond),
+		options.WithHooks(dataloader.Hooks{
+			OnBatchStart: func(context.Context, dataloader.BatchInfo) { calls++ },
+		}),
+	}
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		r := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.StringKeys() {
+			r[k] = dataloader.Result{Result: k, Err: nil}
+		}
+		return &r
+	}
+
+	for _, fn := range []dataloader.StrategyFunction{
+		standard.NewStandardStrategy(opts...),
+		sozu.NewSozuStrategy(opts...),
+		once.NewOnceStrategy(opts...),
+	} {
+		// invoke
+		r, ok := fn(10, batch).Load(context.Background(), dataloader.StringKey("1"))()
+
+		// assert
+		assert.True(t, ok, "Expected result for key")
+		assert.Equal(t, "1", r.Result, "Expected result from the batch function")
+	}
+	assert.Equal(t, 3, calls, "Expected the hooks to be configured on each strategy")
+}
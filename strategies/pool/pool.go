@@ -0,0 +1,313 @@
+/*
+Package pool contains the implementation details for the worker pool strategy.
+
+The pool strategy maintains up to N worker go routines which drain a shared queue of keys.
+Each worker collects keys until it reaches the batch size or the flush interval elapses and
+then calls the batch function, so several batches can be in flight at the same time. This
+suits high throughput services where a single worker calling the batch function serially
+becomes the bottleneck. Workers are started on demand and exit when the queue stays empty
+for a flush interval.
+*/
+package pool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// options contains the configuration of the pool strategy
+type options struct {
+	workers       int
+	batchSize     int
+	flushInterval time.Duration
+	clock         clock.Clock
+	logger        logger.Logger
+	hooks         dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewPoolStrategy returns a new instance of the worker pool strategy.
+// Each worker calls the batch function once it has collected the batch size (the capacity unless set by
+// WithBatchSize) or the flush interval elapsed since it received the first key of the batch.
+func NewPoolStrategy(opts ...Option) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{batchSize: capacity}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+		if o.batchSize < 1 {
+			o.batchSize = 1
+		}
+		if o.workers < 1 {
+			o.workers = 1
+		}
+
+		return &poolStrategy{
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.hooks), o.logger),
+			capacity:  capacity,
+			options:   o,
+
+			queue: make(chan request, o.workers*o.batchSize),
+		}
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithWorkers sets the maximum number of workers, i.e. the number of batches which can be in flight at the
+// same time. Defaults to 4.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithBatchSize sets the number of keys which trigger a worker to call the batch function. Defaults to the
+// capacity.
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		o.batchSize = n
+	}
+}
+
+// WithFlushInterval sets the maximum duration a worker collects keys before calling the batch function.
+// Defaults to 16 milliseconds.
+func WithFlushInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.flushInterval = d
+	}
+}
+
+// WithClock sets the time source of the flush interval, e.g. a clock.Mock in tests. Defaults to the system
+// clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// ===========================================================================================================
+
+// request is a call to Load or LoadMany waiting in the queue
+type request struct {
+	ctx        context.Context
+	keys       []dataloader.Key
+	resultChan chan dataloader.ResultMap
+}
+
+type poolStrategy struct {
+	// pendingKeys, queued, lastFlush (unix nano) and timeouts are accessed atomically and must be first in
+	// the struct to ensure 64-bit alignment
+	pendingKeys int64
+	queued      int64 // number of requests enqueued but not yet received by a worker
+	lastFlush   int64
+	timeouts    uint64
+
+	batchFunc dataloader.BatchFunction
+	capacity  int
+	queue     chan request
+
+	// m guards running and the increment of queued so that workers don't exit while a request is enqueued
+	m       sync.Mutex
+	running int
+
+	options options
+}
+
+// Load returns a Thunk for the specified Key. Calling the Thunk blocks until a worker calls the batch
+// function with the key and it returns.
+func (s *poolStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := s.enqueue(ctx, key)
+
+	var result dataloader.Result
+	var ok bool
+	var once sync.Once
+	return func() (dataloader.Result, bool) {
+		once.Do(func() {
+			result, ok = strategies.Wait(ctx, resultChan, key).GetValue(key)
+		})
+		return result, ok
+	}
+}
+
+// LoadMany returns a ThunkMany for the specified keys. Calling the ThunkMany blocks until a worker calls the
+// batch function with the keys and it returns.
+func (s *poolStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	resultChan := s.enqueue(ctx, keyArr...)
+
+	var resultMap dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			resultMap = strategies.BuildResultMap(keyArr, strategies.Wait(ctx, resultChan, keyArr...))
+		})
+		return resultMap
+	}
+}
+
+// LoadNoOp has no internal implementation since the pool strategy measures batches in keys
+func (*poolStrategy) LoadNoOp(context.Context) {}
+
+// State returns a snapshot of the strategies internal state. Capacity is the batch size of each worker and
+// the worker state is reported as running while any worker is running.
+func (s *poolStrategy) State() dataloader.StrategyState {
+	s.m.Lock()
+	running := s.running
+	s.m.Unlock()
+
+	var lastFlush time.Time
+	if ns := atomic.LoadInt64(&s.lastFlush); ns != 0 {
+		lastFlush = time.Unix(0, ns)
+	}
+
+	state := dataloader.WorkerNotRunning
+	switch {
+	case running > 0:
+		state = dataloader.WorkerRunning
+	case !lastFlush.IsZero():
+		state = dataloader.WorkerRan
+	}
+
+	pending := int(atomic.LoadInt64(&s.pendingKeys))
+	return dataloader.StrategyState{
+		PendingKeys:  pending,
+		Capacity:     s.options.batchSize,
+		CounterValue: pending,
+		WorkerState:  state,
+		LastFlush:    lastFlush,
+		Timeouts:     atomic.LoadUint64(&s.timeouts),
+	}
+}
+
+// Name returns the name of the strategy
+func (*poolStrategy) Name() string {
+	return "pool"
+}
+
+// ============================================== private =============================================
+
+// enqueue adds the keys to the queue, starting a worker if fewer than the maximum are running, and returns
+// the channel on which the result is delivered
+func (s *poolStrategy) enqueue(ctx context.Context, keyArr ...dataloader.Key) chan dataloader.ResultMap {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the worker
+
+	s.m.Lock()
+	atomic.AddInt64(&s.queued, 1)
+	if s.running < s.options.workers {
+		s.running++
+		go s.work()
+	}
+	s.m.Unlock()
+
+	atomic.AddInt64(&s.pendingKeys, int64(len(keyArr)))
+	s.queue <- request{ctx: ctx, keys: keyArr, resultChan: resultChan}
+
+	return resultChan
+}
+
+// work collects requests from the queue into batches until no request is enqueued for a flush interval
+func (s *poolStrategy) work() {
+	s.options.logger.Debug("worker starting")
+	for {
+		var first request
+		select {
+		case first = <-s.queue:
+			atomic.AddInt64(&s.queued, -1)
+		case <-s.options.clock.After(s.options.flushInterval):
+			s.m.Lock()
+			if atomic.LoadInt64(&s.queued) == 0 {
+				s.running--
+				s.m.Unlock()
+				s.options.logger.Debug("worker exiting")
+				return
+			}
+			s.m.Unlock()
+			continue
+		}
+
+		s.execute(s.collect(first))
+	}
+}
+
+// collect adds requests from the queue to the batch until it reaches the batch size or the flush interval
+// elapses
+func (s *poolStrategy) collect(first request) []request {
+	requests := []request{first}
+	size := len(first.keys)
+
+	flush := s.options.clock.After(s.options.flushInterval)
+	for size < s.options.batchSize {
+		select {
+		case r := <-s.queue:
+			atomic.AddInt64(&s.queued, -1)
+			requests = append(requests, r)
+			size += len(r.keys)
+		case <-flush:
+			s.options.logger.Debug("worker flushing after interval", "keys", size)
+			atomic.AddUint64(&s.timeouts, 1)
+			return requests
+		}
+	}
+	return requests
+}
+
+// execute calls the batch function with the keys of the requests and delivers the results. The batch
+// context stays live while any of the requests is waiting (see strategies.BatchContext).
+func (s *poolStrategy) execute(requests []request) {
+	keys := dataloader.NewKeys(s.options.batchSize)
+	ctxs := make([]context.Context, 0, len(requests))
+	for _, r := range requests {
+		keys.Append(r.keys...)
+		ctxs = append(ctxs, r.ctx)
+	}
+	atomic.AddInt64(&s.pendingKeys, -int64(keys.Length()))
+	atomic.StoreInt64(&s.lastFlush, s.options.clock.Now().UnixNano())
+
+	ctx, cancel := strategies.BatchContext(ctxs...)
+	defer cancel()
+
+	s.options.logger.Debug("worker executing batch", "keys", keys.Length())
+	result := s.batchFunc(ctx, keys)
+	for _, r := range requests {
+		r.resultChan <- *result
+		close(r.resultChan)
+	}
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.workers = 4
+	opts.flushInterval = 16 * time.Millisecond
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
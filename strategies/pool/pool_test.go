@@ -0,0 +1,130 @@
+package pool_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies/pool"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== test constants =============================================
+const TEST_TIMEOUT time.Duration = time.Millisecond * 500
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a generic batch function which returns the provided result and calls the provided
+// callback function
+func getBatchFunction(cb func(dataloader.Keys), result string) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		cb(keys)
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			m.Set(key, dataloader.Result{Result: fmt.Sprintf("%s_%s", key, result), Err: nil})
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestLoadTriggeredByBatchSize ensures a worker calls the batch function once it collected the batch size
+func TestLoadTriggeredByBatchSize(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var calls []int
+	cb := func(keys dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		calls = append(calls, keys.Length())
+	}
+
+	batch := getBatchFunction(cb, "pool")
+	strategy := pool.NewPoolStrategy(
+		pool.WithWorkers(1),
+		pool.WithFlushInterval(TEST_TIMEOUT*5),
+	)(2, batch)
+
+	// invoke
+	start := time.Now()
+	first := strategy.Load(context.Background(), PrimaryKey(1))
+	r, ok := strategy.Load(context.Background(), PrimaryKey(2))()
+	first()
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "2_pool", r.Result, "Expected the result for the key")
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected the batch function to be called on batch size")
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, []int{2}, calls, "Expected a single batch with both keys")
+}
+
+// TestLoadConcurrentBatches ensures batches are executed concurrently by the workers
+func TestLoadConcurrentBatches(t *testing.T) {
+	// setup
+	var wg sync.WaitGroup
+	wg.Add(2)
+	cb := func(dataloader.Keys) {
+		wg.Done()
+		wg.Wait() // block until both batches are in flight
+	}
+
+	batch := getBatchFunction(cb, "concurrent")
+	strategy := pool.NewPoolStrategy(pool.WithWorkers(2), pool.WithBatchSize(1))(10, batch)
+
+	// invoke
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first := strategy.Load(context.Background(), PrimaryKey(1))
+		second := strategy.Load(context.Background(), PrimaryKey(2))
+		first()
+		second()
+	}()
+
+	// assert
+	select {
+	case <-done:
+	case <-time.After(TEST_TIMEOUT):
+		assert.Fail(t, "Expected both batches to be in flight at the same time")
+	}
+}
+
+// TestStateWorkersExit ensures the workers exit once the queue stays empty
+func TestStateWorkersExit(t *testing.T) {
+	// setup
+	interval := 10 * time.Millisecond
+	batch := getBatchFunction(func(dataloader.Keys) {}, "state")
+	strategy := pool.NewPoolStrategy(pool.WithFlushInterval(interval))(5, batch)
+	introspectable := strategy.(dataloader.Introspectable)
+
+	// invoke / assert
+	assert.Equal(t, dataloader.WorkerNotRunning, introspectable.State().WorkerState, "Expected no workers")
+
+	strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))()
+	time.Sleep(interval * 5) // allow the workers to exit
+
+	state := introspectable.State()
+	assert.Equal(t, dataloader.WorkerRan, state.WorkerState, "Expected the workers to have exited")
+	assert.Equal(t, 0, state.PendingKeys, "Expected no pending keys")
+	assert.Equal(t, uint64(1), state.Timeouts, "Expected the batch to be flushed after the interval")
+}
@@ -0,0 +1,35 @@
+package strategies
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/logger"
+)
+
+// PanicError is returned for each key in a batch when the batch function panics
+type PanicError = dataloader.PanicError
+
+// RecoverBatch returns a batch function which recovers from a panic in the batch function. The panic is
+// logged to the provided logger and each key in the batch resolves to a result containing a PanicError.
+// Strategies should wrap the batch function with RecoverBatch so that a panicking batch function doesn't
+// kill the strategies worker and block every caller waiting on it.
+func RecoverBatch(batch dataloader.BatchFunction, l logger.Logger) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) (r *dataloader.ResultMap) {
+		defer func() {
+			if v := recover(); v != nil {
+				err := &dataloader.PanicError{Value: v, Stack: debug.Stack()}
+				l.Error("recovered from panic in batch function", "keys", keys.Length(), "panic", v)
+
+				result := dataloader.NewResultMap(keys.Length())
+				for _, k := range keys.StringKeys() {
+					result[k] = dataloader.Result{Result: nil, Err: err}
+				}
+				r = &result
+			}
+		}()
+
+		return batch(ctx, keys)
+	}
+}
@@ -0,0 +1,35 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecoverBatch ensures a panic in the batch function resolves each key to an error
+func TestRecoverBatch(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		panic("batch failure")
+	}
+	keys := dataloader.NewKeysWith(dataloader.StringKey("1"), dataloader.StringKey("2"))
+
+	// invoke
+	var r *dataloader.ResultMap
+	assert.NotPanics(t, func() {
+		r = strategies.RecoverBatch(batch, logger.Noop())(context.Background(), keys)
+	}, "Expected panic to be recovered")
+
+	// assert
+	assert.Equal(t, 2, r.Length(), "Expected result for each key")
+	for _, k := range []dataloader.Key{dataloader.StringKey("1"), dataloader.StringKey("2")} {
+		v, ok := r.GetValue(k)
+		assert.True(t, ok, "Expected result to have been found")
+		assert.EqualError(t, v.Err, "dataloader: batch function panicked: batch failure", "Expected panic error")
+		assert.IsType(t, &strategies.PanicError{}, v.Err, "Expected PanicError")
+	}
+}
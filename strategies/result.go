@@ -0,0 +1,32 @@
+package strategies
+
+import (
+	"context"
+
+	"github.com/andy9775/dataloader"
+)
+
+// Wait blocks until a result is delivered on the channel or the context is done. The keys resolve to the
+// error of the context if it is done first.
+func Wait(ctx context.Context, resultChan <-chan dataloader.ResultMap, keyArr ...dataloader.Key) dataloader.ResultMap {
+	select {
+	case <-ctx.Done():
+		return CancelledResultMap(ctx, keyArr...)
+	case r := <-resultChan:
+		return r
+	}
+}
+
+// BuildResultMap returns a ResultMap with the results of the provided keys only, so that each caller of a
+// shared batch receives its own results
+func BuildResultMap(keyArr []dataloader.Key, r dataloader.ResultMap) dataloader.ResultMap {
+	results := dataloader.NewResultMap(len(keyArr))
+
+	for _, k := range keyArr {
+		if val, ok := r.GetValue(k); ok {
+			results.Set(k, val)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,39 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWaitCancelled ensures the keys resolve to the context error when the context is done before a result
+// is delivered
+func TestWaitCancelled(t *testing.T) {
+	// setup
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// invoke
+	r := strategies.Wait(ctx, make(chan dataloader.ResultMap), dataloader.StringKey("1"))
+
+	// assert
+	assert.Equal(t, context.Canceled, r.GetValueForString("1").Err, "Expected the context error")
+}
+
+// TestBuildResultMap ensures only the results of the provided keys are returned
+func TestBuildResultMap(t *testing.T) {
+	// setup
+	r := dataloader.NewResultMap(2)
+	r.Set(dataloader.StringKey("1"), dataloader.Result{Result: "one", Err: nil})
+	r.Set(dataloader.StringKey("2"), dataloader.Result{Result: "two", Err: nil})
+
+	// invoke
+	results := strategies.BuildResultMap([]dataloader.Key{dataloader.StringKey("1")}, r)
+
+	// assert
+	assert.Equal(t, 1, results.Length(), "Expected the results of the provided keys only")
+	assert.Equal(t, "one", results.GetValueForString("1").Result, "Expected the result for the key")
+}
@@ -12,22 +12,20 @@ package sozu
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
 	"github.com/andy9775/dataloader/strategies"
+	"github.com/andy9775/dataloader/strategies/options"
 
-	"github.com/go-log/log"
+	"github.com/andy9775/dataloader/logger"
 )
 
-// Options contains the strategy configuration
-type options struct {
-	timeout time.Duration
-	logger  log.Logger
-}
-
-// Option accepts the dataloader and sets an option on it.
-type Option func(*options)
+// Option accepts the strategy options and sets an option on them. Options from the options package are
+// accepted by the standard, sozu and once strategies.
+type Option = options.Option
 
 // go routine status values
 // Ensure that only one worker go routine is working to call the batch function
@@ -45,17 +43,11 @@ const (
 // whose length is >= 1 and <= the capacity.
 func NewSozuStrategy(opts ...Option) dataloader.StrategyFunction {
 	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
-		// default options
-		o := options{}
-		formatOptions(&o)
-
-		// format options
-		for _, apply := range opts {
-			apply(&o)
-		}
+		o := options.New(opts...)
 
 		return &sozuStrategy{
-			batchFunc: batch,
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.Hooks), o.Logger),
+			capacity:  capacity,
 			counter:   strategies.NewCounter(capacity),
 
 			workerMutex:     &sync.Mutex{},
@@ -64,7 +56,7 @@ func NewSozuStrategy(opts ...Option) dataloader.StrategyFunction {
 			keyChan: make(chan workerMessage, capacity),
 			options: o,
 
-			keys: dataloader.NewKeys(capacity),
+			keys: dataloader.NewKeys(capacity, dataloader.WithKeysDedup(o.KeyDedup)),
 		}
 	}
 }
@@ -73,22 +65,69 @@ func NewSozuStrategy(opts ...Option) dataloader.StrategyFunction {
 
 // WithTimeout sets the timeout value for the strategy
 func WithTimeout(t time.Duration) Option {
-	return func(o *options) {
-		o.timeout = t
-	}
+	return options.WithTimeout(t)
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return options.WithDeadlineBudget(d)
+}
+
+// WithFlushThreshold sets the number of load calls which trigger the batch function once the first batch
+// has been called, e.g. when the subsequent keys of a request are known to arrive in smaller groups. The
+// threshold is capped at the capacity. Defaults to the capacity.
+func WithFlushThreshold(n int) Option {
+	return options.WithFlushThreshold(n)
+}
+
+// WithIdleTimeout sets the maximum duration the worker waits for another load call before calling the batch
+// function with the pending keys, so stragglers don't wait for the full timeout. Defaults to no idle timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return options.WithIdleTimeout(d)
+}
+
+// WithBackpressure sets the policy applied to loads made while the key channel of the worker is full, e.g.
+// because the worker is calling the batch function. Defaults to options.Block.
+func WithBackpressure(b options.Backpressure) Option {
+	return options.WithBackpressure(b)
+}
+
+// WithKeyDedup sets how the worker detects duplicate keys in a batch, e.g. dataloader.DedupByString for
+// pointer keys. Defaults to dataloader.DedupByKey.
+func WithKeyDedup(d dataloader.KeyDedup) Option {
+	return options.WithKeyDedup(d)
+}
+
+// WithClock sets the time source of the strategies timeouts, e.g. a clock.Mock controlling the timeout in
+// tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return options.WithClock(c)
 }
 
 // WithLogger adds a logger to the strategy. Default is a no op logger.
-func WithLogger(l log.Logger) Option {
-	return func(s *options) {
-		s.logger = l
-	}
+func WithLogger(l logger.Logger) Option {
+	return options.WithLogger(l)
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return options.WithHooks(h)
 }
 
 // ===========================================================================================================
 
 type sozuStrategy struct {
-	counter strategies.Counter
+	// pendingKeys, lastFlush (unix nano), timeouts and saturations are accessed atomically and must be first
+	// in the struct to ensure 64-bit alignment
+	pendingKeys int64
+	lastFlush   int64
+	timeouts    uint64
+	saturations uint64
+
+	counter  strategies.Counter
+	capacity int
 	// Track the keys to pass to the batch function. Once len(keys) == cap(keys),
 	// the batch loading function is called with the keys to resolve.
 	keys      dataloader.Keys
@@ -100,12 +139,14 @@ type sozuStrategy struct {
 	keyChan   chan workerMessage
 	closeChan chan struct{}
 
-	options options
+	options options.Options
 }
 
 type workerMessage struct {
+	ctx        context.Context
 	k          []dataloader.Key
 	resultChan chan dataloader.ResultMap
+	flush      bool // call the batch function with the pending keys
 }
 
 // Load returns the Thunk for the specified Key.
@@ -121,8 +162,8 @@ func (s *sozuStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.
 	s.startWorker(ctx)
 
 	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block in results loop
-	message := workerMessage{k: []dataloader.Key{key}, resultChan: resultChan}
-	s.keyChan <- message // pass key to the worker go routine
+	message := workerMessage{ctx: ctx, k: []dataloader.Key{key}, resultChan: resultChan}
+	s.send(message) // pass key to the worker go routine
 
 	var result dataloader.Result
 	var ok bool
@@ -167,7 +208,8 @@ func (s *sozuStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.
 
 			select {
 			case <-ctx.Done():
-				return dataloader.Result{Result: nil, Err: nil}, false
+				result, ok = strategies.CancelledResult(ctx), true
+				return result, ok
 			case r := <-resultChan:
 				result, ok = r.GetValue(key)
 				return result, ok
@@ -192,8 +234,8 @@ func (s *sozuStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) d
 	s.startWorker(ctx)
 
 	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block in results loop
-	message := workerMessage{k: keyArr, resultChan: resultChan}
-	s.keyChan <- message
+	message := workerMessage{ctx: ctx, k: keyArr, resultChan: resultChan}
+	s.send(message)
 
 	var resultMap dataloader.ResultMap
 
@@ -223,7 +265,8 @@ func (s *sozuStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) d
 
 			select {
 			case <-ctx.Done():
-				return dataloader.NewResultMap(0)
+				resultMap = strategies.CancelledResultMap(ctx, keyArr...)
+				return resultMap
 			case r := <-resultChan:
 				resultMap = buildResultMap(keyArr, r)
 				return resultMap
@@ -240,12 +283,96 @@ func (s *sozuStrategy) LoadNoOp(ctx context.Context) {
 	s.startWorker(ctx) // start the worker in case the first caller is a cache success
 
 	// LoadNoOp passes a nil value to the strategy worker and doesn't block the caller.
-	message := workerMessage{k: nil, resultChan: nil}
-	s.keyChan <- message
+	message := workerMessage{ctx: ctx, k: nil, resultChan: nil}
+	s.send(message)
+}
+
+// Flush signals a running worker to call the batch function with the keys it has received so far.
+// Keys passed to Load or LoadMany before Flush are included in the batch.
+func (s *sozuStrategy) Flush(ctx context.Context) {
+	s.workerMutex.Lock()
+	defer s.workerMutex.Unlock()
+
+	if s.goroutineStatus != running {
+		return
+	}
+
+	/*
+		Don't block if the channel is full. The counter shares the capacity of the channel so
+		the worker will reach capacity and call the batch function without the flush message.
+	*/
+	select {
+	case s.keyChan <- workerMessage{flush: true}:
+	default:
+	}
+}
+
+// State returns a snapshot of the strategies internal state
+func (s *sozuStrategy) State() dataloader.StrategyState {
+	s.workerMutex.Lock()
+	status := s.goroutineStatus
+	s.workerMutex.Unlock()
+
+	var lastFlush time.Time
+	if ns := atomic.LoadInt64(&s.lastFlush); ns != 0 {
+		lastFlush = time.Unix(0, ns)
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  int(atomic.LoadInt64(&s.pendingKeys)),
+		Capacity:     s.capacity,
+		CounterValue: s.counter.Count(),
+		WorkerState:  workerState(status),
+		LastFlush:    lastFlush,
+		Timeouts:     atomic.LoadUint64(&s.timeouts),
+		Saturations:  atomic.LoadUint64(&s.saturations),
+	}
+}
+
+// Name returns the name of the strategy
+func (*sozuStrategy) Name() string {
+	return "sozu"
 }
 
 // ============================================== private =============================================
 
+// batch calls the batch function with the pending keys and records the time of the call
+func (s *sozuStrategy) batch(ctx context.Context) *dataloader.ResultMap {
+	atomic.StoreInt64(&s.lastFlush, s.options.Clock.Now().UnixNano())
+	return s.batchFunc(ctx, s.keys)
+}
+
+// send passes the message to the worker go routine. If the key channel is full the message is handled
+// according to the backpressure policy.
+func (s *sozuStrategy) send(message workerMessage) {
+	select {
+	case s.keyChan <- message:
+		return
+	default:
+	}
+
+	atomic.AddUint64(&s.saturations, 1)
+	s.options.Logger.Debug("key channel full", "keys", len(message.k), "backpressure", s.options.Backpressure)
+
+	switch s.options.Backpressure {
+	case options.Grow:
+		go func() { s.keyChan <- message }()
+	case options.Drop:
+		if message.resultChan != nil {
+			message.resultChan <- strategies.DroppedResultMap(message.k...)
+		}
+	case options.Flush:
+		if message.resultChan != nil {
+			go func() {
+				atomic.StoreInt64(&s.lastFlush, s.options.Clock.Now().UnixNano())
+				message.resultChan <- *s.batchFunc(message.ctx, dataloader.NewKeysWith(message.k...))
+			}()
+		}
+	default:
+		s.keyChan <- message
+	}
+}
+
 // startWorker starts the background go routine if not already running for this strategy instance.
 // The worker accepts keys via an internal channel and calls the batch function once full.
 func (s *sozuStrategy) startWorker(ctx context.Context) {
@@ -253,12 +380,16 @@ func (s *sozuStrategy) startWorker(ctx context.Context) {
 	defer s.workerMutex.Unlock()
 
 	if s.goroutineStatus == notRunning || s.goroutineStatus == ran {
+		threshold := s.capacity
+		if s.goroutineStatus == ran && s.options.FlushThreshold > 0 && s.options.FlushThreshold < s.capacity {
+			threshold = s.options.FlushThreshold // follow-up batch
+		}
 		s.goroutineStatus = running
 		s.closeChan = make(chan struct{})
 
 		go func(ctx context.Context) {
-			subscribers := make([]chan dataloader.ResultMap, 0, s.keys.Capacity())
-			s.options.logger.Logf("starting new worker with capacity: %d", s.keys.Capacity())
+			subscribers := make([]workerMessage, 0, s.keys.Capacity())
+			s.options.Logger.Debug("starting new worker", "capacity", s.keys.Capacity(), "threshold", threshold)
 
 			defer func() {
 				s.workerMutex.Lock()
@@ -267,47 +398,109 @@ func (s *sozuStrategy) startWorker(ctx context.Context) {
 				s.goroutineStatus = ran
 				s.keys.ClearAll()
 				s.counter.ResetCount()
+				atomic.StoreInt64(&s.pendingKeys, 0)
 				close(s.closeChan)
 			}()
 
 			var r *dataloader.ResultMap
+			var flushBy time.Time     // earliest time the batch function must be called to meet the pending deadlines
+			var idle <-chan time.Time // nil, and never ready, without an idle timeout
 			for r == nil {
 				select {
 				case <-ctx.Done():
-					s.options.logger.Log("worker cancelled")
-					return
+					s.options.Logger.Info("worker cancelled", "keys", s.keys.Length(), "error", ctx.Err())
+					if ctx, flushBy = s.cancel(&subscribers); ctx == nil {
+						return
+					}
 				case key := <-s.keyChan:
+					if key.flush {
+						// ignore flushes without pending loads (e.g. sent as the previous worker completed)
+						if len(subscribers) > 0 {
+							s.options.Logger.Debug("worker flushing", "keys", s.keys.Length())
+							r = s.batch(ctx)
+						}
+						continue
+					}
+
 					// if LoadNoOp passes a value through the chan, ignore the data and increment the counter
 					if key.resultChan != nil {
-						subscribers = append(subscribers, key.resultChan)
+						subscribers = append(subscribers, key)
 					}
 					if key.k != nil {
 						s.keys.Append(key.k...)
+						atomic.StoreInt64(&s.pendingKeys, int64(s.keys.Length()))
+					}
+					flushBy = strategies.EarliestFlush(key.ctx, s.options.Clock, flushBy, s.options.DeadlineBudget)
+					if s.options.IdleTimeout > 0 {
+						idle = s.options.Clock.After(s.options.IdleTimeout)
 					}
 
-					if s.counter.Increment() { // hit capacity
-						r = s.batchFunc(ctx, s.keys)
+					if s.counter.Increment() || s.counter.Count() >= threshold { // hit capacity or threshold
+						r = s.batch(ctx)
 					}
-				case <-time.After(s.options.timeout):
-					s.options.logger.Logf("worker timing out with %d keys", s.keys.Length())
-					r = s.batchFunc(ctx, s.keys)
+				case <-idle:
+					s.options.Logger.Debug("worker idle", "keys", s.keys.Length(), "timeout", s.options.IdleTimeout)
+					atomic.AddUint64(&s.timeouts, 1)
+					r = s.batch(ctx)
+				case <-s.options.Clock.After(strategies.FlushTimeout(s.options.Clock, s.options.Timeout, flushBy)):
+					s.options.Logger.Debug("worker timing out", "keys", s.keys.Length(), "timeout", s.options.Timeout)
+					atomic.AddUint64(&s.timeouts, 1)
+					r = s.batch(ctx)
 				}
 			}
 
-			for _, ch := range subscribers {
-				ch <- *r
-				close(ch)
+			for _, sub := range subscribers {
+				sub.resultChan <- *r
+				close(sub.resultChan)
 			}
 		}(ctx)
 	}
 }
 
+// cancel resolves the subscribers whose context is done to the context error and keeps the keys of the
+// remaining subscribers pending, so that they are passed to the batch function together. It returns the
+// context of a remaining subscriber to continue the worker with and the time by which the worker must
+// call the batch function, or a nil context if none remain.
+func (s *sozuStrategy) cancel(subscribers *[]workerMessage) (context.Context, time.Time) {
+	var ctx context.Context
+	var flushBy time.Time
+	live := (*subscribers)[:0]
+	s.keys.ClearAll()
+	for _, sub := range *subscribers {
+		if sub.ctx.Err() != nil {
+			sub.resultChan <- strategies.CancelledResultMap(sub.ctx, sub.k...)
+			close(sub.resultChan)
+			continue
+		}
+
+		if ctx == nil {
+			ctx = sub.ctx
+		}
+		live = append(live, sub)
+		s.keys.Append(sub.k...)
+		flushBy = strategies.EarliestFlush(sub.ctx, s.options.Clock, flushBy, s.options.DeadlineBudget)
+	}
+	*subscribers = live
+	atomic.StoreInt64(&s.pendingKeys, int64(s.keys.Length()))
+
+	if ctx != nil {
+		s.options.Logger.Debug("worker continuing with live loads", "loads", len(live), "keys", s.keys.Length())
+	}
+	return ctx, flushBy
+}
+
 // ============================================== helpers =============================================
 
-// formatOptions configures default values for the loader options
-func formatOptions(opts *options) {
-	opts.timeout = 16 * time.Millisecond
-	opts.logger = log.DefaultLogger
+// workerState maps the go routine status to the reported worker state
+func workerState(status int) dataloader.WorkerState {
+	switch status {
+	case running:
+		return dataloader.WorkerRunning
+	case ran:
+		return dataloader.WorkerRan
+	default:
+		return dataloader.WorkerNotRunning
+	}
 }
 
 // buildResultMap filters through the provided result map and returns an ResultMap
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/andy9775/dataloader/strategies/options"
 	"github.com/andy9775/dataloader/strategies/sozu"
-	"github.com/bouk/monkey"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,7 +40,7 @@ func getBatchFunction(cb func(dataloader.Keys), result string) dataloader.BatchF
 	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
 		cb(keys)
 		m := dataloader.NewResultMap(1)
-		for _, k := range keys.RawKeys() {
+		for _, k := range keys.Keys() {
 			key := k.(PrimaryKey)
 			m.Set(
 				key,
@@ -66,39 +70,9 @@ func timeout(t *testing.T, timeoutChannel chan struct{}, after time.Duration) {
 	}()
 }
 
-type mockLogger struct {
-	logMsgs []string
-	m       sync.Mutex
-}
-
-func (l *mockLogger) Log(v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	for _, value := range v {
-		switch i := value.(type) {
-		case string:
-			l.logMsgs = append(l.logMsgs, i)
-		default:
-			panic("mock logger only takes single log string")
-		}
-	}
-}
-
-func (l *mockLogger) Logf(format string, v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	l.logMsgs = append(l.logMsgs, fmt.Sprintf(format, v...))
-}
-
-func (l *mockLogger) Messages() []string {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	result := make([]string, len(l.logMsgs))
-	copy(result, l.logMsgs)
-	return result
+// timeouts returns the number of times the strategy called the batch function after timing out
+func timeouts(strategy dataloader.Strategy) uint64 {
+	return strategy.(dataloader.Introspectable).State().Timeouts
 }
 
 // ================================================== tests ==================================================
@@ -123,33 +97,18 @@ func TestLoadTimeoutTriggered(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	toWG := sync.WaitGroup{}
-	toWG.Add(1)
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toWG.Done()
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock()
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy()(10, batch) // expects 10 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(10, batch) // expects 10 load calls
 
 	// invoke/assert
 
@@ -158,14 +117,15 @@ func TestLoadTimeoutTriggered(t *testing.T) {
 	thunk := strategy.Load(context.Background(), key2) // --------- Load 		 - call 2
 	strategy.LoadNoOp(context.Background())            // --------- LoadNoOp - call 3
 	assert.Equal(t, 0, callCount, "Load() not expected to block or call batch function")
+	clk.BlockUntil(4) // the worker waits on a new timeout when it starts and after each load
+	clk.Add(16 * time.Millisecond)
 	blockWG.Done() // allow batch function to execute
 	wg.Wait()      // wait for batch function to execute
 
 	assert.Equal(t, 1, callCount, "Batch function expected to be called once")
 	assert.Equal(t, 2, len(k), "Expected to be called with 2 keys")
 
-	toWG.Wait()
-	assert.True(t, timedOut, "Expected function to timeout")
+	assert.Equal(t, uint64(1), timeouts(strategy), "Expected function to timeout")
 
 	r, ok := thunk()
 	assert.True(t, ok, "Expected result to have been found")
@@ -206,34 +166,19 @@ func TestLoadManyTimeoutTriggered(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	toWG := sync.WaitGroup{}
-	toWG.Add(1)
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toWG.Done()
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock()
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 	key3 := PrimaryKey(3)
 
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy()(10, batch) // expected 10 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(10, batch) // expected 10 load calls
 
 	// invoke/assert
 
@@ -242,6 +187,8 @@ func TestLoadManyTimeoutTriggered(t *testing.T) {
 	thunkMany2 := strategy.LoadMany(context.Background(), key2, key3) // --------- LoadMany - call 2
 	strategy.LoadNoOp(context.Background())                           // --------- LoadNoOp - call 3
 	assert.Equal(t, 0, callCount, "LoadMany() not expected to block or call batch function")
+	clk.BlockUntil(4) // the worker waits on a new timeout when it starts and after each load
+	clk.Add(16 * time.Millisecond)
 	blockWG.Done() // allow batch function to execute
 	wg.Wait()      // wait for batch function to execute
 
@@ -249,8 +196,7 @@ func TestLoadManyTimeoutTriggered(t *testing.T) {
 	// capacity is 10, called 2 times with 3 unique keys
 	assert.Equal(t, 3, len(k), "Expected batch function to be called with 3 keys")
 
-	toWG.Wait()
-	assert.True(t, timedOut, "Expected function to have timed out")
+	assert.Equal(t, uint64(1), timeouts(strategy), "Expected function to have timed out")
 
 	r1 := thunkMany2()
 	returned1, ok := r1.GetValue(key2)
@@ -300,33 +246,17 @@ func TestLoadTriggered(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(timeout))(2, batch) // expected 2 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(2, batch) // expected 2 load calls
 
 	// invoke/assert
 
@@ -349,7 +279,7 @@ func TestLoadTriggered(t *testing.T) {
 		"Expected batch function to return on thunk()",
 	)
 
-	assert.False(t, timedOut, "Expected function to not timeout")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Expected function to not timeout")
 
 	// test double call to thunk
 	r1, ok = thunk()
@@ -381,34 +311,18 @@ func TestLoadManyTriggered(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(timeout))(2, batch) // expected 2 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(2, batch) // expected 2 load calls
 
 	// invoke/assert
 
@@ -432,7 +346,7 @@ func TestLoadManyTriggered(t *testing.T) {
 		"Expected batch function to return on thunk()",
 	)
 
-	assert.False(t, timedOut, "Expected function to not timeout")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Expected function to not timeout")
 
 	// test double call to thunk
 	r1 = thunk() // don't block on second call
@@ -458,32 +372,16 @@ func TestLoadBlocked(t *testing.T) {
 	expectedResult := "batch_on_timeout_load_many"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(timeout))(2, batch) // expected 2 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(2, batch) // expected 2 load calls
 
 	// invoke/assert
 
@@ -495,7 +393,7 @@ func TestLoadBlocked(t *testing.T) {
 
 	assert.True(t, ok, "Expected result to have been found")
 	assert.Equal(t, 1, callCount, "Batch function should have been called once")
-	assert.False(t, timedOut, "Batch function should not have timed out")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Batch function should not have timed out")
 	assert.Equal(t, 1, len(k), "Should have been called with one key")
 	assert.Equal(t, fmt.Sprintf("1_%s", expectedResult), r.Result.(string), "Expected result from thunk()")
 
@@ -504,7 +402,7 @@ func TestLoadBlocked(t *testing.T) {
 
 	assert.True(t, ok, "Expected result to have been found")
 	assert.Equal(t, 1, callCount, "Batch function should have been called once")
-	assert.False(t, timedOut, "Batch function should not have timed out")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Batch function should not have timed out")
 	assert.Equal(t, 1, len(k), "Should have been called with one key")
 	assert.Equal(t, fmt.Sprintf("1_%s", expectedResult), r.Result.(string), "Expected result from thunk()")
 }
@@ -521,33 +419,17 @@ func TestLoadManyBlocked(t *testing.T) {
 	expectedResult := "batch_on_timeout_load_many"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(timeout))(2, batch) // expected 2 load calls
+	strategy := sozu.NewSozuStrategy(sozu.WithClock(clk))(2, batch) // expected 2 load calls
 
 	// invoke/assert
 
@@ -558,7 +440,7 @@ func TestLoadManyBlocked(t *testing.T) {
 	r := thunkMany() // block until batch function executes
 
 	assert.Equal(t, 1, callCount, "Batch function should have been called once")
-	assert.False(t, timedOut, "Batch function should not have timed out")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Batch function should not have timed out")
 	assert.Equal(t, 2, len(k), "Should have been called with two keys")
 	returned, ok := r.GetValue(key2)
 	assert.True(t, ok, "Expected result to have been found")
@@ -573,7 +455,7 @@ func TestLoadManyBlocked(t *testing.T) {
 	r = thunkMany() // don't block on second call
 
 	assert.Equal(t, 1, callCount, "Batch function should have been called once")
-	assert.False(t, timedOut, "Batch function should not have timed out")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Batch function should not have timed out")
 	assert.Equal(t, 2, len(k), "Should have been called with two keys")
 	returned, ok = r.GetValue(key2)
 	assert.True(t, ok, "Expected result to have been found")
@@ -597,14 +479,13 @@ func TestCancellableContextLoad(t *testing.T) {
 	expectedResult := "cancel_via_context"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		close(closeChan)
 	}
 
 	key := PrimaryKey(1)
-	log := mockLogger{logMsgs: make([]string, 2), m: sync.Mutex{}}
+	log := logger.Capture()
 	batch := getBatchFunction(cb, expectedResult)
 	strategy := sozu.NewSozuStrategy(
-		sozu.WithLogger(&log),
+		sozu.WithLogger(log),
 	)(2, batch) // expected 2 load calls
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -613,6 +494,7 @@ func TestCancellableContextLoad(t *testing.T) {
 	thunk := strategy.Load(ctx, key)
 	thunk()
 	time.Sleep(100 * time.Millisecond)
+	close(closeChan)
 
 	// assert
 	assert.Equal(t, 0, callCount, "Batch should not have been called")
@@ -630,14 +512,13 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	expectedResult := "cancel_via_context"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		close(closeChan)
 	}
 
 	key := PrimaryKey(1)
-	log := mockLogger{logMsgs: make([]string, 2), m: sync.Mutex{}}
+	log := logger.Capture()
 	batch := getBatchFunction(cb, expectedResult)
 	strategy := sozu.NewSozuStrategy(
-		sozu.WithLogger(&log),
+		sozu.WithLogger(log),
 	)(2, batch) // expected 2 load calls
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -646,6 +527,7 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	thunk := strategy.LoadMany(ctx, key)
 	thunk()
 	time.Sleep(100 * time.Millisecond)
+	close(closeChan)
 
 	// assert
 	assert.Equal(t, 0, callCount, "Batch should not have been called")
@@ -653,6 +535,50 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	assert.Equal(t, "worker cancelled", m[len(m)-1], "Expected worker to cancel and log exit")
 }
 
+// TestCancelledWorkerContext ensures the loads with a live context are batched together once the context of
+// the worker is cancelled, while the cancelled load resolves to the context error
+func TestCancelledWorkerContext(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var callCount int32
+	var k []interface{}
+	cb := func(keys dataloader.Keys) {
+		atomic.AddInt32(&callCount, 1)
+		k = keys.Keys()
+	}
+
+	batch := getBatchFunction(cb, "live")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// invoke
+	cancelled := strategy.Load(ctx, PrimaryKey(1))
+	thunk := strategy.Load(context.Background(), PrimaryKey(2))
+	assert.Eventually(t, func() bool {
+		return strategy.(dataloader.Introspectable).State().PendingKeys == 2
+	}, TEST_TIMEOUT, time.Millisecond, "Expected the worker to receive both keys")
+	cancel()
+	assert.Eventually(t, func() bool {
+		return strategy.(dataloader.Introspectable).State().PendingKeys == 1
+	}, TEST_TIMEOUT, time.Millisecond, "Expected the cancelled key to be removed from the worker")
+	strategy.(dataloader.Flusher).Flush(context.Background())
+
+	c, cancelledOK := cancelled()
+	r, ok := thunk()
+	close(closeChan)
+
+	// assert
+	assert.True(t, cancelledOK, "Expected the cancelled key to resolve")
+	assert.Equal(t, context.Canceled, c.Err, "Expected the context error")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "2_live", r.Result.(string), "Expected result for key")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&callCount), "Expected batch function to be called once")
+	assert.Equal(t, []interface{}{PrimaryKey(2)}, k, "Expected the live key to be batched")
+}
+
 // =============================================== result keys ===============================================
 // TestKeyHandling ensure that the strategy properly handles unprocessed and nil keys
 func TestKeyHandling(t *testing.T) {
@@ -666,7 +592,7 @@ func TestKeyHandling(t *testing.T) {
 	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
 		m := dataloader.NewResultMap(2)
 		for i := 0; i < keys.Length(); i++ {
-			key := keys.RawKeys()[i].(PrimaryKey)
+			key := keys.Keys()[i].(PrimaryKey)
 			if expectedResult[key] != "__skip__" {
 				m.Set(key, dataloader.Result{Result: expectedResult[key], Err: nil})
 			}
@@ -719,3 +645,208 @@ func TestKeyHandling(t *testing.T) {
 
 	}
 }
+
+// TestFlush ensures flushing calls the batch function with the pending keys before reaching capacity
+func TestFlush(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	callCount := 0
+	var k []interface{}
+	cb := func(keys dataloader.Keys) {
+		callCount += 1
+		k = keys.Keys()
+	}
+
+	key := PrimaryKey(1)
+	key2 := PrimaryKey(2)
+	batch := getBatchFunction(cb, "flushed")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), key)
+	thunkMany := strategy.LoadMany(context.Background(), key2)
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_flushed", r.Result.(string), "Expected flushed result")
+	assert.Equal(t, 1, rm.Length(), "Expected result for LoadMany key")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+	assert.ElementsMatch(t, []interface{}{key, key2}, k, "Expected pending keys to be flushed")
+}
+
+// TestFlushThreshold ensures the batch function is called once the threshold is hit in the rounds following
+// the first one
+func TestFlushThreshold(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var mutex sync.Mutex
+	var k [][]interface{}
+	cb := func(keys dataloader.Keys) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		k = append(k, keys.Keys())
+	}
+
+	batch := getBatchFunction(cb, "threshold")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := sozu.NewSozuStrategy(
+		sozu.WithTimeout(TEST_TIMEOUT*5),
+		sozu.WithFlushThreshold(2),
+	)(3, batch)
+
+	// invoke
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(1))
+	thunkMany2 := strategy.LoadMany(context.Background(), PrimaryKey(2))
+	thunkMany3 := strategy.LoadMany(context.Background(), PrimaryKey(3))
+	thunkMany()
+	thunkMany2()
+	thunkMany3()
+
+	thunkMany4 := strategy.LoadMany(context.Background(), PrimaryKey(4))
+	thunkMany5 := strategy.LoadMany(context.Background(), PrimaryKey(5))
+	thunkMany4()
+	thunkMany5()
+	close(closeChan)
+
+	// assert
+	assert.Equal(t, 2, len(k), "Expected batch function to be called twice")
+	assert.Equal(t, 3, len(k[0]), "Expected the first batch to be called at capacity")
+	assert.ElementsMatch(t, []interface{}{PrimaryKey(4), PrimaryKey(5)}, k[1], "Expected threshold keys")
+}
+
+// TestIdleTimeout ensures the batch function is called with the pending keys once no load calls are made
+// within the idle timeout
+func TestIdleTimeout(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	log := logger.Capture()
+	callCount := 0
+	cb := func(keys dataloader.Keys) { callCount += 1 }
+
+	batch := getBatchFunction(cb, "idle")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := sozu.NewSozuStrategy(
+		sozu.WithTimeout(TEST_TIMEOUT*5),
+		sozu.WithIdleTimeout(10*time.Millisecond),
+		sozu.WithLogger(log),
+	)(5, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	r, ok := thunk()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_idle", r.Result.(string), "Expected idle result")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+	assert.Contains(t, log.Messages(), "worker idle", "Expected idle timeout to be logged")
+}
+
+// TestBackpressureDrop ensures loads made while the key channel is full resolve to an error
+func TestBackpressureDrop(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var calls int32
+	started, release := make(chan struct{}), make(chan struct{})
+	batch := getBatchFunction(func(dataloader.Keys) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+	}, "backpressure")
+	strategy := sozu.NewSozuStrategy(sozu.WithBackpressure(options.Drop))(1, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	<-started
+	thunk2 := strategy.Load(context.Background(), PrimaryKey(2)) // fills the key channel
+	r3, ok := strategy.Load(context.Background(), PrimaryKey(3))()
+	close(release)
+	r, _ := thunk()
+	r2, _ := thunk2()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for the dropped key")
+	assert.Equal(t, strategies.ErrKeyChannelFull, r3.Err, "Expected the dropped key to resolve to an error")
+	assert.Equal(t, "1_backpressure", r.Result.(string), "Expected result from the batch")
+	assert.Equal(t, "2_backpressure", r2.Result.(string), "Expected result from the batch")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, uint64(1), state.Saturations, "Expected the saturation to be counted")
+}
+
+// TestState ensures the strategy reports pending keys, the counter and worker state
+func TestState(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batch := getBatchFunction(func(dataloader.Keys) {}, "state")
+	strategy := sozu.NewSozuStrategy(sozu.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+	introspectable := strategy.(dataloader.Introspectable)
+
+	// invoke / assert
+	state := introspectable.State()
+	assert.Equal(t, dataloader.WorkerNotRunning, state.WorkerState, "Expected worker not to be started")
+	assert.Equal(t, 5, state.Capacity, "Expected capacity")
+
+	thunk := strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))
+	strategy.LoadNoOp(context.Background())
+	time.Sleep(10 * time.Millisecond) // allow the worker to receive the keys
+	state = introspectable.State()
+	assert.Equal(t, 2, state.PendingKeys, "Expected pending keys")
+	assert.Equal(t, 2, state.CounterValue, "Expected LoadMany and LoadNoOp to be counted")
+	assert.Equal(t, dataloader.WorkerRunning, state.WorkerState, "Expected worker to be running")
+	assert.True(t, state.LastFlush.IsZero(), "Expected no flush")
+
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	thunk()
+	time.Sleep(10 * time.Millisecond) // allow the worker to exit
+	close(closeChan)
+
+	state = introspectable.State()
+	assert.Equal(t, 0, state.PendingKeys, "Expected keys to be flushed")
+	assert.Equal(t, 0, state.CounterValue, "Expected counter to be reset")
+	assert.Equal(t, dataloader.WorkerRan, state.WorkerState, "Expected worker to have run")
+	assert.False(t, state.LastFlush.IsZero(), "Expected flush time")
+}
+
+// TestBatchFunctionPanic ensures a panic in the batch function resolves the callers keys to an error
+func TestBatchFunctionPanic(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		panic("batch failure")
+	}
+	strategy := sozu.NewSozuStrategy()(2, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(2))
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Error(t, r.Err, "Expected panic to be returned as an error")
+	v, ok := rm.GetValue(PrimaryKey(2))
+	assert.True(t, ok, "Expected result for LoadMany key")
+	assert.Error(t, v.Err, "Expected panic to be returned as an error")
+}
@@ -2,61 +2,51 @@ package standard
 
 import (
 	"context"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/andy9775/dataloader/strategies"
+	"github.com/andy9775/dataloader/strategies/options"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
 
-	"github.com/go-log/log"
+	"github.com/andy9775/dataloader/logger"
 )
 
-// Options contains the strategy configuration
-type options struct {
-	timeout time.Duration
-	logger  log.Logger
-}
-
-// Option accepts the dataloader and sets an option on it.
-type Option func(*options)
+// Option accepts the strategy options and sets an option on them. Options from the options package are
+// accepted by the standard, sozu and once strategies.
+type Option = options.Option
 
 // go routine status values
 // Ensure that only one worker go routine is working to call the batch function
 const (
 	notRunning = 0 // go routine default start value
 	running    = 1 // go routine is waiting for keys array to fill up
-	ran        = 2 // go routine ran
+	ran        = 2 // go routine ran and a new worker starts on the next load
 )
 
 // NewStandardStrategy returns a new instance of the standard strategy.
-// The Standard Strategy, calls the batch function once when the keys array reaches
-// capacity then subsequent calls to `Load()` will call the batch function with
-// the individual keys.
+// The Standard Strategy, calls the batch function when the keys array reaches
+// capacity or after the timeout. Once a batch completes, the next call to `Load()`
+// starts a new batch so keys keep being batched for the lifetime of the strategy.
 func NewStandardStrategy(opts ...Option) dataloader.StrategyFunction {
 	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
-		// default options
-		o := options{}
-		formatOptions(&o)
-
-		// format options
-		for _, apply := range opts {
-			apply(&o)
-		}
+		o := options.New(opts...)
 
 		return &standardStrategy{
-			batchFunc: batch,
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.Hooks), o.Logger),
+			capacity:  capacity,
 			counter:   strategies.NewCounter(capacity),
 
 			workerMutex:     &sync.Mutex{},
 			goroutineStatus: notRunning,
 
-			keyChan:   make(chan workerMessage, capacity),
-			closeChan: make(chan struct{}),
-			options:   o,
+			keyChan: make(chan workerMessage, capacity),
+			options: o,
 
-			keys: dataloader.NewKeys(capacity),
+			keys: dataloader.NewKeys(capacity, dataloader.WithKeysDedup(o.KeyDedup)),
 		}
 	}
 }
@@ -65,22 +55,57 @@ func NewStandardStrategy(opts ...Option) dataloader.StrategyFunction {
 
 // WithTimeout sets the timeout value for the strategy
 func WithTimeout(t time.Duration) Option {
-	return func(o *options) {
-		o.timeout = t
-	}
+	return options.WithTimeout(t)
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return options.WithDeadlineBudget(d)
+}
+
+// WithBackpressure sets the policy applied to loads made while the key channel of the worker is full, e.g.
+// because the worker is calling the batch function. Defaults to options.Block.
+func WithBackpressure(b options.Backpressure) Option {
+	return options.WithBackpressure(b)
+}
+
+// WithKeyDedup sets how the worker detects duplicate keys in a batch, e.g. dataloader.DedupByString for
+// pointer keys. Defaults to dataloader.DedupByKey.
+func WithKeyDedup(d dataloader.KeyDedup) Option {
+	return options.WithKeyDedup(d)
+}
+
+// WithClock sets the time source of the strategies timeouts, e.g. a clock.Mock controlling the timeout in
+// tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return options.WithClock(c)
 }
 
 // WithLogger configures the logger for the strategy. Default is a no op logger.
-func WithLogger(l log.Logger) Option {
-	return func(o *options) {
-		o.logger = l
-	}
+func WithLogger(l logger.Logger) Option {
+	return options.WithLogger(l)
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return options.WithHooks(h)
 }
 
 // ===========================================================================================================
 
 type standardStrategy struct {
-	counter strategies.Counter
+	// pendingKeys, lastFlush (unix nano), timeouts, saturations and queued are accessed atomically and must
+	// be first in the struct to ensure 64-bit alignment
+	pendingKeys int64
+	lastFlush   int64
+	timeouts    uint64
+	saturations uint64
+	queued      int64 // messages sent to, but not yet received by, a worker
+
+	counter  strategies.Counter
+	capacity int
 	// Track the keys to pass to the batch function. Once len(keys) == cap(keys),
 	// the batch loading function is called with the keys to resolve.
 	keys      dataloader.Keys
@@ -89,60 +114,52 @@ type standardStrategy struct {
 	workerMutex     *sync.Mutex
 	goroutineStatus int
 
-	keyChan   chan workerMessage
-	closeChan chan struct{}
+	keyChan chan workerMessage
 
-	options options
+	options options.Options
 }
 
 type workerMessage struct {
+	ctx        context.Context
 	k          []dataloader.Key
 	resultChan chan dataloader.ResultMap
+	flush      bool // call the batch function with the pending keys
 }
 
 // Load returns a Thunk function for the specified Key.
 // Internally Load adds the Key to the Keys array and returns a (blocking) Thunk function which
 // when called returns a value for the provided key.
 func (s *standardStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
-	s.startWorker(ctx)
-
 	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block in results loop
-	message := workerMessage{k: []dataloader.Key{key}, resultChan: resultChan}
-	s.keyChan <- message // pass key to the worker go routine (buffered channel)
+	s.send(workerMessage{ctx: ctx, k: []dataloader.Key{key}, resultChan: resultChan})
 
 	var result dataloader.Result
-	var ok bool
+	var ok, resolved bool
 
 	return func() (dataloader.Result, bool) {
-		if result.Result != nil || result.Err != nil {
+		if resolved {
 			return result, ok
 		}
+		defer func() { resolved = true }()
 
 		/*
 			Dual select statements allow prioritization of cases in situations where both channels have data.
-			In instances where the first select goes to the default case (no message), but before going to the
-			second select data is placed the resultChan and the closeChan is closed (data on both) the second
-			select block will either resolve the first or second case (50/50). Although highly unlikely, in this
-			case the batch function will be executed (50% of the time) in the go routine even though there is
-			data available for the keys
+			A result which is already available is returned even though the context has been cancelled.
 		*/
+		var r dataloader.ResultMap
 		select {
-		case r := <-resultChan:
-			result, ok = r.GetValue(key)
-			return result, ok
+		case r = <-resultChan:
 		default:
+			select {
+			case <-ctx.Done():
+				result, ok = strategies.CancelledResult(ctx), true
+				return result, ok
+			case r = <-resultChan:
+			}
 		}
 
-		select {
-		case <-ctx.Done():
-			return dataloader.Result{Result: nil, Err: nil}, false
-		case r := <-resultChan:
-			result, ok = r.GetValue(key)
-			return result, ok
-		case <-s.closeChan:
-			result, ok = (*s.batchFunc(ctx, dataloader.NewKeysWith(key))).GetValue(key)
-			return result, ok
-		}
+		result, ok = r.GetValue(key)
+		return result, ok
 	}
 }
 
@@ -150,13 +167,11 @@ func (s *standardStrategy) Load(ctx context.Context, key dataloader.Key) dataloa
 // Internally, LoadMany adds the keyArr to the keys array and returns a (blocking) ThunkMany function
 // which when called returns values for the provided keys.
 func (s *standardStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
-	s.startWorker(ctx)
-
 	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block in results loop
-	message := workerMessage{k: keyArr, resultChan: resultChan}
-	s.keyChan <- message
+	s.send(workerMessage{ctx: ctx, k: keyArr, resultChan: resultChan})
 
 	var resultMap dataloader.ResultMap
+	var resolved bool
 
 	return func() dataloader.ResultMap {
 		/*
@@ -166,109 +181,275 @@ func (s *standardStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Ke
 			iterate through the keys and only get it's own data
 		*/
 
-		if resultMap != nil {
+		if resolved {
 			return resultMap
 		}
+		defer func() { resolved = true }()
 
 		/*
 			See comments in Load method RE: dual select statements
 		*/
+		var r dataloader.ResultMap
 		select {
-		case r := <-resultChan:
-			resultMap = buildResultMap(keyArr, r)
-			return resultMap
+		case r = <-resultChan:
 		default:
+			select {
+			case <-ctx.Done():
+				resultMap = strategies.CancelledResultMap(ctx, keyArr...)
+				return resultMap
+			case r = <-resultChan:
+			}
 		}
 
-		select {
-		case <-ctx.Done():
-			return dataloader.NewResultMap(0)
-		case r := <-resultChan:
-			resultMap = buildResultMap(keyArr, r)
-			return resultMap
-		case <-s.closeChan: // batch the keys if closed
-			r := *s.batchFunc(ctx, dataloader.NewKeysWith(keyArr...))
-			resultMap = buildResultMap(keyArr, r)
-			return resultMap
-		}
+		resultMap = buildResultMap(keyArr, r)
+		return resultMap
 	}
 }
 
 // LoadNoOp passes a nil value to the strategy worker and doesn't block the caller.
 // Internally it increments the load counter ensuring the batch function is called on time.
 func (s *standardStrategy) LoadNoOp(ctx context.Context) {
-	s.startWorker(ctx) // start the worker in case the first caller is a cache success
+	// start the worker in case the first caller is a cache success
+	s.send(workerMessage{ctx: ctx, k: nil, resultChan: nil})
+}
+
+// Flush signals a running worker to call the batch function with the keys it has received so far.
+// Keys passed to Load or LoadMany before Flush are included in the batch.
+func (s *standardStrategy) Flush(ctx context.Context) {
+	s.workerMutex.Lock()
+	defer s.workerMutex.Unlock()
+
+	if s.goroutineStatus != running {
+		return
+	}
+
+	/*
+		Don't block if the channel is full. The counter shares the capacity of the channel so
+		the worker will reach capacity and call the batch function without the flush message.
+	*/
+	select {
+	case s.keyChan <- workerMessage{flush: true}:
+	default:
+	}
+}
 
-	// LoadNoOp passes a nil value to the strategy worker and doesn't block the caller.
-	message := workerMessage{k: nil, resultChan: nil}
-	s.keyChan <- message
+// State returns a snapshot of the strategies internal state
+func (s *standardStrategy) State() dataloader.StrategyState {
+	s.workerMutex.Lock()
+	status := s.goroutineStatus
+	s.workerMutex.Unlock()
+
+	var lastFlush time.Time
+	if ns := atomic.LoadInt64(&s.lastFlush); ns != 0 {
+		lastFlush = time.Unix(0, ns)
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  int(atomic.LoadInt64(&s.pendingKeys)),
+		Capacity:     s.capacity,
+		CounterValue: s.counter.Count(),
+		WorkerState:  workerState(status),
+		LastFlush:    lastFlush,
+		Timeouts:     atomic.LoadUint64(&s.timeouts),
+		Saturations:  atomic.LoadUint64(&s.saturations),
+	}
+}
+
+// Name returns the name of the strategy
+func (*standardStrategy) Name() string {
+	return "standard"
 }
 
 // ============================================== private =============================================
 
-// startWorker starts the background go routine if not already running for this strategy instance.
-// The worker accepts keys via an internal channel and calls the batch function once full.
-func (s *standardStrategy) startWorker(ctx context.Context) {
-	s.workerMutex.Lock() // ensure only one worker is started
-	defer s.workerMutex.Unlock()
+// batch calls the batch function with the pending keys and records the time of the call
+func (s *standardStrategy) batch(ctx context.Context) *dataloader.ResultMap {
+	atomic.StoreInt64(&s.lastFlush, s.options.Clock.Now().UnixNano())
+	return s.batchFunc(ctx, s.keys)
+}
 
-	if s.goroutineStatus == notRunning {
+// send passes the message to the worker go routine, starting a new worker if none is running.
+// Messages are counted before they are sent so that an exiting worker can hand them over to a new worker.
+// If the key channel is full the message is handled according to the backpressure policy.
+func (s *standardStrategy) send(message workerMessage) {
+	s.workerMutex.Lock()
+	atomic.AddInt64(&s.queued, 1)
+	if s.goroutineStatus != running {
 		s.goroutineStatus = running
-		s.closeChan = make(chan struct{})
-
-		go func(ctx context.Context) {
-			subscribers := make([]chan dataloader.ResultMap, 0, s.keys.Capacity())
-			s.options.logger.Logf("starting new worker with capacity: %d", s.keys.Capacity())
+		go s.work()
+	}
+	s.workerMutex.Unlock()
 
-			defer func() {
-				s.workerMutex.Lock()
-				defer s.workerMutex.Unlock()
+	select {
+	case s.keyChan <- message: // pass the message to the worker go routine (buffered channel)
+		return
+	default:
+	}
 
-				s.goroutineStatus = ran
-				s.keys.ClearAll()
-				s.counter.ResetCount()
-				close(s.closeChan)
+	atomic.AddUint64(&s.saturations, 1)
+	s.options.Logger.Debug("key channel full", "keys", len(message.k), "backpressure", s.options.Backpressure)
+
+	switch s.options.Backpressure {
+	case options.Grow:
+		go func() { s.keyChan <- message }()
+	case options.Drop:
+		atomic.AddInt64(&s.queued, -1)
+		if message.resultChan != nil {
+			message.resultChan <- strategies.DroppedResultMap(message.k...)
+			close(message.resultChan)
+		}
+	case options.Flush:
+		atomic.AddInt64(&s.queued, -1)
+		if message.resultChan != nil {
+			go func() {
+				atomic.StoreInt64(&s.lastFlush, s.options.Clock.Now().UnixNano())
+				message.resultChan <- *s.batchFunc(message.ctx, dataloader.NewKeysWith(message.k...))
+				close(message.resultChan)
 			}()
+		}
+	default:
+		s.keyChan <- message
+	}
+}
 
-			// loop while adding keys or timeout
-			var r *dataloader.ResultMap
-			for r == nil {
-				select {
-				case <-ctx.Done():
-					s.options.logger.Logf("worker cancelled")
-					return
-				case key := <-s.keyChan:
-					// if LoadNoOp passes a value through the chan, ignore the data and increment the counter
-					if key.resultChan != nil {
-						subscribers = append(subscribers, key.resultChan)
-					}
-					if key.k != nil {
-						s.keys.Append(key.k...)
-					}
-
-					if s.counter.Increment() { // hit capacity
-						r = s.batchFunc(ctx, s.keys)
-					}
-				case <-time.After(s.options.timeout):
-					s.options.logger.Logf("worker timing out with %d keys", s.keys.Length())
-					r = s.batchFunc(ctx, s.keys)
+// work runs a single batch round. The worker accepts keys via an internal channel and calls the
+// batch function once full, flushed or timed out. The round uses the context of its first message
+// until it is done, then the context of a message whose context is live.
+func (s *standardStrategy) work() {
+	subscribers := make([]workerMessage, 0, s.keys.Capacity())
+	s.options.Logger.Debug("starting new worker", "capacity", s.keys.Capacity())
+
+	defer func() {
+		s.workerMutex.Lock()
+		defer s.workerMutex.Unlock()
+
+		s.keys.ClearAll()
+		s.counter.ResetCount()
+		atomic.StoreInt64(&s.pendingKeys, 0)
+
+		// messages sent while the round completed belong to the next round
+		if atomic.LoadInt64(&s.queued) > 0 {
+			go s.work()
+			return
+		}
+		s.goroutineStatus = ran
+	}()
+
+	// wait for the first message of the round
+	var ctx context.Context
+	var flushBy time.Time // earliest time the batch function must be called to meet the pending deadlines
+	for ctx == nil {
+		message := <-s.keyChan
+		if message.flush {
+			continue // ignore flushes without pending loads (e.g. sent as the previous round completed)
+		}
+		ctx = message.ctx
+		flushBy = s.receive(message, &subscribers, flushBy)
+	}
+
+	// loop while adding keys or timeout
+	var r *dataloader.ResultMap
+	if s.counter.Increment() { // hit capacity
+		r = s.batch(ctx)
+	}
+	for r == nil {
+		select {
+		case <-ctx.Done():
+			s.options.Logger.Info("worker cancelled", "keys", s.keys.Length(), "error", ctx.Err())
+			if ctx, flushBy = s.cancel(&subscribers); ctx == nil {
+				return
+			}
+		case message := <-s.keyChan:
+			if message.flush {
+				// ignore flushes without pending loads (e.g. a round started by LoadNoOp)
+				if len(subscribers) > 0 {
+					s.options.Logger.Debug("worker flushing", "keys", s.keys.Length())
+					r = s.batch(ctx)
 				}
+				continue
 			}
 
-			for _, ch := range subscribers {
-				ch <- *r
-				close(ch)
+			flushBy = s.receive(message, &subscribers, flushBy)
+			if s.counter.Increment() { // hit capacity
+				r = s.batch(ctx)
 			}
-		}(ctx)
+		case <-s.options.Clock.After(strategies.FlushTimeout(s.options.Clock, s.options.Timeout, flushBy)):
+			s.options.Logger.Debug("worker timing out", "keys", s.keys.Length(), "timeout", s.options.Timeout)
+			atomic.AddUint64(&s.timeouts, 1)
+			r = s.batch(ctx)
+		}
+	}
+
+	for _, sub := range subscribers {
+		sub.resultChan <- *r
+		close(sub.resultChan)
+	}
+}
+
+// cancel resolves the subscribers whose context is done to the context error and keeps the keys of the
+// remaining subscribers pending, so that they are passed to the batch function together. It returns the
+// context of a remaining subscriber to continue the round with and the time by which the round must be
+// flushed, or a nil context if none remain.
+func (s *standardStrategy) cancel(subscribers *[]workerMessage) (context.Context, time.Time) {
+	var ctx context.Context
+	var flushBy time.Time
+	live := (*subscribers)[:0]
+	s.keys.ClearAll()
+	for _, sub := range *subscribers {
+		if sub.ctx.Err() != nil {
+			sub.resultChan <- strategies.CancelledResultMap(sub.ctx, sub.k...)
+			close(sub.resultChan)
+			continue
+		}
+
+		if ctx == nil {
+			ctx = sub.ctx
+		}
+		live = append(live, sub)
+		s.keys.Append(sub.k...)
+		flushBy = strategies.EarliestFlush(sub.ctx, s.options.Clock, flushBy, s.options.DeadlineBudget)
+	}
+	*subscribers = live
+	atomic.StoreInt64(&s.pendingKeys, int64(s.keys.Length()))
+
+	if ctx != nil {
+		s.options.Logger.Debug("worker continuing with live loads", "loads", len(live), "keys", s.keys.Length())
+	}
+	return ctx, flushBy
+}
+
+// receive adds the keys and result channel of a message to the current round and returns the time by
+// which the round must be flushed
+func (s *standardStrategy) receive(
+	message workerMessage,
+	subscribers *[]workerMessage,
+	flushBy time.Time,
+) time.Time {
+	atomic.AddInt64(&s.queued, -1)
+
+	// if LoadNoOp passes a value through the chan, ignore the data and increment the counter
+	if message.resultChan != nil {
+		*subscribers = append(*subscribers, message)
+	}
+	if message.k != nil {
+		s.keys.Append(message.k...)
+		atomic.StoreInt64(&s.pendingKeys, int64(s.keys.Length()))
 	}
+	return strategies.EarliestFlush(message.ctx, s.options.Clock, flushBy, s.options.DeadlineBudget)
 }
 
 // ============================================== helpers =============================================
 
-// formatOptions configures default values for the loader options
-func formatOptions(opts *options) {
-	opts.timeout = 16 * time.Millisecond
-	opts.logger = log.DefaultLogger
+// workerState maps the go routine status to the reported worker state
+func workerState(status int) dataloader.WorkerState {
+	switch status {
+	case running:
+		return dataloader.WorkerRunning
+	case ran:
+		return dataloader.WorkerRan
+	default:
+		return dataloader.WorkerNotRunning
+	}
 }
 
 // buildResultMap filters through the provided result map and returns an ResultMap
@@ -282,7 +463,5 @@ func buildResultMap(keyArr []dataloader.Key, r dataloader.ResultMap) dataloader.
 		}
 	}
 
-	fmt.Printf("r: %+v\nresults: %+v\n", r, results)
-
 	return results
 }
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/logger"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/andy9775/dataloader/strategies/options"
 	"github.com/andy9775/dataloader/strategies/standard"
-	"github.com/bouk/monkey"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,39 +70,9 @@ func timeout(t *testing.T, timeoutChannel chan struct{}, after time.Duration) {
 	}()
 }
 
-type mockLogger struct {
-	logMsgs []string
-	m       sync.Mutex
-}
-
-func (l *mockLogger) Log(v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	for _, value := range v {
-		switch i := value.(type) {
-		case string:
-			l.logMsgs = append(l.logMsgs, i)
-		default:
-			panic("mock logger only takes single log string")
-		}
-	}
-}
-
-func (l *mockLogger) Logf(format string, v ...interface{}) {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	l.logMsgs = append(l.logMsgs, fmt.Sprintf(format, v...))
-}
-
-func (l *mockLogger) Messages() []string {
-	l.m.Lock()
-	defer l.m.Unlock()
-
-	result := make([]string, len(l.logMsgs))
-	copy(result, l.logMsgs)
-	return result
+// timeouts returns the number of times the strategy called the batch function after timing out
+func timeouts(strategy dataloader.Strategy) uint64 {
+	return strategy.(dataloader.Introspectable).State().Timeouts
 }
 
 // ================================================== tests ==================================================
@@ -123,34 +97,18 @@ func TestLoadNoTimeout(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := standard.NewStandardStrategy(standard.WithTimeout(timeout))(3, batch) // expects 3 load calls
+	strategy := standard.NewStandardStrategy(standard.WithClock(clk))(3, batch) // expects 3 load calls
 
 	// invoke/assert
 	strategy.Load(context.Background(), key)           // --------- Load 		 - call 1
@@ -172,7 +130,7 @@ func TestLoadNoTimeout(t *testing.T) {
 		"Expected result from thunk()",
 	)
 
-	assert.False(t, timedOut, "Expected loader not to timeout")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Expected loader not to timeout")
 
 	// test double call to thunk
 	r, ok = thunk()
@@ -186,6 +144,46 @@ func TestLoadNoTimeout(t *testing.T) {
 	assert.Equal(t, 1, callCount, "Batch function expected to be called once")
 }
 
+// TestLoadNoTimeoutSuccessiveRounds ensures keys loaded after the first batch are batched together again
+func TestLoadNoTimeoutSuccessiveRounds(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var m sync.Mutex
+	var calls [][]interface{}
+	cb := func(keys dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		calls = append(calls, keys.Keys())
+	}
+
+	batch := getBatchFunction(cb, "rounds")
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT*5))(2, batch) // expects 2 load calls
+
+	// invoke
+	thunk1 := strategy.Load(context.Background(), PrimaryKey(1))
+	thunk2 := strategy.Load(context.Background(), PrimaryKey(2))
+	thunk1()
+	thunk2()
+
+	thunk3 := strategy.Load(context.Background(), PrimaryKey(3))
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(4))
+	r, ok := thunk3()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "3_rounds", r.Result.(string), "Expected result from the second round")
+	assert.Equal(t, 1, rm.Length(), "Expected result for LoadMany key")
+	assert.Equal(t, 2, len(calls), "Expected batch function to be called once per round")
+	assert.ElementsMatch(t, []interface{}{PrimaryKey(1), PrimaryKey(2)}, calls[0], "Expected first round keys")
+	assert.ElementsMatch(t, []interface{}{PrimaryKey(3), PrimaryKey(4)}, calls[1], "Expected second round keys")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, uint64(0), state.Timeouts, "Expected rounds to be batched at capacity")
+}
+
 // TestLoadManyNoTimeout tests calling the load function without timing out
 func TestLoadManyNoTimeout(t *testing.T) {
 	// setup
@@ -204,35 +202,19 @@ func TestLoadManyNoTimeout(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		close(closeChan)
 		wg.Done()
 	}
 
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock() // never advanced, so the worker can't time out
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 	key3 := PrimaryKey(3)
 
-	/*
-		ensure the loader doesn't call batch after timeout. If it does, the test will timeout and panic
-	*/
-	timeout := TEST_TIMEOUT * 5
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := standard.NewStandardStrategy(standard.WithTimeout(timeout))(3, batch) // expects 3 load calls
+	strategy := standard.NewStandardStrategy(standard.WithClock(clk))(3, batch) // expects 3 load calls
 
 	// invoke/assert
 	strategy.LoadMany(context.Background(), key)                 // --------- LoadMany 		 - call 1
@@ -255,7 +237,7 @@ func TestLoadManyNoTimeout(t *testing.T) {
 		"Expected result from thunk()",
 	)
 
-	assert.False(t, timedOut, "Expected loader not to timeout")
+	assert.Equal(t, uint64(0), timeouts(strategy), "Expected loader not to timeout")
 
 	// test double call to thunk
 	r = thunk()
@@ -291,48 +273,34 @@ func TestLoadTimeout(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		if callCount == 2 {
 			close(closeChan)
 		}
 		wg.Done()
 	}
 
-	toWG := sync.WaitGroup{}
-	toWG.Add(1)
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toWG.Done()
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock()
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := standard.NewStandardStrategy()(3, batch) // expects 3 load calls
+	strategy := standard.NewStandardStrategy(standard.WithClock(clk))(3, batch) // expects 3 load calls
 
 	// invoke/assert
 
 	thunk2 := strategy.Load(context.Background(), key2) // --------- Load 		 - call 1
 	strategy.LoadNoOp(context.Background())             // --------- LoadNoOp  - call 2
 	assert.Equal(t, 0, callCount, "Load() not expected to block or call batch function")
+	clk.BlockUntil(2) // the worker waits on a new timeout after each load
+	clk.Add(16 * time.Millisecond)
 	blockWG.Done()
 	wg.Wait()
 
-	toWG.Wait()
 	assert.Equal(t, 1, callCount, "Batch function expected to be called once")
 	assert.Equal(t, 1, len(k), "Expected to be called with 1 key")
-	assert.True(t, timedOut, "Expected loader to timeout")
+	assert.Equal(t, uint64(1), timeouts(strategy), "Expected loader to timeout")
 
 	r, ok := thunk2()
 	assert.True(t, ok, "Expected result to have been found")
@@ -343,13 +311,15 @@ func TestLoadTimeout(t *testing.T) {
 		"Expected result from thunk()",
 	)
 
-	// don't wait below - callback is not executing in background go routine - ensure wg doesn't go negative
+	// don't wait below - the thunk blocks until the second round completes - ensure wg doesn't go negative
 	wg.Add(1)
 	thunk := strategy.Load(context.Background(), key) // --------- Load 		 - call 3
+	clk.BlockUntil(1)
+	clk.Add(16 * time.Millisecond)
 	r, ok = thunk()
 	assert.True(t, ok, "Expected result to have been found")
 
-	// called once in go routine after timeout, once in thunk
+	// called once in go routine after timeout, once by the worker of the second round
 	assert.Equal(t, 2, callCount, "Batch function expected to be called twice")
 	assert.Equal(t,
 		fmt.Sprintf("1_%s", expectedResult),
@@ -361,7 +331,7 @@ func TestLoadTimeout(t *testing.T) {
 	r, ok = thunk()
 	assert.True(t, ok, "Expected result to have been found")
 
-	// called once in go routine after timeout, once in thunk
+	// called once in go routine after timeout, once by the worker of the second round
 	assert.Equal(t, 2, callCount, "Batch function expected to be called twice")
 	assert.Equal(t,
 		fmt.Sprintf("1_%s", expectedResult),
@@ -389,49 +359,35 @@ func TestLoadManyTimeout(t *testing.T) {
 	cb := func(keys dataloader.Keys) {
 		blockWG.Wait()
 		callCount += 1
-		k = keys.RawKeys()
+		k = keys.Keys()
 		if callCount == 2 {
 			close(closeChan)
 		}
 		wg.Done()
 	}
 
-	toWG := sync.WaitGroup{}
-	toWG.Add(1)
-	timedOut := false
-	monkey.Patch(time.After, func(t time.Duration) <-chan time.Time {
-		defer monkey.Unpatch(time.After)
-		toChan := make(chan time.Time, 1)
-
-		go func() {
-			time.Sleep(t)
-			timedOut = true
-			toWG.Done()
-			toChan <- time.Now()
-		}()
-
-		return toChan
-	})
+	clk := clock.NewMock()
 
 	key := PrimaryKey(1)
 	key2 := PrimaryKey(2)
 	key3 := PrimaryKey(3)
 
 	batch := getBatchFunction(cb, expectedResult)
-	strategy := standard.NewStandardStrategy()(3, batch) // expects 3 load calls
+	strategy := standard.NewStandardStrategy(standard.WithClock(clk))(3, batch) // expects 3 load calls
 
 	// invoke/assert
 
 	thunkMany2 := strategy.LoadMany(context.Background(), key2) // --------- LoadMany 		 - call 1
 	strategy.LoadNoOp(context.Background())                     // --------- LoadNoOp      - call 2
 	assert.Equal(t, 0, callCount, "Load() not expected to block or call batch function")
+	clk.BlockUntil(2) // the worker waits on a new timeout after each load
+	clk.Add(16 * time.Millisecond)
 	blockWG.Done()
 	wg.Wait()
 
-	toWG.Wait()
 	assert.Equal(t, 1, callCount, "Batch function expected to be called once")
 	assert.Equal(t, 1, len(k), "Expected to be called with 1 key")
-	assert.True(t, timedOut, "Expected loader to timeout")
+	assert.Equal(t, uint64(1), timeouts(strategy), "Expected loader to timeout")
 
 	r := thunkMany2()
 	returned, ok := r.GetValue(key2)
@@ -443,12 +399,14 @@ func TestLoadManyTimeout(t *testing.T) {
 		"Expected result from thunkMany()",
 	)
 
-	// don't wait below - callback is not executing in background go routine - ensure wg doesn't go negative
+	// don't wait below - the thunk blocks until the second round completes - ensure wg doesn't go negative
 	wg.Add(1)
 	thunkMany := strategy.LoadMany(context.Background(), key, key3) // --------- LoadMany 		 - call 3
+	clk.BlockUntil(1)
+	clk.Add(16 * time.Millisecond)
 	r = thunkMany()
 
-	// called once in go routine after timeout, once in thunkMany
+	// called once in go routine after timeout, once by the worker of the second round
 	assert.Equal(t, 2, callCount, "Batch function expected to be called twice")
 	returned, ok = r.GetValue(key3)
 	assert.True(t, ok, "Expected result to have been found")
@@ -462,7 +420,7 @@ func TestLoadManyTimeout(t *testing.T) {
 	// test double call to thunk
 	r = thunkMany()
 
-	// called once in go routine after timeout, once in thunkMany
+	// called once in go routine after timeout, once by the worker of the second round
 	assert.Equal(t, 2, callCount, "Batch function expected to be called twice")
 	returned, ok = r.GetValue(key3)
 	assert.True(t, ok, "Expected result to have been found")
@@ -485,24 +443,26 @@ func TestCancellableContextLoad(t *testing.T) {
 	expectedResult := "cancel_via_context"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		close(closeChan)
 	}
 
 	key := PrimaryKey(1)
-	log := mockLogger{logMsgs: make([]string, 2), m: sync.Mutex{}}
+	log := logger.Capture()
 	batch := getBatchFunction(cb, expectedResult)
 	strategy := standard.NewStandardStrategy(
-		standard.WithLogger(&log),
+		standard.WithLogger(log),
 	)(2, batch) // expected 2 load calls
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// invoke
 	cancel()
 	thunk := strategy.Load(ctx, key)
-	thunk()
+	r, ok := thunk()
 	time.Sleep(100 * time.Millisecond)
+	close(closeChan)
 
 	// assert
+	assert.True(t, ok, "Expected the cancelled key to resolve")
+	assert.Equal(t, context.Canceled, r.Err, "Expected the context error")
 	assert.Equal(t, 0, callCount, "Batch should not have been called")
 	m := log.Messages()
 	assert.Equal(t, "worker cancelled", m[len(m)-1], "Expected worker to cancel and log exit")
@@ -518,29 +478,76 @@ func TestCancellableContextLoadMany(t *testing.T) {
 	expectedResult := "cancel_via_context"
 	cb := func(keys dataloader.Keys) {
 		callCount += 1
-		close(closeChan)
 	}
 
 	key := PrimaryKey(1)
-	log := mockLogger{logMsgs: make([]string, 2), m: sync.Mutex{}}
+	log := logger.Capture()
 	batch := getBatchFunction(cb, expectedResult)
 	strategy := standard.NewStandardStrategy(
-		standard.WithLogger(&log),
+		standard.WithLogger(log),
 	)(2, batch) // expected 2 load calls
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// invoke
 	cancel()
 	thunk := strategy.LoadMany(ctx, key)
-	thunk()
+	r := thunk()
 	time.Sleep(100 * time.Millisecond)
+	close(closeChan)
 
 	// assert
+	v, ok := r.GetValue(key)
+	assert.True(t, ok, "Expected the cancelled key to resolve")
+	assert.Equal(t, context.Canceled, v.Err, "Expected the context error")
 	assert.Equal(t, 0, callCount, "Batch should not have been called")
 	m := log.Messages()
 	assert.Equal(t, "worker cancelled", m[len(m)-1], "Expected worker to cancel and log exit")
 }
 
+// TestCancelledRoundContext ensures the loads with a live context are batched together once the context of
+// the round is cancelled, while the cancelled load resolves to the context error
+func TestCancelledRoundContext(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var callCount int32
+	var k []interface{}
+	cb := func(keys dataloader.Keys) {
+		atomic.AddInt32(&callCount, 1)
+		k = keys.Keys()
+	}
+
+	batch := getBatchFunction(cb, "live")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// invoke
+	cancelled := strategy.Load(ctx, PrimaryKey(1))
+	thunk := strategy.Load(context.Background(), PrimaryKey(2))
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(3))
+	cancel()
+	assert.Eventually(t, func() bool {
+		return strategy.(dataloader.Introspectable).State().PendingKeys == 2
+	}, TEST_TIMEOUT, time.Millisecond, "Expected the cancelled key to be removed from the round")
+	strategy.(dataloader.Flusher).Flush(context.Background())
+
+	c, cancelledOK := cancelled()
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, cancelledOK, "Expected the cancelled key to resolve")
+	assert.Equal(t, context.Canceled, c.Err, "Expected the context error")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "2_live", r.Result.(string), "Expected result for key")
+	assert.Equal(t, "3_live", rm.GetValueForString("3").Result.(string), "Expected result for LoadMany key")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&callCount), "Expected batch function to be called once")
+	assert.ElementsMatch(t, []interface{}{PrimaryKey(2), PrimaryKey(3)}, k, "Expected the live keys to be batched")
+}
+
 // =============================================== result keys ===============================================
 // TestKeyHandling ensure that the strategy properly handles unprocessed and nil keys
 func TestKeyHandling(t *testing.T) {
@@ -607,3 +614,347 @@ func TestKeyHandling(t *testing.T) {
 
 	}
 }
+
+// TestMissingKeyNotFound ensures a key without a result resolves to a not found error which is returned
+// again by later calls to the thunk
+func TestMissingKeyNotFound(t *testing.T) {
+	// setup
+	var calls int32
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		atomic.AddInt32(&calls, 1)
+		m := dataloader.NewResultMap(0)
+		return &m
+	}
+	strategy := standard.NewStandardStrategy()(1, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	r, ok := thunk()
+	r2, ok2 := thunk()
+
+	// assert
+	assert.False(t, ok, "Expected the missing result to not be found")
+	assert.True(t, dataloader.IsNotFound(r.Err), "Expected a not found error")
+	assert.False(t, ok2, "Expected the missing result to not be found")
+	assert.Equal(t, r, r2, "Expected the same result for later calls")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected a single batch function call")
+}
+
+// TestFlush ensures flushing calls the batch function with the pending keys before reaching capacity
+func TestFlush(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	callCount := 0
+	var k []interface{}
+	cb := func(keys dataloader.Keys) {
+		callCount += 1
+		k = keys.Keys()
+	}
+
+	key := PrimaryKey(1)
+	key2 := PrimaryKey(2)
+	batch := getBatchFunction(cb, "flushed")
+	// timeout ensures the batch function is not called by the worker timing out
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), key)
+	thunkMany := strategy.LoadMany(context.Background(), key2)
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_flushed", r.Result.(string), "Expected flushed result")
+	assert.Equal(t, 1, rm.Length(), "Expected result for LoadMany key")
+	assert.Equal(t, 1, callCount, "Expected batch function to be called once")
+	assert.ElementsMatch(t, []interface{}{key, key2}, k, "Expected pending keys to be flushed")
+}
+
+// TestFlushBeforeDeadline ensures the batch function is called with the budget left before the earliest
+// deadline of the pending loads rather than after the timeout
+func TestFlushBeforeDeadline(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	var m sync.Mutex
+	var k []interface{}
+	batch := getBatchFunction(func(keys dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		k = keys.Keys()
+	}, "deadline")
+	strategy := standard.NewStandardStrategy(
+		standard.WithTimeout(TEST_TIMEOUT*5),
+		standard.WithDeadlineBudget(50*time.Millisecond),
+	)(10, batch)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// invoke
+	start := time.Now()
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	thunkMany := strategy.LoadMany(ctx, PrimaryKey(2))
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, time.Since(start) < 100*time.Millisecond, "Expected batch to be called before the deadline")
+	assert.NoError(t, ctx.Err(), "Expected the deadline not to have passed")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_deadline", r.Result.(string), "Expected result from the batch")
+	assert.Equal(t, 1, rm.Length(), "Expected result for LoadMany key")
+	assert.ElementsMatch(t, []interface{}{PrimaryKey(1), PrimaryKey(2)}, k, "Expected keys to be batched together")
+}
+
+// TestFlushOnLoadTimeout ensures a load timeout shortens the time the pending keys wait
+func TestFlushOnLoadTimeout(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batch := getBatchFunction(func(dataloader.Keys) {}, "load_timeout")
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT*5))(10, batch)
+	loader := dataloader.NewDataLoader(10, batch, func(int, dataloader.BatchFunction) dataloader.Strategy {
+		return strategy
+	})
+
+	// invoke
+	start := time.Now()
+	thunk := loader.Load(context.Background(), PrimaryKey(1), dataloader.WithLoadTimeout(10*time.Millisecond))
+	r, ok := thunk()
+	close(closeChan)
+
+	// assert
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected batch to be called after the load timeout")
+	assert.True(t, ok, "Expected result for key")
+	assert.Equal(t, "1_load_timeout", r.Result.(string), "Expected result from the batch")
+}
+
+// TestState ensures the strategy reports pending keys, the counter and worker state
+func TestState(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batch := getBatchFunction(func(dataloader.Keys) {}, "state")
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT*5))(5, batch)
+	introspectable := strategy.(dataloader.Introspectable)
+
+	// invoke / assert
+	state := introspectable.State()
+	assert.Equal(t, dataloader.WorkerNotRunning, state.WorkerState, "Expected worker not to be started")
+	assert.Equal(t, 5, state.Capacity, "Expected capacity")
+
+	thunk := strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))
+	strategy.LoadNoOp(context.Background())
+	time.Sleep(10 * time.Millisecond) // allow the worker to receive the keys
+	state = introspectable.State()
+	assert.Equal(t, 2, state.PendingKeys, "Expected pending keys")
+	assert.Equal(t, 2, state.CounterValue, "Expected LoadMany and LoadNoOp to be counted")
+	assert.Equal(t, dataloader.WorkerRunning, state.WorkerState, "Expected worker to be running")
+	assert.True(t, state.LastFlush.IsZero(), "Expected no flush")
+
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	thunk()
+	time.Sleep(10 * time.Millisecond) // allow the worker to exit
+	close(closeChan)
+
+	state = introspectable.State()
+	assert.Equal(t, 0, state.PendingKeys, "Expected keys to be flushed")
+	assert.Equal(t, 0, state.CounterValue, "Expected counter to be reset")
+	assert.Equal(t, dataloader.WorkerRan, state.WorkerState, "Expected worker to have run")
+	assert.False(t, state.LastFlush.IsZero(), "Expected flush time")
+}
+
+// TestStateCountsTimeouts ensures batches executed after the timeout are counted
+func TestStateCountsTimeouts(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT*5)
+
+	batch := getBatchFunction(func(dataloader.Keys) {}, "timeouts")
+	strategy := standard.NewStandardStrategy(standard.WithTimeout(TEST_TIMEOUT))(5, batch)
+
+	// invoke
+	strategy.Load(context.Background(), PrimaryKey(1))()
+	close(closeChan)
+
+	// assert
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, uint64(1), state.Timeouts, "Expected the timeout to be counted")
+}
+
+// ========================= backpressure =========================
+
+// blockingBatchFunction returns a batch function whose first call signals started and blocks until release
+// is closed, keeping the worker busy so that the key channel fills up
+func blockingBatchFunction(started, release chan struct{}) dataloader.BatchFunction {
+	var calls int32
+	batch := getBatchFunction(func(dataloader.Keys) {}, "backpressure")
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+		return batch(ctx, keys)
+	}
+}
+
+// TestBackpressureDrop ensures loads made while the key channel is full resolve to an error
+func TestBackpressureDrop(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	strategy := standard.NewStandardStrategy(
+		standard.WithBackpressure(options.Drop),
+	)(1, blockingBatchFunction(started, release))
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	<-started
+	thunk2 := strategy.Load(context.Background(), PrimaryKey(2)) // fills the key channel
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(3))
+	rm := thunkMany()
+	close(release)
+	r, _ := thunk()
+	r2, _ := thunk2()
+	close(closeChan)
+
+	// assert
+	v, ok := rm.GetValue(PrimaryKey(3))
+	assert.True(t, ok, "Expected result for the dropped key")
+	assert.Equal(t, strategies.ErrKeyChannelFull, v.Err, "Expected the dropped key to resolve to an error")
+	assert.Equal(t, "1_backpressure", r.Result.(string), "Expected result from the batch")
+	assert.Equal(t, "2_backpressure", r2.Result.(string), "Expected result from the batch")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, uint64(1), state.Saturations, "Expected the saturation to be counted")
+}
+
+// TestBackpressureGrow ensures loads made while the key channel is full don't block the caller
+func TestBackpressureGrow(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	strategy := standard.NewStandardStrategy(
+		standard.WithBackpressure(options.Grow),
+	)(1, blockingBatchFunction(started, release))
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	<-started
+	thunk2 := strategy.Load(context.Background(), PrimaryKey(2)) // fills the key channel
+	thunk3 := strategy.Load(context.Background(), PrimaryKey(3)) // doesn't block
+	strategy.LoadNoOp(context.Background())
+	close(release)
+	r, _ := thunk()
+	r2, _ := thunk2()
+	r3, ok := thunk3()
+	close(closeChan)
+
+	// assert
+	assert.Equal(t, "1_backpressure", r.Result.(string), "Expected result from the batch")
+	assert.Equal(t, "2_backpressure", r2.Result.(string), "Expected result from the batch")
+	assert.True(t, ok, "Expected result for the buffered key")
+	assert.Equal(t, "3_backpressure", r3.Result.(string), "Expected result from the batch")
+	state := strategy.(dataloader.Introspectable).State()
+	assert.Equal(t, uint64(2), state.Saturations, "Expected the saturations to be counted")
+}
+
+// TestBackpressureFlush ensures loads made while the key channel is full call the batch function immediately
+func TestBackpressureFlush(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	strategy := standard.NewStandardStrategy(
+		standard.WithBackpressure(options.Flush),
+	)(1, blockingBatchFunction(started, release))
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	<-started
+	strategy.Load(context.Background(), PrimaryKey(2)) // fills the key channel
+	r3, ok := strategy.Load(context.Background(), PrimaryKey(3))()
+	close(release)
+	r, _ := thunk()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for the flushed key")
+	assert.Equal(t, "3_backpressure", r3.Result.(string), "Expected the key to be batched while the worker is busy")
+	assert.Equal(t, "1_backpressure", r.Result.(string), "Expected result from the batch")
+}
+
+// TestKeyDedup ensures keys loaded more than once in a round are passed to the batch function according to
+// the deduplication mode
+func TestKeyDedup(t *testing.T) {
+	for _, tc := range []struct {
+		dedup    dataloader.KeyDedup
+		expected []interface{}
+	}{
+		{dedup: dataloader.DedupByKey, expected: []interface{}{PrimaryKey(1)}},
+		{dedup: dataloader.DedupNone, expected: []interface{}{PrimaryKey(1), PrimaryKey(1)}},
+	} {
+		// setup
+		closeChan := make(chan struct{})
+		timeout(t, closeChan, TEST_TIMEOUT)
+
+		var k []interface{}
+		batch := getBatchFunction(func(keys dataloader.Keys) { k = keys.Keys() }, "dedup")
+		strategy := standard.NewStandardStrategy(
+			standard.WithTimeout(TEST_TIMEOUT*5),
+			standard.WithKeyDedup(tc.dedup),
+		)(5, batch)
+
+		// invoke
+		thunk := strategy.Load(context.Background(), PrimaryKey(1))
+		thunk2 := strategy.Load(context.Background(), PrimaryKey(1))
+		strategy.(dataloader.Flusher).Flush(context.Background())
+		r, _ := thunk()
+		r2, _ := thunk2()
+		close(closeChan)
+
+		// assert
+		assert.Equal(t, tc.expected, k, "Expected the keys of the batch for mode %d", tc.dedup)
+		assert.Equal(t, "1_dedup", r.Result, "Expected the result of the key")
+		assert.Equal(t, "1_dedup", r2.Result, "Expected the result of the key")
+	}
+}
+
+// TestBatchFunctionPanic ensures a panic in the batch function resolves the callers keys to an error
+func TestBatchFunctionPanic(t *testing.T) {
+	// setup
+	closeChan := make(chan struct{})
+	timeout(t, closeChan, TEST_TIMEOUT)
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		panic("batch failure")
+	}
+	strategy := standard.NewStandardStrategy()(2, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	thunkMany := strategy.LoadMany(context.Background(), PrimaryKey(2))
+	r, ok := thunk()
+	rm := thunkMany()
+	close(closeChan)
+
+	// assert
+	assert.True(t, ok, "Expected result for key")
+	assert.Error(t, r.Err, "Expected panic to be returned as an error")
+	v, ok := rm.GetValue(PrimaryKey(2))
+	assert.True(t, ok, "Expected result for LoadMany key")
+	assert.Error(t, v.Err, "Expected panic to be returned as an error")
+}
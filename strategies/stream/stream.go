@@ -0,0 +1,390 @@
+/*
+Package stream contains the implementation details for the streaming strategy.
+
+The streaming strategy delivers results to callers as the batch function produces them
+rather than once the whole ResultMap is assembled. The batch function reports each result
+with Emit as it is read, e.g. row by row from a database cursor, and still returns the
+complete ResultMap. Callers of LoadManyStream receive each result on a channel as soon as it
+is emitted, while Load and LoadMany behave as with the other strategies.
+
+Results delivered through Emit bypass the loader, they are not cached nor decorated with
+their Source. The ResultMap returned by the batch function is processed by the loader as usual.
+*/
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// KeyResult is a result delivered by LoadManyStream. It is the KeyResult delivered by the loaders
+// ThunkManyStream.
+type KeyResult = dataloader.KeyResult
+
+// Streamer is implemented by strategies which deliver results as they are produced
+type Streamer interface {
+	// LoadManyStream returns a channel which receives the result for each key as soon as it is available.
+	// The channel is closed once the batch function returns or the context is done. Keys without a result
+	// are not delivered.
+	LoadManyStream(context.Context, ...dataloader.Key) <-chan KeyResult
+}
+
+type emitterKey struct{}
+
+// Emit delivers the result for the key to the callers streaming it before the batch function returns. The
+// context must be the context passed to the batch function. Emit returns false if the batch wasn't called
+// by the streaming strategy or has already returned.
+func Emit(ctx context.Context, key dataloader.Key, result dataloader.Result) bool {
+	e, ok := ctx.Value(emitterKey{}).(*emitter)
+	if !ok {
+		return false
+	}
+	return e.emit(key, result)
+}
+
+// options contains the configuration of the stream strategy
+type options struct {
+	timeout time.Duration
+	budget  time.Duration
+	clock   clock.Clock
+	logger  logger.Logger
+	hooks   dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewStreamingStrategy returns a new instance of the streaming strategy.
+// The streaming strategy calls the batch function once the number of pending keys hits the capacity or after
+// the timeout, whichever happens first.
+func NewStreamingStrategy(opts ...Option) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+
+		return &streamStrategy{
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.hooks), o.logger),
+			capacity:  capacity,
+			options:   o,
+
+			keys: dataloader.NewKeys(capacity),
+		}
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithTimeout sets the maximum duration keys wait for the capacity to be hit. Defaults to 16 milliseconds.
+func WithTimeout(t time.Duration) Option {
+	return func(o *options) {
+		o.timeout = t
+	}
+}
+
+// WithDeadlineBudget sets the time left for the batch function to complete before the earliest deadline
+// of the pending loads. The timeout is shortened so the batch function is called with the budget remaining.
+// Defaults to 5 milliseconds.
+func WithDeadlineBudget(d time.Duration) Option {
+	return func(o *options) {
+		o.budget = d
+	}
+}
+
+// WithClock sets the time source of the timeout, e.g. a clock.Mock in tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// ===========================================================================================================
+
+// subscriber is a call to Load, LoadMany or LoadManyStream waiting for results
+type subscriber struct {
+	keys       []dataloader.Key
+	resultChan chan dataloader.ResultMap // set for Load and LoadMany
+	stream     chan KeyResult            // set for LoadManyStream
+}
+
+type streamStrategy struct {
+	batchFunc dataloader.BatchFunction
+	capacity  int
+
+	m sync.Mutex
+	// pending keys and the callers waiting for them
+	keys        dataloader.Keys
+	subscribers []*subscriber
+	ctxs        []context.Context // contexts of the pending loads
+	timer       *strategies.Timer
+	flushBy     time.Time // time the pending keys are flushed by
+	lastFlush   time.Time
+	timeouts    uint64
+
+	options options
+}
+
+// Load returns a Thunk for the specified Key. Calling the Thunk blocks until the batch function returns.
+func (s *streamStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the flush
+	s.enqueue(ctx, &subscriber{keys: []dataloader.Key{key}, resultChan: resultChan})
+
+	var result dataloader.Result
+	var ok bool
+	var once sync.Once
+	return func() (dataloader.Result, bool) {
+		once.Do(func() {
+			result, ok = strategies.Wait(ctx, resultChan, key).GetValue(key)
+		})
+		return result, ok
+	}
+}
+
+// LoadMany returns a ThunkMany for the specified keys. Calling the ThunkMany blocks until the batch function
+// returns.
+func (s *streamStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the flush
+	s.enqueue(ctx, &subscriber{keys: keyArr, resultChan: resultChan})
+
+	var resultMap dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			resultMap = strategies.BuildResultMap(keyArr, strategies.Wait(ctx, resultChan, keyArr...))
+		})
+		return resultMap
+	}
+}
+
+// LoadManyStream returns a channel which receives the result for each key as soon as the batch function
+// emits it (see Emit). Results which weren't emitted are delivered once the batch function returns, after
+// which the channel is closed. If the context is done first the channel is closed without the remaining
+// results.
+func (s *streamStrategy) LoadManyStream(ctx context.Context, keyArr ...dataloader.Key) <-chan KeyResult {
+	// buffered channel won't block the batch function, each key is delivered at most once
+	stream := make(chan KeyResult, len(keyArr))
+	s.enqueue(ctx, &subscriber{keys: keyArr, stream: stream})
+
+	out := make(chan KeyResult)
+	go func() {
+		defer close(out)
+		for r := range stream {
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// LoadNoOp has no internal implementation since the streaming strategy measures its capacity in keys
+func (*streamStrategy) LoadNoOp(context.Context) {}
+
+// Flush calls the batch function with the pending keys without waiting for the capacity or the timeout
+func (s *streamStrategy) Flush(context.Context) {
+	s.options.logger.Debug("flushing on demand")
+	s.flush()
+}
+
+// State returns a snapshot of the strategies internal state. The streaming strategy has no background
+// worker, the worker state is reported as running while keys are pending.
+func (s *streamStrategy) State() dataloader.StrategyState {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	state := dataloader.WorkerNotRunning
+	switch {
+	case len(s.subscribers) > 0:
+		state = dataloader.WorkerRunning
+	case !s.lastFlush.IsZero():
+		state = dataloader.WorkerRan
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  s.keys.Length(),
+		Capacity:     s.capacity,
+		CounterValue: s.keys.Length(), // capacity is measured in keys
+		WorkerState:  state,
+		LastFlush:    s.lastFlush,
+		Timeouts:     s.timeouts,
+	}
+}
+
+// Name returns the name of the strategy
+func (*streamStrategy) Name() string {
+	return "stream"
+}
+
+// ============================================== private =============================================
+
+// enqueue adds the subscribers keys to the pending batch
+func (s *streamStrategy) enqueue(ctx context.Context, sub *subscriber) {
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.flushBy = s.options.clock.Now().Add(s.options.timeout)
+		s.timer = strategies.AfterFunc(s.options.clock, s.options.timeout, func() {
+			s.options.logger.Debug("flushing after timeout", "timeout", s.options.timeout)
+			s.m.Lock()
+			s.timeouts++
+			s.m.Unlock()
+			s.flush()
+		})
+	}
+	if flushBy := strategies.EarliestFlush(ctx, s.options.clock, s.flushBy, s.options.budget); flushBy.Before(s.flushBy) {
+		// flush earlier to leave the batch function time to complete before the deadline of the load
+		s.flushBy = flushBy
+		if s.timer.Stop() { // unless the timer already fired
+			s.timer.Reset(flushBy.Sub(s.options.clock.Now()))
+		}
+	}
+	s.ctxs = append(s.ctxs, ctx)
+	s.keys.Append(sub.keys...)
+	s.subscribers = append(s.subscribers, sub)
+	full := s.keys.Length() >= s.capacity
+	s.m.Unlock()
+
+	if full {
+		s.flush()
+	}
+}
+
+// flush calls the batch function in a background go routine with the pending keys. Results emitted by the
+// batch function are delivered to the streaming subscribers as they arrive. The batch context stays live
+// while any of the pending loads is waiting (see strategies.BatchContext).
+func (s *streamStrategy) flush() {
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.m.Unlock()
+		return
+	}
+
+	keys, subscribers, ctxs := s.keys, s.subscribers, s.ctxs
+	s.keys = dataloader.NewKeys(s.capacity)
+	s.subscribers = nil
+	s.ctxs = nil
+	s.timer.Stop()
+	s.lastFlush = s.options.clock.Now()
+	s.m.Unlock()
+
+	s.options.logger.Debug("flushing", "keys", keys.Length())
+	go func() {
+		ctx, cancel := strategies.BatchContext(ctxs...)
+		defer cancel()
+
+		e := newEmitter(subscribers)
+		r := s.batchFunc(context.WithValue(ctx, emitterKey{}, e), keys)
+		e.close(*r)
+
+		for _, sub := range subscribers {
+			if sub.resultChan != nil {
+				sub.resultChan <- *r
+				close(sub.resultChan)
+			}
+		}
+	}()
+}
+
+// emitter delivers emitted results to the streaming subscribers of a batch
+type emitter struct {
+	m         sync.Mutex
+	closed    bool
+	keys      map[string]dataloader.Key
+	streams   map[string][]chan KeyResult // streaming subscribers by key
+	delivered map[chan KeyResult]map[string]bool
+}
+
+func newEmitter(subscribers []*subscriber) *emitter {
+	e := &emitter{
+		keys:      make(map[string]dataloader.Key),
+		streams:   make(map[string][]chan KeyResult),
+		delivered: make(map[chan KeyResult]map[string]bool),
+	}
+	for _, sub := range subscribers {
+		if sub.stream == nil {
+			continue
+		}
+		e.delivered[sub.stream] = make(map[string]bool, len(sub.keys))
+		for _, key := range sub.keys {
+			e.keys[key.String()] = key
+			e.streams[key.String()] = append(e.streams[key.String()], sub.stream)
+		}
+	}
+	return e
+}
+
+// emit delivers the result to each streaming subscriber of the key which hasn't received it
+func (e *emitter) emit(key dataloader.Key, result dataloader.Result) bool {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.closed {
+		return false
+	}
+	e.deliver(key, result)
+	return true
+}
+
+// close delivers the results which weren't emitted and closes the streams
+func (e *emitter) close(r dataloader.ResultMap) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	for k, key := range e.keys {
+		if v, ok := r[k]; ok {
+			e.deliver(key, v)
+		}
+	}
+	for stream := range e.delivered {
+		close(stream)
+	}
+	e.closed = true
+}
+
+// deliver sends the result to the streams of the key. Must be called with the lock held.
+func (e *emitter) deliver(key dataloader.Key, result dataloader.Result) {
+	k := key.String()
+	for _, stream := range e.streams[k] {
+		if !e.delivered[stream][k] {
+			e.delivered[stream][k] = true
+			stream <- KeyResult{Key: key, Result: result}
+		}
+	}
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.timeout = 16 * time.Millisecond
+	opts.budget = 5 * time.Millisecond
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
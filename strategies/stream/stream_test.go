@@ -0,0 +1,129 @@
+package stream_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== test constants =============================================
+const TEST_TIMEOUT time.Duration = time.Millisecond * 500
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getStreamingBatchFunction returns a batch function which emits the result for each key in order, blocking
+// on the release channel before returning. Key 3 is only returned in the result map.
+func getStreamingBatchFunction(release chan struct{}) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			result := dataloader.Result{Result: key.String(), Err: nil}
+			m.Set(key, result)
+			if key != 3 {
+				stream.Emit(ctx, key, result)
+			}
+		}
+		<-release
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestLoadManyStream ensures emitted results are delivered before the batch function returns
+func TestLoadManyStream(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	batch := getStreamingBatchFunction(release)
+	strategy := stream.NewStreamingStrategy(stream.WithTimeout(TEST_TIMEOUT*5))(3, batch)
+
+	// invoke
+	results := strategy.(stream.Streamer).LoadManyStream(
+		context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3),
+	)
+
+	// assert
+	var received []string
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-results:
+			received = append(received, r.Result.Result.(string))
+		case <-time.After(TEST_TIMEOUT):
+			assert.Fail(t, "Expected the emitted result before the batch function returned")
+			return
+		}
+	}
+	assert.ElementsMatch(t, []string{"1", "2"}, received, "Expected the emitted results")
+
+	close(release)
+	r, ok := <-results
+	assert.True(t, ok, "Expected the result which wasn't emitted")
+	assert.Equal(t, PrimaryKey(3), r.Key, "Expected the key of the result")
+
+	_, ok = <-results
+	assert.False(t, ok, "Expected the channel to be closed")
+}
+
+// TestLoadManyStreamWithThunks ensures thunks receive the complete result map
+func TestLoadManyStreamWithThunks(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	close(release)
+	batch := getStreamingBatchFunction(release)
+	strategy := stream.NewStreamingStrategy(stream.WithTimeout(TEST_TIMEOUT*5))(3, batch)
+
+	// invoke
+	results := strategy.(stream.Streamer).LoadManyStream(context.Background(), PrimaryKey(1))
+	thunk := strategy.LoadMany(context.Background(), PrimaryKey(2), PrimaryKey(3))
+
+	// assert
+	r := thunk()
+	assert.Equal(t, 2, r.Length(), "Expected results for the keys")
+	assert.Equal(t, "3", r.GetValueForString("3").Result, "Expected the result for the key")
+
+	var streamed []dataloader.Key
+	for kr := range results {
+		streamed = append(streamed, kr.Key)
+	}
+	assert.Equal(t, []dataloader.Key{PrimaryKey(1)}, streamed, "Expected only the streamed key")
+}
+
+// TestFlushOnClock ensures the timeout is measured by the clock passed to WithClock
+func TestFlushOnClock(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	release := make(chan struct{})
+	close(release)
+	batch := getStreamingBatchFunction(release)
+	strategy := stream.NewStreamingStrategy(stream.WithTimeout(time.Second), stream.WithClock(clk))(10, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	clk.BlockUntil(1) // wait for the timeout to be scheduled
+	pending := strategy.(dataloader.Introspectable).State().PendingKeys
+	clk.Add(time.Second)
+	r, ok := thunk()
+
+	// assert
+	assert.Equal(t, 1, pending, "Expected the key to be pending until the clock advanced")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1", r.Result, "Expected the result for the key")
+}
@@ -0,0 +1,84 @@
+package strategies
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader/clock"
+)
+
+// Timer calls a function in its own go routine once a duration has elapsed on a clock. It behaves like a
+// time.Timer created by time.AfterFunc, so that strategies which flush after a timeout can be driven by a
+// clock.Mock in tests.
+type Timer struct {
+	clk clock.Clock
+	f   func()
+
+	m    sync.Mutex
+	stop chan struct{} // closed to release the go routine waiting for the current schedule, nil once fired
+}
+
+// AfterFunc waits for the duration to elapse on the clock and then calls f in its own go routine. It returns
+// a Timer that can be used to cancel the call using its Stop method.
+func AfterFunc(clk clock.Clock, d time.Duration, f func()) *Timer {
+	t := &Timer{clk: clk, f: f}
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.schedule(d)
+	return t
+}
+
+// Stop prevents the Timer from firing. It returns true if the call stops the timer, false if the timer has
+// already fired or been stopped.
+func (t *Timer) Stop() bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.stop == nil {
+		return false
+	}
+	close(t.stop)
+	t.stop = nil
+	return true
+}
+
+// Reset changes the timer to fire after the duration. It returns true if the timer had been active, false
+// if the timer had fired or been stopped.
+func (t *Timer) Reset(d time.Duration) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	active := t.stop != nil
+	if active {
+		close(t.stop)
+	}
+	t.schedule(d)
+	return active
+}
+
+// schedule starts a go routine which calls f once the duration elapsed unless the schedule is stopped. The
+// caller must hold the lock.
+func (t *Timer) schedule(d time.Duration) {
+	stop := make(chan struct{})
+	t.stop = stop
+	after := t.clk.After(d)
+
+	go func() {
+		select {
+		case <-after:
+		case <-stop:
+			return
+		}
+
+		t.m.Lock()
+		if t.stop != stop { // stopped or reset while firing
+			t.m.Unlock()
+			return
+		}
+		t.stop = nil
+		t.m.Unlock()
+
+		t.f()
+	}()
+}
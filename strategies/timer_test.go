@@ -0,0 +1,51 @@
+package strategies_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTimerFires ensures the function is called once the duration elapsed on the clock
+func TestTimerFires(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	fired := make(chan struct{})
+
+	// invoke
+	timer := strategies.AfterFunc(clk, time.Second, func() { close(fired) })
+	clk.Add(time.Second)
+
+	// assert
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Expected the timer to fire")
+	}
+	assert.False(t, timer.Stop(), "Expected a fired timer not to be stopped")
+}
+
+// TestTimerStopReset ensures a stopped timer doesn't fire and a reset timer fires after the new duration
+func TestTimerStopReset(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	fired := make(chan struct{}, 2)
+
+	// invoke / assert
+	timer := strategies.AfterFunc(clk, time.Second, func() { fired <- struct{}{} })
+	assert.True(t, timer.Stop(), "Expected the active timer to be stopped")
+	clk.Add(time.Second)
+	assert.Never(t, func() bool { return len(fired) > 0 }, 20*time.Millisecond, time.Millisecond,
+		"Expected the stopped timer not to fire")
+
+	assert.False(t, timer.Reset(time.Minute), "Expected the stopped timer to be inactive")
+	clk.Add(time.Second)
+	assert.Never(t, func() bool { return len(fired) > 0 }, 20*time.Millisecond, time.Millisecond,
+		"Expected the timer not to fire before the new duration")
+	clk.Add(time.Minute)
+	assert.Eventually(t, func() bool { return len(fired) == 1 }, time.Second, time.Millisecond,
+		"Expected the reset timer to fire once")
+}
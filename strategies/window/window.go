@@ -0,0 +1,254 @@
+/*
+Package window contains the implementation details for the window strategy.
+
+The window strategy approximates the event loop tick of the javascript dataloader. Keys
+accumulate for a fixed interval and the batch function is called on every tick of a ticker
+with the keys loaded since the previous tick, regardless of how many loads were made. This
+suits requests where the number of calls to Load isn't known in advance, which makes a
+capacity hard to choose.
+*/
+package window
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies"
+
+	"github.com/andy9775/dataloader/logger"
+)
+
+// options contains the configuration of the window strategy
+type options struct {
+	interval time.Duration
+	clock    clock.Clock
+	logger   logger.Logger
+	hooks    dataloader.Hooks
+}
+
+// Option accepts the strategy options and sets an option on them.
+type Option func(*options)
+
+// NewWindowStrategy returns a new instance of the window strategy.
+// The window strategy calls the batch function on every tick of the interval with the keys loaded since the
+// previous tick. The capacity is only used to size the pending keys, it doesn't trigger the batch function
+// (see WithMaxBatchSize to bound the size of each call). The ticker is stopped while no keys are pending.
+func NewWindowStrategy(opts ...Option) dataloader.StrategyFunction {
+	return func(capacity int, batch dataloader.BatchFunction) dataloader.Strategy {
+		// default options
+		o := options{}
+		formatOptions(&o)
+
+		// format options
+		for _, apply := range opts {
+			apply(&o)
+		}
+
+		return &windowStrategy{
+			batchFunc: strategies.RecoverBatch(strategies.HookBatch(batch, o.hooks), o.logger),
+			capacity:  capacity,
+			options:   o,
+
+			keys: dataloader.NewKeys(capacity),
+		}
+	}
+}
+
+// ============================================== option setters =============================================
+
+// WithInterval sets the duration of each window. Defaults to 16 milliseconds.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// WithClock sets the time source of the interval, e.g. a clock.Mock in tests. Defaults to the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithLogger configures the logger for the strategy. Default is a no op logger.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithHooks sets the callbacks invoked around each call to the batch function
+func WithHooks(h dataloader.Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+// ===========================================================================================================
+
+type windowStrategy struct {
+	batchFunc dataloader.BatchFunction
+	capacity  int
+
+	m sync.Mutex
+	// pending keys and the channels of the callers waiting for them
+	keys        dataloader.Keys
+	subscribers []chan dataloader.ResultMap
+	ctxs        []context.Context // contexts of the pending loads
+	ticking     bool              // true while the ticker go routine is running
+	lastFlush   time.Time
+
+	options options
+}
+
+// Load returns a Thunk for the specified Key. Calling the Thunk blocks until the batch function for the
+// window containing the key returns.
+func (s *windowStrategy) Load(ctx context.Context, key dataloader.Key) dataloader.Thunk {
+	resultChan := s.enqueue(ctx, key)
+
+	var result dataloader.Result
+	var ok bool
+	var once sync.Once
+	return func() (dataloader.Result, bool) {
+		once.Do(func() {
+			result, ok = strategies.Wait(ctx, resultChan, key).GetValue(key)
+		})
+		return result, ok
+	}
+}
+
+// LoadMany returns a ThunkMany for the specified keys. Calling the ThunkMany blocks until the batch function
+// for the window containing the keys returns.
+func (s *windowStrategy) LoadMany(ctx context.Context, keyArr ...dataloader.Key) dataloader.ThunkMany {
+	resultChan := s.enqueue(ctx, keyArr...)
+
+	var resultMap dataloader.ResultMap
+	var once sync.Once
+	return func() dataloader.ResultMap {
+		once.Do(func() {
+			resultMap = strategies.BuildResultMap(keyArr, strategies.Wait(ctx, resultChan, keyArr...))
+		})
+		return resultMap
+	}
+}
+
+// LoadNoOp has no internal implementation since the window strategy doesn't track the number of calls to
+// Load or LoadMany
+func (*windowStrategy) LoadNoOp(context.Context) {}
+
+// Flush calls the batch function with the pending keys without waiting for the next tick
+func (s *windowStrategy) Flush(context.Context) {
+	s.options.logger.Debug("flushing on demand")
+	s.flush()
+}
+
+// State returns a snapshot of the strategies internal state. The worker is reported as running while the
+// ticker is running.
+func (s *windowStrategy) State() dataloader.StrategyState {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	state := dataloader.WorkerNotRunning
+	switch {
+	case s.ticking:
+		state = dataloader.WorkerRunning
+	case !s.lastFlush.IsZero():
+		state = dataloader.WorkerRan
+	}
+
+	return dataloader.StrategyState{
+		PendingKeys:  s.keys.Length(),
+		Capacity:     s.capacity,
+		CounterValue: s.keys.Length(),
+		WorkerState:  state,
+		LastFlush:    s.lastFlush,
+	}
+}
+
+// Name returns the name of the strategy
+func (*windowStrategy) Name() string {
+	return "window"
+}
+
+// ============================================== private =============================================
+
+// enqueue adds the keys to the current window, starting the ticker if it isn't running, and returns the
+// channel on which the result is delivered
+func (s *windowStrategy) enqueue(ctx context.Context, keyArr ...dataloader.Key) chan dataloader.ResultMap {
+	resultChan := make(chan dataloader.ResultMap, 1) // buffered channel won't block the flush
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.ctxs = append(s.ctxs, ctx)
+	s.keys.Append(keyArr...)
+	s.subscribers = append(s.subscribers, resultChan)
+
+	if !s.ticking {
+		s.ticking = true
+		go s.tick()
+	}
+
+	return resultChan
+}
+
+// tick flushes the pending keys on every tick of the interval. The ticker stops once a tick finds no
+// pending keys and is restarted by the next load.
+func (s *windowStrategy) tick() {
+	s.options.logger.Debug("ticker starting", "interval", s.options.interval)
+	for {
+		<-s.options.clock.After(s.options.interval)
+
+		s.m.Lock()
+		if len(s.subscribers) == 0 {
+			s.ticking = false
+			s.m.Unlock()
+			s.options.logger.Debug("ticker stopping")
+			return
+		}
+		s.m.Unlock()
+
+		s.flush()
+	}
+}
+
+// flush calls the batch function in a background go routine with the pending keys. The batch context stays
+// live while any of the pending loads is waiting (see strategies.BatchContext).
+func (s *windowStrategy) flush() {
+	s.m.Lock()
+	if len(s.subscribers) == 0 {
+		s.m.Unlock()
+		return
+	}
+
+	keys, subscribers, ctxs := s.keys, s.subscribers, s.ctxs
+	s.keys = dataloader.NewKeys(s.capacity)
+	s.subscribers = nil
+	s.ctxs = nil
+	s.lastFlush = s.options.clock.Now()
+	s.m.Unlock()
+
+	s.options.logger.Debug("flushing", "keys", keys.Length())
+	go func() {
+		ctx, cancel := strategies.BatchContext(ctxs...)
+		defer cancel()
+
+		r := s.batchFunc(ctx, keys)
+		for _, ch := range subscribers {
+			ch <- *r
+			close(ch)
+		}
+	}()
+}
+
+// ============================================== helpers =============================================
+
+// formatOptions configures default values for the strategy options
+func formatOptions(opts *options) {
+	opts.interval = 16 * time.Millisecond
+	opts.clock = clock.New()
+	opts.logger = logger.Noop()
+}
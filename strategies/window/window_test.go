@@ -0,0 +1,171 @@
+package window_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/clock"
+	"github.com/andy9775/dataloader/strategies/window"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================== test constants =============================================
+const TEST_TIMEOUT time.Duration = time.Millisecond * 500
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// getBatchFunction returns a generic batch function which returns the provided result and calls the provided
+// callback function
+func getBatchFunction(cb func(dataloader.Keys), result string) dataloader.BatchFunction {
+	return func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		cb(keys)
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			key := k.(PrimaryKey)
+			m.Set(key, dataloader.Result{Result: fmt.Sprintf("%s_%s", key, result), Err: nil})
+		}
+		return &m
+	}
+}
+
+// ================================================== tests ==================================================
+
+// TestFlushOnTick ensures every key loaded within a window is passed to a single batch, regardless of the
+// capacity
+func TestFlushOnTick(t *testing.T) {
+	// setup
+	var m sync.Mutex
+	var calls []int
+	cb := func(keys dataloader.Keys) {
+		m.Lock()
+		defer m.Unlock()
+		calls = append(calls, keys.Length())
+	}
+
+	batch := getBatchFunction(cb, "window")
+	strategy := window.NewWindowStrategy(window.WithInterval(TEST_TIMEOUT/5))(2, batch)
+
+	// invoke
+	start := time.Now()
+	thunks := make([]dataloader.Thunk, 5)
+	for i := range thunks {
+		thunks[i] = strategy.Load(context.Background(), PrimaryKey(i))
+	}
+	r, ok := thunks[4]()
+	elapsed := time.Since(start)
+
+	// assert
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "4_window", r.Result, "Expected the result for the key")
+	assert.True(t, elapsed >= TEST_TIMEOUT/5, "Expected the batch function to be called on the tick")
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, []int{5}, calls, "Expected a single batch with every key")
+}
+
+// TestFlushOnDemand ensures Flush calls the batch function without waiting for the tick
+func TestFlushOnDemand(t *testing.T) {
+	// setup
+	batch := getBatchFunction(func(dataloader.Keys) {}, "flush")
+	strategy := window.NewWindowStrategy(window.WithInterval(TEST_TIMEOUT*5))(10, batch)
+
+	// invoke
+	start := time.Now()
+	thunk := strategy.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2))
+	strategy.(dataloader.Flusher).Flush(context.Background())
+	r := thunk()
+
+	// assert
+	assert.True(t, time.Since(start) < TEST_TIMEOUT, "Expected the batch function to be called on flush")
+	assert.Equal(t, 2, r.Length(), "Expected results for both keys")
+}
+
+// TestStateStopsTicker ensures the ticker stops once no keys are pending
+func TestStateStopsTicker(t *testing.T) {
+	// setup
+	interval := 10 * time.Millisecond
+	batch := getBatchFunction(func(dataloader.Keys) {}, "state")
+	strategy := window.NewWindowStrategy(window.WithInterval(interval))(10, batch)
+	introspectable := strategy.(dataloader.Introspectable)
+
+	// invoke / assert
+	assert.Equal(t, dataloader.WorkerNotRunning, introspectable.State().WorkerState, "Expected no ticker")
+
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	assert.Equal(t, dataloader.WorkerRunning, introspectable.State().WorkerState, "Expected the ticker to run")
+	assert.Equal(t, 1, introspectable.State().PendingKeys, "Expected a pending key")
+
+	thunk()
+	time.Sleep(interval * 3) // allow a tick without pending keys
+
+	state := introspectable.State()
+	assert.Equal(t, dataloader.WorkerRan, state.WorkerState, "Expected the ticker to have stopped")
+	assert.Equal(t, 0, state.PendingKeys, "Expected no pending keys")
+	assert.False(t, state.LastFlush.IsZero(), "Expected flush time")
+}
+
+// TestCancelledFirstLoad ensures cancelling the first load of a window doesn't cancel the batch of the other
+// loads in the window
+func TestCancelledFirstLoad(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		keys.ForEach(func(k dataloader.Key) bool {
+			m.Set(k, dataloader.Result{Result: k.String(), Err: ctx.Err()})
+			return true
+		})
+		return &m
+	}
+	strategy := window.NewWindowStrategy(window.WithInterval(20*time.Millisecond))(10, batch)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// invoke
+	cancelled := strategy.Load(ctx, PrimaryKey(1))
+	thunk := strategy.Load(context.Background(), PrimaryKey(2))
+	cancel()
+	c, _ := cancelled()
+	r, ok := thunk()
+
+	// assert
+	assert.Equal(t, context.Canceled, c.Err, "Expected the cancelled load to resolve to the context error")
+	assert.True(t, ok, "Expected result for key")
+	assert.Nil(t, r.Err, "Expected the batch not to be cancelled")
+	assert.Equal(t, "2", r.Result, "Expected result for key")
+}
+
+// TestFlushOnClock ensures the interval is measured by the clock passed to WithClock
+func TestFlushOnClock(t *testing.T) {
+	// setup
+	clk := clock.NewMock()
+	batch := getBatchFunction(func(dataloader.Keys) {}, "clock")
+	strategy := window.NewWindowStrategy(window.WithInterval(time.Second), window.WithClock(clk))(10, batch)
+
+	// invoke
+	thunk := strategy.Load(context.Background(), PrimaryKey(1))
+	clk.BlockUntil(1) // wait for the interval to be scheduled
+	pending := strategy.(dataloader.Introspectable).State().PendingKeys
+	clk.Add(time.Second)
+	r, ok := thunk()
+
+	// assert
+	assert.Equal(t, 1, pending, "Expected the key to be pending until the clock advanced")
+	assert.True(t, ok, "Expected result to have been found")
+	assert.Equal(t, "1_clock", r.Result, "Expected the result for the key")
+}
@@ -2,6 +2,7 @@ package dataloader
 
 import (
 	"context"
+	"time"
 )
 
 // Strategy specifies the interface of loader strategies. A loader strategy specifies the process
@@ -21,3 +22,62 @@ type Strategy interface {
 	// and thus simply increments the loads call counter.
 	LoadNoOp(context.Context)
 }
+
+// Flusher is implemented by strategies which can call the batch function on demand with the keys which
+// have accumulated, instead of waiting for the strategy to reach capacity or time out.
+type Flusher interface {
+	// Flush calls the batch function with the pending keys. Flush is a no-op if no keys are pending.
+	Flush(context.Context)
+}
+
+// Closer is implemented by strategies which hold resources beyond their pending keys, e.g. a subscription
+// to a shared Tracker. SwapStrategy closes the previous strategy once its pending keys are flushed. The
+// loaders returned by NewDataLoader implement Closer by closing their strategy, which the httpmiddleware
+// package and Manager.Close do on teardown.
+type Closer interface {
+	// Close releases the resources of the strategy. Keys must not be loaded after Close.
+	Close()
+}
+
+// WorkerState describes the state of a strategies background worker
+type WorkerState string
+
+// Worker states reported by the bundled strategies
+const (
+	WorkerNotRunning WorkerState = "not-running" // no worker has been started
+	WorkerRunning    WorkerState = "running"     // a worker is waiting for keys
+	WorkerRan        WorkerState = "ran"         // the last worker called the batch function
+)
+
+// StrategyState is a snapshot of the internal state of a strategy
+type StrategyState struct {
+	// PendingKeys is the number of keys waiting to be passed to the batch function
+	PendingKeys int
+	// Capacity is the capacity the strategy was created with
+	Capacity int
+	// CounterValue is the number of loads counted towards the capacity
+	CounterValue int
+	// WorkerState is the state of the background worker
+	WorkerState WorkerState
+	// LastFlush is the time the batch function was last called by the strategy. Zero if never called.
+	LastFlush time.Time
+	// Timeouts is the number of times the strategy called the batch function because its timeout expired
+	// before the capacity was reached
+	Timeouts uint64
+	// Saturations is the number of loads which found the key channel of the background worker full (see
+	// options.Backpressure)
+	Saturations uint64
+}
+
+// Introspectable is implemented by strategies which expose their internal state, for example to health
+// checks and tests.
+type Introspectable interface {
+	// State returns a snapshot of the strategies state. State is safe to call concurrently with loads.
+	State() StrategyState
+}
+
+// Named is implemented by strategies which report a name, for example in traces (see BatchInfo).
+type Named interface {
+	// Name returns the name of the strategy
+	Name() string
+}
@@ -0,0 +1,51 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyResult pairs a key with its result, e.g. for the results delivered by a ThunkManyStream
+type KeyResult struct {
+	Key    Key
+	Result Result
+}
+
+// ThunkManyStream returns a channel which receives the result of each key as soon as it resolves, so that
+// callers can process early results of a large LoadMany before every batch completes. The channel is closed
+// once every key was delivered. Calling ThunkManyStream again returns the same channel.
+type ThunkManyStream func() <-chan KeyResult
+
+// LoadManyStream loads each key like Load and returns a ThunkManyStream delivering the results in the order
+// they resolve. Keys resolved from the cache are delivered first, keys split across multiple batches (e.g.
+// because they exceed the strategies capacity) as each batch returns. Keys without a result are delivered
+// with a NotFoundError.
+func (d *dataloader) LoadManyStream(ctx context.Context, keyArr ...Key) ThunkManyStream {
+	thunks := make([]Thunk, len(keyArr))
+	for i, key := range keyArr {
+		thunks[i] = d.Load(ctx, key)
+	}
+
+	// buffered so that the results of an abandoned stream don't block their go routines
+	resultChan := make(chan KeyResult, len(keyArr))
+	var once sync.Once
+	return func() <-chan KeyResult {
+		once.Do(func() {
+			var wg sync.WaitGroup
+			wg.Add(len(thunks))
+			for i := range thunks {
+				go func(key Key, thunk Thunk) {
+					defer wg.Done()
+
+					resultChan <- KeyResult{Key: key, Result: resolve(key, thunk)}
+				}(keyArr[i], thunks[i])
+			}
+
+			go func() {
+				wg.Wait()
+				close(resultChan)
+			}()
+		})
+		return resultChan
+	}
+}
@@ -0,0 +1,50 @@
+package dataloader_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadManyStream ensures results are delivered as they resolve and the channel is closed once every key
+// was delivered
+func TestLoadManyStream(t *testing.T) {
+	// setup
+	release := make(chan struct{})
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			switch key := k.(PrimaryKey); key {
+			case 2:
+				<-release // key 2 resolves after the other keys were delivered
+				m.Set(key, dataloader.Result{Result: "slow", Err: nil})
+			case 1:
+				m.Set(key, dataloader.Result{Result: "fast", Err: nil})
+			}
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(3, batch, newMockStrategy())
+
+	// invoke
+	stream := loader.LoadManyStream(context.Background(), PrimaryKey(1), PrimaryKey(2), PrimaryKey(3))
+	results := stream()
+	early := map[string]dataloader.Result{}
+	for i := 0; i < 2; i++ {
+		r := <-results
+		early[r.Key.String()] = r.Result
+	}
+	close(release)
+	late := <-results
+	_, open := <-results
+
+	// assert
+	assert.Equal(t, "fast", early["1"].Result, "Expected the resolved key before the slow key")
+	assert.True(t, dataloader.IsNotFound(early["3"].Err), "Expected the missing key with a not found error")
+	assert.Equal(t, PrimaryKey(2), late.Key, "Expected the slow key last")
+	assert.Equal(t, "slow", late.Result.Result, "Expected the result of the slow key")
+	assert.False(t, open, "Expected the channel to be closed once every key was delivered")
+	assert.Equal(t, results, stream(), "Expected the same channel for later calls")
+}
@@ -0,0 +1,99 @@
+package dataloader
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// structKey is a Key which identifies a comparable struct by the hash of its fields
+type structKey struct {
+	value interface{}
+	id    string
+}
+
+// StructKey returns a Key for any comparable struct, e.g. the parameters of a query, without writing a
+// String() method. The string identity of the key is derived from the type of the struct and a hash of
+// its fields, so equal structs share the same identity across processes. Pointer fields are hashed by the
+// value they point to. Raw returns the struct.
+//
+// StructKey panics if the value isn't a comparable struct.
+func StructKey(v interface{}) Key {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct || !t.Comparable() {
+		panic(fmt.Sprintf("dataloader: StructKey of %T, expected a comparable struct", v))
+	}
+
+	h := fnv.New128a()
+	hashValue(h, reflect.ValueOf(v))
+	return structKey{value: v, id: t.String() + "#" + hex.EncodeToString(h.Sum(nil))}
+}
+
+func (k structKey) String() string {
+	return k.id
+}
+
+func (k structKey) Raw() interface{} {
+	return k.value
+}
+
+// ================================== private ==================================
+
+// hashValue writes a deterministic encoding of the value to the hash. Variable length values are prefixed
+// with their length so adjacent fields can't collide.
+func hashValue(h hash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		writeUint(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeString(v.String())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeString(v.Type().Field(i).Name)
+			hashValue(h, v.Field(i))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+		writeUint(1)
+		if v.Kind() == reflect.Interface {
+			writeString(v.Elem().Type().String())
+		}
+		hashValue(h, v.Elem())
+	default: // channels and unsafe pointers are hashed by their address
+		writeUint(uint64(v.Pointer()))
+	}
+}
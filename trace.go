@@ -80,6 +80,9 @@ func (*openTracer) LoadMany(ctx context.Context, keyArr []Key) (context.Context,
 
 func (*openTracer) Batch(ctx context.Context) (context.Context, BatchFinishFunc) {
 	span, spanCtx := opentracing.StartSpanFromContext(ctx, "Dataloader: batch")
+	if id, ok := BatchIDFromContext(ctx); ok {
+		span.SetTag("dataloader.batch_id", id)
+	}
 
 	return spanCtx, func(r ResultMap) {
 		span.SetTag("keys", fmt.Sprintf("[%s]", strings.Join(r.Keys(), ", ")))
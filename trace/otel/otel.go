@@ -0,0 +1,131 @@
+/*
+Package otel contains a tracer which emits OpenTelemetry spans for calls to Load, LoadMany and the batch
+function.
+
+The batch span is started with the context the strategy passes to the batch function. The bundled
+strategies use the context of the first load in the batch, so the batch span is a child of that loads
+span, making the resolution of N+1 queries visible in traces.
+
+	loader := dataloader.NewDataLoader(
+		100,
+		batch,
+		standard.NewStandardStrategy(),
+		dataloader.WithTracer(otel.NewTracer()),
+	)
+*/
+package otel
+
+import (
+	"context"
+
+	"github.com/andy9775/dataloader"
+
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans emitted by the tracer
+const instrumentationName = "github.com/andy9775/dataloader"
+
+// options contains the configuration of the OpenTelemetry tracer
+type options struct {
+	provider trace.TracerProvider
+}
+
+// Option accepts the tracer options and sets an option on it.
+type Option func(*options)
+
+// NewTracer returns a dataloader.Tracer which emits OpenTelemetry spans. Spans are created with the
+// global tracer provider unless WithTracerProvider is set.
+func NewTracer(opts ...Option) dataloader.Tracer {
+	o := options{}
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	if o.provider == nil {
+		o.provider = otelglobal.GetTracerProvider()
+	}
+
+	return &tracer{tracer: o.provider.Tracer(instrumentationName)}
+}
+
+// ============================================== option setters =============================================
+
+// WithTracerProvider sets the tracer provider used to create spans
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// ===========================================================================================================
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) Load(ctx context.Context, key dataloader.Key) (context.Context, dataloader.LoadFinishFunc) {
+	ctx, span := t.tracer.Start(ctx, "dataloader.load", trace.WithAttributes(
+		attribute.String("dataloader.key", key.String()),
+	))
+
+	return ctx, func(r dataloader.Result) {
+		if r.Err != nil {
+			span.RecordError(r.Err)
+			span.SetStatus(codes.Error, r.Err.Error())
+		}
+		span.End()
+	}
+}
+
+func (t *tracer) LoadMany(
+	ctx context.Context,
+	keyArr []dataloader.Key,
+) (context.Context, dataloader.LoadManyFinishFunc) {
+	ctx, span := t.tracer.Start(ctx, "dataloader.loadMany", trace.WithAttributes(
+		attribute.Int("dataloader.key_count", len(keyArr)),
+	))
+
+	return ctx, func(r dataloader.ResultMap) {
+		if errs := countErrors(r); errs > 0 {
+			span.SetAttributes(attribute.Int("dataloader.errors", errs))
+			span.SetStatus(codes.Error, "one or more keys resolved to an error")
+		}
+		span.End()
+	}
+}
+
+func (t *tracer) Batch(ctx context.Context) (context.Context, dataloader.BatchFinishFunc) {
+	var attrs []attribute.KeyValue
+	if info, ok := dataloader.BatchInfoFromContext(ctx); ok {
+		attrs = append(attrs,
+			attribute.Int64("dataloader.batch_id", int64(info.BatchID)),
+			attribute.Int("dataloader.key_count", info.Size),
+			attribute.String("dataloader.strategy", info.Strategy),
+		)
+	}
+	ctx, span := t.tracer.Start(ctx, "dataloader.batch", trace.WithAttributes(attrs...))
+
+	return ctx, func(r dataloader.ResultMap) {
+		if errs := countErrors(r); errs > 0 {
+			span.SetAttributes(attribute.Int("dataloader.errors", errs))
+			span.SetStatus(codes.Error, "one or more keys resolved to an error")
+		}
+		span.End()
+	}
+}
+
+// ================================================= helpers =================================================
+
+func countErrors(r dataloader.ResultMap) int {
+	count := 0
+	for _, v := range r {
+		if v.Err != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+package otel_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/andy9775/dataloader"
+	"github.com/andy9775/dataloader/strategies/standard"
+	"github.com/andy9775/dataloader/trace/otel"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// ==================================== implement concrete keys interface ====================================
+type PrimaryKey int
+
+func (p PrimaryKey) String() string {
+	return strconv.Itoa(int(p))
+}
+
+func (p PrimaryKey) Raw() interface{} {
+	return p
+}
+
+// =============================================== test helpers ==============================================
+
+// attr returns the value of the attribute with the key
+func attr(span sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// ================================================== tests ==================================================
+
+// TestBatchSpanIsChildOfFirstLoad ensures the batch span is annotated and parented to the first load
+func TestBatchSpanIsChildOfFirstLoad(t *testing.T) {
+	// setup
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			m.Set(k.(dataloader.Key), dataloader.Result{Result: k.(dataloader.Key).String(), Err: nil})
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(
+		2,
+		batch,
+		standard.NewStandardStrategy(standard.WithTimeout(time.Second)),
+		dataloader.WithTracer(otel.NewTracer(otel.WithTracerProvider(provider))),
+	)
+
+	// invoke
+	first := loader.Load(context.Background(), PrimaryKey(1))
+	second := loader.Load(context.Background(), PrimaryKey(2))
+	first()
+	second()
+
+	// assert
+	var batchSpan, firstSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "dataloader.batch":
+			batchSpan = span
+		case "dataloader.load":
+			if v, _ := attr(span, "dataloader.key"); v.AsString() == "1" {
+				firstSpan = span
+			}
+		}
+	}
+	if !assert.NotNil(t, batchSpan, "Expected a batch span") || !assert.NotNil(t, firstSpan, "Expected a load span") {
+		return
+	}
+
+	assert.Equal(t, firstSpan.SpanContext().SpanID(), batchSpan.Parent().SpanID(),
+		"Expected the batch span to be a child of the first load span")
+
+	count, _ := attr(batchSpan, "dataloader.key_count")
+	assert.Equal(t, int64(2), count.AsInt64(), "Expected the key count attribute")
+
+	strategy, _ := attr(batchSpan, "dataloader.strategy")
+	assert.Equal(t, "standard", strategy.AsString(), "Expected the strategy name attribute")
+}
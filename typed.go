@@ -0,0 +1,198 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedBatchFunction is the type safe equivalent of the BatchFunction. It is called with the keys to
+// resolve and should return the values found for the keys. Keys missing from the returned map resolve
+// to an error. A non-nil error is returned for every key in the batch.
+type TypedBatchFunction[K comparable, V any] func(context.Context, []K) (map[K]V, error)
+
+// TypedThunk returns the value for the key that it was generated for.
+// Calling the TypedThunk function will block until the result is returned from the batch function.
+type TypedThunk[V any] func() (V, error)
+
+// TypedThunkMany returns the values for the keys that it was generated for.
+// Calling TypedThunkMany will block until the result is returned from the batch function.
+type TypedThunkMany[K comparable, V any] func() (map[K]V, error)
+
+// Loader is a type safe facade over a DataLoader. Internally each key is wrapped in a Key and each
+// value in a Result so that the existing strategies, caches and tracers can be used.
+type Loader[K comparable, V any] struct {
+	loader DataLoader
+}
+
+// NewLoader returns a new type safe Loader. The capacity, strategy and options behave the same as
+// those passed to NewDataLoader.
+func NewLoader[K comparable, V any](
+	capacity int,
+	batch TypedBatchFunction[K, V],
+	fn StrategyFunction,
+	opts ...Option,
+) *Loader[K, V] {
+	batchFunc := func(ctx context.Context, keys Keys) *ResultMap {
+		raw := keys.Keys()
+		typedKeys := make([]K, 0, len(raw))
+		for _, k := range raw {
+			typedKeys = append(typedKeys, k.(K))
+		}
+
+		values, err := batch(ctx, typedKeys)
+
+		r := NewResultMap(len(typedKeys))
+		for _, k := range typedKeys {
+			if err != nil {
+				r.Set(typedKey[K]{k}, Result{Result: nil, Err: err})
+				continue
+			}
+			if v, ok := values[k]; ok {
+				r.Set(typedKey[K]{k}, Result{Result: v, Err: nil})
+			}
+		}
+		return &r
+	}
+
+	return &Loader[K, V]{loader: NewDataLoader(capacity, batchFunc, fn, opts...)}
+}
+
+// Load returns the value for the provided key. Load blocks until the value is resolved.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.Thunk(ctx, key)()
+}
+
+// Thunk returns a TypedThunk for the provided key. Thunk does not block the caller.
+func (l *Loader[K, V]) Thunk(ctx context.Context, key K) TypedThunk[V] {
+	thunk := l.loader.Load(ctx, typedKey[K]{key})
+
+	return func() (V, error) {
+		r, ok := thunk()
+		return typedValue[K, V](key, r, ok)
+	}
+}
+
+// LoadMany returns a TypedThunkMany for the provided keys. LoadMany does not block the caller.
+// The TypedThunkMany returns the values found for the keys and a KeyErrors error containing the
+// error for each key which failed to resolve.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys ...K) TypedThunkMany[K, V] {
+	keyArr := make([]Key, 0, len(keys))
+	for _, k := range keys {
+		keyArr = append(keyArr, typedKey[K]{k})
+	}
+	thunkMany := l.loader.LoadMany(ctx, keyArr...)
+
+	return func() (map[K]V, error) {
+		r := thunkMany()
+
+		errs := KeyErrors{}
+		values := make(map[K]V, len(keys))
+		for _, k := range keys {
+			key := typedKey[K]{k}
+			result, ok := r.GetValue(key)
+			v, err := typedValue[K, V](k, result, ok)
+			if err != nil {
+				errs[key.String()] = err
+				continue
+			}
+			values[k] = v
+		}
+
+		if len(errs) > 0 {
+			return values, errs
+		}
+		return values, nil
+	}
+}
+
+// DataLoader returns the underlying DataLoader
+func (l *Loader[K, V]) DataLoader() DataLoader {
+	return l.loader
+}
+
+// View asserts each result in the ResultMap to V and returns the typed values. Results which contain an
+// error or whose value is not of type V are omitted from the returned map and their errors are returned
+// as a KeyErrors error.
+func View[V any](rm ResultMap) (map[string]V, error) {
+	errs := KeyErrors{}
+	values := make(map[string]V, len(rm))
+	for k, r := range rm {
+		v, err := typedValue[string, V](k, r, true)
+		if err != nil {
+			errs[k] = err
+			continue
+		}
+		values[k] = v
+	}
+
+	if len(errs) > 0 {
+		return values, errs
+	}
+	return values, nil
+}
+
+// As asserts the value of the result to T, e.g. name, err := dataloader.As[string](r). It returns the error
+// of the result, or an error if the value is not of type T. A nil value returns the zero value of T.
+func As[T any](r Result) (T, error) {
+	var v T
+	if r.Err != nil {
+		return v, r.Err
+	}
+	if r.Result == nil {
+		return v, nil
+	}
+
+	v, ok := r.Result.(T)
+	if !ok {
+		return v, fmt.Errorf("dataloader: result has type %T, expected %T", r.Result, v)
+	}
+	return v, nil
+}
+
+// GetAs returns the value of the key in the ResultMap asserted to T. It returns an error if the ResultMap
+// has no result for the key, the result contains an error or its value is not of type T.
+func GetAs[T any](rm ResultMap, key Key) (T, error) {
+	r, ok := rm.GetValue(key)
+	var id interface{}
+	if key != nil {
+		id = key.String()
+	}
+	return typedValue[interface{}, T](id, r, ok)
+}
+
+// ================================================= helpers =================================================
+
+// typedKey wraps a comparable value and implements the Key interface
+type typedKey[K comparable] struct {
+	key K
+}
+
+// String returns the type and the Go syntax representation of the key, e.g. int:1 or string:"1", so that
+// values of different types, or values which format the same with fmt.Sprint, don't share an identifier
+func (k typedKey[K]) String() string {
+	return fmt.Sprintf("%T:%#v", k.key, k.key)
+}
+
+func (k typedKey[K]) Raw() interface{} {
+	return k.key
+}
+
+// typedValue converts the result for the key into the typed value
+func typedValue[K any, V any](key K, r Result, ok bool) (V, error) {
+	var v V
+	if !ok {
+		return v, &NotFoundError{Key: fmt.Sprint(key)}
+	}
+	if r.Err != nil {
+		return v, r.Err
+	}
+	if r.Result == nil {
+		return v, nil
+	}
+
+	v, ok = r.Result.(V)
+	if !ok {
+		return v, fmt.Errorf("dataloader: result for key %v has type %T, expected %T", key, r.Result, v)
+	}
+	return v, nil
+}
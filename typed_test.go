@@ -0,0 +1,170 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTypedLoad ensures the typed loader returns typed values
+func TestTypedLoad(t *testing.T) {
+	// setup
+	var called [][]int
+	batch := func(ctx context.Context, keys []int) (map[int]string, error) {
+		called = append(called, keys)
+		values := make(map[int]string, len(keys))
+		for _, k := range keys {
+			if k != 3 { // 3 is missing
+				values[k] = "value"
+			}
+		}
+		return values, nil
+	}
+	loader := dataloader.NewLoader[int, string](1, batch, newMockStrategy())
+
+	// invoke / assert
+	v, err := loader.Load(context.Background(), 1)
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, "value", v, "Expected typed value")
+
+	values, err := loader.LoadMany(context.Background(), 1, 2, 3)()
+	assert.Error(t, err, "Expected error for missing key")
+	assert.Equal(t, map[int]string{1: "value", 2: "value"}, values, "Expected typed values for found keys")
+	assert.Equal(t, [][]int{{1}, {1, 2, 3}}, called, "Expected batch function to receive typed keys")
+}
+
+// TestTypedLoadError ensures a batch error is returned for the keys
+func TestTypedLoadError(t *testing.T) {
+	// setup
+	expectedErr := errors.New("batch failed")
+	batch := func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, expectedErr
+	}
+	loader := dataloader.NewLoader[string, int](1, batch, newMockStrategy())
+
+	// invoke / assert
+	v, err := loader.Thunk(context.Background(), "key")()
+	assert.Equal(t, expectedErr, err, "Expected batch error")
+	assert.Equal(t, 0, v, "Expected zero value")
+}
+
+// TestTypedKeysFormattedAlike ensures distinct keys which format the same with fmt.Sprint are loaded as
+// different keys
+func TestTypedKeysFormattedAlike(t *testing.T) {
+	// setup
+	type pair struct{ A, B string }
+	batch := func(ctx context.Context, keys []pair) (map[pair]string, error) {
+		values := make(map[pair]string, len(keys))
+		for _, k := range keys {
+			values[k] = k.A + "|" + k.B
+		}
+		return values, nil
+	}
+	loader := dataloader.NewLoader[pair, string](2, batch, newMockStrategy())
+	first, second := pair{A: "a b", B: ""}, pair{A: "a", B: "b "}
+
+	// invoke
+	values, err := loader.LoadMany(context.Background(), first, second)()
+
+	// assert
+	assert.Equal(t, fmt.Sprint(first), fmt.Sprint(second), "Expected the keys to format the same")
+	assert.Nil(t, err, "Expected no error")
+	assert.Equal(t, map[pair]string{first: "a b|", second: "a|b "}, values, "Expected a value for each key")
+}
+
+// TestView ensures View returns the typed values and aggregates the failures
+func TestView(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+	rm := dataloader.NewResultMap(3)
+	rm.Set(PrimaryKey(1), dataloader.Result{Result: "one", Err: nil})
+	rm.Set(PrimaryKey(2), dataloader.Result{Result: 2, Err: nil})
+	rm.Set(PrimaryKey(3), dataloader.Result{Result: nil, Err: expectedErr})
+
+	// invoke
+	values, err := dataloader.View[string](rm)
+
+	// assert
+	assert.Equal(t, map[string]string{"1": "one"}, values, "Expected typed values")
+	errs, ok := err.(dataloader.KeyErrors)
+	assert.True(t, ok, "Expected KeyErrors")
+	assert.Len(t, errs, 2, "Expected an error for each failed result")
+	assert.Equal(t, expectedErr, errs["3"], "Expected result error")
+}
+
+// TestRegistryTypedLoader ensures type safe loaders are retrieved from the registry with their types
+func TestRegistryTypedLoader(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys []int) (map[int]string, error) {
+		values := make(map[int]string, len(keys))
+		for _, k := range keys {
+			values[k] = "value"
+		}
+		return values, nil
+	}
+	registry := dataloader.NewRegistry()
+	dataloader.RegisterLoader(registry, "users", func(ctx context.Context) *dataloader.Loader[int, string] {
+		return dataloader.NewLoader[int, string](1, batch, newMockStrategy())
+	})
+	ctx := registry.NewContext(context.Background())
+
+	// invoke
+	loader, err := dataloader.GetLoader[int, string](registry, ctx, "users")
+	_, typeErr := dataloader.GetLoader[string, string](registry, ctx, "users")
+	untyped, untypedErr := registry.Loader(ctx, "users")
+
+	// assert
+	if assert.NoError(t, err, "Expected no error") {
+		v, err := loader.Load(ctx, 1)
+		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, "value", v, "Expected the typed value")
+	}
+	assert.Error(t, typeErr, "Expected an error for a loader of another type")
+	assert.NoError(t, untypedErr, "Expected no error")
+	assert.True(t, untyped == loader.DataLoader(), "Expected the DataLoader of the typed loader")
+}
+
+// TestAs ensures the value of a result is asserted to the type and failures are returned as errors
+func TestAs(t *testing.T) {
+	// setup
+	expectedErr := errors.New("failed")
+
+	// invoke
+	v, err := dataloader.As[string](dataloader.Result{Result: "value", Err: nil})
+	_, resultErr := dataloader.As[string](dataloader.Result{Result: "value", Err: expectedErr})
+	_, typeErr := dataloader.As[string](dataloader.Result{Result: 1, Err: nil})
+	zero, nilErr := dataloader.As[*int](dataloader.Result{Result: nil, Err: nil})
+
+	// assert
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, "value", v, "Expected the typed value")
+	assert.Equal(t, expectedErr, resultErr, "Expected the error of the result")
+	assert.EqualError(t, typeErr, "dataloader: result has type int, expected string", "Expected a type error")
+	assert.NoError(t, nilErr, "Expected no error for a nil value")
+	assert.Nil(t, zero, "Expected the zero value for a nil value")
+}
+
+// TestGetAs ensures the value of a key is asserted to the type and failures are returned as errors
+func TestGetAs(t *testing.T) {
+	// setup
+	rm := dataloader.NewResultMap(2)
+	rm.Set(PrimaryKey(1), dataloader.Result{Result: "value", Err: nil})
+	rm.Set(PrimaryKey(2), dataloader.Result{Result: 2, Err: nil})
+
+	// invoke
+	v, err := dataloader.GetAs[string](rm, PrimaryKey(1))
+	_, typeErr := dataloader.GetAs[string](rm, PrimaryKey(2))
+	_, missingErr := dataloader.GetAs[string](rm, PrimaryKey(3))
+	_, nilErr := dataloader.GetAs[string](rm, nil)
+
+	// assert
+	assert.NoError(t, err, "Expected no error")
+	assert.Equal(t, "value", v, "Expected the typed value")
+	assert.EqualError(t, typeErr, "dataloader: result for key 2 has type int, expected string", "Expected a type error")
+	assert.EqualError(t, missingErr, "dataloader: no result found for key 3", "Expected a missing result error")
+	assert.Error(t, nilErr, "Expected an error for a nil key")
+}
@@ -0,0 +1,39 @@
+package dataloader
+
+import "sync"
+
+// WaitAll calls the thunks concurrently and returns their results in the order of the thunks, e.g. to fan
+// out the loads of a resolver and gather the results once all of them resolved. A thunk without a result
+// resolves to ErrNotFound.
+func WaitAll(thunks ...Thunk) []Result {
+	results := make([]Result, len(thunks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(thunks))
+	for i := range thunks {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = resolve(nil, thunks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// WaitAllMany calls the thunks concurrently and returns their result maps in the order of the thunks
+func WaitAllMany(thunks ...ThunkMany) []ResultMap {
+	results := make([]ResultMap, len(thunks))
+
+	var wg sync.WaitGroup
+	wg.Add(len(thunks))
+	for i := range thunks {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = thunks[i]()
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
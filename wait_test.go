@@ -0,0 +1,68 @@
+package dataloader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/andy9775/dataloader"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWaitAll ensures the thunks are resolved concurrently and the results are returned in their order
+func TestWaitAll(t *testing.T) {
+	// setup
+	var started sync.WaitGroup
+	started.Add(2)
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		started.Done()
+		started.Wait() // blocks unless both keys are batched concurrently
+		m := dataloader.NewResultMap(1)
+		key := keys.Keys()[0].(PrimaryKey)
+		m.Set(key, dataloader.Result{Result: key.String(), Err: nil})
+		return &m
+	}
+	loader := dataloader.NewDataLoader(1, batch, newMockStrategy())
+	missing := func() (dataloader.Result, bool) { return dataloader.Result{}, false }
+
+	// invoke
+	results := dataloader.WaitAll(
+		loader.Load(context.Background(), PrimaryKey(2)),
+		loader.Load(context.Background(), PrimaryKey(1)),
+		missing,
+	)
+
+	// assert
+	if !assert.Len(t, results, 3, "Expected a result for each thunk") {
+		return
+	}
+	assert.Equal(t, "2", results[0].Result, "Expected the results in the order of the thunks")
+	assert.Equal(t, "1", results[1].Result, "Expected the results in the order of the thunks")
+	assert.Equal(t, dataloader.ErrNotFound, results[2].Err, "Expected a not found error for the missing result")
+}
+
+// TestWaitAllMany ensures the result maps are returned in the order of the thunks
+func TestWaitAllMany(t *testing.T) {
+	// setup
+	batch := func(ctx context.Context, keys dataloader.Keys) *dataloader.ResultMap {
+		m := dataloader.NewResultMap(keys.Length())
+		for _, k := range keys.Keys() {
+			m.Set(k.(PrimaryKey), dataloader.Result{Result: k.(PrimaryKey).String(), Err: nil})
+		}
+		return &m
+	}
+	loader := dataloader.NewDataLoader(2, batch, newMockStrategy())
+
+	// invoke
+	results := dataloader.WaitAllMany(
+		loader.LoadMany(context.Background(), PrimaryKey(1), PrimaryKey(2)),
+		loader.LoadMany(context.Background(), PrimaryKey(3)),
+	)
+
+	// assert
+	if !assert.Len(t, results, 2, "Expected a result map for each thunk") {
+		return
+	}
+	assert.Equal(t, []string{"1", "2"}, results[0].Keys(), "Expected the results of the first thunk")
+	assert.Equal(t, []string{"3"}, results[1].Keys(), "Expected the results of the second thunk")
+}
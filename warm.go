@@ -0,0 +1,28 @@
+package dataloader
+
+import "context"
+
+// warm stores the results returned by the batch function for keys which were not in the batch (e.g. rows
+// fetched by a join) in the loaders cache and the sibling caches.
+func (d *dataloader) warm(ctx context.Context, keys Keys, r ResultMap) {
+	requested := make(map[string]bool, keys.Length())
+	for _, k := range keys.StringKeys() {
+		requested[k] = true
+	}
+
+	unsolicited := NewResultMap(0)
+	for k, v := range r {
+		if !requested[k] {
+			unsolicited[k] = v
+		}
+	}
+	if len(unsolicited) == 0 {
+		return
+	}
+
+	d.logger.Debug("warming caches", "results", len(unsolicited))
+	d.cache.SetResultMap(ctx, unsolicited)
+	for _, c := range d.warmCaches {
+		c.SetResultMap(ctx, unsolicited)
+	}
+}